@@ -0,0 +1,517 @@
+package smb2
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// TarGz writes the tree rooted at root to w as a gzip-compressed tar archive.
+// Entries are named by their slash-separated paths relative to root, and keep their modification times and modes.
+// Symbolic links are archived as links, they aren't followed.
+// File contents are streamed from the reads into the archive, files aren't buffered as a whole.
+// If root is a file, the archive only contains the file.
+func (fs *Share) TarGz(w io.Writer, root string) error {
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+
+	var buf []byte
+
+	err := fs.walkTree(root, func(name, rel string, fi os.FileInfo, link string) error {
+		hdr, err := tar.FileInfoHeader(fi, link)
+		if err != nil {
+			return &os.PathError{Op: "tar", Path: name, Err: err}
+		}
+		hdr.Name = rel
+		if fi.IsDir() {
+			hdr.Name += "/"
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			return nil
+		}
+
+		return fs.copyFileTo(tw, name, hdr.Size, &buf)
+	})
+	if err != nil {
+		tw.Close()
+		zw.Close()
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		zw.Close()
+		return err
+	}
+
+	return zw.Close()
+}
+
+// Zip writes the tree rooted at root to w as a zip archive, like TarGz.
+// Files are deflated. Symbolic links are stored as entries of mode os.ModeSymlink whose content is the target.
+func (fs *Share) Zip(w io.Writer, root string) error {
+	zw := zip.NewWriter(w)
+
+	var buf []byte
+
+	err := fs.walkTree(root, func(name, rel string, fi os.FileInfo, link string) error {
+		hdr, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return &os.PathError{Op: "zip", Path: name, Err: err}
+		}
+		hdr.Name = rel
+
+		switch {
+		case fi.IsDir():
+			hdr.Name += "/"
+			hdr.Method = zip.Store
+		case link != "":
+			hdr.Method = zip.Store
+		default:
+			hdr.Method = zip.Deflate
+		}
+
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case fi.IsDir():
+			return nil
+		case link != "":
+			_, err = io.WriteString(fw, link)
+			return err
+		}
+
+		return fs.copyFileTo(fw, name, fi.Size(), &buf)
+	})
+	if err != nil {
+		zw.Close()
+		return err
+	}
+
+	return zw.Close()
+}
+
+// walkTree calls fn for every file and directory under root, parents first, in lexical order.
+// fn gets the path on the share, the slash-separated path relative to root and, if the entry is a symbolic link, its target.
+// If root is a file, fn is only called for it with its base name as the relative path.
+// Symbolic links aren't followed. Other reparse points (e.g. mount points) are passed as regular entries,
+// but directories aren't descended into, so that the walk can't loop.
+func (fs *Share) walkTree(root string, fn func(name, rel string, fi os.FileInfo, link string) error) error {
+	root, err := cleanPath("walk", root)
+	if err != nil {
+		return err
+	}
+
+	fi, err := fs.Lstat(root)
+	if err != nil {
+		return err
+	}
+
+	if !fi.IsDir() {
+		return fs.walkEntry(root, base(root), fi, fn)
+	}
+
+	return fs.walkDir(root, "", fn)
+}
+
+func (fs *Share) walkDir(name, rel string, fn func(name, rel string, fi os.FileInfo, link string) error) error {
+	fis, err := fs.ReadDir(name)
+	if err != nil {
+		return err
+	}
+
+	for _, fi := range fis {
+		childName := fi.Name()
+		if name != "" {
+			childName = name + string(PathSeparator) + childName
+		}
+
+		if err := fs.walkEntry(childName, path.Join(rel, fi.Name()), fi, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (fs *Share) walkEntry(name, rel string, fi os.FileInfo, fn func(name, rel string, fi os.FileInfo, link string) error) error {
+	if fi.Mode()&os.ModeSymlink != 0 {
+		if link, err := fs.Readlink(name); err == nil {
+			return fn(name, rel, fi, link)
+		}
+
+		// a junction isn't a symbolic link, it's archived as an empty directory
+		mode := fi.Mode() &^ os.ModeSymlink
+		if st, ok := fi.Sys().(*FileStat); ok && st.FileAttributes&FILE_ATTRIBUTE_DIRECTORY != 0 {
+			mode |= os.ModeDir | 0111
+		}
+
+		return fn(name, rel, &modeFileInfo{fi, mode}, "")
+	}
+
+	if err := fn(name, rel, fi, ""); err != nil {
+		return err
+	}
+
+	if fi.IsDir() {
+		return fs.walkDir(name, rel, fn)
+	}
+
+	return nil
+}
+
+// modeFileInfo overrides the mode of a os.FileInfo.
+type modeFileInfo struct {
+	os.FileInfo
+	mode os.FileMode
+}
+
+func (fi *modeFileInfo) Mode() os.FileMode {
+	return fi.mode
+}
+
+func (fi *modeFileInfo) IsDir() bool {
+	return fi.mode.IsDir()
+}
+
+// copyFileTo copies size bytes of the named file to w.
+// *buf is allocated by the first call, and reused by the following ones.
+func (fs *Share) copyFileTo(w io.Writer, name string, size int64, buf *[]byte) error {
+	f, err := fs.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if *buf == nil {
+		*buf = make([]byte, f.maxReadSize())
+	}
+
+	n, err := copyBuffer(io.LimitReader(f, size), w, *buf)
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return &os.PathError{Op: "read", Path: name, Err: io.ErrUnexpectedEOF}
+	}
+
+	return nil
+}
+
+// UntarGz extracts the gzip-compressed tar archive read from r under the directory dest,
+// which is created if necessary, like the parent directories of the entries.
+// Files are created with the permissions of the entries, and the modification times are restored.
+// Symbolic links are created as reparse points. Other entries, such as hard links and devices, are skipped.
+// Entries whose path or link target would escape dest (e.g. "../a" or "/a") are rejected with os.ErrInvalid,
+// and so are the ones going through a link extracted before, which could lead anywhere.
+func (fs *Share) UntarGz(r io.Reader, dest string) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	x, err := fs.newExtractor(dest)
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(zr)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = x.dir(hdr.Name, os.FileMode(hdr.Mode).Perm(), hdr.ModTime)
+		case tar.TypeReg, tar.TypeRegA:
+			err = x.file(hdr.Name, os.FileMode(hdr.Mode).Perm(), hdr.ModTime, tr)
+		case tar.TypeSymlink:
+			err = x.symlink(hdr.Name, hdr.Linkname)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return x.close()
+}
+
+// Unzip extracts the zip archive of size bytes read from r under the directory dest, like UntarGz.
+// Symbolic links are entries of mode os.ModeSymlink whose content is the target.
+func (fs *Share) Unzip(r io.ReaderAt, size int64, dest string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	x, err := fs.newExtractor(dest)
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		mode := zf.Mode()
+
+		switch {
+		case mode.IsDir():
+			err = x.dir(zf.Name, mode.Perm(), zf.Modified)
+		case mode.IsRegular():
+			err = x.unzipFile(zf)
+		case mode&os.ModeSymlink != 0:
+			err = x.unzipSymlink(zf)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return x.close()
+}
+
+// extractor creates the entries of an archive under dest.
+// The modification times of directories are restored by close,
+// since creating the entries under a directory updates it.
+type extractor struct {
+	fs   *Share
+	dest string
+
+	dirs   []string
+	mtimes []time.Time
+
+	links map[string]bool // lower-cased paths of the extracted links relative to dest
+}
+
+func (fs *Share) newExtractor(dest string) (*extractor, error) {
+	dest, err := cleanPath("extract", dest)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fs.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+
+	return &extractor{fs: fs, dest: dest, links: make(map[string]bool)}, nil
+}
+
+// path returns the path on the share of the entry name,
+// or os.ErrInvalid if name is absolute, escapes dest or goes through a link extracted before.
+func (x *extractor) path(name string) (string, error) {
+	rel, err := archivePath(name)
+	if err == nil {
+		err = walkArchivePath(strings.Replace(name, `\`, "/", -1), x.links, true)
+	}
+	if err != nil {
+		return "", &os.PathError{Op: "extract", Path: name, Err: err}
+	}
+
+	rel = strings.Replace(rel, "/", `\`, -1)
+
+	if x.dest == "" {
+		return rel, nil
+	}
+
+	return x.dest + `\` + rel, nil
+}
+
+// archivePath returns the slash-separated clean form of the entry name,
+// or os.ErrInvalid if it's absolute or escapes the top directory.
+func archivePath(name string) (string, error) {
+	name = strings.Replace(name, `\`, "/", -1)
+
+	if strings.HasPrefix(name, "/") || strings.Contains(name, ":") {
+		return "", os.ErrInvalid
+	}
+
+	name = path.Clean(name)
+
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return "", os.ErrInvalid
+	}
+
+	return name, nil
+}
+
+// checkArchiveLink returns os.ErrInvalid if target is absolute or,
+// relatively to the entry name, escapes the top directory.
+func checkArchiveLink(name, target string) error {
+	target = strings.Replace(target, `\`, "/", -1)
+
+	if strings.HasPrefix(target, "/") || strings.Contains(target, ":") {
+		return os.ErrInvalid
+	}
+
+	rel, err := archivePath(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = archivePath(path.Join(path.Dir(rel), target))
+
+	return err
+}
+
+// walkArchivePath walks the components of the slash-separated path p relative to the top directory
+// the way the server resolves them, and returns os.ErrInvalid if it escapes the top directory
+// or goes through one of links, whose targets the lexical form of p doesn't account for.
+// If last is false, the last component may be one of links, which are checked on their own.
+func walkArchivePath(p string, links map[string]bool, last bool) error {
+	var elems []string
+
+	parts := strings.Split(p, "/")
+	for i, e := range parts {
+		switch e {
+		case "", ".":
+			continue
+		case "..":
+			if len(elems) == 0 {
+				return os.ErrInvalid
+			}
+			elems = elems[:len(elems)-1]
+			continue
+		}
+
+		elems = append(elems, e)
+
+		if (last || i < len(parts)-1) && links[strings.ToLower(strings.Join(elems, "/"))] {
+			return os.ErrInvalid
+		}
+	}
+
+	return nil
+}
+
+func (x *extractor) dir(name string, perm os.FileMode, mtime time.Time) error {
+	p, err := x.path(name)
+	if err != nil {
+		return err
+	}
+
+	if err := x.fs.MkdirAll(p, perm); err != nil {
+		return err
+	}
+
+	if !mtime.IsZero() {
+		x.dirs = append(x.dirs, p)
+		x.mtimes = append(x.mtimes, mtime)
+	}
+
+	return nil
+}
+
+func (x *extractor) file(name string, perm os.FileMode, mtime time.Time, r io.Reader) error {
+	p, err := x.path(name)
+	if err != nil {
+		return err
+	}
+
+	if err := x.fs.MkdirAll(dir(p), 0755); err != nil {
+		return err
+	}
+
+	f, err := x.fs.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, r)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return err
+	}
+
+	if !mtime.IsZero() {
+		return x.fs.Chtimes(p, mtime, mtime)
+	}
+
+	return nil
+}
+
+func (x *extractor) unzipFile(zf *zip.File) error {
+	r, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return x.file(zf.Name, zf.Mode().Perm(), zf.Modified, r)
+}
+
+func (x *extractor) symlink(name, target string) error {
+	p, err := x.path(name)
+	if err != nil {
+		return err
+	}
+
+	rel, _ := archivePath(name) // checked by x.path
+
+	// the link must not give a way out of dest to the following entries, neither by itself
+	// nor through the links extracted before
+	err = checkArchiveLink(name, target)
+	if err == nil {
+		err = walkArchivePath(path.Dir(rel)+"/"+strings.Replace(target, `\`, "/", -1), x.links, false)
+	}
+	if err != nil {
+		return &os.LinkError{Op: "extract", Old: target, New: name, Err: err}
+	}
+
+	if err := x.fs.MkdirAll(dir(p), 0755); err != nil {
+		return err
+	}
+
+	if err := x.fs.Symlink(target, p); err != nil {
+		return err
+	}
+
+	x.links[strings.ToLower(rel)] = true
+
+	return nil
+}
+
+func (x *extractor) unzipSymlink(zf *zip.File) error {
+	r, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	target, err := ioutil.ReadAll(io.LimitReader(r, 32*1024))
+	if err != nil {
+		return err
+	}
+
+	return x.symlink(zf.Name, string(target))
+}
+
+func (x *extractor) close() error {
+	for i := len(x.dirs) - 1; i >= 0; i-- {
+		if err := x.fs.Chtimes(x.dirs[i], x.mtimes[i], x.mtimes[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package smb2
+
+import (
+	"os"
+	"testing"
+)
+
+func TestArchivePath(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		path string
+		err  error
+	}{
+		{"a", "a", nil},
+		{"a/b/", "a/b", nil},
+		{"./a//b", "a/b", nil},
+		{`a\b`, "a/b", nil},
+		{"a/../b", "b", nil},
+		{"..", "", os.ErrInvalid},
+		{"../a", "", os.ErrInvalid},
+		{"a/../../b", "", os.ErrInvalid},
+		{`..\a`, "", os.ErrInvalid},
+		{"/a", "", os.ErrInvalid},
+		{`\a`, "", os.ErrInvalid},
+		{"c:/a", "", os.ErrInvalid},
+		{"a:stream", "", os.ErrInvalid},
+		{".", "", os.ErrInvalid},
+	} {
+		p, err := archivePath(tt.name)
+		if err != tt.err || p != tt.path {
+			t.Errorf("%q: expected %q, %v, got %q, %v", tt.name, tt.path, tt.err, p, err)
+		}
+	}
+}
+
+func TestCheckArchiveLink(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		target string
+		err    error
+	}{
+		{"a", "b", nil},
+		{"a/b", "../c", nil},
+		{"a/b/c", `..\..\d`, nil},
+		{"a", "../b", os.ErrInvalid},
+		{"a/b", "../../c", os.ErrInvalid},
+		{"a", "/etc", os.ErrInvalid},
+		{"a", `\etc`, os.ErrInvalid},
+		{"a", `c:\windows`, os.ErrInvalid},
+		{"../a", "b", os.ErrInvalid},
+	} {
+		if err := checkArchiveLink(tt.name, tt.target); err != tt.err {
+			t.Errorf("%q -> %q: expected %v, got %v", tt.name, tt.target, tt.err, err)
+		}
+	}
+}
+
+func TestWalkArchivePath(t *testing.T) {
+	// a -> ., d/l -> ..
+	links := map[string]bool{"a": true, "d/l": true}
+
+	for _, tt := range []struct {
+		path string
+		last bool
+		err  error
+	}{
+		{"b/c", true, nil},
+		{"d/x", true, nil},
+		{"d/../b", true, nil},
+		{"../b", true, os.ErrInvalid},
+		{"a/../../etc/x", true, os.ErrInvalid},
+		{"a/x", true, os.ErrInvalid},
+		{"a", true, os.ErrInvalid},
+		{"D/L/x", true, os.ErrInvalid},
+		// link targets, e.g. x -> d/l/.. chained to d/l escapes the top directory
+		{"./d/l/..", false, os.ErrInvalid},
+		{"./d/l", false, nil},
+		{"d/a", false, nil},
+	} {
+		if err := walkArchivePath(tt.path, links, tt.last); err != tt.err {
+			t.Errorf("%q: expected %v, got %v", tt.path, tt.err, err)
+		}
+	}
+}
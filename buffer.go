@@ -0,0 +1,52 @@
+package smb2
+
+import (
+	"sync"
+)
+
+const (
+	minBufferShift = 9  // 512 bytes
+	maxBufferShift = 24 // 16 MiB, enough for maxDirectTCPSize
+)
+
+// bufferPools holds packet buffers by size class. The class i holds buffers of 1<<(minBufferShift+i) bytes.
+var bufferPools [maxBufferShift - minBufferShift + 1]sync.Pool
+
+// bufferClass returns the index of the smallest class which can hold n bytes, or -1 if n is too large.
+func bufferClass(n int) int {
+	for i := range bufferPools {
+		if n <= 1<<uint(minBufferShift+i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// getBuffer returns a zeroed buffer of n bytes, which may be returned to the pool by putBuffer.
+func getBuffer(n int) []byte {
+	i := bufferClass(n)
+	if i < 0 {
+		return make([]byte, n)
+	}
+	if b, ok := bufferPools[i].Get().([]byte); ok {
+		return b[:n]
+	}
+	return make([]byte, n, 1<<uint(minBufferShift+i))
+}
+
+// putBuffer zeroes b and returns it to the pool.
+// b must have been returned by getBuffer, and must not be referenced anymore.
+// Buffers whose capacity doesn't match a size class are left to the garbage collector.
+func putBuffer(b []byte) {
+	i := bufferClass(cap(b))
+	if i < 0 || cap(b) != 1<<uint(minBufferShift+i) {
+		return
+	}
+
+	b = b[:cap(b)]
+	for j := range b {
+		b[j] = 0
+	}
+
+	bufferPools[i].Put(b)
+}
@@ -0,0 +1,61 @@
+package smb2
+
+import (
+	"testing"
+)
+
+func TestBufferPool(t *testing.T) {
+	for _, tc := range []struct {
+		n   int
+		cap int
+	}{
+		{0, 512},
+		{1, 512},
+		{512, 512},
+		{513, 1024},
+		{64 << 10, 64 << 10},
+		{64<<10 + 1, 128 << 10},
+		{maxDirectTCPSize, 16 << 20},
+		{16<<20 + 1, 16<<20 + 1},
+	} {
+		b := getBuffer(tc.n)
+		if len(b) != tc.n {
+			t.Errorf("getBuffer(%d): expected len %d, got %d", tc.n, tc.n, len(b))
+		}
+		if cap(b) != tc.cap {
+			t.Errorf("getBuffer(%d): expected cap %d, got %d", tc.n, tc.cap, cap(b))
+		}
+		putBuffer(b)
+	}
+}
+
+func TestBufferPoolZeroed(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		b := getBuffer(1000)
+		for j, c := range b[:cap(b)] {
+			if c != 0 {
+				t.Fatalf("byte %d isn't zeroed", j)
+			}
+		}
+		for j := range b[:cap(b)] {
+			b[:cap(b)][j] = 0xff
+		}
+		putBuffer(b)
+	}
+}
+
+func TestBufferPoolForeign(t *testing.T) {
+	b := make([]byte, 1000)
+	for j := range b {
+		b[j] = 0xff
+	}
+
+	// buffers which don't match a size class are left untouched
+	putBuffer(b)
+
+	for j, c := range b {
+		if c != 0xff {
+			t.Fatalf("byte %d is modified", j)
+		}
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"math/rand"
 	"net"
 	"os"
@@ -12,12 +11,14 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	. "github.com/nodauf/go-smb2/internal/erref"
 	. "github.com/nodauf/go-smb2/internal/smb2"
 
 	"github.com/nodauf/go-smb2/internal/msrpc"
+	"github.com/nodauf/go-smb2/internal/ntlm"
 )
 
 // Dialer contains options for func (*Dialer) Dial.
@@ -25,7 +26,170 @@ type Dialer struct {
 	MaxCreditBalance uint16 // if it's zero, clientMaxCreditBalance is used. (See feature.go for more details)
 	Negotiator       Negotiator
 	Initiator        Initiator
-}
+
+	// MaxConcurrentRequests limits the number of requests waiting for the responses.
+	// Further requests block until a response arrives or their context is done.
+	// Note that a request the server keeps pending (e.g. a change notification) holds its slot.
+	// The acknowledgements of oplock breaks don't wait for a slot.
+	// If it's zero, the number is only limited by the credits granted by the server.
+	MaxConcurrentRequests int
+
+	// MaxMessageSize caps the size of the messages received from the server, so that a broken length header
+	// doesn't make the client allocate the claimed size. A larger message fails the connection.
+	// If it's zero, the cap is the largest of the max transact, read and write sizes negotiated with the server,
+	// plus 4 MiB for the headers of compound and encrypted responses.
+	MaxMessageSize int
+
+	// ReadLimit and WriteLimit cap the throughput of reads and writes in bytes per second.
+	// The limits are shared by all the files of the session. If they're zero, the throughput is unlimited.
+	ReadLimit  int
+	WriteLimit int
+
+	// NegotiateTimeout limits the time to wait for the negotiate response, independently of the context passed to DialContext.
+	// If the server doesn't respond in time, DialContext returns *NegotiateTimeoutError.
+	// If it's zero, only the context limits the time.
+	NegotiateTimeout time.Duration
+
+	// OnSigningFailure selects what happens when a response fails signature verification.
+	// Either way, the request waiting for the response gets ErrSignatureMismatch.
+	OnSigningFailure SigningFailureAction
+
+	// ValidateNegotiate enables the validation of the negotiation (FSCTL_VALIDATE_NEGOTIATE_INFO).
+	// If it's on, on SMB 3.0 and 3.0.2, DialContext connects to IPC$ after the session setup and asks the server
+	// to confirm the negotiated dialect, capabilities and security mode, so that a downgrade of the negotiation
+	// by a man in the middle is detected. On mismatch, the session is logged off, the connection is closed
+	// and DialContext returns ErrNegotiateMismatch. It's on if it's nil, so only a pointer to false turns it off.
+	ValidateNegotiate *bool
+
+	// RejectGuest fails the dial with ErrGuestSession if the server grants a guest or anonymous session,
+	// e.g. when a server maps unknown users or wrong passwords to the guest account,
+	// so that a downgrade from the requested credentials doesn't pass for an authenticated session.
+	RejectGuest bool
+
+	// RetryPolicy retries the operations of the session's shares which fail with a transient error.
+	// Only idempotent operations are retried unless the policy says otherwise (See RetryPolicy for more details).
+	// If it's nil, operations aren't retried.
+	RetryPolicy *RetryPolicy
+
+	// AutoReauth re-authenticates the session by Initiator when the server expires it,
+	// and sends the requests failing with STATUS_NETWORK_SESSION_EXPIRED again,
+	// so that long-running jobs outlive the session lifetime of the server.
+	// If it's false, the requests fail with the STATUS_NETWORK_SESSION_EXPIRED response error.
+	AutoReauth bool
+
+	// MaxOpenFiles caps the number of files the session keeps open at a time.
+	// Opening more fails with ErrTooManyOpenFiles, so that leaked files are caught
+	// before they exhaust the handle quota of the server. If it's zero, it's unlimited.
+	MaxOpenFiles int
+
+	// AsyncWaitTimeout limits the time to wait for the final response of a request the server has answered
+	// with an interim STATUS_PENDING response, e.g. a read of a named pipe waiting for a message.
+	// Once it's exceeded, the request is canceled by a CANCEL request and fails with ErrAsyncTimeout.
+	// The time starts with the interim response, so servers answering most of the requests with short interim
+	// timeouts keep working. If it's zero, the final response is waited for as long as the context allows.
+	// It doesn't apply to func (*File) ChangeNotify, which waits for a change to occur.
+	// The credits granted by the interim responses are accounted either way.
+	AsyncWaitTimeout time.Duration
+
+	// WarnOnLeak records the stack which opens each file, and logs it to os.Stderr
+	// if the file is garbage collected without being closed, so that leaked handles are traced to their opens.
+	// The leaked handle is then left open, rather than closed by the finalizer as it's otherwise,
+	// so that the behavior being debugged doesn't change.
+	// Recording the stacks is costly, so it's meant for debugging.
+	WarnOnLeak bool
+
+	// OplockBreakAckTimeout limits the time to wait for the response to the acknowledgement of an oplock break
+	// (See OpenOptions.OplockLevel). The acknowledgement is sent as soon as the break notification is received,
+	// before OnOplockBreak is called, so that a slow callback doesn't keep the other clients of the file waiting.
+	// If it's zero, it's 35 seconds, the default break timeout of Windows servers.
+	OplockBreakAckTimeout time.Duration
+
+	// OnOplockBreak is called with the name of the file and the new oplock level, an OplockLevel* constant,
+	// whenever the server breaks the oplock of an open file, once the break has been acknowledged.
+	// It's called on a goroutine of its own, so it may flush the cached data of the file.
+	OnOplockBreak func(name string, level uint8)
+
+	// MaxReadChunk and MaxWriteChunk cap the size of the chunks large reads and writes are split into,
+	// for servers or middleboxes which advertise large max sizes but fail on single large requests.
+	// They only lower the negotiated max sizes, and apply to all the shares of the session,
+	// on top of func (*Share) SetIOChunkSize. If they're zero, the negotiated max sizes are used.
+	MaxReadChunk  int
+	MaxWriteChunk int
+
+	// OnCreditWait is called with the time a request waited for credits, whenever none was available
+	// when it was sent. Frequent waits mean the server grants too few credits for the concurrency,
+	// see func (*Session) Credits. It's called on the goroutine of the request, so it must not block.
+	OnCreditWait func(waited time.Duration)
+
+	// OnAuthToken is called with the tokens of the authentication mechanism exchanged by the session setup,
+	// e.g. the NTLM NEGOTIATE, CHALLENGE and AUTHENTICATE messages, without their SPNEGO wrapping.
+	// step is AuthTokenNegotiate for the first token of the client, AuthTokenChallenge for the tokens of the server,
+	// and AuthTokenAuthenticate for the tokens of the client answering them.
+	// The token is a copy, which may be retained.
+	OnAuthToken func(step string, token []byte)
+
+	// OnMessage is called with the header of each request sent and each response received, including the interim
+	// responses and the oplock break notifications, so that the message ids assigned to the calls can be
+	// correlated with packet captures. The headers are the ones before the signing and the encryption.
+	// It's called by the goroutine sending the request or by the receiver of the connection, so it must not block.
+	OnMessage func(m Message)
+
+	// SecurityMode overrides the security mode the client advertises by the negotiate request,
+	// a combination of SecurityModeSigningEnabled and SecurityModeSigningRequired.
+	// If it's zero, SecurityModeSigningRequired is advertised if Negotiator.RequireMessageSigning is set,
+	// and SecurityModeSigningEnabled otherwise.
+	// Advertising SecurityModeSigningRequired enforces signing like Negotiator.RequireMessageSigning,
+	// while RequireMessageSigning enforces signing whatever is advertised.
+	SecurityMode uint16
+
+	// SigningAlgorithms are the signing algorithms offered by SMB 3.1.1 in preference order,
+	// SigningAlgorithm* constants. If it's set, the dial fails with an AlgorithmMismatchError
+	// unless the signing algorithm in use is one of them, whatever the dialect:
+	// e.g. []uint16{SigningAlgorithmAESGMAC} requires SMB 3.1.1 and a server supporting AES-GMAC.
+	// If it's empty, AES-GMAC and AES-CMAC are offered, and any algorithm is accepted.
+	SigningAlgorithms []uint16
+
+	// EncryptionCiphers are the ciphers offered by SMB 3.1.1 in preference order, Cipher* constants.
+	// If it's set, the dial fails with an AlgorithmMismatchError unless the cipher in use is one of them,
+	// so a connection without a cipher fails too: e.g. []uint16{CipherAES256GCM} requires SMB 3.1.1
+	// and a server supporting AES-256-GCM. If it's empty, AES-128-GCM and AES-128-CCM are offered,
+	// and any cipher is accepted.
+	EncryptionCiphers []uint16
+
+	// CompatibilityMode only offers SMB 3.0 and 2.1, without negotiate contexts, for servers (e.g. some NAS)
+	// which claim SMB 3.0 support but fail the negotiation when they see the SMB 3.1.1 negotiate contexts.
+	// It costs the protections of SMB 3.1.1: the negotiation isn't covered by the preauth integrity hash,
+	// so a downgrade is only detected by the validation of SMB 3.0 (See ValidateNegotiate), and not at all on 2.1;
+	// the encryption is AES-128-CCM on 3.0 and unavailable on 2.1; and the signing is AES-CMAC or HMAC-SHA256.
+	// SigningAlgorithms and EncryptionCiphers can't be satisfied, since they're offered by SMB 3.1.1.
+	// Negotiator.SpecifiedDialect, if it's set, must be SMB 3.0 or 2.1.
+	CompatibilityMode bool
+}
+
+// Security modes of Dialer.SecurityMode. (See [MS-SMB2] 2.2.3)
+const (
+	SecurityModeSigningEnabled  = SMB2_NEGOTIATE_SIGNING_ENABLED
+	SecurityModeSigningRequired = SMB2_NEGOTIATE_SIGNING_REQUIRED
+)
+
+// Steps of Dialer.OnAuthToken.
+const (
+	AuthTokenNegotiate    = "negotiate"
+	AuthTokenChallenge    = "challenge"
+	AuthTokenAuthenticate = "authenticate"
+)
+
+// SigningFailureAction is the action taken when a response fails signature verification.
+type SigningFailureAction int
+
+const (
+	// SigningFailureError only fails the request waiting for the response. The session keeps working.
+	SigningFailureError SigningFailureAction = iota
+
+	// SigningFailureDropSession fails every outstanding request and closes the connection,
+	// so that no more data is received from a possibly tampered connection.
+	SigningFailureDropSession
+)
 
 // Dial performs negotiation and authentication.
 // It returns a session. It doesn't support NetBIOS transport.
@@ -41,6 +205,38 @@ func (d *Dialer) Dial(tcpConn net.Conn) (*Session, error) {
 // This implementation doesn't support multi-session on the same TCP connection.
 // If you want to use another session, you need to prepare another TCP connection at first.
 func (d *Dialer) DialContext(ctx context.Context, tcpConn net.Conn) (*Session, error) {
+	return d.dial(ctx, tcpConn, nil)
+}
+
+// Bind binds a new connection to the session s as an additional channel (SMB 3.x multichannel).
+// See func (*Dialer) BindContext for more details.
+func (d *Dialer) Bind(tcpConn net.Conn, s *Session) (*Session, error) {
+	return d.BindContext(context.Background(), tcpConn, s)
+}
+
+// BindContext binds a new connection to the session s as an additional channel (SMB 3.x multichannel),
+// using the provided context. The connection is negotiated with the dialect and the client guid of s,
+// then authenticated by d.Initiator, which must authenticate the same user as s.
+// The returned session has the same session id as s and shares its encryption keys and limits,
+// but signs its requests by the signing key of the new channel.
+// It returns ErrNotSupported if s uses a dialect older than SMB 3.0 or the server doesn't support multichannel.
+func (d *Dialer) BindContext(ctx context.Context, tcpConn net.Conn, s *Session) (*Session, error) {
+	if s == nil || s.s == nil {
+		return nil, &InternalError{"no session to bind"}
+	}
+
+	c, err := d.dial(ctx, tcpConn, s.s)
+	if err != nil {
+		if c != nil && c.s != nil {
+			c.s.conn.t.Close()
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (d *Dialer) dial(ctx context.Context, tcpConn net.Conn, bind *session) (*Session, error) {
 	if ctx == nil {
 		panic("nil context")
 	}
@@ -59,15 +255,94 @@ func (d *Dialer) DialContext(ctx context.Context, tcpConn net.Conn) (*Session, e
 	}
 
 	a := openAccount(maxCreditBalance)
+	a.onWait = d.OnCreditWait
+
+	nctx := ctx
+	if d.NegotiateTimeout > 0 {
+		var cancel context.CancelFunc
+		nctx, cancel = context.WithTimeout(ctx, d.NegotiateTimeout)
+		defer cancel()
+	}
 
-	conn, err := d.Negotiator.negotiate(direct(tcpConn), a, ctx)
+	n := d.Negotiator
+	n.securityMode = d.SecurityMode
+	n.signingAlgs = d.SigningAlgorithms
+	n.ciphers = d.EncryptionCiphers
+	n.maxMessageSize = d.MaxMessageSize
+	n.compatibilityMode = d.CompatibilityMode
+	n.onMessage = d.OnMessage
+	if bind != nil {
+		// a channel must be negotiated like the connection of the session
+		n.ClientGuid = bind.clientGuid
+		n.SpecifiedDialect = bind.dialect
+	}
+
+	conn, err := n.negotiate(direct(tcpConn), a, nctx)
 	if err != nil {
 		return nil, err
 	}
 
-	s, err := sessionSetup(conn, d.Initiator, ctx)
+	if err := conn.checkAlgorithms(d.SigningAlgorithms, d.EncryptionCiphers); err != nil {
+		return nil, err
+	}
+
+	if d.MaxConcurrentRequests > 0 {
+		conn.sem = make(chan struct{}, d.MaxConcurrentRequests)
+	}
+
+	conn.dropOnSigningFailure = d.OnSigningFailure == SigningFailureDropSession
+	conn.onAuthToken = d.OnAuthToken
+	conn.asyncWaitTimeout = d.AsyncWaitTimeout
+
+	s, err := sessionSetup(conn, d.Initiator, bind, ctx)
+	if s != nil {
+		if bind != nil {
+			s.readLimiter = bind.readLimiter
+			s.writeLimiter = bind.writeLimiter
+		} else {
+			s.readLimiter = newLimiter(d.ReadLimit)
+			s.writeLimiter = newLimiter(d.WriteLimit)
+		}
+		if d.RetryPolicy != nil {
+			p := *d.RetryPolicy
+			s.retryPolicy = &p
+		}
+		s.maxReadChunk = d.MaxReadChunk
+		s.maxWriteChunk = d.MaxWriteChunk
+		if d.AutoReauth {
+			s.initiator = d.Initiator
+		}
+		s.maxOpenFiles = int32(d.MaxOpenFiles)
+		s.warnOnLeak = d.WarnOnLeak
+		s.oplockBreakAckTimeout = d.OplockBreakAckTimeout
+		s.onOplockBreak = d.OnOplockBreak
+		s.serverInfo.Dialect = s.dialect
+		if i, ok := d.Initiator.(interface{ infoMap() *ntlm.InfoMap }); ok {
+			if m := i.infoMap(); m != nil {
+				s.serverInfo.DnsComputerName = m.DnsComputerName
+				s.serverInfo.NbComputerName = m.NbComputerName
+				s.serverInfo.NbDomainName = m.NbDomainName
+				s.serverInfo.DnsDomainName = m.DnsDomainName
+				s.serverInfo.DnsTreeName = m.DnsTreeName
+			}
+		}
+	}
+
+	addr := tcpConn.RemoteAddr().String()
 
-	return &Session{s: s, ctx: context.Background(), addr: tcpConn.RemoteAddr().String()}, err
+	if err == nil && d.RejectGuest && s.sessionFlags&(SMB2_SESSION_FLAG_IS_GUEST|SMB2_SESSION_FLAG_IS_NULL) != 0 {
+		conn.t.Close()
+		return nil, ErrGuestSession
+	}
+
+	if err == nil && (d.ValidateNegotiate == nil || *d.ValidateNegotiate) {
+		if err := s.validateNegotiate(hostname(addr), ctx); err != nil {
+			conn.t.Close()
+			return nil, err
+		}
+	}
+
+	return &Session{s: s, ctx: context.Background(), addr: addr}, err
 }
 
 // Session represents a SMB session.
@@ -84,26 +359,145 @@ func (c *Session) WithContext(ctx context.Context) *Session {
 	return &Session{s: c.s, ctx: ctx, addr: c.addr}
 }
 
-// Logoff invalidates the current SMB session.
+// Logoff unmounts the shares mounted on the session, and invalidates the current SMB session.
 func (c *Session) Logoff() error {
 	return c.s.logoff(c.ctx)
 }
 
+// SessionID returns the id of the session, which is shared by all the channels bound to it.
+func (c *Session) SessionID() uint64 {
+	return c.s.sessionId
+}
+
+// Closed returns a channel which is closed once the session is unusable: its connection has failed
+// or has been closed by the server, the server has deleted the session, or it has been logged off.
+// The reason is returned by func (*Session) Err then. Long-lived users may select on it to reconnect promptly.
+func (c *Session) Closed() <-chan struct{} {
+	return c.s.closedChan()
+}
+
+// Err returns nil until the channel returned by func (*Session) Closed is closed, and the reason afterwards:
+// ErrSessionDeleted if the server has deleted the session, ErrLoggedOff if it has been logged off,
+// or the error of the connection, e.g. ErrConnectionClosed.
+func (c *Session) Err() error {
+	return c.s.closeReason()
+}
+
+// OpenFileCount returns the number of files opened by the session and not closed yet,
+// including the named pipes and the directories opened internally.
+func (c *Session) OpenFileCount() int {
+	return int(atomic.LoadInt32(&c.s._openFiles))
+}
+
+// IsGuest reports whether the server granted a guest session (SMB2_SESSION_FLAG_IS_GUEST),
+// whose requests aren't signed nor encrypted, whatever credentials were presented.
+func (c *Session) IsGuest() bool {
+	return c.s.sessionFlags&SMB2_SESSION_FLAG_IS_GUEST != 0
+}
+
+// IsAnonymous reports whether the server granted an anonymous session (SMB2_SESSION_FLAG_IS_NULL).
+func (c *Session) IsAnonymous() bool {
+	return c.s.sessionFlags&SMB2_SESSION_FLAG_IS_NULL != 0
+}
+
 // SigningRequired returns whehter the current connection requires signing
 func (c *Session) SigningRequired() bool {
 	return c.s.conn.requireSigning
 }
 
+// SessionStats contains statistics of a session returned by func (*Session) Stats.
+type SessionStats struct {
+	InFlightRequests int // number of requests waiting for the responses
+}
+
+// Stats returns the current statistics of the session.
+func (c *Session) Stats() SessionStats {
+	return SessionStats{
+		InFlightRequests: c.s.conn.inFlight(),
+	}
+}
+
+// Credits returns the credits the server has granted to the connection, which are the credits
+// available for new requests and the credits charged by the requests waiting for the responses,
+// and the available credits. If none is available, new requests wait for the responses.
+func (c *Session) Credits() (granted, available int) {
+	return c.s.conn.account.credits()
+}
+
+// CreditCharge returns the credits a request charges whose payload is payloadSize bytes,
+// the larger of the payload sizes of the request and of its expected response:
+// the length of a READ or a WRITE, the input or max output size of an IOCTL,
+// or the output buffer length of a QUERY_DIRECTORY or a QUERY_INFO.
+// If the connection supports multi-credit requests (SMB 2.1 or later with large MTU), it's one per started 64 KiB,
+// e.g. 1 for 65536 bytes and 2 for 65537 bytes, and it's always one otherwise.
+func (c *Session) CreditCharge(payloadSize int) uint16 {
+	return c.s.conn.creditCharge(payloadSize)
+}
+
+// Ping sends an ECHO request and waits for the response, so that health checks and connection pools
+// can tell the session alive without touching the file system. ctx bounds the wait.
+// It returns ErrConnectionClosed right away if the server has closed the connection.
+func (c *Session) Ping(ctx context.Context) error {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	var err error
+
+	req := new(EchoRequest)
+	req.CreditCharge, _, err = c.s.conn.loanCredit(0, ctx)
+	if err != nil {
+		return err
+	}
+
+	rr, err := c.s.send(req, ctx)
+	if err != nil {
+		return err
+	}
+
+	pkt, err := c.s.recv(rr)
+	if err != nil {
+		return err
+	}
+
+	res, err := accept(SMB2_ECHO, pkt)
+	if err != nil {
+		return err
+	}
+
+	if EchoResponseDecoder(res).IsInvalid() {
+		return &InvalidResponseError{"broken echo response format"}
+	}
+
+	return nil
+}
+
+// ServerInfo contains information about the server returned by func (*Session) ServerInfo.
+// The names come from the target information of the NTLM challenge, so they're empty for other initiators.
+type ServerInfo struct {
+	DnsComputerName string // e.g. "server.example.com"
+	NbComputerName  string // NetBIOS computer name, e.g. "SERVER"
+	NbDomainName    string // NetBIOS domain name, e.g. "EXAMPLE"
+	DnsDomainName   string // e.g. "example.com"
+	DnsTreeName     string // DNS name of the forest
+
+	Dialect uint16 // negotiated dialect, e.g. 0x0311 for SMB 3.1.1
+}
+
+// ServerInfo returns the names of the server and its domain gathered by the session setup, and the negotiated dialect.
+func (c *Session) ServerInfo() ServerInfo {
+	return c.s.serverInfo
+}
+
 // Mount mounts the SMB share.
-// sharename must follow format like `<share>` or `\\<server>\<share>`.
+// sharename must follow format like `<share>`, `\\<server>\<share>` or `//<server>/<share>`.
+// On a named pipe share (IPC$), only named pipes can be opened, the other file operations fail with ErrPipeShare.
+// If the access to the share is denied on a dialect older than SMB 3.0, the share may require encryption,
+// which the error tells by an *os.PathError whose Err is an *EncryptionMismatchError.
 // Note that the mounted share doesn't inherit session's context.
 // If you want to use the same context, call Share.WithContext manually.
 func (c *Session) Mount(sharename string) (*Share, error) {
-	sharename = normPath(sharename)
-
-	if !strings.ContainsRune(sharename, '\\') {
-		sharename = fmt.Sprintf(`\\%s\%s`, c.addr, sharename)
-	}
+	sharename = mountPath(sharename, hostname(c.addr))
 
 	if err := validateMountPath(sharename); err != nil {
 		return nil, err
@@ -117,8 +511,21 @@ func (c *Session) Mount(sharename string) (*Share, error) {
 	return &Share{treeConn: tc, ctx: context.Background()}, nil
 }
 
+// Shares returns the shares mounted on the session and not unmounted yet in the order of their tree ids,
+// including the ones mounted by concurrent calls of func (*Session) Mount. Logoff unmounts all of them.
+// The returned shares don't inherit the contexts of the mounted ones.
+func (c *Session) Shares() []*Share {
+	tcs := c.s.trees()
+
+	shares := make([]*Share, len(tcs))
+	for i, tc := range tcs {
+		shares[i] = &Share{treeConn: tc, ctx: context.Background()}
+	}
+	return shares
+}
+
 func (c *Session) ListSharenames() ([]string, error) {
-	servername := c.addr
+	servername := hostname(c.addr)
 
 	fs, err := c.Mount(fmt.Sprintf(`\\%s\IPC$`, servername))
 	if err != nil {
@@ -144,7 +551,10 @@ func (c *Session) ListSharenames() ([]string, error) {
 		MaxOutputResponse: 4280,
 		Flags:             SMB2_0_IOCTL_IS_FSCTL,
 		Input: &msrpc.Bind{
-			CallId: callId,
+			CallId:       callId,
+			InterfaceId:  msrpc.SRVSVC_UUID,
+			Version:      msrpc.SRVSVC_VERSION,
+			VersionMinor: msrpc.SRVSVC_VERSION_MINOR,
 		},
 	}
 
@@ -182,7 +592,7 @@ func (c *Session) ListSharenames() ([]string, error) {
 
 			rlen := 4280 - len(output)
 
-			n, err := f.readAt(buf[:rlen], 0)
+			n, err := f.readPipe(buf[:rlen])
 			if err != nil {
 				return nil, &os.PathError{Op: "listSharenames", Path: f.name, Err: err}
 			}
@@ -195,7 +605,7 @@ func (c *Session) ListSharenames() ([]string, error) {
 			}
 
 			for r2.IsIncomplete() {
-				n, err := f.readAt(buf, 0)
+				n, err := f.readPipe(buf)
 				if err != nil {
 					return nil, &os.PathError{Op: "listSharenames", Path: f.name, Err: err}
 				}
@@ -240,34 +650,90 @@ func (fs *Share) WithContext(ctx context.Context) *Share {
 	}
 }
 
+// Name returns the name of the share as mounted, e.g. `\\server\share`.
+func (fs *Share) Name() string {
+	return fs.path
+}
+
+// TreeID returns the id of the tree connection of the share, as sent in the headers of its requests.
+func (fs *Share) TreeID() uint32 {
+	return fs.treeId
+}
+
 // Umount disconects the current SMB tree.
 func (fs *Share) Umount() error {
 	return fs.treeConn.disconnect(fs.ctx)
 }
 
+// EncryptionRequired reports whether requests to the share are encrypted,
+// because the server requires encryption on the session or on the share.
+func (fs *Share) EncryptionRequired() bool {
+	return fs.sessionFlags&SMB2_SESSION_FLAG_ENCRYPT_DATA != 0 || fs.shareFlags&SMB2_SHAREFLAG_ENCRYPT_DATA != 0
+}
+
 func (fs *Share) Create(name string) (*File, error) {
 	return fs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
 }
 
 func (fs *Share) newFile(fd FileIdDecoder, name string) *File {
-	f := &File{fs: fs, fd: fd.Decode(), name: name}
+	// the requests on the file are bound to a context of its own, so that Close can cancel the outstanding ones
+	ctx, cancel := context.WithCancel(fs.ctx)
+
+	f := &File{fs: fs.WithContext(ctx), fd: fd.Decode(), name: name, ctx: fs.ctx, cancel: cancel}
+
+	if fs.warnOnLeak {
+		f.stack = stack()
+	}
 
-	runtime.SetFinalizer(f, (*File).close)
+	runtime.SetFinalizer(f, (*File).finalize)
 
 	return f
 }
 
+// stack returns the stack trace of the calling goroutine.
+func stack() []byte {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
 func (fs *Share) Open(name string) (*File, error) {
 	return fs.OpenFile(name, os.O_RDONLY, 0)
 }
 
 func (fs *Share) OpenFile(name string, flag int, perm os.FileMode) (*File, error) {
-	name = normPath(name)
+	return fs.OpenFileWithOptions(name, flag, perm, nil)
+}
+
+// OpenFileWithOptions is the generalized open call like func (*Share) OpenFile.
+// opts may be nil, in which case it behaves the same as func (*Share) OpenFile.
+func (fs *Share) OpenFileWithOptions(name string, flag int, perm os.FileMode, opts *OpenOptions) (*File, error) {
+	var f *File
+	// opening an existing file is idempotent, creating or truncating one isn't
+	err := fs.retry(flag&(os.O_CREATE|os.O_TRUNC) == 0, func() (err error) {
+		f, err = fs.openFile(name, flag, perm, opts)
+		return
+	})
+	return f, err
+}
 
-	if err := validatePath("open", name, false); err != nil {
+func (fs *Share) openFile(name string, flag int, perm os.FileMode, opts *OpenOptions) (*File, error) {
+	name, err := cleanPath("open", name)
+	if err != nil {
 		return nil, err
 	}
 
+	if flag&(os.O_CREATE|os.O_TRUNC) != 0 {
+		if err := fs.checkDiskShare("open", name); err != nil {
+			return nil, err
+		}
+	}
+
 	var access uint32
 	switch flag & (os.O_RDONLY | os.O_WRONLY | os.O_RDWR) {
 	case os.O_RDONLY:
@@ -301,27 +767,48 @@ func (fs *Share) OpenFile(name string, flag int, perm os.FileMode) (*File, error
 		createmode = FILE_OPEN
 	}
 
-	var attrs uint32 = FILE_ATTRIBUTE_NORMAL
+	// like NTFS, a created file has the archive bit
+	var attrs uint32 = FILE_ATTRIBUTE_ARCHIVE
 	if perm&0200 == 0 {
-		attrs = FILE_ATTRIBUTE_READONLY
+		attrs |= FILE_ATTRIBUTE_READONLY
+	}
+
+	var options uint32 = FILE_SYNCHRONOUS_IO_NONALERT
+	var impersonation uint32 = Impersonation
+	var contexts []Encoder
+	var oplockLevel uint8 = SMB2_OPLOCK_LEVEL_NONE
+	if opts != nil {
+		options |= opts.createOptions()
+		impersonation = opts.impersonationLevel()
+		contexts = opts.createContexts()
+		oplockLevel = opts.OplockLevel
 	}
 
 	req := &CreateRequest{
 		SecurityFlags:        0,
-		RequestedOplockLevel: SMB2_OPLOCK_LEVEL_NONE,
-		ImpersonationLevel:   Impersonation,
+		RequestedOplockLevel: oplockLevel,
+		ImpersonationLevel:   impersonation,
 		SmbCreateFlags:       0,
 		DesiredAccess:        access,
 		FileAttributes:       attrs,
 		ShareAccess:          sharemode,
 		CreateDisposition:    createmode,
-		CreateOptions:        FILE_SYNCHRONOUS_IO_NONALERT,
+		CreateOptions:        options,
+		Contexts:             contexts,
 	}
 
 	f, err := fs.createFile(name, req, true)
 	if err != nil {
 		return nil, &os.PathError{Op: "open", Path: name, Err: err}
 	}
+	if opts != nil && opts.NoBuffering {
+		fi, err := f.statfs()
+		if err != nil {
+			f.close()
+			return nil, &os.PathError{Op: "open", Path: name, Err: err}
+		}
+		f.sectorSize = int64(fi.BlockSize())
+	}
 	if flag&os.O_APPEND != 0 {
 		f.seek(0, io.SeekEnd)
 	}
@@ -329,9 +816,18 @@ func (fs *Share) OpenFile(name string, flag int, perm os.FileMode) (*File, error
 }
 
 func (fs *Share) Mkdir(name string, perm os.FileMode) error {
-	name = normPath(name)
+	return fs.retry(false, func() error {
+		return fs.mkdir(name, perm)
+	})
+}
+
+func (fs *Share) mkdir(name string, perm os.FileMode) error {
+	name, err := cleanPath("mkdir", name)
+	if err != nil {
+		return err
+	}
 
-	if err := validatePath("mkdir", name, false); err != nil {
+	if err := fs.checkDiskShare("mkdir", name); err != nil {
 		return err
 	}
 
@@ -360,9 +856,17 @@ func (fs *Share) Mkdir(name string, perm os.FileMode) error {
 }
 
 func (fs *Share) Readlink(name string) (string, error) {
-	name = normPath(name)
+	var v string
+	err := fs.retry(true, func() (err error) {
+		v, err = fs.readlink(name)
+		return
+	})
+	return v, err
+}
 
-	if err := validatePath("readlink", name, false); err != nil {
+func (fs *Share) readlink(name string) (string, error) {
+	name, err := cleanPath("readlink", name)
+	if err != nil {
 		return "", err
 	}
 
@@ -406,7 +910,10 @@ func (fs *Share) Readlink(name string) (string, error) {
 		return "", &os.PathError{Op: "readlink", Path: f.name, Err: &InvalidResponseError{"broken symbolic link response data buffer format"}}
 	}
 
-	target := r.SubstituteName()
+	target, err := decodeName(r.SubstituteName())
+	if err != nil {
+		return "", &os.PathError{Op: "readlink", Path: f.name, Err: err}
+	}
 
 	switch {
 	case strings.HasPrefix(target, `\??\UNC\`):
@@ -419,20 +926,25 @@ func (fs *Share) Readlink(name string) (string, error) {
 }
 
 func (fs *Share) Remove(name string) error {
-	err := fs.remove(name)
-	if os.IsPermission(err) {
-		if e := fs.Chmod(name, 0666); e != nil {
-			return err
+	return fs.retry(false, func() error {
+		err := fs.remove(name)
+		if os.IsPermission(err) {
+			if e := fs.chmod(name, 0666); e != nil {
+				return err
+			}
+			return fs.remove(name)
 		}
-		return fs.remove(name)
-	}
-	return err
+		return err
+	})
 }
 
 func (fs *Share) remove(name string) error {
-	name = normPath(name)
+	name, err := cleanPath("remove", name)
+	if err != nil {
+		return err
+	}
 
-	if err := validatePath("remove", name, false); err != nil {
+	if err := fs.checkDiskShare("remove", name); err != nil {
 		return err
 	}
 
@@ -467,14 +979,23 @@ func (fs *Share) remove(name string) error {
 }
 
 func (fs *Share) Rename(oldpath, newpath string) error {
-	oldpath = normPath(oldpath)
-	newpath = normPath(newpath)
+	return fs.retry(false, func() error {
+		return fs.rename(oldpath, newpath)
+	})
+}
 
-	if err := validatePath("rename from", oldpath, false); err != nil {
+func (fs *Share) rename(oldpath, newpath string) error {
+	oldpath, err := cleanPath("rename from", oldpath)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.checkDiskShare("rename", oldpath); err != nil {
 		return err
 	}
 
-	if err := validatePath("rename to", newpath, false); err != nil {
+	newpath, err = cleanPath("rename to", newpath)
+	if err != nil {
 		return err
 	}
 
@@ -525,14 +1046,24 @@ func (fs *Share) Rename(oldpath, newpath string) error {
 // So, if you know the target server is Windows, you should avoid that format.
 // If you want to use an absolute target path on windows, you can use // `C:\dir\name` format instead.
 func (fs *Share) Symlink(target, linkpath string) error {
+	return fs.retry(false, func() error {
+		return fs.symlink(target, linkpath)
+	})
+}
+
+func (fs *Share) symlink(target, linkpath string) error {
 	target = normPath(target)
-	linkpath = normPath(linkpath)
 
 	if err := validatePath("symlink target", target, true); err != nil {
 		return err
 	}
 
-	if err := validatePath("symlink linkpath", linkpath, false); err != nil {
+	linkpath, err := cleanPath("symlink linkpath", linkpath)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.checkDiskShare("symlink", linkpath); err != nil {
 		return err
 	}
 
@@ -599,10 +1130,20 @@ func (fs *Share) Symlink(target, linkpath string) error {
 	return nil
 }
 
+// Lstat returns a os.FileInfo describing the named file, like os.Lstat.
+// If the file is a symbolic link or a junction, it describes the link itself, whose mode has os.ModeSymlink.
 func (fs *Share) Lstat(name string) (os.FileInfo, error) {
-	name = normPath(name)
+	var v os.FileInfo
+	err := fs.retry(true, func() (err error) {
+		v, err = fs.lstat(name)
+		return
+	})
+	return v, err
+}
 
-	if err := validatePath("lstat", name, false); err != nil {
+func (fs *Share) lstat(name string) (os.FileInfo, error) {
+	name, err := cleanPath("lstat", name)
+	if err != nil {
 		return nil, err
 	}
 
@@ -633,10 +1174,20 @@ func (fs *Share) Lstat(name string) (os.FileInfo, error) {
 	return fi, nil
 }
 
+// Stat returns a os.FileInfo describing the named file, like os.Stat.
+// Symbolic links and junctions are followed, so it describes their target.
 func (fs *Share) Stat(name string) (os.FileInfo, error) {
-	name = normPath(name)
+	var v os.FileInfo
+	err := fs.retry(true, func() (err error) {
+		v, err = fs.stat(name)
+		return
+	})
+	return v, err
+}
 
-	if err := validatePath("stat", name, false); err != nil {
+func (fs *Share) stat(name string) (os.FileInfo, error) {
+	name, err := cleanPath("stat", name)
+	if err != nil {
 		return nil, err
 	}
 
@@ -664,13 +1215,68 @@ func (fs *Share) Stat(name string) (os.FileInfo, error) {
 	if err != nil {
 		return nil, &os.PathError{Op: "stat", Path: name, Err: err}
 	}
+	// the file may have been opened by the path of the link target, but it's named like the link, as by os.Stat
+	fi.(*FileStat).FileName = base(name)
 	return fi, nil
 }
 
+// IsDir reports whether the named file is a directory. Symbolic links and junctions are followed like by Stat,
+// so a link to a directory is a directory, and a dangling link fails.
+// Other reparse points (e.g. deduplicated or cloud directories) are directories by their attributes.
+// It's cheaper than Stat, since the attributes are taken from the CREATE response without querying the file.
+func (fs *Share) IsDir(name string) (bool, error) {
+	var isDir bool
+	err := fs.retry(true, func() (err error) {
+		isDir, err = fs.isDir(name)
+		return
+	})
+	return isDir, err
+}
+
+func (fs *Share) isDir(name string) (bool, error) {
+	name, err := cleanPath("isdir", name)
+	if err != nil {
+		return false, err
+	}
+
+	create := &CreateRequest{
+		SecurityFlags:        0,
+		RequestedOplockLevel: SMB2_OPLOCK_LEVEL_NONE,
+		ImpersonationLevel:   Impersonation,
+		SmbCreateFlags:       0,
+		DesiredAccess:        FILE_READ_ATTRIBUTES,
+		FileAttributes:       FILE_ATTRIBUTE_NORMAL,
+		ShareAccess:          FILE_SHARE_READ | FILE_SHARE_WRITE | FILE_SHARE_DELETE,
+		CreateDisposition:    FILE_OPEN,
+		CreateOptions:        0,
+	}
+
+	f, err := fs.createFile(name, create, true)
+	if err != nil {
+		return false, &os.PathError{Op: "isdir", Path: name, Err: err}
+	}
+
+	isDir := f.attrs&FILE_ATTRIBUTE_DIRECTORY != 0
+
+	if err := f.close(); err != nil {
+		return false, &os.PathError{Op: "isdir", Path: name, Err: err}
+	}
+	return isDir, nil
+}
+
 func (fs *Share) Truncate(name string, size int64) error {
-	name = normPath(name)
+	return fs.retry(false, func() error {
+		return fs.truncate(name, size)
+	})
+}
 
-	if err := validatePath("truncate", name, false); err != nil {
+func (fs *Share) truncate(name string, size int64) error {
+	name, err := cleanPath("truncate", name)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.checkDiskShare("truncate", name); err != nil {
 		return err
 	}
 
@@ -706,9 +1312,18 @@ func (fs *Share) Truncate(name string, size int64) error {
 }
 
 func (fs *Share) Chtimes(name string, atime time.Time, mtime time.Time) error {
-	name = normPath(name)
+	return fs.retry(false, func() error {
+		return fs.chtimes(name, atime, mtime)
+	})
+}
+
+func (fs *Share) chtimes(name string, atime time.Time, mtime time.Time) error {
+	name, err := cleanPath("chtimes", name)
+	if err != nil {
+		return err
+	}
 
-	if err := validatePath("chtimes", name, false); err != nil {
+	if err := fs.checkDiskShare("chtimes", name); err != nil {
 		return err
 	}
 
@@ -748,10 +1363,70 @@ func (fs *Share) Chtimes(name string, atime time.Time, mtime time.Time) error {
 	return nil
 }
 
+// SuppressTimeUpdate is a special time of func (*Share) SetFileTime and func (*File) SetFileTime,
+// which is sent as the FILETIME -1 like Windows, so that the server doesn't update the timestamp
+// by the following operations on the handle (e.g. the access time of reads for backups).
+var SuppressTimeUpdate = time.Date(1600, time.December, 31, 23, 59, 59, 999999900, time.UTC)
+
+// SetFileTime sets the creation, access, write and change times of the named file.
+// A nil time is left unchanged. Unlike func (*Share) Chtimes, it can restore all the timestamps of Windows.
+// SuppressTimeUpdate only lasts as long as the handle, so it's rather useful with func (*File) SetFileTime.
+func (fs *Share) SetFileTime(name string, created, accessed, written, changed *time.Time) error {
+	return fs.retry(false, func() error {
+		return fs.setFileTime(name, created, accessed, written, changed)
+	})
+}
+
+func (fs *Share) setFileTime(name string, created, accessed, written, changed *time.Time) error {
+	name, err := cleanPath("setfiletime", name)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.checkDiskShare("setfiletime", name); err != nil {
+		return err
+	}
+
+	create := &CreateRequest{
+		SecurityFlags:        0,
+		RequestedOplockLevel: SMB2_OPLOCK_LEVEL_NONE,
+		ImpersonationLevel:   Impersonation,
+		SmbCreateFlags:       0,
+		DesiredAccess:        FILE_WRITE_ATTRIBUTES,
+		FileAttributes:       FILE_ATTRIBUTE_NORMAL,
+		ShareAccess:          FILE_SHARE_READ | FILE_SHARE_WRITE,
+		CreateDisposition:    FILE_OPEN,
+		CreateOptions:        0,
+	}
+
+	f, err := fs.createFile(name, create, true)
+	if err != nil {
+		return &os.PathError{Op: "setfiletime", Path: name, Err: err}
+	}
+
+	err = f.setFileTime(created, accessed, written, changed)
+	if e := f.close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return &os.PathError{Op: "setfiletime", Path: name, Err: err}
+	}
+	return nil
+}
+
 func (fs *Share) Chmod(name string, mode os.FileMode) error {
-	name = normPath(name)
+	return fs.retry(false, func() error {
+		return fs.chmod(name, mode)
+	})
+}
+
+func (fs *Share) chmod(name string, mode os.FileMode) error {
+	name, err := cleanPath("chmod", name)
+	if err != nil {
+		return err
+	}
 
-	if err := validatePath("chmod", name, false); err != nil {
+	if err := fs.checkDiskShare("chmod", name); err != nil {
 		return err
 	}
 
@@ -782,7 +1457,14 @@ func (fs *Share) Chmod(name string, mode os.FileMode) error {
 	return nil
 }
 
+// ReadDir reads the directory named by dirname and returns its entries sorted by name.
+// The entries are *FileStat filled from the enumeration (sizes, times, attributes and reparse tags),
+// so reading their information doesn't take more round trips.
 func (fs *Share) ReadDir(dirname string) ([]os.FileInfo, error) {
+	if err := fs.checkDiskShare("readdir", normPath(dirname)); err != nil {
+		return nil, err
+	}
+
 	f, err := fs.Open(dirname)
 	if err != nil {
 		return nil, err
@@ -799,20 +1481,81 @@ func (fs *Share) ReadDir(dirname string) ([]os.FileInfo, error) {
 	return fis, nil
 }
 
-func (fs *Share) ReadFile(filename string) ([]byte, error) {
-	f, err := fs.Open(filename)
-	if err != nil {
+// ReadDirContext is like func (*Share) ReadDir, but the enumeration is bound to ctx rather than the context of the share.
+// If ctx is done in the middle of it, the entries read so far are returned sorted with ctx.Err().
+// The directory is closed anyway, by the context of the share.
+func (fs *Share) ReadDirContext(ctx context.Context, dirname string) ([]os.FileInfo, error) {
+	if err := fs.checkDiskShare("readdir", normPath(dirname)); err != nil {
 		return nil, err
 	}
-	defer f.Close()
-
-	return ioutil.ReadAll(f)
-}
 
-func (fs *Share) WriteFile(filename string, data []byte, perm os.FileMode) error {
-	f, err := fs.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	f, err := fs.WithContext(ctx).Open(dirname)
 	if err != nil {
-		return err
+		if ctx.Err() != nil {
+			return []os.FileInfo{}, ctx.Err()
+		}
+		return nil, err
+	}
+	defer func() {
+		// ctx may be done, so the handle is closed by the share's context rather than leaked on the server
+		f.ctx = fs.ctx
+		f.Close()
+	}()
+
+	fis, err := f.readdirAll(f.fs, 0)
+
+	sort.Slice(fis, func(i, j int) bool { return fis[i].Name() < fis[j].Name() })
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return fis, ctx.Err()
+		}
+		return nil, &os.PathError{Op: "readdir", Path: f.name, Err: err}
+	}
+
+	return fis, nil
+}
+
+// ReadFile reads the named file and returns the contents, like os.ReadFile.
+// The buffer is sized from the file size, so that the file is read by as few requests as possible.
+// A successful call returns err == nil, not err == io.EOF.
+// It has the signature of ReadFile of fs.ReadFileFS.
+func (fs *Share) ReadFile(filename string) ([]byte, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// the size is the end of file of the CREATE response, which saves a QUERY_INFO request
+	var size int
+	if n := f.endOfFile; n >= 0 && int64(int(n)) == n {
+		size = int(n)
+	}
+	size++ // one byte for the final read at EOF, the file may also have grown
+
+	data := make([]byte, 0, size)
+	for {
+		if len(data) == cap(data) {
+			data = append(data, 0)[:len(data)]
+		}
+		n, err := f.Read(data[len(data):cap(data)])
+		data = data[:len(data)+n]
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return data, err
+		}
+	}
+}
+
+// WriteFile writes data to the named file, creating it if necessary, like os.WriteFile.
+// If the file doesn't exist, WriteFile creates it with permissions perm; otherwise it truncates it before writing.
+func (fs *Share) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	f, err := fs.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
 	}
 
 	_, err = f.Write(data)
@@ -824,9 +1567,21 @@ func (fs *Share) WriteFile(filename string, data []byte, perm os.FileMode) error
 }
 
 func (fs *Share) Statfs(name string) (FileFsInfo, error) {
-	name = normPath(name)
+	var v FileFsInfo
+	err := fs.retry(true, func() (err error) {
+		v, err = fs.statfs(name)
+		return
+	})
+	return v, err
+}
+
+func (fs *Share) statfs(name string) (FileFsInfo, error) {
+	name, err := cleanPath("statfs", name)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := validatePath("statfs", name, false); err != nil {
+	if err := fs.checkDiskShare("statfs", name); err != nil {
 		return nil, err
 	}
 
@@ -857,17 +1612,75 @@ func (fs *Share) Statfs(name string) (FileFsInfo, error) {
 	return fi, nil
 }
 
-func (fs *Share) createFile(name string, req *CreateRequest, followSymlinks bool) (f *File, err error) {
-	if followSymlinks {
-		return fs.createFileRec(name, req)
+// SetIOChunkSize sets the size of the chunks large reads and writes are split into.
+// The sizes are clamped to the max sizes negotiated with the server, and zero or negative values restore them.
+// It affects all files of the share, including the ones already opened and the ones of shares returned by WithContext.
+func (fs *Share) SetIOChunkSize(read, write int) {
+	if max := fs.negotiatedMaxReadSize(); read > max {
+		read = max
 	}
+	if read < 0 {
+		read = 0
+	}
+	if max := fs.negotiatedMaxWriteSize(); write > max {
+		write = max
+	}
+	if write < 0 {
+		write = 0
+	}
+	fs.setChunkSize(read, write)
+}
 
-	req.CreditCharge, _, err = fs.loanCredit(0)
+func (fs *Share) negotiatedMaxReadSize() int {
+	size := int(fs.maxReadSize)
+	if size > winMaxPayloadSize {
+		size = winMaxPayloadSize
+	}
+	if fs.conn.capabilities&SMB2_GLOBAL_CAP_LARGE_MTU == 0 {
+		if size > singleCreditMaxPayloadSize {
+			size = singleCreditMaxPayloadSize
+		}
+	}
+	return size
+}
+
+func (fs *Share) negotiatedMaxWriteSize() int {
+	size := int(fs.maxWriteSize)
+	if size > winMaxPayloadSize {
+		size = winMaxPayloadSize
+	}
+	if fs.conn.capabilities&SMB2_GLOBAL_CAP_LARGE_MTU == 0 {
+		if size > singleCreditMaxPayloadSize {
+			size = singleCreditMaxPayloadSize
+		}
+	}
+	return size
+}
+
+// checkDiskShare returns ErrPipeShare if the share is a named pipe share (e.g. IPC$),
+// on which only named pipes can be opened.
+func (fs *Share) checkDiskShare(op, name string) error {
+	if fs.shareType == SMB2_SHARE_TYPE_PIPE {
+		return &os.PathError{Op: op, Path: name, Err: ErrPipeShare}
+	}
+	return nil
+}
+
+func (fs *Share) createFile(name string, req *CreateRequest, followSymlinks bool) (f *File, err error) {
+	if err := fs.acquireFile(); err != nil {
+		return nil, err
+	}
 	defer func() {
 		if err != nil {
-			fs.chargeCredit(req.CreditCharge)
+			fs.releaseFile()
 		}
 	}()
+
+	if followSymlinks {
+		return fs.createFileRec(name, req)
+	}
+
+	req.CreditCharge, _, err = fs.loanCredit(0)
 	if err != nil {
 		return nil, err
 	}
@@ -884,7 +1697,21 @@ func (fs *Share) createFile(name string, req *CreateRequest, followSymlinks bool
 		return nil, &InvalidResponseError{"broken create response format"}
 	}
 
+	contexts, err := parseCreateContexts(r.CreateContexts())
+	if err != nil {
+		fs.closeOpened(r.FileId())
+		return nil, err
+	}
+
 	f = fs.newFile(r.FileId(), name)
+	f.followed = req.CreateOptions&FILE_OPEN_REPARSE_POINT == 0
+	f.contexts = contexts
+	f.attrs = r.FileAttributes()
+	f.endOfFile = r.EndofFile()
+	if f.attrs&(FILE_ATTRIBUTE_ARCHIVE|FILE_ATTRIBUTE_DIRECTORY) != 0 {
+		f._archived = 1
+	}
+	f.grantOplock(r.OplockLevel())
 
 	return f, nil
 }
@@ -892,11 +1719,6 @@ func (fs *Share) createFile(name string, req *CreateRequest, followSymlinks bool
 func (fs *Share) createFileRec(name string, req *CreateRequest) (f *File, err error) {
 	for i := 0; i < clientMaxSymlinkDepth; i++ {
 		req.CreditCharge, _, err = fs.loanCredit(0)
-		defer func() {
-			if err != nil {
-				fs.chargeCredit(req.CreditCharge)
-			}
-		}()
 		if err != nil {
 			return nil, err
 		}
@@ -922,7 +1744,21 @@ func (fs *Share) createFileRec(name string, req *CreateRequest) (f *File, err er
 			return nil, &InvalidResponseError{"broken create response format"}
 		}
 
+		contexts, err := parseCreateContexts(r.CreateContexts())
+		if err != nil {
+			fs.closeOpened(r.FileId())
+			return nil, err
+		}
+
 		f = fs.newFile(r.FileId(), name)
+		f.followed = req.CreateOptions&FILE_OPEN_REPARSE_POINT == 0
+		f.contexts = contexts
+		f.attrs = r.FileAttributes()
+		f.endOfFile = r.EndofFile()
+		if f.attrs&(FILE_ATTRIBUTE_ARCHIVE|FILE_ATTRIBUTE_DIRECTORY) != 0 {
+			f._archived = 1
+		}
+		f.grantOplock(r.OplockLevel())
 
 		return f, nil
 	}
@@ -930,6 +1766,22 @@ func (fs *Share) createFileRec(name string, req *CreateRequest) (f *File, err er
 	return nil, &InternalError{"Too many levels of symbolic links"}
 }
 
+// closeOpened closes the handle of a successful CREATE response which the client can't use,
+// so that it isn't leaked on the server. The open file count is released by the caller.
+func (fs *Share) closeOpened(fd FileIdDecoder) {
+	req := &CloseRequest{FileId: fd.Decode()}
+
+	var err error
+
+	req.CreditCharge, _, err = fs.loanCredit(0)
+	if err == nil {
+		_, err = fs.sendRecv(SMB2_CLOSE, req)
+	}
+	if err != nil {
+		logger.Println("close:", err)
+	}
+}
+
 func evalSymlinkError(name string, errData []byte) (string, error) {
 	d := SymbolicLinkErrorResponseDecoder(errData)
 	if d.IsInvalid() {
@@ -982,11 +1834,34 @@ type File struct {
 	dirents     []os.FileInfo
 	noMoreFiles bool
 
+	// followed is set if the file was opened without FILE_OPEN_REPARSE_POINT,
+	// in which case it's the target of the symbolic links rather than a link.
+	followed bool
+
+	contexts  map[string][]byte // create contexts of the CREATE response
+	attrs     uint32            // file attributes of the CREATE response
+	endOfFile int64             // end of file of the CREATE response
+	oplock    *oplock           // oplock granted by the CREATE response, nil if none was
+
+	_archived int32 // the archive bit needn't be set by a write (accessed atomically)
+	_stale    int32 // the handle is closed or revoked on the server (accessed atomically)
+	_closed   int32 // Close has been called (accessed atomically)
+	_released int32 // the handle has been closed (accessed atomically)
+
+	stack []byte // stack which opened the file if Dialer.WarnOnLeak is set, nil otherwise
+
+	sectorSize int64 // alignment of the reads and writes if the file is opened with OpenOptions.NoBuffering, zero otherwise
+
+	ctx    context.Context    // context of the share which opened the file, by which the CLOSE request is sent
+	cancel context.CancelFunc // cancels the context of fs, nil if the file isn't opened by newFile
+
 	offset int64
 
 	m sync.Mutex
 }
 
+// Close closes the file. The calls outstanding on the file, such as a read on a pipe waiting for a message,
+// return os.ErrClosed rather than blocking the close.
 func (f *File) Close() error {
 	if f == nil {
 		return os.ErrInvalid
@@ -1000,20 +1875,43 @@ func (f *File) Close() error {
 }
 
 func (f *File) close() error {
-	if f == nil || f.fd == nil {
+	if f == nil || atomic.LoadInt32(&f._released) != 0 {
 		return os.ErrInvalid
 	}
 
+	// the handle is dropped even if the CLOSE fails (e.g. by a transport error or a timeout),
+	// since the file can't be closed again
+	defer f.release()
+
+	// the outstanding requests (e.g. a read on a pipe waiting for a message) fail with os.ErrClosed
+	// rather than keeping the close waiting, the server completes them once the handle is closed
+	fs := f.fs
+	if f.cancel != nil {
+		atomic.StoreInt32(&f._closed, 1)
+		f.cancel()
+
+		fs = fs.WithContext(f.ctx)
+	}
+
 	req := &CloseRequest{
 		Flags: 0,
 	}
 
-	req.CreditCharge = 1
+	var err error
+
+	req.CreditCharge, _, err = fs.loanCredit(0)
+	if err != nil {
+		return err
+	}
 
 	req.FileId = f.fd
 
-	res, err := f.sendRecv(SMB2_CLOSE, req)
+	res, err := f.sendRecvBy(fs, SMB2_CLOSE, req)
 	if err != nil {
+		if err == ErrStaleHandle {
+			// the server has already released the handle
+			return nil
+		}
 		return err
 	}
 
@@ -1022,11 +1920,28 @@ func (f *File) close() error {
 		return &InvalidResponseError{"broken close response format"}
 	}
 
-	f.fd = nil
+	return nil
+}
 
-	runtime.SetFinalizer(f, nil)
+// finalize closes the file garbage collected without being closed, unless its stack is recorded,
+// in which case it only warns of the leak.
+func (f *File) finalize() {
+	if f.stack != nil {
+		leakLogger.Printf("file %s was garbage collected without being closed, opened by:\n%s", f.name, f.stack)
+		return
+	}
 
-	return nil
+	f.close()
+}
+
+// release marks the handle as closed, and uncounts it from the open files of the session.
+func (f *File) release() {
+	if atomic.CompareAndSwapInt32(&f._released, 0, 1) {
+		f.dropOplock()
+		f.fs.releaseFile()
+	}
+
+	runtime.SetFinalizer(f, nil)
 }
 
 func (f *File) remove() error {
@@ -1049,6 +1964,16 @@ func (f *File) Name() string {
 	return f.name
 }
 
+// Handle returns the SMB2 FileId of the open, the persistent part followed by the volatile part,
+// as sent in the requests on the file. With func (*Session) SessionID and func (*Share) TreeID,
+// it identifies the open in packet captures or in requests built by other tools.
+// The id is a copy, and it's meaningless once the file is closed.
+func (f *File) Handle() [16]byte {
+	var h [16]byte
+	f.fd.Encode(h[:])
+	return h
+}
+
 func (f *File) Read(b []byte) (n int, err error) {
 	f.m.Lock()
 	defer f.m.Unlock()
@@ -1058,7 +1983,10 @@ func (f *File) Read(b []byte) (n int, err error) {
 		return -1, &os.PathError{Op: "read", Path: f.name, Err: err}
 	}
 
-	n, err = f.readAt(b, off)
+	err = f.fs.retry(true, func() (err error) {
+		n, err = f.readAt(b, off)
+		return
+	})
 	if n != 0 {
 		if _, e := f.seek(off+int64(n), io.SeekStart); err == nil {
 			err = e
@@ -1070,23 +1998,59 @@ func (f *File) Read(b []byte) (n int, err error) {
 		}
 		return n, &os.PathError{Op: "read", Path: f.name, Err: err}
 	}
+	if n == 0 && len(b) != 0 {
+		return 0, io.EOF
+	}
 
 	return
 }
 
 // ReadAt implements io.ReaderAt.
+// It returns io.EOF if fewer than len(b) bytes are read because the end of file is reached.
 func (f *File) ReadAt(b []byte, off int64) (n int, err error) {
 	if off < 0 {
 		return -1, os.ErrInvalid
 	}
 
-	n, err = f.readAt(b, off)
+	err = f.fs.retry(true, func() (err error) {
+		n, err = f.readAt(b, off)
+		return
+	})
+	if err != nil {
+		if err, ok := err.(*ResponseError); ok && NtStatus(err.Code) == STATUS_END_OF_FILE {
+			return n, io.EOF
+		}
+		return n, &os.PathError{Op: "read", Path: f.name, Err: err}
+	}
+	if n < len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// ReadAtBuffer is like ReadAt, but the payload of each READ response is received directly into b
+// instead of being copied from an intermediate buffer.
+// Reads larger than the max read size are split into chunks as usual.
+// Responses which can't be received directly (e.g. encrypted ones) are copied into b.
+// If an error is returned, the contents of b are unspecified.
+func (f *File) ReadAtBuffer(b []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return -1, os.ErrInvalid
+	}
+
+	err = f.fs.retry(true, func() (err error) {
+		n, err = f.readAtBuffer(b, off)
+		return
+	})
 	if err != nil {
 		if err, ok := err.(*ResponseError); ok && NtStatus(err.Code) == STATUS_END_OF_FILE {
 			return n, io.EOF
 		}
 		return n, &os.PathError{Op: "read", Path: f.name, Err: err}
 	}
+	if n < len(b) {
+		return n, io.EOF
+	}
 	return n, nil
 }
 
@@ -1094,27 +2058,23 @@ const winMaxPayloadSize = 1024 * 1024 // windows system don't accept more than 1
 const singleCreditMaxPayloadSize = 64 * 1024
 
 func (f *File) maxReadSize() int {
-	size := int(f.fs.maxReadSize)
-	if size > winMaxPayloadSize {
-		size = winMaxPayloadSize
+	size := f.fs.negotiatedMaxReadSize()
+	if n := f.fs.readChunkSize(); n > 0 && n < size {
+		size = n
 	}
-	if f.fs.conn.capabilities&SMB2_GLOBAL_CAP_LARGE_MTU == 0 {
-		if size > singleCreditMaxPayloadSize {
-			size = singleCreditMaxPayloadSize
-		}
+	if n := f.fs.maxReadChunk; n > 0 && n < size {
+		size = n
 	}
 	return size
 }
 
 func (f *File) maxWriteSize() int {
-	size := int(f.fs.maxWriteSize)
-	if size > winMaxPayloadSize {
-		size = winMaxPayloadSize
+	size := f.fs.negotiatedMaxWriteSize()
+	if n := f.fs.writeChunkSize(); n > 0 && n < size {
+		size = n
 	}
-	if f.fs.conn.capabilities&SMB2_GLOBAL_CAP_LARGE_MTU == 0 {
-		if size > singleCreditMaxPayloadSize {
-			size = singleCreditMaxPayloadSize
-		}
+	if n := f.fs.maxWriteChunk; n > 0 && n < size {
+		size = n
 	}
 	return size
 }
@@ -1133,60 +2093,83 @@ func (f *File) maxTransactSize() int {
 }
 
 func (f *File) readAt(b []byte, off int64) (n int, err error) {
+	return f.readAtChunks(b, off, false)
+}
+
+func (f *File) readAtBuffer(b []byte, off int64) (n int, err error) {
+	return f.readAtChunks(b, off, true)
+}
+
+func (f *File) readAtChunks(b []byte, off int64, direct bool) (n int, err error) {
 	if off < 0 {
 		return -1, os.ErrInvalid
 	}
 
-	maxReadSize := f.maxReadSize()
+	if !f.aligned(len(b), off) {
+		return 0, ErrMisaligned
+	}
 
-	for {
-		switch {
-		case len(b)-n == 0:
-			return n, nil
-		case len(b)-n <= maxReadSize:
-			bs, isEOF, err := f.readAtChunk(len(b)-n, int64(n)+off)
-			if err != nil {
-				if err, ok := err.(*ResponseError); ok && NtStatus(err.Code) == STATUS_END_OF_FILE && n != 0 {
-					return n, nil
-				}
-				return 0, err
-			}
+	maxReadSize := f.maxReadSize()
 
-			n += copy(b[n:], bs)
+	for n < len(b) {
+		size := len(b) - n
+		if size > maxReadSize {
+			size = maxReadSize
+		}
 
-			if isEOF {
+		m, err := f.readAtChunk(b[n:n+size], int64(n)+off, direct)
+		if err != nil {
+			if err, ok := err.(*ResponseError); ok && NtStatus(err.Code) == STATUS_END_OF_FILE && n != 0 {
 				return n, nil
 			}
-		default:
-			bs, isEOF, err := f.readAtChunk(maxReadSize, int64(n)+off)
-			if err != nil {
-				if err, ok := err.(*ResponseError); ok && NtStatus(err.Code) == STATUS_END_OF_FILE && n != 0 {
-					return n, nil
-				}
-				return 0, err
-			}
+			return 0, err
+		}
 
-			n += copy(b[n:], bs)
+		// servers may return fewer bytes than requested before the end of file,
+		// so only STATUS_END_OF_FILE or an empty read ends the loop.
+		if m == 0 {
+			break
+		}
 
-			if isEOF {
-				return n, nil
-			}
+		n += m
+
+		// a short read of a pipe is a whole message, the next read would wait for the next message
+		if m < size && f.fs.shareType == SMB2_SHARE_TYPE_PIPE {
+			break
 		}
 	}
+
+	return n, nil
 }
 
-func (f *File) readAtChunk(n int, off int64) (bs []byte, isEOF bool, err error) {
-	creditCharge, m, err := f.fs.loanCredit(n)
-	defer func() {
-		if err != nil {
-			f.fs.chargeCredit(creditCharge)
-		}
-	}()
+// readPipe reads a message, or a part of it if b is too small, from a named pipe into b.
+// Unlike readAt, it doesn't retry short reads, which are normal for pipes.
+func (f *File) readPipe(b []byte) (n int, err error) {
+	if maxReadSize := f.maxReadSize(); len(b) > maxReadSize {
+		b = b[:maxReadSize]
+	}
+	return f.readAtChunk(b, 0, false)
+}
+
+// readAtChunk reads up to len(b) bytes, which must not exceed the max read size, into b.
+// If direct is true, the payload is received directly into b when possible.
+func (f *File) readAtChunk(b []byte, off int64, direct bool) (n int, err error) {
+	if atomic.LoadInt32(&f._stale) != 0 {
+		return 0, ErrStaleHandle
+	}
+
+	creditCharge, m, err := f.fs.loanCredit(len(b))
+	if err != nil {
+		return 0, f.handleError(err)
+	}
+
+	err = f.fs.readLimiter.wait(m, f.fs.ctx)
 	if err != nil {
-		return nil, false, err
+		f.fs.chargeCredit(creditCharge)
+		return 0, f.handleError(err)
 	}
 
-	req := &ReadRequest{
+	rreq := &ReadRequest{
 		Padding:         0,
 		Flags:           0,
 		Length:          uint32(m),
@@ -1197,35 +2180,102 @@ func (f *File) readAtChunk(n int, off int64) (bs []byte, isEOF bool, err error)
 		ReadChannelInfo: nil,
 	}
 
-	req.FileId = f.fd
+	rreq.FileId = f.fd
 
-	req.CreditCharge = creditCharge
+	rreq.CreditCharge = creditCharge
+
+	var req Packet = rreq
+	if direct {
+		req = &directReadRequest{ReadRequest: rreq, dst: b[:m]}
+	}
 
-	res, err := f.sendRecv(SMB2_READ, req)
+	rr, err := f.fs.send(req, f.fs.ctx)
 	if err != nil {
-		return nil, false, err
+		return 0, f.handleError(err)
 	}
 
-	r := ReadResponseDecoder(res)
-	if r.IsInvalid() {
-		return nil, false, &InvalidResponseError{"broken read response format"}
+	pkt, err := f.fs.recv(rr)
+	if err != nil {
+		return 0, f.handleError(err)
+	}
+
+	res, err := accept(SMB2_READ, pkt)
+	if err != nil && res == nil {
+		return 0, f.handleError(err)
 	}
 
-	bs = r.Data()
+	// STATUS_BUFFER_OVERFLOW is returned with the data, if the rest of a pipe message doesn't fit in b
+	overflow := err
+
+	r := ReadResponseDecoder(res)
+
+	if rr.direct {
+		// the payload is already in b
+		if len(r) < 16 || r.StructureSize() != 17 {
+			return 0, &InvalidResponseError{"broken read response format"}
+		}
+
+		n = int(r.DataLength())
+	} else {
+		if r.IsInvalid() {
+			return 0, &InvalidResponseError{"broken read response format"}
+		}
+
+		data := r.Data()
+		if len(data) > m {
+			return 0, &InvalidResponseError{"read response exceeds the requested length"}
+		}
 
-	return bs, len(bs) < m, nil
+		n = copy(b, data)
+
+		if rr.recvPooled {
+			putBuffer(pkt)
+		}
+	}
+
+	return n, overflow
 }
 
+// Readdir reads the contents of the directory like func (*os.File) Readdir.
+// Like func (*Share) ReadDir, the entries are filled from the enumeration.
 func (f *File) Readdir(n int) (fi []os.FileInfo, err error) {
 	f.m.Lock()
 	defer f.m.Unlock()
 
+	return f.readdirN(f.fs, n)
+}
+
+// ReaddirContext is like func (*File) Readdir, but the enumeration is bound to ctx rather than the context of the share.
+// If ctx is done in the middle of it, the entries read so far, up to n, are returned with ctx.Err(),
+// and the next call goes on with the enumeration.
+func (f *File) ReaddirContext(ctx context.Context, n int) (fi []os.FileInfo, err error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	fi, err = f.readdirN(f.fs.WithContext(ctx), n)
+	if err != nil && ctx.Err() != nil {
+		// the entries read before ctx was done are returned rather than kept for the next call
+		fi = f.dirents
+		if n > 0 && len(fi) > n {
+			fi, f.dirents = fi[:n], fi[n:]
+		} else {
+			f.dirents = []os.FileInfo{}
+		}
+		return fi, ctx.Err()
+	}
+
+	return fi, err
+}
+
+// readdirN reads the next n entries of the directory by fs, or all of them if n <= 0, like func (*File) Readdir.
+// f.m must be held.
+func (f *File) readdirN(fs *Share, n int) (fi []os.FileInfo, err error) {
 	if !f.noMoreFiles {
 		if f.dirents == nil {
 			f.dirents = []os.FileInfo{}
 		}
 		for n <= 0 || n > len(f.dirents) {
-			dirents, err := f.readdir()
+			dirents, err := f.readdir(fs, 0)
 			if len(dirents) > 0 {
 				f.dirents = append(f.dirents, dirents...)
 			}
@@ -1295,25 +2345,12 @@ func (f *File) seek(offset int64, whence int) (ret int64, err error) {
 	case io.SeekCurrent:
 		f.offset += offset
 	case io.SeekEnd:
-		req := &QueryInfoRequest{
-			InfoType:              SMB2_0_INFO_FILE,
-			FileInfoClass:         FileStandardInformation,
-			AdditionalInformation: 0,
-			Flags:                 0,
-			OutputBufferLength:    24,
-		}
-
-		infoBytes, err := f.queryInfo(req)
+		size, err := f.size()
 		if err != nil {
 			return -1, err
 		}
 
-		info := FileStandardInformationDecoder(infoBytes)
-		if info.IsInvalid() {
-			return -1, &InvalidResponseError{"broken query info response format"}
-		}
-
-		f.offset = offset + info.EndOfFile()
+		f.offset = offset + size
 	default:
 		return -1, os.ErrInvalid
 	}
@@ -1321,50 +2358,188 @@ func (f *File) seek(offset int64, whence int) (ret int64, err error) {
 	return f.offset, nil
 }
 
+// size returns the end of file of f.
+func (f *File) size() (int64, error) {
+	req := &QueryInfoRequest{
+		InfoType:              SMB2_0_INFO_FILE,
+		FileInfoClass:         FileStandardInformation,
+		AdditionalInformation: 0,
+		Flags:                 0,
+		OutputBufferLength:    24,
+	}
+
+	infoBytes, err := f.queryInfo(req)
+	if err != nil {
+		return -1, err
+	}
+
+	info := FileStandardInformationDecoder(infoBytes)
+	if info.IsInvalid() {
+		return -1, &InvalidResponseError{"broken query info response format"}
+	}
+
+	return info.EndOfFile(), nil
+}
+
 func (f *File) Stat() (os.FileInfo, error) {
-	fi, err := f.stat()
+	var fi os.FileInfo
+	err := f.fs.retry(true, func() (err error) {
+		fi, err = f.stat()
+		return
+	})
+	if err != nil {
+		return nil, &os.PathError{Op: "stat", Path: f.name, Err: err}
+	}
+	return fi, nil
+}
+
+func (f *File) stat() (os.FileInfo, error) {
+	req := &QueryInfoRequest{
+		InfoType:              SMB2_0_INFO_FILE,
+		FileInfoClass:         FileAllInformation,
+		AdditionalInformation: 0,
+		Flags:                 0,
+		OutputBufferLength:    uint32(f.maxTransactSize()),
+	}
+
+	var infoBytes, tagBytes []byte
+	var err error
+	if f.attrs&FILE_ATTRIBUTE_REPARSE_POINT != 0 {
+		// the CREATE response tells that the file is a reparse point, so its tag is queried in the same round trip
+		infoBytes, tagBytes, err = f.queryInfoWithTag(req)
+	} else {
+		infoBytes, err = f.queryInfo(req)
+	}
+	if err != nil {
+		if _, ok := err.(*ResponseError); ok && f.fs.shareType == SMB2_SHARE_TYPE_PIPE {
+			// the named pipe file systems of some servers don't support FileAllInformation
+			return f.statPipe()
+		}
+		return nil, err
+	}
+
+	info := FileAllInformationDecoder(infoBytes)
+	if info.IsInvalid() {
+		return nil, &InvalidResponseError{"broken query info response format"}
+	}
+
+	basic := info.BasicInformation()
+	std := info.StandardInformation()
+
+	fi := &FileStat{
+		CreationTime:   time.Unix(0, basic.CreationTime().Nanoseconds()),
+		LastAccessTime: time.Unix(0, basic.LastAccessTime().Nanoseconds()),
+		LastWriteTime:  time.Unix(0, basic.LastWriteTime().Nanoseconds()),
+		ChangeTime:     time.Unix(0, basic.ChangeTime().Nanoseconds()),
+		EndOfFile:      std.EndOfFile(),
+		AllocationSize: std.AllocationSize(),
+		FileAttributes: basic.FileAttributes(),
+		FileName:       base(f.name),
+		followed:       f.followed,
+		pipe:           f.fs.shareType == SMB2_SHARE_TYPE_PIPE,
+	}
+
+	if fi.FileAttributes&FILE_ATTRIBUTE_REPARSE_POINT != 0 && tagBytes != nil {
+		tag := FileAttributeTagInformationDecoder(tagBytes)
+		if tag.IsInvalid() {
+			return nil, &InvalidResponseError{"broken query info response format"}
+		}
+		fi.ReparseTag = tag.ReparseTag()
+	}
+
+	return fi, nil
+}
+
+// queryInfoWithTag sends the QUERY_INFO request compounded with one of FileAttributeTagInformation,
+// and returns the output of req and the reparse tag information. The tag information is nil if the server
+// doesn't report it.
+func (f *File) queryInfoWithTag(req *QueryInfoRequest) (infoBytes, tagBytes []byte, err error) {
+	tagReq := &QueryInfoRequest{
+		InfoType:              SMB2_0_INFO_FILE,
+		FileInfoClass:         FileAttributeTagInformation,
+		AdditionalInformation: 0,
+		Flags:                 0,
+		OutputBufferLength:    8,
+	}
+
+	if f.maxTransactSize() < int(req.OutputBufferLength) {
+		return nil, nil, &InternalError{fmt.Sprintf("payload size %d exceeds max transact size %d", req.OutputBufferLength, f.maxTransactSize())}
+	}
+
+	// the requests take the credits of the QUERY_INFO request and one more
+	charge := f.fs.session.conn.creditCharge(int(req.OutputBufferLength))
+	credits, _, err := f.fs.session.conn.account.loan(charge+1, f.fs.ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if credits < charge+1 {
+		// the server didn't grant enough credits for a compound request yet
+		f.fs.chargeCredit(credits)
+
+		infoBytes, err = f.queryInfo(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		tagBytes, err = f.queryInfo(tagReq)
+		if err != nil {
+			if _, ok := err.(*ResponseError); ok {
+				return infoBytes, nil, nil
+			}
+			return nil, nil, err
+		}
+		return infoBytes, tagBytes, nil
+	}
+
+	req.CreditCharge = charge
+	tagReq.CreditCharge = 1
+
+	if atomic.LoadInt32(&f._stale) != 0 {
+		f.fs.chargeCredit(req.CreditCharge)
+		f.fs.chargeCredit(tagReq.CreditCharge)
+		return nil, nil, ErrStaleHandle
+	}
+
+	req.FileId = f.fd
+	tagReq.FileId = f.fd
+
+	rrs, err := f.fs.sendCompound([]Packet{req, tagReq}, f.fs.treeConn, f.fs.ctx)
 	if err != nil {
-		return nil, &os.PathError{Op: "stat", Path: f.name, Err: err}
+		return nil, nil, f.handleError(err)
 	}
-	return fi, nil
-}
 
-func (f *File) stat() (os.FileInfo, error) {
-	req := &QueryInfoRequest{
-		InfoType:              SMB2_0_INFO_FILE,
-		FileInfoClass:         FileAllInformation,
-		AdditionalInformation: 0,
-		Flags:                 0,
-		OutputBufferLength:    uint32(f.maxTransactSize()),
+	res, err := f.fs.recvCompound(rrs, []uint16{SMB2_QUERY_INFO, SMB2_QUERY_INFO})
+	if res[0] == nil {
+		return nil, nil, f.handleError(err)
 	}
 
-	infoBytes, err := f.queryInfo(req)
-	if err != nil {
-		return nil, err
+	r := QueryInfoResponseDecoder(res[0])
+	if r.IsInvalid() {
+		return nil, nil, &InvalidResponseError{"broken query info response format"}
 	}
+	infoBytes = r.OutputBuffer()
 
-	info := FileAllInformationDecoder(infoBytes)
-	if info.IsInvalid() {
-		return nil, &InvalidResponseError{"broken query info response format"}
+	if res[1] == nil {
+		if _, ok := err.(*ResponseError); ok {
+			return infoBytes, nil, nil
+		}
+		return nil, nil, f.handleError(err)
 	}
 
-	basic := info.BasicInformation()
-	std := info.StandardInformation()
+	r = QueryInfoResponseDecoder(res[1])
+	if r.IsInvalid() {
+		return nil, nil, &InvalidResponseError{"broken query info response format"}
+	}
 
-	return &FileStat{
-		CreationTime:   time.Unix(0, basic.CreationTime().Nanoseconds()),
-		LastAccessTime: time.Unix(0, basic.LastAccessTime().Nanoseconds()),
-		LastWriteTime:  time.Unix(0, basic.LastWriteTime().Nanoseconds()),
-		ChangeTime:     time.Unix(0, basic.ChangeTime().Nanoseconds()),
-		EndOfFile:      std.EndOfFile(),
-		AllocationSize: std.AllocationSize(),
-		FileAttributes: basic.FileAttributes(),
-		FileName:       base(f.name),
-	}, nil
+	return infoBytes, r.OutputBuffer(), nil
 }
 
 func (f *File) Statfs() (FileFsInfo, error) {
-	fi, err := f.statfs()
+	var fi FileFsInfo
+	err := f.fs.retry(true, func() (err error) {
+		fi, err = f.statfs()
+		return
+	})
 	if err != nil {
 		return nil, &os.PathError{Op: "statfs", Path: f.name, Err: err}
 	}
@@ -1435,16 +2610,14 @@ func (f *File) statfs() (FileFsInfo, error) {
 	}, nil
 }
 
+// Sync commits the contents of the file to stable storage by a SMB2 FLUSH request,
+// which the server only answers once the data are flushed.
+// Writes aren't buffered by the client, so all the data written before are flushed.
 func (f *File) Sync() (err error) {
 	req := new(FlushRequest)
 	req.FileId = f.fd
 
 	req.CreditCharge, _, err = f.fs.loanCredit(0)
-	defer func() {
-		if err != nil {
-			f.fs.chargeCredit(req.CreditCharge)
-		}
-	}()
 	if err != nil {
 		return &os.PathError{Op: "sync", Path: f.name, Err: err}
 	}
@@ -1467,7 +2640,9 @@ func (f *File) Truncate(size int64) error {
 		return os.ErrInvalid
 	}
 
-	err := f.truncate(size)
+	err := f.fs.retry(false, func() error {
+		return f.truncate(size)
+	})
 	if err != nil {
 		return &os.PathError{Op: "truncate", Path: f.name, Err: err}
 	}
@@ -1490,8 +2665,52 @@ func (f *File) truncate(size int64) error {
 	return nil
 }
 
+// SetFileTime sets the creation, access, write and change times of the file.
+// A nil time is left unchanged. SuppressTimeUpdate keeps the server from updating the timestamp
+// by the following operations on f.
+func (f *File) SetFileTime(created, accessed, written, changed *time.Time) error {
+	err := f.fs.retry(false, func() error {
+		return f.setFileTime(created, accessed, written, changed)
+	})
+	if err != nil {
+		return &os.PathError{Op: "setfiletime", Path: f.name, Err: err}
+	}
+	return nil
+}
+
+func (f *File) setFileTime(created, accessed, written, changed *time.Time) error {
+	info := &SetInfoRequest{
+		FileInfoClass:         FileBasicInformation,
+		AdditionalInformation: 0,
+		Input: &FileBasicInformationEncoder{
+			CreationTime:   toFiletime(created),
+			LastAccessTime: toFiletime(accessed),
+			LastWriteTime:  toFiletime(written),
+			ChangeTime:     toFiletime(changed),
+		},
+	}
+
+	return f.setInfo(info)
+}
+
+// toFiletime returns the FILETIME of a time of SetFileTime.
+// nil (and the zero time) is encoded as 0, which leaves the timestamp unchanged,
+// and SuppressTimeUpdate as -1.
+func toFiletime(t *time.Time) *Filetime {
+	switch {
+	case t == nil || t.IsZero():
+		return nil
+	case t.Equal(SuppressTimeUpdate):
+		return &Filetime{LowDateTime: 0xffffffff, HighDateTime: 0xffffffff}
+	default:
+		return NsecToFiletime(t.UnixNano())
+	}
+}
+
 func (f *File) Chmod(mode os.FileMode) error {
-	err := f.chmod(mode)
+	err := f.fs.retry(false, func() error {
+		return f.chmod(mode)
+	})
 	if err != nil {
 		return &os.PathError{Op: "chmod", Path: f.name, Err: err}
 	}
@@ -1499,6 +2718,43 @@ func (f *File) Chmod(mode os.FileMode) error {
 }
 
 func (f *File) chmod(mode os.FileMode) error {
+	return f.modifyAttributes(func(attrs uint32) uint32 {
+		if mode&0200 != 0 {
+			return attrs &^ FILE_ATTRIBUTE_READONLY
+		}
+		return attrs | FILE_ATTRIBUTE_READONLY
+	})
+}
+
+// ClearArchiveBit clears the archive bit of the file, which marks it as backed up.
+// The bit is set again by the following writes on any handle, like NTFS does.
+func (f *File) ClearArchiveBit() error {
+	err := f.fs.retry(false, func() error {
+		return f.modifyAttributes(func(attrs uint32) uint32 {
+			return attrs &^ FILE_ATTRIBUTE_ARCHIVE
+		})
+	})
+	if err != nil {
+		return &os.PathError{Op: "cleararchivebit", Path: f.name, Err: err}
+	}
+	atomic.StoreInt32(&f._archived, 0)
+	return nil
+}
+
+// setArchiveBit sets the archive bit of the file after a write, for the servers which don't set it like NTFS.
+// It's done once per handle, and a failure doesn't fail the write,
+// since the handle may lack the access to the attributes.
+func (f *File) setArchiveBit() {
+	if f.fs.shareType == SMB2_SHARE_TYPE_PIPE || !atomic.CompareAndSwapInt32(&f._archived, 0, 1) {
+		return
+	}
+	f.modifyAttributes(func(attrs uint32) uint32 {
+		return attrs | FILE_ATTRIBUTE_ARCHIVE
+	})
+}
+
+// modifyAttributes queries the attributes of the file and sets them to modify(attrs), unless it's unchanged.
+func (f *File) modifyAttributes(modify func(attrs uint32) uint32) error {
 	req := &QueryInfoRequest{
 		InfoType:              SMB2_0_INFO_FILE,
 		FileInfoClass:         FileBasicInformation,
@@ -1517,12 +2773,13 @@ func (f *File) chmod(mode os.FileMode) error {
 		return &InvalidResponseError{"broken query info response format"}
 	}
 
-	attrs := base.FileAttributes()
-
-	if mode&0200 != 0 {
-		attrs &^= FILE_ATTRIBUTE_READONLY
-	} else {
-		attrs |= FILE_ATTRIBUTE_READONLY
+	attrs := modify(base.FileAttributes())
+	if attrs == base.FileAttributes() {
+		return nil
+	}
+	if attrs == 0 {
+		// zero leaves the attributes unchanged, so clearing all of them needs FILE_ATTRIBUTE_NORMAL
+		attrs = FILE_ATTRIBUTE_NORMAL
 	}
 
 	info := &SetInfoRequest{
@@ -1549,7 +2806,10 @@ func (f *File) Write(b []byte) (n int, err error) {
 		return -1, &os.PathError{Op: "write", Path: f.name, Err: err}
 	}
 
-	n, err = f.writeAt(b, off)
+	err = f.fs.retry(false, func() (err error) {
+		n, err = f.writeAt(b, off)
+		return
+	})
 	if n != 0 {
 		if _, e := f.seek(off+int64(n), io.SeekStart); err == nil {
 			err = e
@@ -1564,7 +2824,10 @@ func (f *File) Write(b []byte) (n int, err error) {
 
 // WriteAt implements io.WriterAt.
 func (f *File) WriteAt(b []byte, off int64) (n int, err error) {
-	n, err = f.writeAt(b, off)
+	err = f.fs.retry(false, func() (err error) {
+		n, err = f.writeAt(b, off)
+		return
+	})
 	if err != nil {
 		return n, &os.PathError{Op: "write", Path: f.name, Err: err}
 	}
@@ -1580,11 +2843,17 @@ func (f *File) writeAt(b []byte, off int64) (n int, err error) {
 		return 0, nil
 	}
 
+	if !f.aligned(len(b), off) {
+		return 0, ErrMisaligned
+	}
+
 	maxWriteSize := f.maxWriteSize()
 
 	for {
 		switch {
 		case len(b)-n == 0:
+			f.setArchiveBit()
+
 			return n, nil
 		case len(b)-n <= maxWriteSize:
 			m, err := f.writeAtChunk(b[n:], int64(n)+off)
@@ -1604,16 +2873,23 @@ func (f *File) writeAt(b []byte, off int64) (n int, err error) {
 	}
 }
 
+// aligned reports whether a read or write of n bytes at off is aligned to the sector size,
+// which is only required if the file is opened with OpenOptions.NoBuffering.
+func (f *File) aligned(n int, off int64) bool {
+	return f.sectorSize == 0 || (int64(n)%f.sectorSize == 0 && off%f.sectorSize == 0)
+}
+
 // writeAt allows partial write
 func (f *File) writeAtChunk(b []byte, off int64) (n int, err error) {
 	creditCharge, m, err := f.fs.loanCredit(len(b))
-	defer func() {
-		if err != nil {
-			f.fs.chargeCredit(creditCharge)
-		}
-	}()
 	if err != nil {
-		return 0, err
+		return 0, f.handleError(err)
+	}
+
+	err = f.fs.writeLimiter.wait(m, f.fs.ctx)
+	if err != nil {
+		f.fs.chargeCredit(creditCharge)
+		return 0, f.handleError(err)
 	}
 
 	req := &WriteRequest{
@@ -1673,6 +2949,36 @@ func (f *File) copyTo(wf *File) (supported bool, n int64, err error) {
 	f.m.Lock()
 	defer f.m.Unlock()
 
+	off, err := f.seek(0, io.SeekCurrent)
+	if err != nil {
+		return true, -1, &os.LinkError{Op: "copy", Old: f.name, New: wf.name, Err: err}
+	}
+
+	end, err := f.size()
+	if err != nil {
+		return true, -1, &os.LinkError{Op: "copy", Old: f.name, New: wf.name, Err: err}
+	}
+
+	woff, err := wf.seek(0, io.SeekCurrent)
+	if err != nil {
+		return true, -1, &os.LinkError{Op: "copy", Old: f.name, New: wf.name, Err: err}
+	}
+
+	if end < off {
+		end = off
+	}
+
+	supported, n, err = f.copyRange(wf, off, woff, end-off)
+	if err != nil {
+		return supported, -1, &os.LinkError{Op: "copy", Old: f.name, New: wf.name, Err: err}
+	}
+	return supported, n, nil
+}
+
+// copyRange copies length bytes of f from off to wf at woff by server-side copy (FSCTL_SRV_COPYCHUNK),
+// and returns the number of bytes copied, which is less than length if the end of f is reached.
+// supported is false if the server doesn't support server-side copy.
+func (f *File) copyRange(wf *File, off, woff, length int64) (supported bool, n int64, err error) {
 	req := &IoctlRequest{
 		CtlCode:           FSCTL_SRV_REQUEST_RESUME_KEY,
 		OutputOffset:      0,
@@ -1688,71 +2994,34 @@ func (f *File) copyTo(wf *File) (supported bool, n int64, err error) {
 			return false, -1, nil
 		}
 
-		return true, -1, &os.LinkError{Op: "copy", Old: f.name, New: wf.name, Err: err}
-
+		return true, -1, err
 	}
 
 	sr := SrvRequestResumeKeyResponseDecoder(output)
 	if sr.IsInvalid() {
-		return true, -1, &os.LinkError{Op: "copy", Old: f.name, New: wf.name, Err: &InvalidResponseError{"broken srv request resume key response format"}}
-	}
-
-	off, err := f.seek(0, io.SeekCurrent)
-	if err != nil {
-		return true, -1, &os.LinkError{Op: "copy", Old: f.name, New: wf.name, Err: err}
-	}
-
-	end, err := f.seek(0, io.SeekEnd)
-	if err != nil {
-		return true, -1, &os.LinkError{Op: "copy", Old: f.name, New: wf.name, Err: err}
-	}
-
-	woff, err := wf.seek(0, io.SeekCurrent)
-	if err != nil {
-		return true, -1, &os.LinkError{Op: "copy", Old: f.name, New: wf.name, Err: err}
+		return true, -1, &InvalidResponseError{"broken srv request resume key response format"}
 	}
 
-	var chunks []*SrvCopychunk
+	// https://msdn.microsoft.com/en-us/library/cc512134(v=vs.85).aspx
+	const maxChunkSize = 1024 * 1024
+	const maxChunks = 16
 
-	remains := end
+	for n < length {
+		var chunks []*SrvCopychunk
 
-	for {
-		const maxChunkSize = 1024 * 1024
-		const maxTotalSize = 16 * 1024 * 1024
-		// https://msdn.microsoft.com/en-us/library/cc512134(v=vs.85).aspx
-
-		if remains < maxTotalSize {
-			nchunks := remains / maxChunkSize
-
-			chunks = make([]*SrvCopychunk, nchunks, nchunks+1)
-			for i := range chunks {
-				chunks[i] = &SrvCopychunk{
-					SourceOffset: off + int64(i)*maxChunkSize,
-					TargetOffset: woff + int64(i)*maxChunkSize,
-					Length:       maxChunkSize,
-				}
+		for remains := length - n; remains > 0 && len(chunks) < maxChunks; {
+			size := int64(maxChunkSize)
+			if remains < size {
+				size = remains
 			}
 
-			remains %= maxChunkSize
-			if remains != 0 {
-				chunks = append(chunks, &SrvCopychunk{
-					SourceOffset: off + int64(nchunks)*maxChunkSize,
-					TargetOffset: woff + int64(nchunks)*maxChunkSize,
-					Length:       uint32(remains),
-				})
-				remains = 0
-			}
-		} else {
-			chunks = make([]*SrvCopychunk, 16)
-			for i := range chunks {
-				chunks[i] = &SrvCopychunk{
-					SourceOffset: off + int64(i)*maxChunkSize,
-					TargetOffset: woff + int64(i)*maxChunkSize,
-					Length:       maxChunkSize,
-				}
-			}
+			chunks = append(chunks, &SrvCopychunk{
+				SourceOffset: off + n + int64(len(chunks))*maxChunkSize,
+				TargetOffset: woff + n + int64(len(chunks))*maxChunkSize,
+				Length:       uint32(size),
+			})
 
-			remains -= maxTotalSize
+			remains -= size
 		}
 
 		scc := &SrvCopychunkCopy{
@@ -1773,60 +3042,38 @@ func (f *File) copyTo(wf *File) (supported bool, n int64, err error) {
 
 		output, err = wf.ioctl(cReq)
 		if err != nil {
-			return true, -1, &os.LinkError{Op: "copy", Old: f.name, New: wf.name, Err: err}
+			return true, -1, err
 		}
 
 		c := SrvCopychunkResponseDecoder(output)
 		if c.IsInvalid() {
-			return true, -1, &os.LinkError{Op: "copy", Old: f.name, New: wf.name, Err: &InvalidResponseError{"broken srv copy chunk response format"}}
+			return true, -1, &InvalidResponseError{"broken srv copy chunk response format"}
 		}
 
-		n += int64(c.TotalBytesWritten())
-
-		if remains == 0 {
-			return true, n, nil
+		written := int64(c.TotalBytesWritten())
+		if written == 0 {
+			// the end of f is reached
+			break
 		}
+
+		n += written
 	}
+
+	return true, n, nil
 }
 
 // ReadFrom implements io.ReadFrom.
 // If r is *File on the same *Share as f, it invokes server-side copy.
+// Otherwise, r is read into pipelined writes of the default CopyOptions.
 func (f *File) ReadFrom(r io.Reader) (n int64, err error) {
-	rf, ok := r.(*File)
-	if ok && rf.fs == f.fs {
-		if supported, n, err := rf.copyTo(f); supported {
-			return n, err
-		}
-
-		maxBufferSize := f.maxReadSize()
-		if maxWriteSize := f.maxWriteSize(); maxWriteSize < maxBufferSize {
-			maxBufferSize = maxWriteSize
-		}
-
-		return copyBuffer(r, f, make([]byte, maxBufferSize))
-	}
-
-	return copyBuffer(r, f, make([]byte, f.maxWriteSize()))
+	return f.ReadFromWithOptions(r, nil)
 }
 
 // WriteTo implements io.WriteTo.
 // If w is *File on the same *Share as f, it invokes server-side copy.
+// Otherwise, w is written from pipelined reads of the default CopyOptions.
 func (f *File) WriteTo(w io.Writer) (n int64, err error) {
-	wf, ok := w.(*File)
-	if ok && wf.fs == f.fs {
-		if supported, n, err := f.copyTo(wf); supported {
-			return n, err
-		}
-
-		maxBufferSize := f.maxReadSize()
-		if maxWriteSize := f.maxWriteSize(); maxWriteSize < maxBufferSize {
-			maxBufferSize = maxWriteSize
-		}
-
-		return copyBuffer(f, w, make([]byte, maxBufferSize))
-	}
-
-	return copyBuffer(f, w, make([]byte, f.maxReadSize()))
+	return f.WriteToWithOptions(w, nil)
 }
 
 func (f *File) WriteString(s string) (n int, err error) {
@@ -1851,11 +3098,6 @@ func (f *File) ioctl(req *IoctlRequest) (output []byte, err error) {
 	}
 
 	req.CreditCharge, _, err = f.fs.loanCredit(payloadSize)
-	defer func() {
-		if err != nil {
-			f.fs.chargeCredit(req.CreditCharge)
-		}
-	}()
 	if err != nil {
 		return nil, err
 	}
@@ -1879,10 +3121,85 @@ func (f *File) ioctl(req *IoctlRequest) (output []byte, err error) {
 	return r.Output(), nil
 }
 
-func (f *File) readdir() (fi []os.FileInfo, err error) {
+// readdirAll reads the rest of the entries of the directory until STATUS_NO_MORE_FILES, the first request having flags.
+// The requests are sent by fs, and it stops before reading another page if the context of fs is done. If it fails, the entries read so far are returned with the error.
+func (f *File) readdirAll(fs *Share, flags uint8) ([]os.FileInfo, error) {
+	fis := []os.FileInfo{}
+
+	for {
+		if err := fs.ctx.Err(); err != nil {
+			return fis, &ContextError{Err: err}
+		}
+
+		dirents, err := f.readdir(fs, flags)
+		fis = append(fis, dirents...)
+		if err != nil {
+			if rerr, ok := err.(*ResponseError); ok && NtStatus(rerr.Code) == STATUS_NO_MORE_FILES {
+				return fis, nil
+			}
+			return fis, err
+		}
+
+		flags = 0
+	}
+}
+
+// dirInfoClasses are the information classes of the directory enumeration, from the richest to the simplest.
+// Some minimal servers reject the richer ones with STATUS_INVALID_INFO_CLASS, so the next one is tried,
+// and the tree connection remembers the one accepted.
+var dirInfoClasses = []uint8{
+	FileIdBothDirectoryInformation,
+	FileBothDirectoryInformation,
+	FileFullDirectoryInformation,
+	FileDirectoryInformation,
+}
+
+// dirInfoDecoder decodes an entry of any of dirInfoClasses.
+type dirInfoDecoder interface {
+	IsInvalid() bool
+	NextEntryOffset() uint32
+	CreationTime() FiletimeDecoder
+	LastAccessTime() FiletimeDecoder
+	LastWriteTime() FiletimeDecoder
+	ChangeTime() FiletimeDecoder
+	EndOfFile() int64
+	AllocationSize() int64
+	FileAttributes() uint32
+	FileName() string
+}
+
+func newDirInfoDecoder(class uint8, b []byte) dirInfoDecoder {
+	switch class {
+	case FileIdBothDirectoryInformation:
+		return FileIdBothDirectoryInformationDecoder(b)
+	case FileBothDirectoryInformation:
+		return FileBothDirectoryInformationDecoder(b)
+	case FileFullDirectoryInformation:
+		return FileFullDirectoryInformationDecoder(b)
+	default:
+		return FileDirectoryInformationDecoder(b)
+	}
+}
+
+// readdir reads the next entries of the directory. flags may have RESTART_SCANS to read them from the beginning.
+func (f *File) readdir(fs *Share, flags uint8) (fi []os.FileInfo, err error) {
+	for {
+		level := atomic.LoadInt32(&f.fs._dirInfoLevel)
+
+		fi, err = f.readdirClass(fs, dirInfoClasses[level], flags)
+		if rerr, ok := err.(*ResponseError); ok && NtStatus(rerr.Code) == STATUS_INVALID_INFO_CLASS && int(level)+1 < len(dirInfoClasses) {
+			atomic.CompareAndSwapInt32(&f.fs._dirInfoLevel, level, level+1)
+			continue
+		}
+		return fi, err
+	}
+}
+
+// readdirClass reads the next entries of the directory by the information class, sending the request by fs.
+func (f *File) readdirClass(fs *Share, class uint8, flags uint8) (fi []os.FileInfo, err error) {
 	req := &QueryDirectoryRequest{
-		FileInfoClass:      FileDirectoryInformation,
-		Flags:              0,
+		FileInfoClass:      class,
+		Flags:              flags,
 		FileIndex:          0,
 		OutputBufferLength: uint32(f.maxTransactSize()),
 		FileName:           "*",
@@ -1894,19 +3211,14 @@ func (f *File) readdir() (fi []os.FileInfo, err error) {
 		return nil, &InternalError{fmt.Sprintf("payload size %d exceeds max transact size %d", payloadSize, f.maxTransactSize())}
 	}
 
-	req.CreditCharge, _, err = f.fs.loanCredit(payloadSize)
-	defer func() {
-		if err != nil {
-			f.fs.chargeCredit(req.CreditCharge)
-		}
-	}()
+	req.CreditCharge, _, err = fs.loanCredit(payloadSize)
 	if err != nil {
 		return nil, err
 	}
 
 	req.FileId = f.fd
 
-	res, err := f.sendRecv(SMB2_QUERY_DIRECTORY, req)
+	res, err := f.sendRecvBy(fs, SMB2_QUERY_DIRECTORY, req)
 	if err != nil {
 		return nil, err
 	}
@@ -1919,15 +3231,18 @@ func (f *File) readdir() (fi []os.FileInfo, err error) {
 	output := r.OutputBuffer()
 
 	for {
-		info := FileDirectoryInformationDecoder(output)
+		info := newDirInfoDecoder(class, output)
 		if info.IsInvalid() {
 			return nil, &InvalidResponseError{"broken query directory response format"}
 		}
 
-		name := info.FileName()
+		name, err := decodeName(info.FileName())
+		if err != nil {
+			return nil, err
+		}
 
 		if name != "." && name != ".." {
-			fi = append(fi, &FileStat{
+			st := &FileStat{
 				CreationTime:   time.Unix(0, info.CreationTime().Nanoseconds()),
 				LastAccessTime: time.Unix(0, info.LastAccessTime().Nanoseconds()),
 				LastWriteTime:  time.Unix(0, info.LastWriteTime().Nanoseconds()),
@@ -1936,7 +3251,11 @@ func (f *File) readdir() (fi []os.FileInfo, err error) {
 				AllocationSize: info.AllocationSize(),
 				FileAttributes: info.FileAttributes(),
 				FileName:       name,
-			})
+			}
+			if ea, ok := info.(interface{ EaSize() uint32 }); ok && st.FileAttributes&FILE_ATTRIBUTE_REPARSE_POINT != 0 {
+				st.ReparseTag = ea.EaSize() // the EA size of a reparse point is its tag
+			}
+			fi = append(fi, st)
 		}
 
 		next := info.NextEntryOffset()
@@ -1948,7 +3267,69 @@ func (f *File) readdir() (fi []os.FileInfo, err error) {
 	}
 }
 
+// queryInfo sends the QUERY_INFO request. If the output doesn't fit in req.OutputBufferLength,
+// which variable-length information such as security descriptors and extended attributes may not,
+// the request is sent again with the length the server requires (STATUS_BUFFER_TOO_SMALL),
+// or with a doubled length if the server doesn't tell it (STATUS_BUFFER_OVERFLOW), up to the max transact size.
 func (f *File) queryInfo(req *QueryInfoRequest) (infoBytes []byte, err error) {
+	for {
+		infoBytes, err = f.queryInfoOnce(req)
+		if err == nil {
+			return infoBytes, nil
+		}
+
+		n := queryInfoRetryLength(err, req.OutputBufferLength, uint32(f.maxTransactSize()))
+		if n == 0 {
+			return nil, err
+		}
+
+		req.OutputBufferLength = n
+	}
+}
+
+// requiredBufferLength returns the required buffer length carried by the error data of STATUS_BUFFER_TOO_SMALL,
+// or zero if there is none.
+func requiredBufferLength(rerr *ResponseError) int {
+	if len(rerr.data) == 0 {
+		return 0
+	}
+	r := SmallBufferErrorResponseDecoder(rerr.data[0])
+	if r.IsInvalid() {
+		return 0
+	}
+	return int(r.RequiredBufferLength())
+}
+
+// queryInfoRetryLength returns the output buffer length to retry a QUERY_INFO request failing with err,
+// whose output buffer length was length, or zero if it can't be retried.
+func queryInfoRetryLength(err error, length, maxLength uint32) uint32 {
+	rerr, ok := err.(*ResponseError)
+	if !ok {
+		return 0
+	}
+
+	var n uint32
+
+	switch NtStatus(rerr.Code) {
+	case STATUS_BUFFER_TOO_SMALL:
+		n = uint32(requiredBufferLength(rerr))
+	case STATUS_BUFFER_OVERFLOW:
+		n = 2 * length
+		if n > maxLength {
+			n = maxLength
+		}
+	default:
+		return 0
+	}
+
+	if n <= length || n > maxLength {
+		return 0
+	}
+
+	return n
+}
+
+func (f *File) queryInfoOnce(req *QueryInfoRequest) (infoBytes []byte, err error) {
 	payloadSize := f.encodeSize(req.Input)
 	if payloadSize < int(req.OutputBufferLength) {
 		payloadSize = int(req.OutputBufferLength)
@@ -1959,11 +3340,6 @@ func (f *File) queryInfo(req *QueryInfoRequest) (infoBytes []byte, err error) {
 	}
 
 	req.CreditCharge, _, err = f.fs.loanCredit(payloadSize)
-	defer func() {
-		if err != nil {
-			f.fs.chargeCredit(req.CreditCharge)
-		}
-	}()
 	if err != nil {
 		return nil, err
 	}
@@ -1991,18 +3367,15 @@ func (f *File) setInfo(req *SetInfoRequest) (err error) {
 	}
 
 	req.CreditCharge, _, err = f.fs.loanCredit(payloadSize)
-	defer func() {
-		if err != nil {
-			f.fs.chargeCredit(req.CreditCharge)
-		}
-	}()
 	if err != nil {
 		return err
 	}
 
 	req.FileId = f.fd
 
-	req.InfoType = SMB2_0_INFO_FILE
+	if req.InfoType == 0 {
+		req.InfoType = SMB2_0_INFO_FILE
+	}
 
 	res, err := f.sendRecv(SMB2_SET_INFO, req)
 	if err != nil {
@@ -2018,7 +3391,49 @@ func (f *File) setInfo(req *SetInfoRequest) (err error) {
 }
 
 func (f *File) sendRecv(cmd uint16, req Packet) (res []byte, err error) {
-	return f.fs.sendRecv(cmd, req)
+	return f.sendRecvBy(f.fs, cmd, req)
+}
+
+// sendRecvBy sends the request on the file by fs, which is f.fs or one of another context.
+func (f *File) sendRecvBy(fs *Share, cmd uint16, req Packet) (res []byte, err error) {
+	if atomic.LoadInt32(&f._stale) != 0 {
+		fs.chargeCredit(req.Header().CreditCharge)
+		return nil, ErrStaleHandle
+	}
+
+	res, err = fs.sendRecv(cmd, req)
+	if err != nil {
+		// the response may carry data with some errors (e.g. STATUS_BUFFER_OVERFLOW of IOCTL)
+		return res, f.handleError(err)
+	}
+	return res, nil
+}
+
+// handleError returns ErrStaleHandle if err reports that the handle is closed or revoked on the server,
+// after which no more requests are sent for the file.
+func (f *File) handleError(err error) error {
+	if f.closing() {
+		// the request was cancelled by Close, or completed by the server as the handle was closed
+		if _, ok := err.(*ContextError); ok {
+			return os.ErrClosed
+		}
+		if rerr, ok := err.(*ResponseError); ok && NtStatus(rerr.Code) == STATUS_FILE_CLOSED {
+			return os.ErrClosed
+		}
+	}
+	if rerr, ok := err.(*ResponseError); ok {
+		switch NtStatus(rerr.Code) {
+		case STATUS_FILE_CLOSED, STATUS_FILE_HANDLE_REVOKED:
+			atomic.StoreInt32(&f._stale, 1)
+			return ErrStaleHandle
+		}
+	}
+	return err
+}
+
+// closing reports whether Close has cancelled the context of the file, rather than the context of the share.
+func (f *File) closing() bool {
+	return atomic.LoadInt32(&f._closed) != 0 && f.ctx.Err() == nil
 }
 
 type FileStat struct {
@@ -2026,10 +3441,22 @@ type FileStat struct {
 	LastAccessTime time.Time
 	LastWriteTime  time.Time
 	ChangeTime     time.Time
-	EndOfFile      int64
+
+	// EndOfFile is the logical size of the file returned by Size.
+	EndOfFile int64
+
+	// AllocationSize is the size the file takes on disk, in multiples of the cluster size.
+	// It's smaller than EndOfFile for sparse and compressed files, so it's what quotas and capacity are reported by.
 	AllocationSize int64
+
 	FileAttributes uint32
 	FileName       string
+
+	// ReparseTag is the tag of a reparse point (e.g. IO_REPARSE_TAG_SYMLINK), or zero if the server didn't report it.
+	ReparseTag uint32
+
+	followed bool // the symbolic links were followed, so a reparse point isn't a link
+	pipe     bool // a file of a pipe share
 }
 
 func (fs *FileStat) Name() string {
@@ -2040,26 +3467,59 @@ func (fs *FileStat) Size() int64 {
 	return fs.EndOfFile
 }
 
+// Mode returns the file mode bits like os.FileMode on Windows.
+// Symbolic links and junctions have os.ModeSymlink but not os.ModeDir, even if they link to directories,
+// so that walks like filepath.Walk don't descend into them. Other reparse points (e.g. deduplicated files)
+// are regular files or directories. Devices have os.ModeDevice, and the files of pipe shares os.ModeNamedPipe.
 func (fs *FileStat) Mode() os.FileMode {
 	var m os.FileMode
 
-	if fs.FileAttributes&FILE_ATTRIBUTE_DIRECTORY != 0 {
-		m |= os.ModeDir | 0111
-	}
-
 	if fs.FileAttributes&FILE_ATTRIBUTE_READONLY != 0 {
 		m |= 0444
 	} else {
 		m |= 0666
 	}
 
-	if fs.FileAttributes&FILE_ATTRIBUTE_REPARSE_POINT != 0 {
-		m |= os.ModeSymlink
+	if fs.isLink() {
+		return m | os.ModeSymlink
+	}
+
+	if fs.FileAttributes&FILE_ATTRIBUTE_DIRECTORY != 0 {
+		m |= os.ModeDir | 0111
+	}
+
+	if fs.FileAttributes&FILE_ATTRIBUTE_DEVICE != 0 {
+		m |= os.ModeDevice
+	}
+
+	if fs.pipe {
+		m |= os.ModeNamedPipe
 	}
 
 	return m
 }
 
+// isLink reports whether the file is a symbolic link or a junction.
+// If the tag is unknown, any reparse point is assumed to be a link.
+func (fs *FileStat) isLink() bool {
+	if fs.FileAttributes&FILE_ATTRIBUTE_REPARSE_POINT == 0 || fs.followed {
+		return false
+	}
+
+	switch fs.ReparseTag {
+	case IO_REPARSE_TAG_SYMLINK, IO_REPARSE_TAG_MOUNT_POINT, IO_REPARSE_TAG_RESERVED_ZERO:
+		return true
+	}
+
+	return false
+}
+
+// IsArchive reports whether the archive bit of the file is set,
+// which means the file has been modified since it was backed up.
+func (fs *FileStat) IsArchive() bool {
+	return fs.FileAttributes&FILE_ATTRIBUTE_ARCHIVE != 0
+}
+
 func (fs *FileStat) ModTime() time.Time {
 	return fs.LastWriteTime
 }
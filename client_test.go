@@ -2,7 +2,22 @@ package smb2
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/nodauf/go-smb2/internal/utf16le"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
 )
 
 type partialReader struct {
@@ -36,3 +51,1791 @@ func TestCopyBufferPartialRead(t *testing.T) {
 		t.Fatal("data not equal")
 	}
 }
+
+func TestReadAtShortReads(t *testing.T) {
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	f, srv, tr := newFakeFile(data, 1000)
+	defer tr.Close()
+
+	for _, tc := range []struct {
+		off  int64
+		size int
+		n    int
+	}{
+		{0, 1, 1},
+		{0, 1000, 1000},
+		{0, 1001, 1001},
+		{123, 5000, 5000},
+		{0, len(data), len(data)},
+		{0, len(data) + 10, len(data)},
+		{int64(len(data)) - 10, 100, 10},
+	} {
+		for _, direct := range []bool{false, true} {
+			buf := make([]byte, tc.size)
+
+			var n int
+			var err error
+			if direct {
+				n, err = f.readAtBuffer(buf, tc.off)
+			} else {
+				n, err = f.readAt(buf, tc.off)
+			}
+			if err != nil {
+				t.Fatalf("off %d, size %d: %v", tc.off, tc.size, err)
+			}
+			if n != tc.n {
+				t.Errorf("off %d, size %d: expected %d bytes, got %d", tc.off, tc.size, tc.n, n)
+			}
+			if !bytes.Equal(buf[:n], data[tc.off:tc.off+int64(n)]) {
+				t.Errorf("off %d, size %d: unexpected content", tc.off, tc.size)
+			}
+		}
+	}
+
+	if srv.reads == 0 {
+		t.Error("server isn't used")
+	}
+}
+
+func TestReadEOF(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+
+	for _, emptyEOF := range []bool{false, true} {
+		f, srv, tr := newFakeFile(data, 4096)
+		srv.emptyEOF = emptyEOF
+
+		bs, err := ioutil.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(bs, data) {
+			t.Error("unexpected content")
+		}
+
+		n, err := f.Read(make([]byte, 10))
+		if n != 0 || err != io.EOF {
+			t.Errorf("read at EOF: expected 0, EOF, got %d, %v", n, err)
+		}
+
+		n, err = f.ReadAt(make([]byte, 10), int64(len(data)))
+		if n != 0 || err != io.EOF {
+			t.Errorf("read at EOF: expected 0, EOF, got %d, %v", n, err)
+		}
+
+		n, err = f.ReadAt(make([]byte, 10), int64(len(data))+100)
+		if n != 0 || err != io.EOF {
+			t.Errorf("read past EOF: expected 0, EOF, got %d, %v", n, err)
+		}
+
+		n, err = f.ReadAt(make([]byte, 10), int64(len(data))-5)
+		if n != 5 || err != io.EOF {
+			t.Errorf("read across EOF: expected 5, EOF, got %d, %v", n, err)
+		}
+
+		n, err = f.ReadAtBuffer(make([]byte, 10), int64(len(data))-5)
+		if n != 5 || err != io.EOF {
+			t.Errorf("read across EOF: expected 5, EOF, got %d, %v", n, err)
+		}
+
+		n, err = f.ReadAt(make([]byte, 10), 0)
+		if n != 10 || err != nil {
+			t.Errorf("read before EOF: expected 10, nil, got %d, %v", n, err)
+		}
+
+		f.offset = 0
+
+		var buf bytes.Buffer
+
+		m, err := f.WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("write to: unexpected content of %d bytes", m)
+		}
+
+		tr.Close()
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	data := bytes.Repeat([]byte("0123456789"), 10)
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE, size: int64(len(data))},
+		},
+	}
+	fsrv := &fakeFileServer{tr: tr, data: data, maxRead: 1024}
+
+	var cmds []uint16
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+		cmds = append(cmds, q.Command())
+		if q.Command() == SMB2_READ {
+			fsrv.handle(req)
+			return
+		}
+		srv.handle(req)
+	}
+
+	fs := newFakeShare(tr)
+
+	bs, err := fs.ReadFile("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bs, data) {
+		t.Errorf("expected %q, got %q", data, bs)
+	}
+
+	// the buffer is sized by the end of file of the CREATE response rather than by a QUERY_INFO request
+	if len(cmds) < 2 || cmds[0] != SMB2_CREATE || cmds[1] != SMB2_READ {
+		t.Errorf("expected CREATE and READ requests, got %v", cmds)
+	}
+	for _, cmd := range cmds {
+		if cmd == SMB2_QUERY_INFO {
+			t.Errorf("unexpected QUERY_INFO request: %v", cmds)
+		}
+	}
+}
+
+func TestNegotiateTimeout(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	// the server reads the negotiate request, but never responds
+	go io.Copy(ioutil.Discard, c2)
+
+	d := &Dialer{
+		Initiator: &NTLMInitiator{
+			User:     "user",
+			Password: "password",
+		},
+		NegotiateTimeout: 100 * time.Millisecond,
+	}
+
+	_, err := d.Dial(c1)
+
+	nerr, ok := err.(*NegotiateTimeoutError)
+	if !ok {
+		t.Fatalf("expected *NegotiateTimeoutError, got %v", err)
+	}
+	if !os.IsTimeout(err) {
+		t.Error("expected timeout error")
+	}
+	if !reflect.DeepEqual(nerr.Dialects, clientDialects) {
+		t.Errorf("expected dialects %v, got %v", clientDialects, nerr.Dialects)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "3.1.1, 3.0.2, 3.0, 2.1, 2.0.2") {
+		t.Errorf("unexpected message: %s", msg)
+	}
+}
+
+// The fake servers answer the opens of symbolic links by the error response of [MS-SMB2] 2.2.2.2.1,
+// whose encoding must match what the client decodes.
+func TestEncodeSymbolicLinkErrorResponse(t *testing.T) {
+	res := &ErrorResponse{
+		PacketHeader: PacketHeader{Status: uint32(STATUS_STOPPED_ON_SYMLINK)},
+		ErrorData: &SymbolicLinkErrorResponse{
+			UnparsedPathLength: 8,
+			Flags:              SYMLINK_FLAG_RELATIVE,
+			SubstituteName:     `target`,
+			PrintName:          `print`,
+		},
+	}
+	pkt := make([]byte, res.Size())
+	res.Encode(pkt)
+
+	r := ErrorResponseDecoder(pkt[64:])
+	if r.IsInvalid() {
+		t.Fatal("broken error response")
+	}
+	// ByteCount follows StructureSize, ErrorContextCount and Reserved (See [MS-SMB2] 2.2.2)
+	if n := r.ByteCount(); n != uint32(res.ErrorData.Size()) {
+		t.Errorf("expected %d bytes of error data, got %d", res.ErrorData.Size(), n)
+	}
+
+	d := SymbolicLinkErrorResponseDecoder(r.ErrorData())
+	if d.IsInvalid() {
+		t.Fatal("broken symbolic link error response")
+	}
+
+	// the names follow the 28 bytes of the fixed fields, and ReparseDataLength counts the bytes from SubstituteNameOffset
+	names := 2*len(`target`) + 2*len(`print`)
+	if n := d.SymLinkLength(); n != uint32(24+names) {
+		t.Errorf("expected SymLinkLength %d, got %d", 24+names, n)
+	}
+	if n := d.ReparseDataLength(); n != uint16(12+names) {
+		t.Errorf("expected ReparseDataLength %d, got %d", 12+names, n)
+	}
+	if !bytes.Equal(d.PathBuffer(), append(utf16le.EncodeStringToBytes(`target`), utf16le.EncodeStringToBytes(`print`)...)) {
+		t.Errorf("unexpected path buffer %x", d.PathBuffer())
+	}
+	if d.SubstituteName() != `target` || d.PrintName() != `print` {
+		t.Errorf("unexpected names %q, %q", d.SubstituteName(), d.PrintName())
+	}
+	if d.UnparsedPathLength() != 8 || d.Flags() != SYMLINK_FLAG_RELATIVE {
+		t.Errorf("unexpected UnparsedPathLength %d, Flags %#x", d.UnparsedPathLength(), d.Flags())
+	}
+}
+
+func TestStatLstat(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`:     {attrs: FILE_ATTRIBUTE_ARCHIVE, size: 11},
+			`link`:     {attrs: FILE_ATTRIBUTE_ARCHIVE | FILE_ATTRIBUTE_REPARSE_POINT, tag: IO_REPARSE_TAG_SYMLINK, link: `file`},
+			`dir`:      {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`junction`: {attrs: FILE_ATTRIBUTE_DIRECTORY | FILE_ATTRIBUTE_REPARSE_POINT, tag: IO_REPARSE_TAG_MOUNT_POINT, junction: `dir`},
+			`dedup`:    {attrs: FILE_ATTRIBUTE_ARCHIVE | FILE_ATTRIBUTE_REPARSE_POINT, tag: 0x80000013, size: 5},
+			`hsm`:      {attrs: FILE_ATTRIBUTE_DIRECTORY | FILE_ATTRIBUTE_REPARSE_POINT, tag: IO_REPARSE_TAG_HSM},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	tests := []struct {
+		name  string
+		lstat bool
+		mode  os.FileMode
+		size  int64
+	}{
+		{name: "link", mode: 0666, size: 11},
+		{name: "link", lstat: true, mode: os.ModeSymlink | 0666},
+		{name: "junction", mode: os.ModeDir | 0777},
+		{name: "junction", lstat: true, mode: os.ModeSymlink | 0666},
+		{name: "file", lstat: true, mode: 0666, size: 11},
+		// reparse points which aren't links
+		{name: "dedup", lstat: true, mode: 0666, size: 5},
+		{name: "hsm", lstat: true, mode: os.ModeDir | 0777},
+	}
+
+	for _, tt := range tests {
+		stat := fs.Stat
+		if tt.lstat {
+			stat = fs.Lstat
+		}
+
+		fi, err := stat(tt.name)
+		if err != nil {
+			t.Fatalf("%s (lstat: %v): %v", tt.name, tt.lstat, err)
+		}
+		if fi.Mode() != tt.mode {
+			t.Errorf("%s (lstat: %v): expected mode %v, got %v", tt.name, tt.lstat, tt.mode, fi.Mode())
+		}
+		if fi.Size() != tt.size {
+			t.Errorf("%s (lstat: %v): expected size %d, got %d", tt.name, tt.lstat, tt.size, fi.Size())
+		}
+		if fi.Name() != tt.name {
+			t.Errorf("%s (lstat: %v): unexpected name %q", tt.name, tt.lstat, fi.Name())
+		}
+	}
+
+	// Stat of the link is answered by the target, which the client opens after STATUS_STOPPED_ON_SYMLINK
+	f, err := fs.Open(`link`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("an opened link must describe the target, got mode %v", fi.Mode())
+	}
+}
+
+func TestStatReparseTagCompound(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE, size: 11},
+			`link`: {attrs: FILE_ATTRIBUTE_ARCHIVE | FILE_ATTRIBUTE_REPARSE_POINT, tag: IO_REPARSE_TAG_SYMLINK, link: `file`},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	if err := openCreditWindow(fs, tr, 8); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tag of a reparse point, which the CREATE response tells, is queried along with the other information
+	fi, err := fs.Lstat(`link`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := fi.(*FileStat); st.ReparseTag != IO_REPARSE_TAG_SYMLINK || st.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("unexpected tag %#x, mode %v", st.ReparseTag, st.Mode())
+	}
+
+	srv.m.Lock()
+	cmds, compounds := srv.cmds, srv.compounds
+	srv.cmds, srv.compounds = nil, 0
+	srv.m.Unlock()
+
+	if want := []uint16{SMB2_CREATE, SMB2_QUERY_INFO, SMB2_QUERY_INFO, SMB2_CLOSE}; !reflect.DeepEqual(cmds, want) || compounds != 1 {
+		t.Errorf("expected commands %v in a compound, got %v in %d compounds", want, cmds, compounds)
+	}
+
+	if _, err := fs.Lstat(`file`); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.m.Lock()
+	cmds, compounds = srv.cmds, srv.compounds
+	srv.m.Unlock()
+
+	if want := []uint16{SMB2_CREATE, SMB2_QUERY_INFO, SMB2_CLOSE}; !reflect.DeepEqual(cmds, want) || compounds != 0 {
+		t.Errorf("expected commands %v, got %v in %d compounds", want, cmds, compounds)
+	}
+}
+
+func TestFileStatMode(t *testing.T) {
+	tests := []struct {
+		name string
+		fi   *FileStat
+		mode os.FileMode
+	}{
+		{"file", &FileStat{FileAttributes: FILE_ATTRIBUTE_ARCHIVE}, 0666},
+		{"readonly", &FileStat{FileAttributes: FILE_ATTRIBUTE_READONLY}, 0444},
+		{"dir", &FileStat{FileAttributes: FILE_ATTRIBUTE_DIRECTORY}, os.ModeDir | 0777},
+		{"symlink", &FileStat{FileAttributes: FILE_ATTRIBUTE_REPARSE_POINT, ReparseTag: IO_REPARSE_TAG_SYMLINK}, os.ModeSymlink | 0666},
+		{"dir symlink", &FileStat{FileAttributes: FILE_ATTRIBUTE_DIRECTORY | FILE_ATTRIBUTE_REPARSE_POINT, ReparseTag: IO_REPARSE_TAG_SYMLINK}, os.ModeSymlink | 0666},
+		{"junction", &FileStat{FileAttributes: FILE_ATTRIBUTE_DIRECTORY | FILE_ATTRIBUTE_REPARSE_POINT, ReparseTag: IO_REPARSE_TAG_MOUNT_POINT}, os.ModeSymlink | 0666},
+		{"unknown tag", &FileStat{FileAttributes: FILE_ATTRIBUTE_REPARSE_POINT}, os.ModeSymlink | 0666},
+		{"other tag", &FileStat{FileAttributes: FILE_ATTRIBUTE_REPARSE_POINT, ReparseTag: IO_REPARSE_TAG_DFSR}, 0666},
+		{"followed", &FileStat{FileAttributes: FILE_ATTRIBUTE_REPARSE_POINT, ReparseTag: IO_REPARSE_TAG_SYMLINK, followed: true}, 0666},
+		{"device", &FileStat{FileAttributes: FILE_ATTRIBUTE_DEVICE}, os.ModeDevice | 0666},
+		{"pipe", &FileStat{FileAttributes: FILE_ATTRIBUTE_NORMAL, pipe: true}, os.ModeNamedPipe | 0666},
+	}
+
+	for _, tt := range tests {
+		if mode := tt.fi.Mode(); mode != tt.mode {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.mode, mode)
+		}
+		if tt.fi.IsDir() != tt.mode.IsDir() {
+			t.Errorf("%s: unexpected IsDir: %v", tt.name, tt.fi.IsDir())
+		}
+	}
+}
+
+func TestReaddirReparseTag(t *testing.T) {
+	entry := func(name string, attrs, tag uint32) []byte {
+		n := utf16le.EncodeStringToBytes(name)
+		b := make([]byte, 68, 68+len(n))
+		binary.LittleEndian.PutUint32(b[56:60], attrs)
+		binary.LittleEndian.PutUint32(b[60:64], uint32(len(n)))
+		binary.LittleEndian.PutUint32(b[64:68], tag)
+		return append(b, n...)
+	}
+
+	link := entry("link", FILE_ATTRIBUTE_REPARSE_POINT, IO_REPARSE_TAG_SYMLINK)
+	binary.LittleEndian.PutUint32(link[:4], uint32(len(link)+4)) // NextEntryOffset, aligned
+	output := append(link, 0, 0, 0, 0)
+	output = append(output, entry("file😀", FILE_ATTRIBUTE_ARCHIVE, 42)...)
+
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+
+		pkt := newFakeResponse(req, q.CreditCharge())
+		pkt = append(pkt[:64], make([]byte, 8+len(output))...)
+		binary.LittleEndian.PutUint16(pkt[64:66], 9)                   // StructureSize
+		binary.LittleEndian.PutUint16(pkt[66:68], 64+8)                // OutputBufferOffset
+		binary.LittleEndian.PutUint32(pkt[68:72], uint32(len(output))) // OutputBufferLength
+		copy(pkt[72:], output)
+		tr.push(pkt)
+	}
+
+	f := &File{fs: newFakeShare(tr), fd: &FileId{}, name: "dir"}
+
+	// the entries are FileFullDirectoryInformation
+	f.fs._dirInfoLevel = 2
+
+	fis, err := f.readdir(f.fs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(fis))
+	}
+
+	if st := fis[0].(*FileStat); st.Name() != "link" || st.ReparseTag != IO_REPARSE_TAG_SYMLINK || st.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("unexpected link entry: %q, tag %#x, mode %v", st.Name(), st.ReparseTag, st.Mode())
+	}
+	// the EA size of a file which isn't a reparse point isn't a tag
+	if st := fis[1].(*FileStat); st.Name() != "file😀" || st.ReparseTag != 0 || st.Mode() != 0666 {
+		t.Errorf("unexpected file entry: %q, tag %#x, mode %v", st.Name(), st.ReparseTag, st.Mode())
+	}
+}
+
+func TestReaddirInfoClassFallback(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`dir`:      {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\file`: {attrs: FILE_ATTRIBUTE_ARCHIVE, size: 11},
+			`dir\link`: {attrs: FILE_ATTRIBUTE_ARCHIVE | FILE_ATTRIBUTE_REPARSE_POINT, tag: IO_REPARSE_TAG_SYMLINK},
+		},
+		rejectedClasses: map[uint8]bool{
+			FileIdBothDirectoryInformation: true,
+			FileBothDirectoryInformation:   true,
+			FileFullDirectoryInformation:   true,
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	for i := 0; i < 2; i++ {
+		fis, err := fs.ReadDir(`dir`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fis) != 2 || fis[0].Name() != "file" || fis[0].Size() != 11 || fis[1].Name() != "link" {
+			t.Fatalf("unexpected entries %v", fis)
+		}
+		// FileDirectoryInformation has no reparse tag
+		if tag := fis[1].(*FileStat).ReparseTag; tag != 0 {
+			t.Errorf("unexpected reparse tag %#x", tag)
+		}
+	}
+
+	// the accepted class is remembered by the second enumeration, which ends by STATUS_NO_MORE_FILES
+	want := []uint8{
+		FileIdBothDirectoryInformation, FileBothDirectoryInformation, FileFullDirectoryInformation, FileDirectoryInformation, FileDirectoryInformation,
+		FileDirectoryInformation, FileDirectoryInformation,
+	}
+	if !reflect.DeepEqual(srv.dirClasses, want) {
+		t.Errorf("expected classes %v, got %v", want, srv.dirClasses)
+	}
+
+	// the richest class is used by the servers which support it
+	tr = newFakeTransport()
+	defer tr.Close()
+
+	srv.tr = tr
+	srv.rejectedClasses = nil
+	srv.dirClasses = nil
+	tr.handler = srv.handle
+
+	fs = newFakeShare(tr)
+
+	fis, err := fs.ReadDir(`dir`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag := fis[1].(*FileStat).ReparseTag; tag != IO_REPARSE_TAG_SYMLINK {
+		t.Errorf("expected reparse tag %#x, got %#x", IO_REPARSE_TAG_SYMLINK, tag)
+	}
+	if srv.dirClasses[0] != FileIdBothDirectoryInformation {
+		t.Errorf("expected FileIdBothDirectoryInformation, got %v", srv.dirClasses[0])
+	}
+}
+
+func TestNonBMPNames(t *testing.T) {
+	const name = `dir\😀 𠀋.txt`
+
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			name: {attrs: FILE_ATTRIBUTE_ARCHIVE, size: 3},
+		},
+	}
+
+	var raw []byte
+	tr.handler = func(req []byte) {
+		if q := PacketCodec(req); q.Command() == SMB2_CREATE {
+			r := CreateRequestDecoder(q.Data())
+			raw = append([]byte{}, req[r.NameOffset():int(r.NameOffset())+int(r.NameLength())]...)
+		}
+		srv.handle(req)
+	}
+
+	fs := newFakeShare(tr)
+
+	fi, err := fs.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Name() != `😀 𠀋.txt` {
+		t.Errorf("unexpected name: %q", fi.Name())
+	}
+
+	// each character out of the BMP is a surrogate pair
+	want := []byte{'d', 0, 'i', 0, 'r', 0, '\\', 0, 0x3d, 0xd8, 0x00, 0xde, ' ', 0, 0x40, 0xd8, 0x0b, 0xdc, '.', 0, 't', 0, 'x', 0, 't', 0}
+	if !bytes.Equal(raw, want) {
+		t.Errorf("unexpected encoded name: %x", raw)
+	}
+
+	if _, err := fs.Stat("dir\\a\x00b"); err == nil {
+		t.Error("a NUL in a path must be rejected")
+	}
+}
+
+func TestCreateContexts(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE, access: FILE_READ_DATA | FILE_READ_ATTRIBUTES},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	req := CreateFileRequest{
+		DesiredAccess:     AccessReadData,
+		ShareAccess:       FileShareRead,
+		CreateDisposition: FileOpen,
+		Contexts:          []CreateContext{{Name: "MxAc"}},
+	}
+
+	f, err := fs.CreateFile("file", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// QueryStatus followed by MaximalAccess (See [MS-SMB2] 2.2.14.2.5)
+	if data, ok := f.CreateContexts()["MxAc"]; !ok || len(data) != 8 {
+		t.Errorf("expected a MxAc context, got %v", f.CreateContexts())
+	} else if access := binary.LittleEndian.Uint32(data[4:]); access != FILE_READ_DATA|FILE_READ_ATTRIBUTES {
+		t.Errorf("unexpected maximal access: %#x", access)
+	}
+
+	req.Contexts = nil
+
+	g, err := fs.CreateFile("file", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	if len(g.CreateContexts()) != 0 {
+		t.Errorf("unexpected contexts: %v", g.CreateContexts())
+	}
+}
+
+func TestQueryMaximalAccess(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE, access: FILE_READ_DATA | FILE_READ_ATTRIBUTES},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	f, err := fs.OpenFileWithOptions("file", os.O_RDONLY, 0, &OpenOptions{QueryMaximalAccess: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if access := f.MaximalAccess(); access != FILE_READ_DATA|FILE_READ_ATTRIBUTES {
+		t.Errorf("unexpected maximal access: %#x", access)
+	}
+
+	g, err := fs.Open("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	if access := g.MaximalAccess(); access != 0 {
+		t.Errorf("the maximal access wasn't queried, got %#x", access)
+	}
+}
+
+func TestCreateBrokenContexts(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+		brokenContexts: true,
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	// with and without following the symbolic links
+	for _, open := range []func() error{
+		func() error { _, err := fs.OpenFile("file", os.O_RDONLY, 0); return err },
+		func() error { _, err := fs.Lstat("file"); return err },
+	} {
+		srv.m.Lock()
+		srv.cmds = nil
+		srv.m.Unlock()
+
+		if err := open(); err == nil {
+			t.Fatal("expected an error for the broken create contexts")
+		}
+
+		// the opened handle is closed rather than leaked
+		srv.m.Lock()
+		if !reflect.DeepEqual(srv.cmds, []uint16{SMB2_CREATE, SMB2_CLOSE}) {
+			t.Errorf("unexpected requests %v", srv.cmds)
+		}
+		srv.m.Unlock()
+
+		if n := atomic.LoadInt32(&fs.session._openFiles); n != 0 {
+			t.Errorf("expected no open files, got %d", n)
+		}
+	}
+}
+
+func TestParseCreateContexts(t *testing.T) {
+	a := &CreateContextRequest{Name: []byte("MxAc"), Data: []byte{0, 0, 0, 0, 1, 0, 0, 0}}
+	b := &CreateContextRequest{Name: []byte("QFid"), Data: bytes.Repeat([]byte{7}, 32)}
+
+	bs := make([]byte, Roundup(a.Size(), 8)+b.Size())
+	a.Encode(bs)
+	binary.LittleEndian.PutUint32(bs[:4], uint32(Roundup(a.Size(), 8))) // Next
+	b.Encode(bs[Roundup(a.Size(), 8):])
+
+	contexts, err := parseCreateContexts(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]byte{"MxAc": a.Data, "QFid": b.Data}
+	if !reflect.DeepEqual(contexts, want) {
+		t.Errorf("expected %v, got %v", want, contexts)
+	}
+
+	// the next context is out of the buffer
+	binary.LittleEndian.PutUint32(bs[:4], uint32(len(bs)))
+	if _, err := parseCreateContexts(bs); err == nil {
+		t.Error("expected an error")
+	}
+
+	if _, err := parseCreateContexts(bs[:10]); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSync(t *testing.T) {
+	f, srv, tr := newFakeFile(nil, 4096)
+	defer tr.Close()
+
+	if _, err := f.Write([]byte("hello world")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.m.Lock()
+	cmds := srv.cmds
+	data := srv.data
+	srv.m.Unlock()
+
+	if !reflect.DeepEqual(cmds, []uint16{SMB2_WRITE, SMB2_FLUSH}) {
+		t.Errorf("expected a WRITE followed by a FLUSH, got %v", cmds)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("unexpected data: %q", data)
+	}
+
+	// the error of the FLUSH is returned
+	srv.m.Lock()
+	srv.fail = srv.reads + 1
+	srv.failStatus = STATUS_DISK_FULL
+	srv.m.Unlock()
+
+	err := f.Sync()
+	if e, ok := err.(*os.PathError); !ok || e.Op != "sync" {
+		t.Fatalf("expected a *os.PathError of sync, got %v", err)
+	} else if rerr, ok := e.Err.(*ResponseError); !ok || NtStatus(rerr.Code) != STATUS_DISK_FULL {
+		t.Errorf("expected STATUS_DISK_FULL, got %v", e.Err)
+	}
+}
+
+func TestStaleHandle(t *testing.T) {
+	for _, status := range []NtStatus{STATUS_FILE_CLOSED, STATUS_FILE_HANDLE_REVOKED} {
+		f, srv, tr := newFakeFile([]byte("hello world"), 4096)
+
+		srv.fail = 1
+		srv.failStatus = status
+
+		buf := make([]byte, 5)
+
+		_, err := f.ReadAt(buf, 0)
+		if e, ok := err.(*os.PathError); !ok || e.Err != ErrStaleHandle {
+			t.Errorf("%v: expected ErrStaleHandle, got %v", status, err)
+		}
+
+		// the handle isn't used anymore
+		_, err = f.ReadAt(buf, 0)
+		if e, ok := err.(*os.PathError); !ok || e.Err != ErrStaleHandle {
+			t.Errorf("%v: expected ErrStaleHandle, got %v", status, err)
+		}
+		if err := f.Close(); err != nil {
+			t.Errorf("%v: unexpected error: %v", status, err)
+		}
+
+		srv.m.Lock()
+		if srv.reads != 1 {
+			t.Errorf("%v: expected 1 request, got %d", status, srv.reads)
+		}
+		srv.m.Unlock()
+
+		tr.Close()
+	}
+}
+
+func TestOpenImpersonationLevel(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+
+	var levels []uint32
+	tr.handler = func(req []byte) {
+		if q := PacketCodec(req); q.Command() == SMB2_CREATE {
+			levels = append(levels, CreateRequestDecoder(q.Data()).ImpersonationLevel())
+		}
+		srv.handle(req)
+	}
+
+	fs := newFakeShare(tr)
+
+	anonymous, identification, delegate := uint32(ImpersonationLevelAnonymous), uint32(ImpersonationLevelIdentification), uint32(ImpersonationLevelDelegate)
+
+	for _, opts := range []*OpenOptions{nil, {}, {ImpersonationLevel: &delegate}, {ImpersonationLevel: &identification}, {ImpersonationLevel: &anonymous}} {
+		f, err := fs.OpenFileWithOptions("file", os.O_RDONLY, 0, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+
+	want := []uint32{Impersonation, Impersonation, Delegate, Identification, Anonymous}
+	if !reflect.DeepEqual(levels, want) {
+		t.Errorf("expected %v, got %v", want, levels)
+	}
+}
+
+func TestOpenFileAccessHints(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+
+	var options []uint32
+	tr.handler = func(req []byte) {
+		if q := PacketCodec(req); q.Command() == SMB2_CREATE {
+			options = append(options, CreateRequestDecoder(q.Data()).CreateOptions()&(FILE_SEQUENTIAL_ONLY|FILE_RANDOM_ACCESS))
+		}
+		srv.handle(req)
+	}
+
+	fs := newFakeShare(tr)
+
+	for _, opts := range []*OpenOptions{nil, {SequentialScan: true}, {RandomAccess: true}, {SequentialScan: true, RandomAccess: true}} {
+		f, err := fs.OpenFileWithOptions("file", os.O_RDONLY, 0, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+
+	want := []uint32{0, FILE_SEQUENTIAL_ONLY, FILE_RANDOM_ACCESS, FILE_SEQUENTIAL_ONLY}
+	if !reflect.DeepEqual(options, want) {
+		t.Errorf("expected %#x, got %#x", want, options)
+	}
+}
+
+func TestOpenFileNoBuffering(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+
+	var options uint32
+	var writes int
+	tr.handler = func(req []byte) {
+		switch q := PacketCodec(req); q.Command() {
+		case SMB2_CREATE:
+			options = CreateRequestDecoder(q.Data()).CreateOptions()
+		case SMB2_WRITE:
+			writes++
+		}
+		srv.handle(req)
+	}
+
+	fs := newFakeShare(tr)
+
+	f, err := fs.OpenFileWithOptions("file", os.O_RDWR, 0, &OpenOptions{WriteThrough: true, NoBuffering: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if want := uint32(FILE_WRITE_THROUGH | FILE_NO_INTERMEDIATE_BUFFERING); options&want != want {
+		t.Errorf("expected create options %#x, got %#x", want, options)
+	}
+
+	if _, err := f.WriteAt(make([]byte, 1024), 512); err != nil {
+		t.Errorf("unexpected error of an aligned write: %v", err)
+	}
+
+	for _, c := range []struct {
+		n   int
+		off int64
+	}{{100, 0}, {512, 100}} {
+		if _, err := f.WriteAt(make([]byte, c.n), c.off); err == nil || err.(*os.PathError).Err != ErrMisaligned {
+			t.Errorf("%d at %d: expected ErrMisaligned, got %v", c.n, c.off, err)
+		}
+		if _, err := f.ReadAt(make([]byte, c.n), c.off); err == nil || err.(*os.PathError).Err != ErrMisaligned {
+			t.Errorf("%d at %d: expected ErrMisaligned, got %v", c.n, c.off, err)
+		}
+	}
+
+	if writes != 1 {
+		t.Errorf("expected the misaligned writes not to be sent, got %d writes", writes)
+	}
+}
+
+func TestOpenFileID(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`dir\file`: {attrs: FILE_ATTRIBUTE_ARCHIVE, size: 42},
+		},
+		fileIds: map[uint64]string{0x0001000000001234: `dir\file`},
+	}
+
+	var options []uint32
+	var names [][]byte
+	tr.handler = func(req []byte) {
+		if q := PacketCodec(req); q.Command() == SMB2_CREATE {
+			r := CreateRequestDecoder(q.Data())
+			options = append(options, r.CreateOptions())
+			names = append(names, append([]byte(nil), req[r.NameOffset():int(r.NameOffset())+int(r.NameLength())]...))
+		}
+		srv.handle(req)
+	}
+
+	fs := newFakeShare(tr)
+
+	f, err := fs.OpenFileID(0x0001000000001234)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Size() != 42 {
+		t.Errorf("expected the size of the file, got %d", st.Size())
+	}
+	f.Close()
+
+	if len(options) != 1 || options[0]&FILE_OPEN_BY_FILE_ID == 0 {
+		t.Errorf("expected FILE_OPEN_BY_FILE_ID, got %#x", options)
+	}
+	if want := []byte{0x34, 0x12, 0, 0, 0, 0, 1, 0}; len(names) != 1 || !bytes.Equal(names[0], want) {
+		t.Errorf("expected the name %x, got %x", want, names)
+	}
+
+	if _, err := fs.OpenFileID(0x5678); err == nil || err.(*os.PathError).Err != ErrNotSupported {
+		t.Errorf("expected ErrNotSupported of an unknown file id, got %v", err)
+	}
+
+	srv.fileIds = nil
+
+	if _, err := fs.OpenFileID(0x0001000000001234); err == nil || err.(*os.PathError).Err != ErrNotSupported {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestPipeShare(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`srvsvc`: {},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+	fs.shareType = SMB2_SHARE_TYPE_PIPE
+
+	checks := map[string]func() error{
+		"mkdir":  func() error { return fs.Mkdir("dir", 0755) },
+		"remove": func() error { return fs.Remove("srvsvc") },
+		"rename": func() error { return fs.Rename("srvsvc", "x") },
+		"create": func() error { _, err := fs.Create("file"); return err },
+		"readdir": func() error {
+			_, err := fs.ReadDir("")
+			return err
+		},
+		"statfs": func() error { _, err := fs.Statfs(""); return err },
+	}
+	for name, fn := range checks {
+		err := fn()
+		if e, ok := err.(*os.PathError); !ok || e.Err != ErrPipeShare {
+			t.Errorf("%s: expected ErrPipeShare, got %v", name, err)
+		}
+	}
+
+	// pipes can still be opened
+	f, err := fs.OpenFile("srvsvc", os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+}
+
+func TestQueryInfoRetry(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	var lengths []uint32
+	var size uint32
+	var status NtStatus
+
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+		n := QueryInfoRequestDecoder(q.Data()).OutputBufferLength()
+		lengths = append(lengths, n)
+
+		hdr := PacketHeader{
+			Command:               SMB2_QUERY_INFO,
+			CreditRequestResponse: q.CreditCharge(),
+			Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+			MessageId:             q.MessageId(),
+			TreeId:                q.TreeId(),
+			SessionId:             q.SessionId(),
+		}
+
+		var res Packet
+		switch {
+		case n >= size:
+			res = &QueryInfoResponse{PacketHeader: hdr, Output: fakeBytes(make([]byte, size))}
+		case status == STATUS_BUFFER_TOO_SMALL:
+			hdr.Status = uint32(status)
+			res = &ErrorResponse{PacketHeader: hdr, ErrorData: &SmallBufferErrorResponse{RequiredBufferLength: size}}
+		default:
+			hdr.Status = uint32(status)
+			res = &ErrorResponse{PacketHeader: hdr}
+		}
+
+		pkt := make([]byte, res.Size())
+		res.Encode(pkt)
+		tr.push(pkt)
+	}
+
+	f := &File{fs: newFakeShare(tr), fd: &FileId{}, name: "file"}
+
+	for _, tt := range []struct {
+		status  NtStatus
+		size    uint32
+		lengths []uint32
+		ok      bool
+	}{
+		// the required length is returned
+		{STATUS_BUFFER_TOO_SMALL, 1000, []uint32{64, 1000}, true},
+		// the length is doubled
+		{STATUS_BUFFER_OVERFLOW, 200, []uint32{64, 128, 256}, true},
+		// the length doesn't exceed the max transact size
+		{STATUS_BUFFER_OVERFLOW, 1 << 20, []uint32{64, 128, 256, 512, 1024, 2048, 4096, 8192, 16384, 32768, 65536}, false},
+		{STATUS_BUFFER_TOO_SMALL, 1 << 20, []uint32{64}, false},
+	} {
+		lengths = nil
+		size = tt.size
+		status = tt.status
+
+		req := &QueryInfoRequest{
+			InfoType:              SMB2_0_INFO_SECURITY,
+			AdditionalInformation: OWNER_SECURITY_INFORMATION,
+			OutputBufferLength:    64,
+		}
+
+		info, err := f.queryInfo(req)
+		if tt.ok {
+			if err != nil {
+				t.Errorf("%v: %v", tt.status, err)
+			} else if uint32(len(info)) != tt.size {
+				t.Errorf("%v: unexpected info length: %d", tt.status, len(info))
+			}
+		} else if rerr, ok := err.(*ResponseError); !ok || NtStatus(rerr.Code) != tt.status {
+			t.Errorf("%v: expected the status, got %v", tt.status, err)
+		}
+		if !reflect.DeepEqual(lengths, tt.lengths) {
+			t.Errorf("%v: unexpected output buffer lengths: %v", tt.status, lengths)
+		}
+	}
+}
+
+func TestReadDirInfo(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 600, time.UTC)
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`dir`:        {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\a.txt`:  {attrs: FILE_ATTRIBUTE_ARCHIVE, size: 12345, mtime: mtime},
+			`dir\b`:      {attrs: FILE_ATTRIBUTE_DIRECTORY, mtime: mtime.Add(time.Hour)},
+			`dir\b\c`:    {attrs: FILE_ATTRIBUTE_ARCHIVE},
+			`dir\ro.txt`: {attrs: FILE_ATTRIBUTE_READONLY, size: 1, mtime: mtime},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	fis, err := fs.ReadDir("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the entries are filled from the enumeration, getting them doesn't take more requests
+	if !reflect.DeepEqual(srv.cmds, []uint16{SMB2_CREATE, SMB2_QUERY_DIRECTORY, SMB2_QUERY_DIRECTORY, SMB2_CLOSE}) {
+		t.Errorf("unexpected requests: %v", srv.cmds)
+	}
+
+	if len(fis) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(fis))
+	}
+	for i, expected := range []struct {
+		name  string
+		size  int64
+		mode  os.FileMode
+		mtime time.Time
+	}{
+		{"a.txt", 12345, 0666, mtime},
+		{"b", 0, os.ModeDir | 0777, mtime.Add(time.Hour)},
+		{"ro.txt", 1, 0444, mtime},
+	} {
+		fi := fis[i]
+		if fi.Name() != expected.name || fi.Size() != expected.size || fi.Mode() != expected.mode || !fi.ModTime().Equal(expected.mtime) {
+			t.Errorf("unexpected entry: %q, %d, %v, %v", fi.Name(), fi.Size(), fi.Mode(), fi.ModTime())
+		}
+		if st, ok := fi.Sys().(*FileStat); !ok || st.FileAttributes != srv.entries[`dir\`+expected.name].attrs {
+			t.Errorf("%s: unexpected attributes: %v", expected.name, fi.Sys())
+		}
+	}
+}
+
+func TestReadDirContext(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`dir`:   {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\b`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+			`dir\a`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the context is done while the server is enumerating the second page, which is never answered
+	var queries int
+	tr.handler = func(pkt []byte) {
+		if PacketCodec(pkt).Command() == SMB2_QUERY_DIRECTORY {
+			queries++
+			if queries == 2 {
+				cancel()
+				return
+			}
+		}
+		srv.handle(pkt)
+	}
+
+	fs := newFakeShare(tr)
+	// the abandoned request is never answered, so it keeps its credit
+	if err := openCreditWindow(fs, tr, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	fis, err := fs.ReadDirContext(ctx, "dir")
+	if err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	if len(fis) != 2 || fis[0].Name() != "a" || fis[1].Name() != "b" {
+		t.Errorf("expected the entries of the first page, got %v", fis)
+	}
+
+	// the directory is closed anyway
+	if !reflect.DeepEqual(srv.cmds, []uint16{SMB2_CREATE, SMB2_QUERY_DIRECTORY, SMB2_CLOSE}) {
+		t.Errorf("unexpected requests: %v", srv.cmds)
+	}
+
+	if _, err := fs.ReadDirContext(ctx, "dir"); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestReaddirContext(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`dir`:   {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\b`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+			`dir\a`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the context is done while the server is enumerating the second page, which is never answered
+	var queries int
+	tr.handler = func(pkt []byte) {
+		if PacketCodec(pkt).Command() == SMB2_QUERY_DIRECTORY {
+			queries++
+			if queries == 2 {
+				cancel()
+				return
+			}
+		}
+		srv.handle(pkt)
+	}
+
+	fs := newFakeShare(tr)
+	// the abandoned request is never answered, so it keeps its credit
+	if err := openCreditWindow(fs, tr, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fs.Open("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	first, err := f.ReaddirContext(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 1 {
+		t.Fatalf("expected an entry, got %v", first)
+	}
+
+	// the rest of the first page is returned with the error
+	rest, err := f.ReaddirContext(ctx, 3)
+	if err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	if len(rest) != 1 || rest[0].Name() == first[0].Name() {
+		t.Errorf("expected the other entry of the first page, got %v", rest)
+	}
+
+	if queries != 2 {
+		t.Errorf("expected 2 queries, got %d", queries)
+	}
+}
+
+func TestMaxOpenFiles(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+	fs.maxOpenFiles = 2
+
+	c := &Session{s: fs.session}
+
+	f1, err := fs.Open("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := fs.Open("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fs.Open("file")
+	if perr, ok := err.(*os.PathError); !ok || perr.Err != ErrTooManyOpenFiles {
+		t.Fatalf("expected ErrTooManyOpenFiles, got %v", err)
+	}
+	if n := c.OpenFileCount(); n != 2 {
+		t.Errorf("expected 2 open files, got %d", n)
+	}
+
+	if err := f1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f1.Close(); err == nil {
+		t.Error("expected an error closing the file twice")
+	}
+
+	// a failed open doesn't count
+	if _, err := fs.Open("missing"); !os.IsNotExist(err) {
+		t.Fatalf("expected a not exist error, got %v", err)
+	}
+	if n := c.OpenFileCount(); n != 1 {
+		t.Errorf("expected 1 open file, got %d", n)
+	}
+
+	f3, err := fs.Open("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f2.Close()
+
+	// a failed CLOSE drops the handle too
+	tr.handler = func(req []byte) {
+		tr.Close()
+	}
+
+	if err := f3.Close(); err == nil {
+		t.Error("expected an error closing the file over a closed connection")
+	}
+
+	if n := c.OpenFileCount(); n != 0 {
+		t.Errorf("expected no open files, got %d", n)
+	}
+}
+
+func TestWarnOnLeak(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+	fs.warnOnLeak = true
+
+	var buf bytes.Buffer
+
+	out := leakLogger.Writer()
+	leakLogger.SetOutput(&buf)
+	defer leakLogger.SetOutput(out)
+
+	f, err := fs.Open("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("unexpected warning for a closed file: %s", buf.Bytes())
+	}
+
+	f, err = fs.Open("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// as the finalizer does
+	f.finalize()
+
+	if !strings.Contains(buf.String(), "file file was garbage collected without being closed") ||
+		!strings.Contains(buf.String(), "TestWarnOnLeak") {
+		t.Errorf("unexpected warning: %s", buf.Bytes())
+	}
+
+	// the leaked handle is left open
+	if n := (&Session{s: fs.session}).OpenFileCount(); n != 1 {
+		t.Errorf("expected the leaked file to be left open, got %d open files", n)
+	}
+	srv.m.Lock()
+	cmds := srv.cmds
+	srv.m.Unlock()
+	if cmds[len(cmds)-1] == SMB2_CLOSE {
+		t.Errorf("expected the leaked file not to be closed, got requests %v", cmds)
+	}
+	// the real finalizer isn't left to warn once the logger is restored
+	f.Close()
+}
+
+func TestStatAllocationSize(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`dir`:        {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\sparse`: {attrs: FILE_ATTRIBUTE_ARCHIVE | FILE_ATTRIBUTE_SPARSE_FILE, size: 1 << 20, alloc: 4096},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	fi, err := fs.Stat(`dir\sparse`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fis, err := fs.ReadDir(`dir`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fi := range []os.FileInfo{fi, fis[0]} {
+		if st := fi.(*FileStat); st.Size() != 1<<20 || st.AllocationSize != 4096 {
+			t.Errorf("expected size %d allocated in %d bytes, got %d in %d", 1<<20, 4096, st.Size(), st.AllocationSize)
+		}
+	}
+}
+
+func TestCloseUnblocksRead(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`pipe`: {attrs: FILE_ATTRIBUTE_NORMAL},
+		},
+	}
+
+	// like a pipe without a message, the server doesn't answer the reads until the handle is closed
+	reading := make(chan struct{}, 16)
+	tr.handler = func(pkt []byte) {
+		if PacketCodec(pkt).Command() == SMB2_READ {
+			reading <- struct{}{}
+			return
+		}
+		srv.handle(pkt)
+	}
+
+	fs := newFakeShare(tr)
+	// a read is pending while the file is closed
+	if err := openCreditWindow(fs, tr, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 8; i++ {
+		f, err := fs.Open("pipe")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := f.Read(make([]byte, 16))
+			done <- err
+		}()
+
+		// the first close waits for the read to be sent, the other ones race with it
+		if i == 0 {
+			<-reading
+		}
+
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case err := <-done:
+			if e, ok := err.(*os.PathError); !ok || e.Err != os.ErrClosed {
+				t.Errorf("expected os.ErrClosed, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("the read is still blocked after close")
+		}
+	}
+}
+
+func TestMaxChunk(t *testing.T) {
+	f, srv, tr := newFakeFile(make([]byte, 3000), 64*1024)
+	defer tr.Close()
+
+	f.fs.maxReadChunk = 1000
+	f.fs.maxWriteChunk = 1024
+
+	buf := make([]byte, 3000)
+	if _, err := f.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt(buf[:2500], 0); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.m.Lock()
+	cmds := srv.cmds
+	srv.m.Unlock()
+
+	expected := []uint16{SMB2_READ, SMB2_READ, SMB2_READ, SMB2_WRITE, SMB2_WRITE, SMB2_WRITE}
+	if !reflect.DeepEqual(cmds, expected) {
+		t.Errorf("expected %v, got %v", expected, cmds)
+	}
+
+	// the caps don't raise the negotiated sizes
+	f.fs.maxReadChunk = 1 << 30
+	f.fs.maxWriteChunk = 1 << 30
+	if n := f.maxReadSize(); n != 64*1024 {
+		t.Errorf("unexpected max read size: %d", n)
+	}
+	if n := f.maxWriteSize(); n != 64*1024 {
+		t.Errorf("unexpected max write size: %d", n)
+	}
+
+	// SetIOChunkSize applies under the caps
+	f.fs.maxReadChunk = 4096
+	f.fs.SetIOChunkSize(2048, 8192)
+	if n := f.maxReadSize(); n != 2048 {
+		t.Errorf("unexpected max read size: %d", n)
+	}
+	if n := f.maxWriteSize(); n != 8192 {
+		t.Errorf("unexpected max write size: %d", n)
+	}
+}
+
+func TestIsDir(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`:     {attrs: FILE_ATTRIBUTE_ARCHIVE},
+			`dir`:      {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`link`:     {attrs: FILE_ATTRIBUTE_ARCHIVE | FILE_ATTRIBUTE_REPARSE_POINT, tag: IO_REPARSE_TAG_SYMLINK, link: `dir`},
+			`junction`: {attrs: FILE_ATTRIBUTE_DIRECTORY | FILE_ATTRIBUTE_REPARSE_POINT, tag: IO_REPARSE_TAG_MOUNT_POINT, junction: `dir`},
+			`filejunc`: {attrs: FILE_ATTRIBUTE_DIRECTORY | FILE_ATTRIBUTE_REPARSE_POINT, tag: IO_REPARSE_TAG_MOUNT_POINT, junction: `file`},
+			`dangling`: {attrs: FILE_ATTRIBUTE_DIRECTORY | FILE_ATTRIBUTE_REPARSE_POINT, tag: IO_REPARSE_TAG_MOUNT_POINT, junction: `nothing`},
+			`hsm`:      {attrs: FILE_ATTRIBUTE_DIRECTORY | FILE_ATTRIBUTE_REPARSE_POINT, tag: IO_REPARSE_TAG_HSM},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	for name, expected := range map[string]bool{
+		"file":     false,
+		"dir":      true,
+		"link":     true,
+		"junction": true,
+		"filejunc": false,
+		"hsm":      true,
+	} {
+		srv.cmds = nil
+
+		isDir, err := fs.IsDir(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if isDir != expected {
+			t.Errorf("%s: expected %v, got %v", name, expected, isDir)
+		}
+		// the attributes of the CREATE response are used
+		for _, cmd := range srv.cmds {
+			if cmd == SMB2_QUERY_INFO {
+				t.Errorf("%s: the file was queried", name)
+			}
+		}
+	}
+
+	if _, err := fs.IsDir("dangling"); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
+
+func TestSetFileTime(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	created := time.Date(2001, time.February, 3, 4, 5, 6, 700, time.UTC)
+	changed := time.Date(2010, time.November, 12, 13, 14, 15, 1600, time.UTC)
+
+	err := fs.SetFileTime("file", &created, &SuppressTimeUpdate, nil, &changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := FileBasicInformationDecoder(srv.entries[`file`].basic)
+	if info.IsInvalid() {
+		t.Fatal("FileBasicInformation wasn't set")
+	}
+	if nsec := info.CreationTime().Nanoseconds(); nsec != created.UnixNano() {
+		t.Errorf("creation time: expected %d, got %d", created.UnixNano(), nsec)
+	}
+	if ft := info.LastAccessTime(); ft.LowDateTime() != 0xffffffff || ft.HighDateTime() != 0xffffffff {
+		t.Errorf("access time: expected -1, got %x", []byte(ft))
+	}
+	if ft := info.LastWriteTime(); ft.LowDateTime() != 0 || ft.HighDateTime() != 0 {
+		t.Errorf("write time: expected 0, got %x", []byte(ft))
+	}
+	if nsec := info.ChangeTime().Nanoseconds(); nsec != changed.UnixNano() {
+		t.Errorf("change time: expected %d, got %d", changed.UnixNano(), nsec)
+	}
+	if attrs := info.FileAttributes(); attrs != 0 {
+		t.Errorf("attributes: expected 0, got %x", attrs)
+	}
+
+	if err := fs.SetFileTime("nothing", &created, nil, nil, nil); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
+
+func TestArchiveBit(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+		setAttrs: true,
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	f, err := fs.OpenFile("file", os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := f.ClearArchiveBit(); err != nil {
+		t.Fatal(err)
+	}
+	// clearing the only attribute needs FILE_ATTRIBUTE_NORMAL
+	if attrs := FileBasicInformationDecoder(srv.entries[`file`].basic).FileAttributes(); attrs != FILE_ATTRIBUTE_NORMAL {
+		t.Errorf("expected FILE_ATTRIBUTE_NORMAL, got %x", attrs)
+	}
+
+	st, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.(*FileStat).IsArchive() {
+		t.Error("the archive bit wasn't cleared")
+	}
+
+	for i := 0; i < 2; i++ {
+		srv.cmds = nil
+
+		if _, err := f.Write([]byte("data")); err != nil {
+			t.Fatal(err)
+		}
+
+		var sets int
+		for _, cmd := range srv.cmds {
+			if cmd == SMB2_SET_INFO {
+				sets++
+			}
+		}
+		// the bit is set once per handle
+		if expected := 1 - i; sets != expected {
+			t.Errorf("write %d: expected %d SET_INFO requests, got %d", i, expected, sets)
+		}
+	}
+
+	st, err = f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.(*FileStat).IsArchive() {
+		t.Error("the archive bit wasn't set by the write")
+	}
+}
+
+func TestFileHandle(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`a`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+			`b`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+	if id := fs.TreeID(); id != 1 {
+		t.Errorf("tree id: %d, want 1", id)
+	}
+
+	f, err := fs.Open(`a`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	g, err := fs.Open(`b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	// the fake server numbers the opens by the first byte of the persistent id
+	h := f.Handle()
+	if h != [16]byte{1} {
+		t.Errorf("handle: %x, want the persistent id 1", h)
+	}
+	if g.Handle() == h {
+		t.Errorf("handles of two opens are equal: %x", h)
+	}
+
+	h[0] = 0xff
+	if f.Handle()[0] != 1 {
+		t.Errorf("the handle isn't a copy")
+	}
+}
+
+func TestReadDirEntries(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`dir`:          {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\file`:     {attrs: FILE_ATTRIBUTE_ARCHIVE, size: 11},
+			`dir\link`:     {attrs: FILE_ATTRIBUTE_ARCHIVE | FILE_ATTRIBUTE_REPARSE_POINT, tag: IO_REPARSE_TAG_SYMLINK},
+			`dir\sub`:      {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\sub\file`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	entries, err := fs.ReadDirEntries(`dir`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.m.Lock()
+	ncmds := len(srv.cmds)
+	srv.m.Unlock()
+
+	want := []struct {
+		name string
+		typ  os.FileMode
+	}{
+		{"file", 0},
+		{"link", os.ModeSymlink},
+		{"sub", os.ModeDir},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(entries))
+	}
+	for i, tt := range want {
+		e := entries[i]
+		if e.Name() != tt.name || e.Type() != tt.typ || e.IsDir() != tt.typ.IsDir() {
+			t.Errorf("expected %s of type %v, got %s of type %v", tt.name, tt.typ, e.Name(), e.Type())
+		}
+		fi, err := e.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Name() != tt.name || fi.Mode()&os.ModeType != tt.typ {
+			t.Errorf("unexpected info of %s: %s %v", tt.name, fi.Name(), fi.Mode())
+		}
+	}
+	if fi, _ := entries[0].Info(); fi.Size() != 11 {
+		t.Errorf("expected size 11, got %d", fi.Size())
+	}
+
+	// neither Type nor Info takes a round trip
+	srv.m.Lock()
+	defer srv.m.Unlock()
+	if len(srv.cmds) != ncmds {
+		t.Errorf("unexpected requests %v", srv.cmds[ncmds:])
+	}
+	for _, cmd := range srv.cmds {
+		if cmd != SMB2_CREATE && cmd != SMB2_QUERY_DIRECTORY && cmd != SMB2_CLOSE {
+			t.Errorf("unexpected request %v", cmd)
+		}
+	}
+}
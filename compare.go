@@ -0,0 +1,78 @@
+package smb2
+
+import (
+	"bytes"
+	"io"
+)
+
+// SameContent reports whether the content of the remote file is the same as what local reads,
+// e.g. to decide whether a file must be uploaded again when the timestamps can't be trusted.
+// If the size of local is known, because it has a Len method (e.g. *bytes.Reader) or it's an io.Seeker,
+// the sizes are compared first, so that files of different sizes aren't read at all.
+// Otherwise, both are read in lockstep by chunks of the max read size, up to the first chunk which differs.
+// local is read from its current offset.
+func (fs *Share) SameContent(remote string, local io.Reader) (bool, error) {
+	f, err := fs.Open(remote)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if n, ok := remainingSize(local); ok {
+		fi, err := f.Stat()
+		if err != nil {
+			return false, err
+		}
+		if fi.Size() != n {
+			return false, nil
+		}
+	}
+
+	size := f.maxReadSize()
+
+	rbuf := make([]byte, size)
+	lbuf := make([]byte, size)
+
+	for {
+		rn, rerr := io.ReadFull(f, rbuf)
+		if rerr != nil && rerr != io.EOF && rerr != io.ErrUnexpectedEOF {
+			return false, rerr
+		}
+
+		ln, lerr := io.ReadFull(local, lbuf)
+		if lerr != nil && lerr != io.EOF && lerr != io.ErrUnexpectedEOF {
+			return false, lerr
+		}
+
+		if rn != ln || !bytes.Equal(rbuf[:rn], lbuf[:ln]) {
+			return false, nil
+		}
+
+		// both reached the end of file, since the chunks are the same size
+		if rerr != nil {
+			return true, nil
+		}
+	}
+}
+
+// remainingSize returns the number of bytes left to read from r if it's known.
+func remainingSize(r io.Reader) (int64, bool) {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len()), true
+	case io.Seeker:
+		cur, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		end, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, false
+		}
+		if _, err := r.Seek(cur, io.SeekStart); err != nil {
+			return 0, false
+		}
+		return end - cur, true
+	}
+	return 0, false
+}
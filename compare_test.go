@@ -0,0 +1,65 @@
+package smb2
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+func TestSameContent(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 16*1024)
+
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE, size: int64(len(data))},
+		},
+	}
+	fileSrv := &fakeFileServer{tr: tr, data: data, maxRead: 64 * 1024}
+	tr.handler = func(req []byte) {
+		if PacketCodec(req).Command() == SMB2_READ {
+			fileSrv.handle(req)
+			return
+		}
+		srv.handle(req)
+	}
+
+	fs := newFakeShare(tr)
+
+	changed := append([]byte{}, data...)
+	changed[100] = 'x'
+
+	tests := []struct {
+		name  string
+		local io.Reader
+		same  bool
+		reads int
+	}{
+		{name: "same", local: bytes.NewReader(data), same: true, reads: 5},
+		// the size of a reader hidden by io.MultiReader isn't known
+		{name: "same unknown size", local: io.MultiReader(bytes.NewReader(data)), same: true, reads: 5},
+		{name: "shorter", local: bytes.NewReader(data[1:]), same: false, reads: 0},
+		{name: "first chunk differs", local: io.MultiReader(bytes.NewReader(changed)), same: false, reads: 1},
+		{name: "longer", local: io.MultiReader(bytes.NewReader(data), bytes.NewReader([]byte("tail"))), same: false, reads: 5},
+	}
+
+	for _, tt := range tests {
+		fileSrv.reads = 0
+
+		same, err := fs.SameContent(`file`, tt.local)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if same != tt.same {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.same, same)
+		}
+		if fileSrv.reads != tt.reads {
+			t.Errorf("%s: expected %d reads, got %d", tt.name, tt.reads, fileSrv.reads)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package smb2
+
+import (
+	"os"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// maxBatchRemoves is the number of names removed by a compound request of func (*Share) RemoveBatch.
+const maxBatchRemoves = 32
+
+// relatedFileId refers to the file opened by the previous request of a compound request. (See [MS-SMB2] 3.2.4.1.4)
+var relatedFileId = &FileId{
+	Persistent: [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	Volatile:   [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+}
+
+// RemoveBatch removes the named files and empty directories like func (*Share) Remove,
+// and returns their errors in the order of names. The error of a removed name is nil.
+// The removals are compounded into requests of several names each, so that they take a single round trip,
+// which makes removing many files fast on high-latency links.
+// The names which fail with a permission error are removed again by func (*Share) Remove,
+// which clears their read-only attribute.
+func (fs *Share) RemoveBatch(names []string) []error {
+	errs := make([]error, len(names))
+
+	paths := make([]string, len(names)) // cleaned names
+	todo := make([]int, 0, len(names))  // indices of the names to remove
+	for i, name := range names {
+		path, err := cleanPath("remove", name)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		paths[i] = path
+		if err := fs.checkDiskShare("remove", path); err != nil {
+			errs[i] = err
+			continue
+		}
+		todo = append(todo, i)
+	}
+
+	for len(todo) > 0 {
+		n := len(todo)
+		if n > maxBatchRemoves {
+			n = maxBatchRemoves
+		}
+
+		// a removal takes the credits of a CREATE, a SET_INFO and a CLOSE request
+		credits, _, err := fs.session.conn.account.loan(uint16(3*n), fs.ctx)
+		if err != nil {
+			for _, i := range todo {
+				errs[i] = &os.PathError{Op: "remove", Path: paths[i], Err: err}
+			}
+			break
+		}
+
+		n = int(credits) / 3
+		if extra := credits - uint16(3*n); extra > 0 {
+			fs.chargeCredit(extra)
+		}
+
+		if n == 0 {
+			// the server didn't grant enough credits for a compound request yet
+			errs[todo[0]] = fs.Remove(names[todo[0]])
+			todo = todo[1:]
+			continue
+		}
+
+		fs.removeCompound(paths, todo[:n], errs)
+		todo = todo[n:]
+	}
+
+	for i, err := range errs {
+		if os.IsPermission(err) {
+			errs[i] = fs.Remove(names[i])
+		}
+	}
+
+	return errs
+}
+
+// removeCompound removes the cleaned paths of the indices idx by a compound request of CREATE, SET_INFO and CLOSE chains,
+// which remove them like func (*Share) remove, and sets their errors in errs. The credits of the requests must be loaned.
+func (fs *Share) removeCompound(paths []string, idx []int, errs []error) {
+	reqs := make([]Packet, 0, 3*len(idx))
+
+	for _, i := range idx {
+		create := &CreateRequest{
+			SecurityFlags:        0,
+			RequestedOplockLevel: SMB2_OPLOCK_LEVEL_NONE,
+			ImpersonationLevel:   Impersonation,
+			SmbCreateFlags:       0,
+			DesiredAccess:        DELETE,
+			FileAttributes:       0,
+			ShareAccess:          FILE_SHARE_DELETE,
+			CreateDisposition:    FILE_OPEN,
+			CreateOptions:        FILE_OPEN_REPARSE_POINT,
+			Name:                 paths[i],
+		}
+		create.CreditCharge = 1
+
+		// FILE_DELETE_ON_CLOSE doesn't work for reparse point, so use FileDispositionInformation instead
+		info := &SetInfoRequest{
+			InfoType:              SMB2_0_INFO_FILE,
+			FileInfoClass:         FileDispositionInformation,
+			AdditionalInformation: 0,
+			FileId:                relatedFileId,
+			Input: &FileDispositionInformationEncoder{
+				DeletePending: 1,
+			},
+		}
+		info.CreditCharge = 1
+		info.Flags = SMB2_FLAGS_RELATED_OPERATIONS
+
+		cl := &CloseRequest{
+			Flags:  0,
+			FileId: relatedFileId,
+		}
+		cl.CreditCharge = 1
+		cl.PacketHeader.Flags = SMB2_FLAGS_RELATED_OPERATIONS
+
+		reqs = append(reqs, create, info, cl)
+	}
+
+	rrs, err := fs.sendCompound(reqs, fs.treeConn, fs.ctx)
+	if err != nil {
+		for _, i := range idx {
+			errs[i] = &os.PathError{Op: "remove", Path: paths[i], Err: err}
+		}
+		return
+	}
+
+	for j, i := range idx {
+		_, err := fs.recvCompound(rrs[3*j:3*j+3], []uint16{SMB2_CREATE, SMB2_SET_INFO, SMB2_CLOSE})
+		if err != nil {
+			errs[i] = &os.PathError{Op: "remove", Path: paths[i], Err: err}
+		}
+	}
+}
+
+// recvCompound receives the responses of the requests of a compound request, whose commands are cmds, and returns their payloads.
+// All the responses are received even after one fails, so that their credits are accounted and none is left outstanding.
+// The error is the first one in the order of the requests. It's the cause of the failures of the related requests following it,
+// which the server fails with the same status or with STATUS_INVALID_PARAMETER, as they have no file to operate on.
+// The payloads of the failed requests are nil.
+func (fs *Share) recvCompound(rrs []*requestResponse, cmds []uint16) (res [][]byte, err error) {
+	res = make([][]byte, len(rrs))
+
+	for k, rr := range rrs {
+		pkt, e := fs.recv(rr)
+		if e == nil {
+			res[k], e = accept(cmds[k], pkt)
+		}
+		if e != nil {
+			res[k] = nil
+			if err == nil {
+				err = e
+			}
+		}
+	}
+
+	return res, err
+}
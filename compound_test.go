@@ -0,0 +1,387 @@
+package smb2
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+func TestRemoveBatch(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`a`:        {attrs: FILE_ATTRIBUTE_ARCHIVE},
+			`dir`:      {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\b`:    {attrs: FILE_ATTRIBUTE_ARCHIVE},
+			`link`:     {attrs: FILE_ATTRIBUTE_REPARSE_POINT, tag: IO_REPARSE_TAG_SYMLINK, link: `a`},
+			`readonly`: {attrs: FILE_ATTRIBUTE_READONLY},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+	// enough credits for a single compound request
+	if err := openCreditWindow(fs, tr, 15); err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{`a`, `missing`, `dir\b`, `link`, `readonly`, "a\x00b"}
+
+	errs := fs.RemoveBatch(names)
+	if len(errs) != len(names) {
+		t.Fatalf("expected %d errors, got %d", len(names), len(errs))
+	}
+
+	for i, name := range names {
+		err := errs[i]
+		switch name {
+		case `missing`:
+			if !os.IsNotExist(err) {
+				t.Errorf("%s: expected a not exist error, got %v", name, err)
+			}
+		case `readonly`:
+			// Remove can't clear the attribute either, since the server doesn't support it
+			if !os.IsPermission(err) {
+				t.Errorf("%s: expected a permission error, got %v", name, err)
+			}
+		case "a\x00b":
+			if err == nil {
+				t.Errorf("%s: expected an invalid path error", name)
+			}
+		default:
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", name, err)
+			}
+		}
+	}
+
+	srv.m.Lock()
+	defer srv.m.Unlock()
+
+	if srv.compounds != 1 {
+		t.Errorf("expected a single compound request, got %d", srv.compounds)
+	}
+	for _, name := range []string{`a`, `dir\b`, `link`} {
+		if _, ok := srv.entries[name]; ok {
+			t.Errorf("%s isn't removed", name)
+		}
+	}
+	for _, name := range []string{`dir`, `readonly`} {
+		if _, ok := srv.entries[name]; !ok {
+			t.Errorf("%s is removed", name)
+		}
+	}
+}
+
+func TestRemoveBatchWithoutCredits(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`a`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+			`b`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+	tr.handler = srv.handle
+
+	// the server doesn't grant more than a credit, so the names are removed one by one
+	fs := newFakeShare(tr)
+
+	for i, err := range fs.RemoveBatch([]string{`a`, `b`}) {
+		if err != nil {
+			t.Errorf("%d: unexpected error: %v", i, err)
+		}
+	}
+
+	srv.m.Lock()
+	defer srv.m.Unlock()
+
+	if len(srv.entries) != 0 {
+		t.Errorf("unexpected entries: %v", srv.entries)
+	}
+	if srv.compounds != 0 {
+		t.Errorf("expected no compound requests, got %d", srv.compounds)
+	}
+}
+
+func TestSendCompoundSigned(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	var msg []byte
+	tr.handler = func(pkt []byte) {
+		msg = pkt
+	}
+
+	fs := newFakeShare(tr)
+
+	key := bytes.Repeat([]byte{0x42}, 16)
+	signer, err := newSigner(SMB210, AES_CMAC, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := newSigner(SMB210, AES_CMAC, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs.session.sessionFlags = 0
+	fs.session.signer = signer
+
+	create := &CreateRequest{Name: "a"}
+	create.CreditCharge = 1
+	cl := &CloseRequest{FileId: relatedFileId}
+	cl.CreditCharge = 1
+	cl.PacketHeader.Flags = SMB2_FLAGS_RELATED_OPERATIONS
+
+	if _, err := fs.sendCompound([]Packet{create, cl}, fs.treeConn, fs.ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	v := &session{verifier: verifier}
+
+	p := PacketCodec(msg)
+	next := int(p.NextCommand())
+	if next == 0 || next%8 != 0 {
+		t.Fatalf("unexpected next command offset: %d", next)
+	}
+	if p.Command() != SMB2_CREATE || !v.verify(msg[:next], nil) {
+		t.Error("the CREATE request isn't signed")
+	}
+
+	q := PacketCodec(msg[next:])
+	if q.Command() != SMB2_CLOSE || q.NextCommand() != 0 || q.Flags()&SMB2_FLAGS_RELATED_OPERATIONS == 0 {
+		t.Errorf("unexpected CLOSE request: command %d, flags %#x", q.Command(), q.Flags())
+	}
+	if q.MessageId() != p.MessageId()+1 {
+		t.Errorf("expected message id %d, got %d", p.MessageId()+1, q.MessageId())
+	}
+	if !v.verify(msg[next:], nil) {
+		t.Error("the CLOSE request isn't signed")
+	}
+}
+
+// compoundResponder answers each compound request by a single compound response, whose responses are made by respond
+// from the requests in order.
+func compoundResponder(tr *fakeTransport, respond func(i int, req PacketCodec) []byte) {
+	tr.handler = func(pkt []byte) {
+		var res []byte
+
+		for i := 0; ; i++ {
+			q := PacketCodec(pkt)
+			next := q.NextCommand()
+			if next != 0 {
+				q = PacketCodec(pkt[:next])
+			}
+
+			r := respond(i, q)
+			if next != 0 {
+				r = append(r, make([]byte, Roundup(len(r), 8)-len(r))...)
+				PacketCodec(r).SetNextCommand(uint32(len(r)))
+			}
+			res = append(res, r...)
+
+			if next == 0 {
+				break
+			}
+			pkt = pkt[next:]
+		}
+
+		tr.push(res)
+	}
+}
+
+// encodeTestResponse returns the response to req by res, or an error response of status if it isn't STATUS_SUCCESS.
+func encodeTestResponse(req PacketCodec, res Packet, status NtStatus) []byte {
+	hdr := res.Header()
+	hdr.Command = req.Command()
+	hdr.CreditRequestResponse = req.CreditCharge()
+	hdr.Flags = SMB2_FLAGS_SERVER_TO_REDIR | req.Flags()&SMB2_FLAGS_RELATED_OPERATIONS
+	hdr.MessageId = req.MessageId()
+	hdr.TreeId = req.TreeId()
+	hdr.SessionId = req.SessionId()
+
+	if status != STATUS_SUCCESS {
+		hdr.Status = uint32(status)
+		res = &ErrorResponse{PacketHeader: *hdr}
+	}
+
+	pkt := make([]byte, res.Size())
+	res.Encode(pkt)
+	PacketCodec(pkt).SetCommand(req.Command())
+	return pkt
+}
+
+func TestRecvCompoundErrors(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	var statuses []NtStatus
+
+	compoundResponder(tr, func(i int, q PacketCodec) []byte {
+		switch q.Command() {
+		case SMB2_CREATE:
+			return encodeTestResponse(q, &CreateResponse{
+				CreationTime:   &Filetime{},
+				LastAccessTime: &Filetime{},
+				LastWriteTime:  &Filetime{},
+				ChangeTime:     &Filetime{},
+				FileId:         &FileId{},
+			}, statuses[i])
+		case SMB2_READ:
+			if statuses[i] == STATUS_SUCCESS {
+				pkt := newTestReadResponse(q.MessageId(), q.SessionId(), []byte("data"))
+				p := PacketCodec(pkt)
+				p.SetTreeId(q.TreeId())
+				p.SetCreditResponse(q.CreditCharge())
+				return pkt
+			}
+			return encodeTestResponse(q, &ErrorResponse{}, statuses[i])
+		default:
+			return encodeTestResponse(q, &CloseResponse{
+				CreationTime:   &Filetime{},
+				LastAccessTime: &Filetime{},
+				LastWriteTime:  &Filetime{},
+				ChangeTime:     &Filetime{},
+			}, statuses[i])
+		}
+	})
+
+	fs := newFakeShare(tr)
+	if err := openCreditWindow(fs, tr, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	cmds := []uint16{SMB2_CREATE, SMB2_READ, SMB2_CLOSE}
+
+	send := func() []*requestResponse {
+		create := &CreateRequest{Name: "a", DesiredAccess: FILE_READ_DATA, CreateDisposition: FILE_OPEN}
+		create.CreditCharge = 1
+		read := &ReadRequest{Length: 4, FileId: relatedFileId}
+		read.CreditCharge = 1
+		read.PacketHeader.Flags = SMB2_FLAGS_RELATED_OPERATIONS
+		cl := &CloseRequest{FileId: relatedFileId}
+		cl.CreditCharge = 1
+		cl.PacketHeader.Flags = SMB2_FLAGS_RELATED_OPERATIONS
+
+		if _, _, err := fs.conn.account.loan(3, fs.ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		rrs, err := fs.sendCompound([]Packet{create, read, cl}, fs.treeConn, fs.ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rrs
+	}
+
+	tests := []struct {
+		statuses []NtStatus
+		isErr    func(error) bool
+		ok       []bool // whether the payloads are returned
+	}{
+		{[]NtStatus{STATUS_SUCCESS, STATUS_SUCCESS, STATUS_SUCCESS}, nil, []bool{true, true, true}},
+		// the middle command fails, the file is still closed
+		{[]NtStatus{STATUS_SUCCESS, STATUS_ACCESS_DENIED, STATUS_SUCCESS}, os.IsPermission, []bool{true, false, true}},
+		// the related commands fail like the CREATE or with STATUS_INVALID_PARAMETER, the error of the CREATE is returned
+		{[]NtStatus{STATUS_OBJECT_NAME_NOT_FOUND, STATUS_OBJECT_NAME_NOT_FOUND, STATUS_INVALID_PARAMETER}, os.IsNotExist, []bool{false, false, false}},
+	}
+
+	for i, tt := range tests {
+		statuses = tt.statuses
+
+		res, err := fs.recvCompound(send(), cmds)
+		if tt.isErr == nil {
+			if err != nil {
+				t.Errorf("%d: unexpected error %v", i, err)
+			}
+		} else if !tt.isErr(err) {
+			t.Errorf("%d: unexpected error %v", i, err)
+		}
+		for k, ok := range tt.ok {
+			if (res[k] != nil) != ok {
+				t.Errorf("%d: unexpected payload of response %d: %x", i, k, res[k])
+			}
+		}
+		if ok := tt.ok[1]; ok && !bytes.Equal(ReadResponseDecoder(res[1]).Data(), []byte("data")) {
+			t.Errorf("%d: unexpected read data %q", i, ReadResponseDecoder(res[1]).Data())
+		}
+
+		// all the responses are consumed, and their credits accounted
+		if granted, available := fs.conn.account.credits(); granted != 3 || available != 3 {
+			t.Errorf("%d: expected 3 credits granted and available, got %d, %d", i, granted, available)
+		}
+	}
+}
+
+func TestRecvCompoundBrokenNextCommand(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	tr.handler = func(pkt []byte) {
+		q := PacketCodec(pkt)
+		create := encodeTestResponse(q, &CreateResponse{
+			CreationTime:   &Filetime{},
+			LastAccessTime: &Filetime{},
+			LastWriteTime:  &Filetime{},
+			ChangeTime:     &Filetime{},
+			FileId:         &FileId{},
+		}, STATUS_SUCCESS)
+		create = append(create, make([]byte, Roundup(len(create), 8)-len(create))...)
+
+		cl := encodeTestResponse(PacketCodec(pkt[q.NextCommand():]), &CloseResponse{
+			CreationTime:   &Filetime{},
+			LastAccessTime: &Filetime{},
+			LastWriteTime:  &Filetime{},
+			ChangeTime:     &Filetime{},
+		}, STATUS_SUCCESS)
+
+		// the offset of the CLOSE response points past the end of the message
+		PacketCodec(create).SetNextCommand(uint32(Roundup(len(create)+len(cl)+8, 8)))
+
+		tr.push(append(create, cl...))
+	}
+
+	fs := newFakeShare(tr)
+	if err := openCreditWindow(fs, tr, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := fs.conn.account.loan(2, fs.ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	create := &CreateRequest{Name: "a"}
+	create.CreditCharge = 1
+	cl := &CloseRequest{FileId: relatedFileId}
+	cl.CreditCharge = 1
+	cl.PacketHeader.Flags = SMB2_FLAGS_RELATED_OPERATIONS
+
+	// used to block forever without a deadline
+	rrs, err := fs.sendCompound([]Packet{create, cl}, fs.treeConn, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the CREATE response is handled, while the rest is dropped rather than misparsed,
+	// so the CLOSE request fails with the error
+	if _, err := fs.recv(rrs[0]); err != nil {
+		t.Errorf("unexpected error of the CREATE response: %v", err)
+	}
+	if _, err := fs.recv(rrs[1]); err == nil {
+		t.Error("expected the CLOSE response to be dropped")
+	} else if _, ok := err.(*InvalidResponseError); !ok {
+		t.Errorf("expected an InvalidResponseError, got %v", err)
+	}
+	if _, ok := fs.conn.outstandingRequests.get(rrs[1].msgId); ok {
+		t.Error("the CLOSE request is still outstanding")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"crypto/sha512"
 	"fmt"
+	"io"
 	"os"
 	"sync"
 	"sync/atomic"
@@ -19,19 +20,74 @@ type Negotiator struct {
 	RequireMessageSigning bool     // enforce signing?
 	ClientGuid            [16]byte // if it's zero, generated by crypto/rand.
 	SpecifiedDialect      uint16   // if it's zero, clientDialects is used. (See feature.go for more details)
+
+	securityMode uint16   // Dialer.SecurityMode
+	signingAlgs  []uint16 // Dialer.SigningAlgorithms
+	ciphers      []uint16 // Dialer.EncryptionCiphers
+
+	maxMessageSize int // Dialer.MaxMessageSize
+
+	compatibilityMode bool // Dialer.CompatibilityMode
+
+	onMessage func(m Message) // Dialer.OnMessage
+}
+
+// dialectName returns the conventional name of the dialect, e.g. "3.1.1".
+func dialectName(d uint16) string {
+	switch d {
+	case SMB202:
+		return "2.0.2"
+	case SMB210:
+		return "2.1"
+	case SMB300:
+		return "3.0"
+	case SMB302:
+		return "3.0.2"
+	case SMB311:
+		return "3.1.1"
+	default:
+		return fmt.Sprintf("%#04x", d)
+	}
 }
 
 func (n *Negotiator) makeRequest() (*NegotiateRequest, error) {
 	req := new(NegotiateRequest)
 
-	if n.RequireMessageSigning {
+	switch {
+	case n.securityMode != 0:
+		req.SecurityMode = n.securityMode
+	case n.RequireMessageSigning:
 		req.SecurityMode = SMB2_NEGOTIATE_SIGNING_REQUIRED
-	} else {
+	default:
 		req.SecurityMode = SMB2_NEGOTIATE_SIGNING_ENABLED
 	}
 
 	req.Capabilities = clientCapabilities
 
+	ciphers := clientCiphers
+	if len(n.ciphers) > 0 {
+		for _, c := range n.ciphers {
+			switch c {
+			case AES128CCM, AES128GCM, AES256CCM, AES256GCM:
+			default:
+				return nil, &InternalError{fmt.Sprintf("unsupported cipher specified: %d", c)}
+			}
+		}
+		ciphers = n.ciphers
+	}
+
+	signingAlgs := clientSigningAlgs
+	if len(n.signingAlgs) > 0 {
+		for _, alg := range n.signingAlgs {
+			switch alg {
+			case HMAC_SHA256, AES_CMAC, AES_GMAC:
+			default:
+				return nil, &InternalError{fmt.Sprintf("unsupported signing algorithm specified: %d", alg)}
+			}
+		}
+		signingAlgs = n.signingAlgs
+	}
+
 	if n.ClientGuid == zero {
 		_, err := rand.Read(req.ClientGuid[:])
 		if err != nil {
@@ -41,6 +97,20 @@ func (n *Negotiator) makeRequest() (*NegotiateRequest, error) {
 		req.ClientGuid = n.ClientGuid
 	}
 
+	if n.compatibilityMode {
+		// neither SMB 3.1.1 nor its negotiate contexts are offered
+		switch n.SpecifiedDialect {
+		case UnknownSMB:
+			req.Dialects = clientCompatibilityDialects
+		case SMB210, SMB300:
+			req.Dialects = []uint16{n.SpecifiedDialect}
+		default:
+			return nil, &InternalError{fmt.Sprintf("dialect %s isn't supported by the compatibility mode", dialectName(n.SpecifiedDialect))}
+		}
+
+		return req, nil
+	}
+
 	if n.SpecifiedDialect != UnknownSMB {
 		req.Dialects = []uint16{n.SpecifiedDialect}
 
@@ -59,10 +129,18 @@ func (n *Negotiator) makeRequest() (*NegotiateRequest, error) {
 			}
 
 			cc := &CipherContext{
-				Ciphers: clientCiphers,
+				Ciphers: ciphers,
+			}
+
+			sc := &SigningContext{
+				SigningAlgorithms: signingAlgs,
 			}
 
-			req.Contexts = append(req.Contexts, hc, cc)
+			rc := &RDMATransformContext{
+				RDMATransformIds: clientRDMATransforms,
+			}
+
+			req.Contexts = append(req.Contexts, hc, cc, sc, rc)
 		default:
 			return nil, &InternalError{"unsupported dialect specified"}
 		}
@@ -78,10 +156,18 @@ func (n *Negotiator) makeRequest() (*NegotiateRequest, error) {
 		}
 
 		cc := &CipherContext{
-			Ciphers: clientCiphers,
+			Ciphers: ciphers,
+		}
+
+		sc := &SigningContext{
+			SigningAlgorithms: signingAlgs,
 		}
 
-		req.Contexts = append(req.Contexts, hc, cc)
+		rc := &RDMATransformContext{
+			RDMATransformIds: clientRDMATransforms,
+		}
+
+		req.Contexts = append(req.Contexts, hc, cc, sc, rc)
 	}
 
 	return req, nil
@@ -96,6 +182,13 @@ func (n *Negotiator) negotiate(t transport, a *account, ctx context.Context) (*c
 		wdone:               make(chan struct{}, 1),
 		write:               make(chan []byte, 1),
 		werr:                make(chan error, 1),
+		onMessage:           n.onMessage,
+	}
+
+	if n.maxMessageSize > 0 {
+		conn.setMaxMessageSize(n.maxMessageSize)
+	} else {
+		conn.setMaxMessageSize(clientMaxMessageSlack)
 	}
 
 	go conn.runSender()
@@ -107,16 +200,19 @@ retry:
 		return nil, err
 	}
 
-	req.CreditCharge = 1
+	req.CreditCharge, _, err = conn.loanCredit(0, ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	rr, err := conn.send(req, ctx)
 	if err != nil {
-		return nil, err
+		return nil, negotiateError(err, req)
 	}
 
 	pkt, err := conn.recv(rr)
 	if err != nil {
-		return nil, err
+		return nil, negotiateError(err, req)
 	}
 
 	res, err := accept(SMB2_NEGOTIATE, pkt)
@@ -139,7 +235,7 @@ retry:
 		return nil, &InvalidResponseError{"unexpected dialect returned"}
 	}
 
-	conn.requireSigning = n.RequireMessageSigning || r.SecurityMode()&SMB2_NEGOTIATE_SIGNING_REQUIRED != 0
+	conn.requireSigning = n.RequireMessageSigning || req.SecurityMode&SMB2_NEGOTIATE_SIGNING_REQUIRED != 0 || r.SecurityMode()&SMB2_NEGOTIATE_SIGNING_REQUIRED != 0
 	conn.capabilities = clientCapabilities & r.Capabilities()
 	conn.dialect = r.DialectRevision()
 	conn.maxTransactSize = r.MaxTransactSize()
@@ -147,14 +243,33 @@ retry:
 	conn.maxWriteSize = r.MaxWriteSize()
 	conn.sequenceWindow = 1
 
+	if n.maxMessageSize <= 0 {
+		max := conn.maxTransactSize
+		if conn.maxReadSize > max {
+			max = conn.maxReadSize
+		}
+		if conn.maxWriteSize > max {
+			max = conn.maxWriteSize
+		}
+		conn.setMaxMessageSize(int(uint64(max) + clientMaxMessageSlack))
+	}
+
 	// conn.gssNegotiateToken = r.SecurityBuffer()
-	// conn.clientGuid = n.ClientGuid
-	// copy(conn.serverGuid[:], r.ServerGuid())
+	conn.clientGuid = req.ClientGuid
+	conn.clientSecurityMode = req.SecurityMode
+	conn.clientDialects = req.Dialects
+	copy(conn.serverGuid[:], r.ServerGuid())
+	conn.serverSecurityMode = r.SecurityMode()
+	conn.serverCapabilities = r.Capabilities()
+	conn.negotiateResponse = append([]byte{}, pkt...)
 
 	if conn.dialect != SMB311 {
 		return conn, nil
 	}
 
+	// servers which don't understand SMB2_SIGNING_CAPABILITIES use AES-CMAC
+	conn.signingId = AES_CMAC
+
 	// handle context for SMB311
 	list := r.NegotiateContextList()
 	for count := r.NegotiateContextCount(); count > 0; count-- {
@@ -163,6 +278,8 @@ retry:
 			return nil, &InvalidResponseError{"broken negotiate context format"}
 		}
 
+		conn.negotiateContexts = append(conn.negotiateContexts, parseNegotiateContext(ctx))
+
 		switch ctx.ContextType() {
 		case SMB2_PREAUTH_INTEGRITY_CAPABILITIES:
 			d := HashContextDataDecoder(ctx.Data())
@@ -207,11 +324,35 @@ retry:
 			conn.cipherId = ciphs[0]
 
 			switch conn.cipherId {
+			case 0: // no common cipher, the connection isn't encrypted
 			case AES128CCM:
 			case AES128GCM:
+			case AES256CCM:
+			case AES256GCM:
 			default:
 				return nil, &InvalidResponseError{"unknown cipher algorithm"}
 			}
+		case SMB2_SIGNING_CAPABILITIES:
+			d := SigningContextDataDecoder(ctx.Data())
+			if d.IsInvalid() {
+				return nil, &InvalidResponseError{"broken signing context data format"}
+			}
+
+			algs := d.SigningAlgorithms()
+
+			if len(algs) != 1 {
+				return nil, &InvalidResponseError{"multiple signing algorithms"}
+			}
+
+			conn.signingId = algs[0]
+
+			switch conn.signingId {
+			case HMAC_SHA256:
+			case AES_CMAC:
+			case AES_GMAC:
+			default:
+				return nil, &InvalidResponseError{"unknown signing algorithm"}
+			}
 		default:
 			// skip unsupported context
 		}
@@ -228,14 +369,67 @@ retry:
 	return conn, nil
 }
 
+// negotiateError reports a timed out negotiate request with the offered dialects.
+func negotiateError(err error, req *NegotiateRequest) error {
+	if err, ok := err.(*ContextError); ok && err.Err == context.DeadlineExceeded {
+		return &NegotiateTimeoutError{Dialects: req.Dialects}
+	}
+	return err
+}
+
 type requestResponse struct {
 	msgId         uint64
 	asyncId       uint64
+	creditCharge  uint16
 	creditRequest uint16
 	pkt           []byte // request packet
 	ctx           context.Context
 	recv          chan []byte
+	async         chan struct{} // closed by the interim response if Dialer.AsyncWaitTimeout is set, nil otherwise
 	err           error
+
+	conn *conn         // non-nil if the request holds an in-flight slot
+	sem  chan struct{} // semaphore the slot was taken from
+
+	dm      sync.Mutex // held by the receiver while it writes into dst
+	dst     []byte     // if non-nil, the payload of the READ response is received directly into dst
+	revoked bool       // true if the payload mustn't be received into dst anymore (guarded by dm)
+	direct  bool       // true if the payload has been received into dst
+
+	pooled     bool // pkt is a pooled buffer, which is returned to the pool once it's written
+	recvPooled bool // the received packet is a whole pooled buffer, see putBuffer
+
+	following []*requestResponse // requests after this one in its compound request
+
+	// the request is kept for sending it again if the session has expired and Dialer.AutoReauth is set,
+	// req is nil otherwise or once it has been sent again
+	req   Packet
+	tc    *treeConn
+	epoch uint32 // re-authentications of the session before the request
+}
+
+// release frees the in-flight slot held by rr.
+func (rr *requestResponse) release() {
+	if rr.conn != nil {
+		if rr.dst != nil {
+			atomic.AddInt32(&rr.conn._directReads, -1)
+		}
+		rr.conn.release(rr.sem)
+	}
+}
+
+// revoke prevents the receiver from writing into rr.dst.
+// It waits for the receiver if it's writing into rr.dst.
+func (rr *requestResponse) revoke() {
+	rr.dm.Lock()
+	rr.revoked = true
+	rr.dm.Unlock()
+}
+
+// directReadRequest is a READ request whose payload is received directly into dst.
+type directReadRequest struct {
+	*ReadRequest
+	dst []byte
 }
 
 type outstandingRequests struct {
@@ -263,6 +457,15 @@ func (r *outstandingRequests) pop(msgId uint64) (*requestResponse, bool) {
 	return rr, true
 }
 
+func (r *outstandingRequests) get(msgId uint64) (*requestResponse, bool) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	rr, ok := r.requests[msgId]
+
+	return rr, ok
+}
+
 func (r *outstandingRequests) set(msgId uint64, rr *requestResponse) {
 	r.m.Lock()
 	defer r.m.Unlock()
@@ -277,6 +480,7 @@ func (r *outstandingRequests) shutdown(err error) {
 	for _, rr := range r.requests {
 		rr.err = err
 		close(rr.recv)
+		rr.release()
 	}
 }
 
@@ -295,6 +499,16 @@ type conn struct {
 	preauthIntegrityHashId    uint16
 	preauthIntegrityHashValue [64]byte
 	cipherId                  uint16
+	signingId                 uint16
+
+	dropOnSigningFailure bool
+
+	onAuthToken func(step string, token []byte) // see Dialer.OnAuthToken
+	onMessage   func(m Message)                 // see Dialer.OnMessage
+
+	asyncWaitTimeout time.Duration // see Dialer.AsyncWaitTimeout, zero means no limit
+
+	abandonedWrite bool // the result of the last write hasn't been received from werr (guarded by m)
 
 	account *account
 
@@ -308,10 +522,39 @@ type conn struct {
 	err error
 
 	// gssNegotiateToken []byte
-	// serverGuid        [16]byte
-	// clientGuid        [16]byte
+
+	// sent and received by negotiate, validated by FSCTL_VALIDATE_NEGOTIATE_INFO
+	clientGuid         [16]byte
+	clientSecurityMode uint16
+	clientDialects     []uint16
+	serverGuid         [16]byte
+	serverSecurityMode uint16
+	serverCapabilities uint32
+
+	negotiateResponse []byte             // see func (*Session) RawNegotiateResponse
+	negotiateContexts []NegotiateContext // see func (*Session) NegotiateContexts
 
 	_useSession int32 // receiver use session?
+
+	sem       chan struct{} // limits the number of in-flight requests, nil means unlimited
+	_inFlight int32         // number of requests waiting for the responses
+
+	_directReads int32 // number of outstanding directReadRequest
+
+	_maxMessageSize int32 // max size of a received message, see Dialer.MaxMessageSize
+}
+
+// setMaxMessageSize sets the max size of the messages the receiver accepts,
+// which is at most the max size of the transport anyway.
+func (conn *conn) setMaxMessageSize(n int) {
+	if n > maxDirectTCPSize {
+		n = maxDirectTCPSize
+	}
+	atomic.StoreInt32(&conn._maxMessageSize, int32(n))
+}
+
+func (conn *conn) maxMessageSize() int {
+	return int(atomic.LoadInt32(&conn._maxMessageSize))
 }
 
 func (conn *conn) useSession() bool {
@@ -322,6 +565,16 @@ func (conn *conn) enableSession() {
 	atomic.StoreInt32(&conn._useSession, 1)
 }
 
+// isClosed reports whether the receiver has stopped, i.e. the connection is no longer usable.
+func (conn *conn) isClosed() bool {
+	select {
+	case <-conn.wdone:
+		return true
+	default:
+		return false
+	}
+}
+
 func (conn *conn) newTimer() *time.Timer {
 	return time.NewTimer(5 * time.Second)
 }
@@ -340,14 +593,17 @@ func (conn *conn) sendRecv(cmd uint16, req Packet, ctx context.Context) (res []b
 	return accept(cmd, pkt)
 }
 
-func (conn *conn) loanCredit(payloadSize int, ctx context.Context) (creditCharge uint16, grantedPayloadSize int, err error) {
+// creditCharge returns the credit charge of a request of payloadSize bytes, which is always one
+// unless the connection supports multi-credit requests.
+func (conn *conn) creditCharge(payloadSize int) uint16 {
 	if conn.capabilities&SMB2_GLOBAL_CAP_LARGE_MTU == 0 {
-		creditCharge = 1
-	} else {
-		creditCharge = uint16((payloadSize-1)/(64*1024) + 1)
+		return 1
 	}
+	return creditCharge(payloadSize)
+}
 
-	creditCharge, isComplete, err := conn.account.loan(creditCharge, ctx)
+func (conn *conn) loanCredit(payloadSize int, ctx context.Context) (creditCharge uint16, grantedPayloadSize int, err error) {
+	creditCharge, isComplete, err := conn.account.loan(conn.creditCharge(payloadSize), ctx)
 	if err != nil {
 		return creditCharge, 0, err
 	}
@@ -355,136 +611,527 @@ func (conn *conn) loanCredit(payloadSize int, ctx context.Context) (creditCharge
 		return creditCharge, payloadSize, nil
 	}
 
-	return creditCharge, 64 * 1024 * int(creditCharge), nil
+	return creditCharge, creditPayloadSize * int(creditCharge), nil
 }
 
+// chargeCredit gives back the credits loaned to a request which isn't sent.
 func (conn *conn) chargeCredit(creditCharge uint16) {
-	conn.account.charge(creditCharge, creditCharge)
+	conn.account.refund(creditCharge)
 }
 
 func (conn *conn) send(req Packet, ctx context.Context) (rr *requestResponse, err error) {
 	return conn.sendWith(req, nil, ctx)
 }
 
+// sendWith sends req on tc, or outside of a tree if tc is nil. Every request but CANCEL holds loaned credits,
+// which are given back if it isn't written to the connection. Once it's written, its response settles the credits.
 func (conn *conn) sendWith(req Packet, tc *treeConn, ctx context.Context) (rr *requestResponse, err error) {
+	_, isCancel := req.(*CancelRequest)
+
+	var sem chan struct{}
+
+	switch req.(type) {
+	case *CancelRequest:
+	case *OplockBreakAcknowledgement:
+		// the acknowledgement doesn't wait for a slot, since the requests holding the slots
+		// may be blocked by the server until the break is acknowledged
+		atomic.AddInt32(&conn._inFlight, 1)
+	default:
+		sem, err = conn.acquire(ctx)
+		if err != nil {
+			conn.account.refund(req.Header().CreditCharge)
+
+			return nil, err
+		}
+	}
+
+	abort := func() {
+		if !isCancel {
+			conn.release(sem)
+			conn.account.refund(req.Header().CreditCharge)
+		}
+	}
+
 	conn.m.Lock()
 	defer conn.m.Unlock()
 
+	if err := conn.prepare(ctx); err != nil {
+		abort()
+
+		return nil, err
+	}
+
+	rr, err = conn.makeRequestResponse(req, tc, ctx)
+	if err != nil {
+		// makeRequestResponse has given back the credits
+		if !isCancel {
+			conn.release(sem)
+		}
+
+		return nil, err
+	}
+
+	if !isCancel {
+		rr.conn = conn
+		rr.sem = sem
+
+		if rr.dst != nil {
+			atomic.AddInt32(&conn._directReads, 1)
+		}
+
+		if s := conn.session; s != nil && s.initiator != nil {
+			if _, ok := req.(*SessionSetupRequest); !ok {
+				rr.req = req
+				rr.tc = tc
+				rr.epoch = atomic.LoadUint32(&s._epoch)
+			}
+		}
+	}
+
+	err = conn.transmit(rr.pkt, rr.pooled, []*requestResponse{rr}, ctx)
+	if rr.pooled {
+		rr.pkt = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return rr, nil
+}
+
+// prepare checks that a request can be written to the connection. conn.m must be held.
+func (conn *conn) prepare(ctx context.Context) error {
 	if conn.err != nil {
-		return nil, conn.err
+		return conn.err
 	}
 
 	select {
 	case <-ctx.Done():
-		return nil, &ContextError{Err: ctx.Err()}
+		return &ContextError{Err: ctx.Err()}
 	default:
 		// do nothing
 	}
 
-	rr, err = conn.makeRequestResponse(req, tc, ctx)
-	if err != nil {
-		return nil, err
-	}
+	return conn.drainWrite(ctx)
+}
 
+// transmit writes pkt, the packet of rrs, to the connection. If it isn't written, rrs are unsent,
+// and if the write fails or ctx is done while waiting for it, they're abandoned. conn.m must be held.
+func (conn *conn) transmit(pkt []byte, pooled bool, rrs []*requestResponse, ctx context.Context) error {
 	select {
-	case conn.write <- rr.pkt:
+	case conn.write <- pkt:
 		select {
-		case err = <-conn.werr:
+		case err := <-conn.werr:
+			if pooled {
+				putBuffer(pkt)
+			}
 			if err != nil {
-				conn.outstandingRequests.pop(rr.msgId)
+				for _, rr := range rrs {
+					conn.abandon(rr)
+				}
 
-				return nil, &TransportError{err}
+				return &TransportError{err}
 			}
+
+			return nil
 		case <-ctx.Done():
-			conn.outstandingRequests.pop(rr.msgId)
+			// the writer still owns pkt
+			conn.abandonedWrite = true
+			for _, rr := range rrs {
+				conn.abandon(rr)
+			}
 
-			return nil, &ContextError{Err: ctx.Err()}
+			return &ContextError{Err: ctx.Err()}
 		}
 	case <-ctx.Done():
-		conn.outstandingRequests.pop(rr.msgId)
+		if pooled {
+			putBuffer(pkt)
+		}
+		for i := len(rrs) - 1; i >= 0; i-- {
+			conn.unsend(rrs[i])
+		}
 
-		return nil, &ContextError{Err: ctx.Err()}
+		return &ContextError{Err: ctx.Err()}
 	}
+}
 
-	return rr, nil
+// drainWrite receives the result of the write of a request which gave up waiting for it,
+// so that it isn't taken for the result of the next write. conn.m must be held.
+func (conn *conn) drainWrite(ctx context.Context) error {
+	if !conn.abandonedWrite {
+		return nil
+	}
+
+	select {
+	case <-conn.werr:
+		conn.abandonedWrite = false
+		return nil
+	case <-ctx.Done():
+		return &ContextError{Err: ctx.Err()}
+	}
 }
 
-func (conn *conn) makeRequestResponse(req Packet, tc *treeConn, ctx context.Context) (rr *requestResponse, err error) {
-	hdr := req.Header()
+// sendCompound sends reqs as a compound request, so that they take a single round trip, and returns their
+// requestResponses in order. The caller sets SMB2_FLAGS_RELATED_OPERATIONS on the requests which work on
+// the file opened by the previous ones. The compound takes a single in-flight slot,
+// which is held by the last request. The credits loaned to the requests are given back if it isn't written.
+func (conn *conn) sendCompound(reqs []Packet, tc *treeConn, ctx context.Context) (rrs []*requestResponse, err error) {
+	refund := func() {
+		for _, req := range reqs {
+			conn.account.refund(req.Header().CreditCharge)
+		}
+	}
 
-	var msgId uint64
+	sem, err := conn.acquire(ctx)
+	if err != nil {
+		refund()
 
-	if _, ok := req.(*CancelRequest); !ok {
-		msgId = conn.sequenceWindow
+		return nil, err
+	}
+
+	conn.m.Lock()
+	defer conn.m.Unlock()
+
+	if err := conn.prepare(ctx); err != nil {
+		conn.release(sem)
+		refund()
+
+		return nil, err
+	}
+
+	rrs, pkt, pooled, err := conn.makeCompoundRequestResponses(reqs, tc, ctx)
+	if err != nil {
+		// makeCompoundRequestResponses has given back the credits
+		conn.release(sem)
+
+		return nil, err
+	}
+
+	last := rrs[len(rrs)-1]
+	last.conn = conn
+	last.sem = sem
+
+	if err := conn.transmit(pkt, pooled, rrs, ctx); err != nil {
+		return nil, err
+	}
+
+	return rrs, nil
+}
+
+// makeCompoundRequestResponses encodes reqs into a compound request packet, each of them signed,
+// and the whole packet encrypted if needed.
+func (conn *conn) makeCompoundRequestResponses(reqs []Packet, tc *treeConn, ctx context.Context) (rrs []*requestResponse, pkt []byte, pooled bool, err error) {
+	defer func() {
+		if err != nil {
+			// the compound isn't sent, so its message ids are taken by the next request
+			for _, rr := range rrs {
+				conn.outstandingRequests.pop(rr.msgId)
+				conn.unassign(rr.creditCharge, rr.creditRequest)
+			}
+			for _, req := range reqs[len(rrs):] {
+				conn.account.refund(req.Header().CreditCharge)
+			}
+		}
+	}()
+
+	s := conn.session
+	if s == nil {
+		return nil, nil, false, &InternalError{"compound requests need a session"}
+	}
+
+	encrypt, sign, err := s.protection(tc)
+	if err != nil {
+		return nil, nil, false, err
+	}
 
-		creditCharge := hdr.CreditCharge
+	// every request but the last one is padded to 8 bytes
+	sizes := make([]int, len(reqs))
+	size := 0
+	for i, req := range reqs {
+		sizes[i] = req.Size()
+		if i < len(reqs)-1 {
+			sizes[i] = Roundup(sizes[i], 8)
+		}
+		size += sizes[i]
+	}
+
+	pkt = make([]byte, size)
+
+	off := 0
+	for i, req := range reqs {
+		hdr := req.Header()
+
+		msgId := conn.assign(hdr)
+
+		s.address(hdr, tc)
+
+		p := pkt[off : off+sizes[i]]
+
+		req.Encode(p)
+
+		if i < len(reqs)-1 {
+			PacketCodec(p).SetNextCommand(uint32(sizes[i]))
+		}
+
+		conn.traceMessage(p, false)
+
+		if sign {
+			s.sign(p)
+		}
 
-		conn.sequenceWindow += uint64(creditCharge)
-		if hdr.CreditRequestResponse == 0 {
-			hdr.CreditRequestResponse = creditCharge
+		rr := &requestResponse{
+			msgId:         msgId,
+			creditCharge:  hdr.CreditCharge,
+			creditRequest: hdr.CreditRequestResponse,
+			ctx:           ctx,
+			recv:          make(chan []byte, 1),
 		}
 
-		hdr.CreditRequestResponse += conn.account.opening()
+		rrs = append(rrs, rr)
+
+		conn.outstandingRequests.set(msgId, rr)
+
+		off += sizes[i]
 	}
 
+	for i, rr := range rrs {
+		rr.following = rrs[i+1:]
+	}
+
+	if encrypt {
+		c, err := s.encrypt(pkt)
+		if err != nil {
+			return rrs, nil, false, &InternalError{err.Error()}
+		}
+		return rrs, c, true, nil
+	}
+
+	return rrs, pkt, false, nil
+}
+
+// acquire blocks until the number of in-flight requests gets below Dialer.MaxConcurrentRequests.
+// It returns the semaphore the slot was taken from, which must be passed to release.
+func (conn *conn) acquire(ctx context.Context) (chan struct{}, error) {
+	sem := conn.sem
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, &ContextError{Err: ctx.Err()}
+		case <-conn.wdone:
+			conn.m.Lock()
+			defer conn.m.Unlock()
+
+			if conn.err != nil {
+				return nil, conn.err
+			}
+			return nil, &InternalError{"connection closed"}
+		}
+	}
+	atomic.AddInt32(&conn._inFlight, 1)
+	return sem, nil
+}
+
+func (conn *conn) release(sem chan struct{}) {
+	atomic.AddInt32(&conn._inFlight, -1)
+	if sem != nil {
+		<-sem
+	}
+}
+
+func (conn *conn) inFlight() int {
+	return int(atomic.LoadInt32(&conn._inFlight))
+}
+
+// abandon removes rr from the outstanding requests before the response arrives.
+// The credits granted by the response are still added to the balance when it arrives.
+// The receiver doesn't write into rr.dst anymore once it returns.
+func (conn *conn) abandon(rr *requestResponse) {
+	if rr, ok := conn.outstandingRequests.pop(rr.msgId); ok {
+		conn.account.forget(rr.creditCharge, rr.creditRequest)
+		rr.release()
+	}
+	rr.revoke()
+}
+
+// unsend drops rr, whose packet isn't written to the connection, so that its message ids are taken by the next request
+// and its credits are given back. conn.m must be held.
+func (conn *conn) unsend(rr *requestResponse) {
+	if rr.creditCharge == 0 {
+		// a CANCEL request takes neither message ids nor credits
+		return
+	}
+
+	conn.outstandingRequests.pop(rr.msgId)
+	conn.unassign(rr.creditCharge, rr.creditRequest)
+	rr.release()
+}
+
+// assign takes the message ids of the request of hdr from the sequence window, and requests credits for it.
+// conn.m must be held.
+func (conn *conn) assign(hdr *PacketHeader) (msgId uint64) {
+	msgId = conn.sequenceWindow
+
+	conn.sequenceWindow += uint64(hdr.CreditCharge)
+
+	hdr.CreditRequestResponse = conn.account.request(hdr.CreditCharge, hdr.CreditRequestResponse)
 	hdr.MessageId = msgId
 
+	return msgId
+}
+
+// unassign reverts assign for a request which isn't sent, so that its message ids are taken by the next request.
+// conn.m must be held.
+func (conn *conn) unassign(creditCharge, creditRequest uint16) {
+	conn.sequenceWindow -= uint64(creditCharge)
+	conn.account.withdraw(creditCharge, creditRequest)
+}
+
+func (conn *conn) makeRequestResponse(req Packet, tc *treeConn, ctx context.Context) (rr *requestResponse, err error) {
+	hdr := req.Header()
+
+	var msgId uint64
+	var creditCharge uint16 // a CANCEL request doesn't charge credits
+
+	if _, ok := req.(*CancelRequest); ok {
+		// a CANCEL request refers to the request to cancel by its message id
+		msgId = hdr.MessageId
+	} else {
+		msgId = conn.assign(hdr)
+
+		creditCharge = hdr.CreditCharge
+
+		defer func() {
+			if err != nil {
+				conn.unassign(creditCharge, hdr.CreditRequestResponse)
+			}
+		}()
+	}
+
 	s := conn.session
 
 	if s != nil {
-		hdr.SessionId = s.sessionId
-
-		if tc != nil {
-			hdr.TreeId = tc.treeId
-		}
+		s.address(hdr, tc)
 	}
 
-	pkt := make([]byte, req.Size())
+	var pkt []byte
+	var pooled bool
+
+	switch req.(type) {
+	case *NegotiateRequest, *SessionSetupRequest:
+		// the packet is kept for computing the preauth integrity hash
+		pkt = make([]byte, req.Size())
+	default:
+		pkt = getBuffer(req.Size())
+		pooled = true
+	}
 
 	req.Encode(pkt)
 
+	conn.traceMessage(pkt, false)
+
 	if s != nil {
-		if _, ok := req.(*SessionSetupRequest); !ok {
-			if s.sessionFlags&SMB2_SESSION_FLAG_ENCRYPT_DATA != 0 || (tc != nil && tc.shareFlags&SMB2_SHAREFLAG_ENCRYPT_DATA != 0) {
-				pkt, err = s.encrypt(pkt)
+		if _, ok := req.(*SessionSetupRequest); ok {
+			// a binding is signed by the key of the session being bound, and a re-authentication by the key of the session,
+			// while the setup of a new session isn't signed, since it has no key yet
+			if s.signer != nil {
+				pkt = s.sign(pkt)
+			}
+		} else {
+			encrypt, sign, err := s.protection(tc)
+			if err != nil {
+				if pooled {
+					putBuffer(pkt)
+				}
+				return nil, err
+			}
+			switch {
+			case encrypt:
+				c, err := s.encrypt(pkt)
 				if err != nil {
 					return nil, &InternalError{err.Error()}
 				}
-			} else {
-				if s.sessionFlags&(SMB2_SESSION_FLAG_IS_GUEST|SMB2_SESSION_FLAG_IS_NULL) == 0 {
-					pkt = s.sign(pkt)
+				if pooled {
+					putBuffer(pkt)
 				}
+				pkt, pooled = c, true
+			case sign:
+				pkt = s.sign(pkt)
 			}
 		}
 	}
 
 	rr = &requestResponse{
 		msgId:         msgId,
+		creditCharge:  creditCharge,
 		creditRequest: hdr.CreditRequestResponse,
 		pkt:           pkt,
 		ctx:           ctx,
 		recv:          make(chan []byte, 1),
+		pooled:        pooled,
 	}
 
-	conn.outstandingRequests.set(msgId, rr)
+	if _, ok := req.(*ChangeNotifyRequest); !ok && conn.asyncWaitTimeout > 0 && creditCharge != 0 {
+		// a CHANGE_NOTIFY request is pending until a change occurs, which may take any time
+		rr.async = make(chan struct{})
+	}
+
+	if r, ok := req.(*directReadRequest); ok {
+		rr.dst = r.dst
+	}
+
+	if _, ok := req.(*CancelRequest); !ok {
+		// a CANCEL request has no response
+		conn.outstandingRequests.set(msgId, rr)
+	}
 
 	return rr, nil
 }
 
 func (conn *conn) recv(rr *requestResponse) ([]byte, error) {
-	select {
-	case pkt := <-rr.recv:
-		if rr.err != nil {
-			return nil, rr.err
-		}
-		return pkt, nil
-	case <-rr.ctx.Done():
-		conn.outstandingRequests.pop(rr.msgId)
+	async := rr.async
+	var timeout <-chan time.Time
+
+	for {
+		select {
+		case pkt := <-rr.recv:
+			if rr.err != nil {
+				return nil, rr.err
+			}
+			return pkt, nil
+		case <-async:
+			// the server has answered with an interim response, the final one is waited for up to Dialer.AsyncWaitTimeout
+			async = nil
 
-		return nil, &ContextError{Err: rr.ctx.Err()}
+			t := time.NewTimer(conn.asyncWaitTimeout)
+			defer t.Stop()
+
+			timeout = t.C
+		case <-timeout:
+			conn.abandon(rr)
+			conn.cancel(rr)
+
+			return nil, ErrAsyncTimeout
+		case <-rr.ctx.Done():
+			conn.abandon(rr)
+
+			return nil, &ContextError{Err: rr.ctx.Err()}
+		}
 	}
 }
 
+// cancel asks the server to cancel the async request rr by a CANCEL request. (See [MS-SMB2] 3.2.4.24)
+// rr must be abandoned, so that the STATUS_CANCELLED response of the server is taken for that of an abandoned request,
+// whose credits are kept.
+func (conn *conn) cancel(rr *requestResponse) {
+	req := new(CancelRequest)
+	req.Flags = SMB2_FLAGS_ASYNC_COMMAND
+	req.MessageId = rr.msgId
+	req.AsyncId = rr.asyncId
+
+	conn.send(req, rr.ctx)
+}
+
 func (conn *conn) runSender() {
 	for {
 		select {
@@ -504,22 +1151,29 @@ func (conn *conn) runReciever() {
 	for {
 		n, e := conn.t.ReadSize()
 		if e != nil {
-			err = &TransportError{e}
+			err = readError(e)
 
 			goto exit
 		}
 
-		pkt := make([]byte, n)
+		if max := conn.maxMessageSize(); max > 0 && n > max {
+			// the message can't be skipped without reading it, so the connection can't be used anymore
+			err = &InvalidResponseError{fmt.Sprintf("message size %d exceeds the max message size %d", n, max)}
 
-		_, e = conn.t.Read(pkt)
-		if e != nil {
-			err = &TransportError{e}
+			conn.t.Close()
 
 			goto exit
 		}
 
 		hasSession := conn.useSession()
 
+		pkt, payload, pooled, e := conn.readPacket(n, hasSession)
+		if e != nil {
+			err = readError(e)
+
+			goto exit
+		}
+
 		var isEncrypted bool
 
 		if hasSession {
@@ -530,15 +1184,21 @@ func (conn *conn) runReciever() {
 				continue
 			}
 
+			if isEncrypted {
+				// the decrypted packet shares the buffer
+				pooled = false
+			}
+
 			p := PacketCodec(pkt)
 			if s := conn.session; s != nil {
-				if s.sessionId != p.SessionId() {
+				// the oplock break notifications aren't bound to the session on some dialects
+				if s.sessionId != p.SessionId() && p.MessageId() != 0xFFFFFFFFFFFFFFFF {
 					logger.Println("skip:", &InvalidResponseError{"unknown session id"})
 
 					continue
 				}
 
-				if tc, ok := s.treeConnTables[p.TreeId()]; ok {
+				if tc := s.lookupTree(p.TreeId()); tc != nil {
 					if tc.treeId != p.TreeId() {
 						logger.Println("skip:", &InvalidResponseError{"unknown tree id"})
 
@@ -553,26 +1213,66 @@ func (conn *conn) runReciever() {
 		for {
 			p := PacketCodec(pkt)
 
+			var broken []*requestResponse // requests of the rest of the compound whose responses are dropped
+			var brokenErr error
+
 			if off := p.NextCommand(); off != 0 {
-				pkt, next = pkt[:off], pkt[off:]
+				if off < 64 || off%8 != 0 || int(off) >= len(pkt) {
+					// the boundary of the response is unknown, so the rest of the compound response is dropped,
+					// and its requests fail with the error
+					brokenErr = &InvalidResponseError{fmt.Sprintf("broken next command offset %d of a compound response of %d bytes", off, len(pkt))}
+
+					logger.Println("skip:", brokenErr)
+
+					if rr, ok := conn.outstandingRequests.get(p.MessageId()); ok {
+						broken = rr.following
+					}
+
+					next = nil
+				} else {
+					pkt, next = pkt[:off:off], pkt[off:]
+					pooled = false
+				}
 			} else {
 				next = nil
 			}
 
 			if hasSession {
-				e = conn.tryVerify(pkt, isEncrypted)
+				e = conn.tryVerify(pkt, payload, isEncrypted)
 			}
 
-			e = conn.tryHandle(pkt, e)
+			isMismatch := e == ErrSignatureMismatch
+			isDeleted := hasSession && e == nil && conn.isSessionDeleted(pkt)
+
+			e = conn.tryHandle(pkt, pooled, e)
 			if e != nil {
 				logger.Println("skip:", e)
 			}
 
+			conn.fail(broken, brokenErr)
+
+			if isMismatch && conn.dropOnSigningFailure {
+				err = ErrSignatureMismatch
+
+				conn.t.Close()
+
+				goto exit
+			}
+
+			if isDeleted {
+				// the connection carries a single session, so it's useless once the session is gone
+				err = ErrSessionDeleted
+
+				conn.t.Close()
+
+				goto exit
+			}
+
 			if next == nil {
 				break
 			}
 
-			pkt = next
+			pkt, payload = next, nil
 		}
 	}
 
@@ -584,6 +1284,11 @@ exit:
 		logger.Println("error:", err)
 	}
 
+	if err == ErrConnectionClosed {
+		// the write half is released too, the server won't read anything anymore
+		conn.t.Close()
+	}
+
 	conn.m.Lock()
 	defer conn.m.Unlock()
 
@@ -591,9 +1296,112 @@ exit:
 
 	conn.err = err
 
+	if err != nil {
+		// the requests waiting for credits would wait for the responses of the outstanding requests otherwise
+		conn.account.close(err)
+	}
+
+	if s := conn.session; s != nil {
+		if err == nil {
+			s.close(ErrLoggedOff)
+		} else {
+			s.close(err)
+		}
+	}
+
 	close(conn.wdone)
 }
 
+// fail makes the outstanding requests of rrs fail with err, since their responses can't be received anymore.
+func (conn *conn) fail(rrs []*requestResponse, err error) {
+	for _, rr := range rrs {
+		if rr, ok := conn.outstandingRequests.pop(rr.msgId); ok {
+			conn.account.forget(rr.creditCharge, rr.creditRequest)
+			rr.err = err
+			close(rr.recv)
+			rr.release()
+		}
+	}
+}
+
+// isSessionDeleted reports whether pkt tells that the server has deleted the session of conn,
+// e.g. because an administrator has closed it.
+func (conn *conn) isSessionDeleted(pkt []byte) bool {
+	p := PacketCodec(pkt)
+
+	return conn.session != nil && p.SessionId() == conn.session.sessionId && NtStatus(p.Status()) == STATUS_USER_SESSION_DELETED
+}
+
+// readError returns the error of the receiver for the read error e of the transport.
+// An EOF means the server has closed the connection, possibly in the middle of a packet.
+func readError(e error) error {
+	if e == io.EOF || e == io.ErrUnexpectedEOF {
+		return ErrConnectionClosed
+	}
+	return &TransportError{e}
+}
+
+// readPacket reads a packet of n bytes from the transport.
+// If it's a successful READ response whose request has a destination buffer,
+// the payload is read directly into the buffer and returned as payload, which isn't part of pkt.
+// Otherwise, pkt is read into a pooled buffer and pooled is true.
+func (conn *conn) readPacket(n int, hasSession bool) (pkt, payload []byte, pooled bool, err error) {
+	const hdrSize = 64 + 16 // SMB2 header + READ response without payload
+
+	if !hasSession || n <= hdrSize || atomic.LoadInt32(&conn._directReads) == 0 {
+		pkt = getBuffer(n)
+
+		_, err = conn.t.Read(pkt)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		return pkt, nil, true, nil
+	}
+
+	hdr := make([]byte, hdrSize)
+
+	_, err = conn.t.Read(hdr)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	p := PacketCodec(hdr)
+	r := ReadResponseDecoder(p.Data())
+
+	if !p.IsInvalid() && p.Command() == SMB2_READ && NtStatus(p.Status()) == STATUS_SUCCESS && p.NextCommand() == 0 &&
+		r.StructureSize() == 17 && int(r.DataOffset()) == hdrSize && int(r.DataLength()) == n-hdrSize {
+		if rr, ok := conn.outstandingRequests.get(p.MessageId()); ok {
+			rr.dm.Lock()
+			defer rr.dm.Unlock()
+
+			if !rr.revoked && rr.dst != nil && len(rr.dst) >= n-hdrSize {
+				payload = rr.dst[:n-hdrSize]
+
+				_, err = conn.t.Read(payload)
+				if err != nil {
+					return nil, nil, false, err
+				}
+
+				rr.direct = true
+
+				return hdr, payload, false, nil
+			}
+		}
+	}
+
+	pkt = getBuffer(n)
+
+	copy(pkt, hdr)
+
+	_, err = conn.t.Read(pkt[hdrSize:])
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	return pkt, nil, true, nil
+}
+
 func accept(cmd uint16, pkt []byte) (res []byte, err error) {
 	p := PacketCodec(pkt)
 	if command := p.Command(); cmd != command {
@@ -630,6 +1438,10 @@ func accept(cmd uint16, pkt []byte) (res []byte, err error) {
 		}
 	case SMB2_READ:
 		if status == STATUS_BUFFER_OVERFLOW {
+			// a part of a message of a named pipe, which is followed by the rest
+			if !ReadResponseDecoder(p.Data()).IsInvalid() {
+				return p.Data(), &ResponseError{Code: uint32(status)}
+			}
 			return nil, &ResponseError{Code: uint32(status)}
 		}
 	case SMB2_CHANGE_NOTIFY:
@@ -699,27 +1511,33 @@ func (conn *conn) tryDecrypt(pkt []byte) ([]byte, error, bool) {
 	return pkt, nil, false
 }
 
-func (conn *conn) tryVerify(pkt []byte, isEncrypted bool) error {
+func (conn *conn) tryVerify(pkt, payload []byte, isEncrypted bool) error {
 	p := PacketCodec(pkt)
 
-	msgId := p.MessageId()
+	// the decryption has authenticated the message already
+	if isEncrypted || isSigningExempt(p) {
+		return nil
+	}
 
-	if msgId != 0xFFFFFFFFFFFFFFFF {
-		if p.Flags()&SMB2_FLAGS_SIGNED != 0 {
-			if conn.session == nil || conn.session.sessionId != p.SessionId() {
-				return &InvalidResponseError{"unknown session id returned"}
-			} else {
-				if !conn.session.verify(pkt) {
-					return &InvalidResponseError{"unverified packet returned"}
-				}
-			}
+	// the server can't sign the responses of the session it has deleted, but a signed one is verified
+	if p.Flags()&SMB2_FLAGS_SIGNED == 0 && conn.isSessionDeleted(pkt) {
+		return nil
+	}
+
+	if p.Flags()&SMB2_FLAGS_SIGNED != 0 {
+		if conn.session == nil || conn.session.sessionId != p.SessionId() {
+			return &InvalidResponseError{"unknown session id returned"}
 		} else {
-			if conn.requireSigning && !isEncrypted {
-				if conn.session != nil {
-					if conn.session.sessionFlags&(SMB2_SESSION_FLAG_IS_GUEST|SMB2_SESSION_FLAG_IS_NULL) == 0 {
-						if conn.session.sessionId == p.SessionId() {
-							return &InvalidResponseError{"signing required"}
-						}
+			if !conn.session.verify(pkt, payload) {
+				return ErrSignatureMismatch
+			}
+		}
+	} else {
+		if conn.requireSigning {
+			if conn.session != nil {
+				if conn.session.sessionFlags&(SMB2_SESSION_FLAG_IS_GUEST|SMB2_SESSION_FLAG_IS_NULL) == 0 {
+					if conn.session.sessionId == p.SessionId() {
+						return ErrSignatureMismatch
 					}
 				}
 			}
@@ -729,27 +1547,90 @@ func (conn *conn) tryVerify(pkt []byte, isEncrypted bool) error {
 	return nil
 }
 
-func (conn *conn) tryHandle(pkt []byte, e error) error {
+// isSigningExempt reports whether the response p is neither verified nor required to be signed,
+// since the server doesn't sign it even if signing is required (See [MS-SMB2] 3.2.5.1.3 and 3.3.4.1.1):
+//   - the oplock break notifications, whose message id is 0xFFFFFFFFFFFFFFFF
+//   - the interim responses (STATUS_PENDING) of the async requests
+//   - the NEGOTIATE responses, which precede the keys
+//   - the SESSION_SETUP responses asking for more processing, e.g. the first leg of a re-authentication
+func isSigningExempt(p PacketCodec) bool {
+	switch {
+	case p.MessageId() == 0xFFFFFFFFFFFFFFFF:
+		return true
+	case NtStatus(p.Status()) == STATUS_PENDING:
+		return true
+	case p.Command() == SMB2_NEGOTIATE:
+		return true
+	case p.Command() == SMB2_SESSION_SETUP && NtStatus(p.Status()) == STATUS_MORE_PROCESSING_REQUIRED:
+		return true
+	}
+	return false
+}
+
+func (conn *conn) tryHandle(pkt []byte, pooled bool, e error) error {
+	conn.traceMessage(pkt, true)
+
 	p := PacketCodec(pkt)
 
 	msgId := p.MessageId()
 
+	if msgId == 0xFFFFFFFFFFFFFFFF && p.Command() == SMB2_OPLOCK_BREAK && e == nil {
+		defer func() {
+			if pooled {
+				putBuffer(pkt)
+			}
+		}()
+
+		if conn.session == nil {
+			return &InvalidResponseError{"oplock break notification without session"}
+		}
+		return conn.session.handleOplockBreak(pkt)
+	}
+
 	rr, ok := conn.outstandingRequests.pop(msgId)
 	switch {
 	case !ok:
+		// the request may have been abandoned, keep the granted credits anyway
+		conn.account.charge(p.CreditResponse(), 0)
+
+		if pooled {
+			putBuffer(pkt)
+		}
+
 		return &InvalidResponseError{"unknown message id returned"}
 	case e != nil:
+		if pooled {
+			putBuffer(pkt)
+		}
+
+		// the response isn't trusted, so its credits aren't granted, but the requested ones aren't pending anymore
+		conn.account.forget(rr.creditCharge, rr.creditRequest)
+
 		rr.err = e
 
 		close(rr.recv)
+		rr.release()
 	case NtStatus(p.Status()) == STATUS_PENDING:
+		if rr.async != nil && rr.asyncId == 0 {
+			defer close(rr.async)
+		}
 		rr.asyncId = p.AsyncId()
 		conn.account.charge(p.CreditResponse(), rr.creditRequest)
+		conn.account.consume(rr.creditCharge)
+		rr.creditRequest = 0 // settled by the interim response
+		rr.creditCharge = 0
 		conn.outstandingRequests.set(msgId, rr)
+
+		if pooled {
+			putBuffer(pkt)
+		}
 	default:
 		conn.account.charge(p.CreditResponse(), rr.creditRequest)
+		conn.account.consume(rr.creditCharge)
 
+		rr.recvPooled = pooled
 		rr.recv <- pkt
+		rr.release()
 	}
 
 	return nil
@@ -0,0 +1,766 @@
+package smb2
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nodauf/go-smb2/internal/utf16le"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+func newTestReadResponse(msgId uint64, sessionId uint64, data []byte) []byte {
+	pkt := make([]byte, 64+16+len(data))
+
+	p := PacketCodec(pkt)
+	p.SetProtocolId()
+	p.SetStructureSize()
+	p.SetCommand(SMB2_READ)
+	p.SetFlags(SMB2_FLAGS_SERVER_TO_REDIR)
+	p.SetMessageId(msgId)
+	p.SetSessionId(sessionId)
+
+	res := pkt[64:]
+	binary.LittleEndian.PutUint16(res[:2], 17)                 // StructureSize
+	res[2] = 64 + 16                                           // DataOffset
+	binary.LittleEndian.PutUint32(res[4:8], uint32(len(data))) // DataLength
+	copy(res[16:], data)
+
+	return pkt
+}
+
+func newTestDirectRead(conn *conn, msgId uint64, dst []byte) *requestResponse {
+	rr := newTestRequestResponse(conn, msgId)
+	rr.dst = dst
+	rr.conn = conn
+	atomic.AddInt32(&conn._directReads, 1)
+	return rr
+}
+
+func TestDirectRead(t *testing.T) {
+	tr := newFakeTransport()
+	conn := newSignedTestConn(tr)
+	defer tr.Close()
+
+	data := bytes.Repeat([]byte("0123456789"), 100)
+
+	for i, tc := range []struct {
+		dst    []byte
+		tamper bool
+		direct bool
+		err    error
+	}{
+		{make([]byte, len(data)), false, true, nil},
+		{make([]byte, len(data)+1), false, true, nil},
+		{make([]byte, len(data)-1), false, false, nil}, // too small, falls back to copy
+		{make([]byte, len(data)), true, true, ErrSignatureMismatch},
+	} {
+		msgId := uint64(i + 1)
+
+		rr := newTestDirectRead(conn, msgId, tc.dst)
+
+		pkt := newTestReadResponse(msgId, conn.session.sessionId, data)
+		conn.session.sign(pkt)
+		if tc.tamper {
+			pkt[len(pkt)-1] ^= 0xff
+		}
+		tr.push(pkt)
+
+		pkt, err := conn.recv(rr)
+		if err != tc.err {
+			t.Errorf("%d: expected %v, got %v", i, tc.err, err)
+			continue
+		}
+		if rr.direct != tc.direct {
+			t.Errorf("%d: expected direct %v, got %v", i, tc.direct, rr.direct)
+		}
+		if err != nil {
+			continue
+		}
+
+		if tc.direct {
+			if len(pkt) != 64+16 {
+				t.Errorf("%d: expected header only, got %d bytes", i, len(pkt))
+			}
+			if !bytes.Equal(tc.dst[:len(data)], data) {
+				t.Errorf("%d: payload isn't received into the buffer", i)
+			}
+		} else {
+			if !bytes.Equal(ReadResponseDecoder(PacketCodec(pkt).Data()).Data(), data) {
+				t.Errorf("%d: broken payload", i)
+			}
+		}
+	}
+
+	if n := atomic.LoadInt32(&conn._directReads); n != 0 {
+		t.Errorf("expected no outstanding direct reads, got %d", n)
+	}
+}
+
+func TestAbandonDirectRead(t *testing.T) {
+	tr := newFakeTransport()
+	conn := newSignedTestConn(tr)
+	defer tr.Close()
+
+	rr := newTestDirectRead(conn, 1, make([]byte, 10))
+
+	conn.abandon(rr)
+
+	if !rr.revoked {
+		t.Error("expected the buffer to be revoked")
+	}
+	if n := atomic.LoadInt32(&conn._directReads); n != 0 {
+		t.Errorf("expected no outstanding direct reads, got %d", n)
+	}
+}
+
+func BenchmarkDirectRead(b *testing.B) {
+	data := make([]byte, 64<<10)
+
+	for _, direct := range []bool{false, true} {
+		name := "Copy"
+		if direct {
+			name = "Direct"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			tr := newFakeTransport()
+			conn := newSignedTestConn(tr)
+			conn.requireSigning = false
+			defer tr.Close()
+
+			pkt := newTestReadResponse(0, conn.session.sessionId, data)
+			buf := make([]byte, len(data))
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				msgId := uint64(i + 1)
+
+				var rr *requestResponse
+				if direct {
+					rr = newTestDirectRead(conn, msgId, buf)
+				} else {
+					rr = newTestRequestResponse(conn, msgId)
+				}
+
+				PacketCodec(pkt).SetMessageId(msgId)
+				tr.push(pkt)
+
+				pkt, err := conn.recv(rr)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if !direct {
+					copy(buf, ReadResponseDecoder(PacketCodec(pkt).Data()).Data())
+					if rr.recvPooled {
+						putBuffer(pkt)
+					}
+				}
+			}
+		})
+	}
+}
+
+// newTestNegotiateContext returns a negotiate context of the type typ carrying data.
+func newTestNegotiateContext(typ uint16, data []byte) fakeBytes {
+	ctx := make([]byte, 8+len(data))
+	binary.LittleEndian.PutUint16(ctx[:2], typ)
+	binary.LittleEndian.PutUint16(ctx[2:4], uint16(len(data)))
+	copy(ctx[8:], data)
+	return ctx
+}
+
+func TestNegotiateContexts(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	rdma := make([]byte, 8+4)
+	binary.LittleEndian.PutUint16(rdma[:2], 2)          // TransformCount
+	binary.LittleEndian.PutUint16(rdma[8:10], 1)        // SMB2_RDMA_TRANSFORM_ENCRYPTION
+	binary.LittleEndian.PutUint16(rdma[10:12], 2)       // SMB2_RDMA_TRANSFORM_SIGNING
+	transport := []byte{1, 0, 0, 0}                     // SMB2_ACCEPT_TRANSPORT_LEVEL_SECURITY
+	unknown := []byte{0xde, 0xad, 0xbe, 0xef, 0x01}     // a context the package doesn't know
+	netname := utf16le.EncodeStringToBytes("nas.local") // echoed by some servers
+
+	var offered []uint16 // RDMA transforms offered by the client
+
+	tr.handler = func(pkt []byte) {
+		q := PacketCodec(pkt)
+
+		r := NegotiateRequestDecoder(q.Data())
+		list := r.NegotiateContextList()
+		for count := r.NegotiateContextCount(); count > 0 && len(list) >= 8; count-- {
+			ctx := NegotiateContextDecoder(list)
+			if ctx.ContextType() == SMB2_RDMA_TRANSFORM_CAPABILITIES {
+				offered = RDMATransformContextDataDecoder(ctx.Data()).RDMATransformIds()
+			}
+			if off := ctx.Next(); off < len(list) {
+				list = list[off:]
+			} else {
+				list = nil
+			}
+		}
+
+		res := &NegotiateResponse{
+			PacketHeader: PacketHeader{
+				Command:               SMB2_NEGOTIATE,
+				CreditRequestResponse: 1,
+				Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+				MessageId:             q.MessageId(),
+			},
+			DialectRevision: SMB311,
+			Capabilities:    SMB2_GLOBAL_CAP_LARGE_MTU | SMB2_GLOBAL_CAP_MULTI_CHANNEL,
+			MaxTransactSize: 65536,
+			MaxReadSize:     65536,
+			MaxWriteSize:    65536,
+			SystemTime:      &Filetime{},
+			ServerStartTime: &Filetime{},
+			Contexts: []Encoder{
+				&HashContext{HashAlgorithms: []uint16{SHA512}, HashSalt: make([]byte, 32)},
+				&CipherContext{Ciphers: []uint16{AES128GCM}},
+				&SigningContext{SigningAlgorithms: []uint16{AES_GMAC}},
+				newTestNegotiateContext(SMB2_NETNAME_NEGOTIATE_CONTEXT_ID, netname),
+				newTestNegotiateContext(SMB2_TRANSPORT_CAPABILITIES, transport),
+				newTestNegotiateContext(SMB2_RDMA_TRANSFORM_CAPABILITIES, rdma),
+				newTestNegotiateContext(0x100, unknown),
+			},
+		}
+		buf := make([]byte, res.Size())
+		res.Encode(buf)
+		PacketCodec(buf).SetCommand(SMB2_NEGOTIATE)
+		tr.push(buf)
+	}
+
+	n := &Negotiator{}
+	conn, err := n.negotiate(tr, openAccount(clientMaxCreditBalance), context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Session{s: &session{conn: conn}}
+
+	raw := c.RawNegotiateResponse()
+	if r := NegotiateResponseDecoder(PacketCodec(raw).Data()); r.IsInvalid() || r.NegotiateContextCount() != 7 {
+		t.Fatalf("unexpected raw negotiate response: %x", raw)
+	}
+
+	ctxs := c.NegotiateContexts()
+	if len(ctxs) != 7 {
+		t.Fatalf("expected 7 contexts, got %d", len(ctxs))
+	}
+
+	expected := []NegotiateContext{
+		{Type: NegotiateContextPreauthIntegrity, HashAlgorithms: []uint16{SHA512}},
+		{Type: NegotiateContextEncryption, Ciphers: []uint16{AES128GCM}},
+		{Type: NegotiateContextSigning, SigningAlgorithms: []uint16{AES_GMAC}},
+		{Type: NegotiateContextNetName, NetName: "nas.local"},
+		{Type: NegotiateContextTransportCapabilities, TransportFlags: 1},
+		{Type: NegotiateContextRDMATransform, RDMATransformIds: []uint16{1, 2}},
+		{Type: 0x100, Data: unknown},
+	}
+	for i, ctx := range ctxs {
+		if i < len(expected)-1 {
+			ctx.Data = nil
+		}
+		if !reflect.DeepEqual(ctx, expected[i]) {
+			t.Errorf("context %d: expected %+v, got %+v", i, expected[i], ctx)
+		}
+	}
+
+	if conn.cipherId != AES128GCM || conn.signingId != AES_GMAC {
+		t.Errorf("unexpected cipher %d or signing algorithm %d", conn.cipherId, conn.signingId)
+	}
+
+	if max := conn.maxMessageSize(); max != 65536+clientMaxMessageSlack {
+		t.Errorf("expected the max message size %d, got %d", 65536+clientMaxMessageSlack, max)
+	}
+
+	if !reflect.DeepEqual(offered, []uint16{RDMATransformEncryption, RDMATransformSigning}) {
+		t.Errorf("expected the RDMA transforms to be offered, got %v", offered)
+	}
+
+	info := c.ConnInfo()
+	if !info.MultiChannel || !info.RDMATransformsSupported || !reflect.DeepEqual(info.RDMATransformIds, []uint16{1, 2}) {
+		t.Errorf("expected the server to accept the RDMA transforms, got %+v", info)
+	}
+	if info.SigningAlgorithm != SigningAlgorithmAESGMAC || info.Cipher != CipherAES128GCM {
+		t.Errorf("expected AES-GMAC signing and AES-128-GCM encryption, got %d, %d", info.SigningAlgorithm, info.Cipher)
+	}
+}
+
+func TestConnInfoAlgorithms(t *testing.T) {
+	tests := []struct {
+		dialect      uint16
+		capabilities uint32
+		signing      uint16
+		cipher       uint16
+	}{
+		{SMB202, 0, SigningAlgorithmHMACSHA256, 0},
+		{SMB210, SMB2_GLOBAL_CAP_LARGE_MTU, SigningAlgorithmHMACSHA256, 0},
+		{SMB300, 0, SigningAlgorithmAESCMAC, 0},
+		{SMB302, SMB2_GLOBAL_CAP_ENCRYPTION, SigningAlgorithmAESCMAC, CipherAES128CCM},
+	}
+
+	for _, tt := range tests {
+		conn := &conn{dialect: tt.dialect, capabilities: tt.capabilities}
+		c := &Session{s: &session{conn: conn}}
+
+		info := c.ConnInfo()
+		if info.SigningAlgorithm != tt.signing || info.Cipher != tt.cipher {
+			t.Errorf("%#x: expected signing algorithm %d and cipher %d, got %d, %d", tt.dialect, tt.signing, tt.cipher, info.SigningAlgorithm, info.Cipher)
+		}
+	}
+}
+
+func TestNegotiateAllowedAlgorithms(t *testing.T) {
+	tests := []struct {
+		name          string
+		dialect       uint16
+		signingAlgs   []uint16 // Dialer.SigningAlgorithms
+		ciphers       []uint16 // Dialer.EncryptionCiphers
+		serverSigning []uint16 // supported by the server
+		serverCiphers []uint16
+		cipher        uint16 // expected to be selected
+		mismatch      bool
+	}{
+		{name: "default", dialect: SMB311, serverSigning: []uint16{AES_CMAC}, serverCiphers: []uint16{AES128CCM}, cipher: AES128CCM},
+		{
+			name:          "aes-256-gcm",
+			dialect:       SMB311,
+			ciphers:       []uint16{CipherAES256GCM, CipherAES128GCM},
+			serverSigning: []uint16{AES_CMAC},
+			serverCiphers: []uint16{AES128GCM, AES256GCM},
+			cipher:        AES256GCM,
+		},
+		{
+			name:          "no common cipher",
+			dialect:       SMB311,
+			ciphers:       []uint16{CipherAES256GCM},
+			serverSigning: []uint16{AES_CMAC},
+			serverCiphers: []uint16{AES128GCM},
+			mismatch:      true,
+		},
+		{
+			name:          "gmac unsupported",
+			dialect:       SMB311,
+			signingAlgs:   []uint16{SigningAlgorithmAESGMAC},
+			serverSigning: []uint16{AES_CMAC},
+			serverCiphers: []uint16{AES128GCM},
+			cipher:        AES128GCM,
+			mismatch:      true,
+		},
+		{
+			name:        "gmac by older dialect",
+			dialect:     SMB302,
+			signingAlgs: []uint16{SigningAlgorithmAESGMAC},
+			mismatch:    true,
+		},
+		{
+			name:     "cipher by older dialect",
+			dialect:  SMB210,
+			ciphers:  []uint16{CipherAES128CCM},
+			mismatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newFakeTransport()
+			defer tr.Close()
+
+			var offeredSigning, offeredCiphers []uint16
+
+			// selectAlg returns the first offered algorithm supported by the server, or zero
+			selectAlg := func(offered, supported []uint16) uint16 {
+				for _, a := range offered {
+					for _, b := range supported {
+						if a == b {
+							return a
+						}
+					}
+				}
+				return 0
+			}
+
+			tr.handler = func(pkt []byte) {
+				q := PacketCodec(pkt)
+
+				r := NegotiateRequestDecoder(q.Data())
+				list := r.NegotiateContextList()
+				for count := r.NegotiateContextCount(); count > 0 && len(list) >= 8; count-- {
+					ctx := NegotiateContextDecoder(list)
+					switch ctx.ContextType() {
+					case SMB2_ENCRYPTION_CAPABILITIES:
+						offeredCiphers = CipherContextDataDecoder(ctx.Data()).Ciphers()
+					case SMB2_SIGNING_CAPABILITIES:
+						offeredSigning = SigningContextDataDecoder(ctx.Data()).SigningAlgorithms()
+					}
+					if off := ctx.Next(); off < len(list) {
+						list = list[off:]
+					} else {
+						list = nil
+					}
+				}
+
+				res := &NegotiateResponse{
+					PacketHeader: PacketHeader{
+						Command:               SMB2_NEGOTIATE,
+						CreditRequestResponse: 1,
+						Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+						MessageId:             q.MessageId(),
+					},
+					DialectRevision: tt.dialect,
+					SystemTime:      &Filetime{},
+					ServerStartTime: &Filetime{},
+				}
+				if tt.dialect == SMB311 {
+					res.Contexts = []Encoder{
+						&HashContext{HashAlgorithms: []uint16{SHA512}, HashSalt: make([]byte, 32)},
+						&CipherContext{Ciphers: []uint16{selectAlg(offeredCiphers, tt.serverCiphers)}},
+						&SigningContext{SigningAlgorithms: []uint16{selectAlg(offeredSigning, tt.serverSigning)}},
+					}
+				}
+				buf := make([]byte, res.Size())
+				res.Encode(buf)
+				PacketCodec(buf).SetCommand(SMB2_NEGOTIATE)
+				tr.push(buf)
+			}
+
+			n := &Negotiator{signingAlgs: tt.signingAlgs, ciphers: tt.ciphers}
+			conn, err := n.negotiate(tr, openAccount(clientMaxCreditBalance), context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedSigning, expectedCiphers := tt.signingAlgs, tt.ciphers
+			if expectedSigning == nil {
+				expectedSigning = clientSigningAlgs
+			}
+			if expectedCiphers == nil {
+				expectedCiphers = clientCiphers
+			}
+			if !reflect.DeepEqual(offeredSigning, expectedSigning) || !reflect.DeepEqual(offeredCiphers, expectedCiphers) {
+				t.Errorf("expected %v and %v to be offered, got %v, %v", expectedSigning, expectedCiphers, offeredSigning, offeredCiphers)
+			}
+
+			if tt.dialect == SMB311 && conn.cipherId != tt.cipher {
+				t.Errorf("expected cipher %d, got %d", tt.cipher, conn.cipherId)
+			}
+
+			err = conn.checkAlgorithms(tt.signingAlgs, tt.ciphers)
+			if _, ok := err.(*AlgorithmMismatchError); ok != tt.mismatch {
+				t.Errorf("expected mismatch %v, got %v", tt.mismatch, err)
+			}
+		})
+	}
+
+	n := &Negotiator{ciphers: []uint16{0x10}}
+	if _, err := n.makeRequest(); err == nil {
+		t.Error("expected an unknown cipher to be rejected")
+	}
+}
+
+func TestNegotiateSecurityMode(t *testing.T) {
+	tests := []struct {
+		name           string
+		n              Negotiator
+		securityMode   uint16
+		serverMode     uint16
+		expected       uint16
+		requireSigning bool
+	}{
+		{name: "default", expected: SecurityModeSigningEnabled},
+		{name: "require", n: Negotiator{RequireMessageSigning: true}, expected: SecurityModeSigningRequired, requireSigning: true},
+		{name: "server requires", serverMode: SMB2_NEGOTIATE_SIGNING_REQUIRED, expected: SecurityModeSigningEnabled, requireSigning: true},
+		{
+			name:           "override required",
+			securityMode:   SecurityModeSigningEnabled | SecurityModeSigningRequired,
+			expected:       SecurityModeSigningEnabled | SecurityModeSigningRequired,
+			requireSigning: true,
+		},
+		{
+			// RequireMessageSigning still enforces signing, even if it isn't advertised
+			name:           "override enabled",
+			n:              Negotiator{RequireMessageSigning: true},
+			securityMode:   SecurityModeSigningEnabled,
+			expected:       SecurityModeSigningEnabled,
+			requireSigning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newFakeTransport()
+			defer tr.Close()
+
+			var advertised uint16
+
+			tr.handler = func(pkt []byte) {
+				q := PacketCodec(pkt)
+				advertised = NegotiateRequestDecoder(q.Data()).SecurityMode()
+
+				res := &NegotiateResponse{
+					PacketHeader: PacketHeader{
+						Command:               SMB2_NEGOTIATE,
+						CreditRequestResponse: 1,
+						Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+						MessageId:             q.MessageId(),
+					},
+					SecurityMode:    SMB2_NEGOTIATE_SIGNING_ENABLED | tt.serverMode,
+					DialectRevision: SMB210,
+					SystemTime:      &Filetime{},
+					ServerStartTime: &Filetime{},
+				}
+				buf := make([]byte, res.Size())
+				res.Encode(buf)
+				PacketCodec(buf).SetCommand(SMB2_NEGOTIATE)
+				tr.push(buf)
+			}
+
+			n := tt.n
+			n.SpecifiedDialect = SMB210
+			n.securityMode = tt.securityMode
+
+			conn, err := n.negotiate(tr, openAccount(clientMaxCreditBalance), context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if advertised != tt.expected {
+				t.Errorf("expected security mode %d to be advertised, got %d", tt.expected, advertised)
+			}
+			if conn.clientSecurityMode != tt.expected {
+				t.Errorf("expected security mode %d to be validated, got %d", tt.expected, conn.clientSecurityMode)
+			}
+			if conn.requireSigning != tt.requireSigning {
+				t.Errorf("expected requireSigning %v, got %v", tt.requireSigning, conn.requireSigning)
+			}
+		})
+	}
+}
+
+func TestAsyncWaitTimeout(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	var cancels []PacketCodec
+
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+
+		if q.Command() == SMB2_CANCEL {
+			cancels = append(cancels, q)
+
+			// the canceled request completes
+			pkt := newFakeResponse(req, 1)
+			PacketCodec(pkt).SetCommand(SMB2_FLUSH)
+			PacketCodec(pkt).SetStatus(uint32(STATUS_CANCELLED))
+			tr.push(pkt)
+			return
+		}
+
+		// an interim response granting 4 credits
+		pkt := newFakeResponse(req, 4)
+		p := PacketCodec(pkt)
+		p.SetStatus(uint32(STATUS_PENDING))
+		p.SetFlags(SMB2_FLAGS_SERVER_TO_REDIR | SMB2_FLAGS_ASYNC_COMMAND)
+		p.SetAsyncId(42)
+		tr.push(pkt)
+	}
+
+	conn := newFakeConn(tr, 64)
+	conn.asyncWaitTimeout = 10 * time.Millisecond
+
+	var err error
+
+	req := &FlushRequest{FileId: &FileId{}}
+	req.CreditCharge, _, err = conn.account.loan(1, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.CreditRequestResponse = 4
+
+	rr, err := conn.send(req, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := conn.recv(rr); err != ErrAsyncTimeout {
+		t.Fatalf("expected ErrAsyncTimeout, got %v", err)
+	}
+
+	if len(cancels) != 1 {
+		t.Fatalf("expected a CANCEL request, got %d", len(cancels))
+	}
+	if c := cancels[0]; c.MessageId() != rr.msgId || c.AsyncId() != 42 || c.Flags()&SMB2_FLAGS_ASYNC_COMMAND == 0 {
+		t.Errorf("unexpected CANCEL request: message id %d, async id %d, flags %#x", c.MessageId(), c.AsyncId(), c.Flags())
+	}
+
+	// the credit of the request is consumed, and the credits of the interim response
+	// and of the final response of the canceled request are kept
+	deadline := time.Now().Add(time.Second)
+	for {
+		if granted, _ := conn.account.credits(); granted == 4+1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			granted, _ := conn.account.credits()
+			t.Fatalf("expected %d credits, got %d", 5, granted)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	conn := newFakeConn(direct(client), 64)
+
+	go func() {
+		defer server.Close()
+
+		// the request is read, and the connection is closed in the middle of its response
+		var size [4]byte
+		if _, err := io.ReadFull(server, size[:]); err != nil {
+			return
+		}
+		if _, err := io.ReadFull(server, make([]byte, binary.BigEndian.Uint32(size[:]))); err != nil {
+			return
+		}
+		server.Write([]byte{0, 0, 0, 128})
+		server.Write(make([]byte, 10))
+	}()
+
+	var err error
+
+	req := &FlushRequest{FileId: &FileId{}}
+	req.CreditCharge, _, err = conn.account.loan(1, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr, err := conn.send(req, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no credit is left, so the loan waits for the response of the outstanding request
+	loaned := make(chan error, 1)
+	go func() {
+		_, _, err := conn.loanCredit(0, context.Background())
+		loaned <- err
+	}()
+
+	if _, err := conn.recv(rr); err != ErrConnectionClosed {
+		t.Errorf("expected ErrConnectionClosed for the outstanding request, got %v", err)
+	}
+
+	select {
+	case err := <-loaned:
+		if err != ErrConnectionClosed {
+			t.Errorf("expected ErrConnectionClosed for the loan, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the loan is still waiting for credits")
+	}
+
+	// the receiver has exited and the connection is dead
+	select {
+	case <-conn.wdone:
+	case <-time.After(time.Second):
+		t.Fatal("the receiver is still running")
+	}
+
+	req = &FlushRequest{FileId: &FileId{}}
+	req.CreditCharge = 1
+	if _, err := conn.send(req, context.Background()); err != ErrConnectionClosed {
+		t.Errorf("expected ErrConnectionClosed for a new request, got %v", err)
+	}
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	conn := newFakeConn(direct(client), 64)
+	conn.setMaxMessageSize(1024)
+
+	go func() {
+		defer server.Close()
+
+		var size [4]byte
+		if _, err := io.ReadFull(server, size[:]); err != nil {
+			return
+		}
+		if _, err := io.ReadFull(server, make([]byte, binary.BigEndian.Uint32(size[:]))); err != nil {
+			return
+		}
+		// a length header claiming 1 MiB, which isn't followed by the message
+		server.Write([]byte{0, 0x10, 0, 0})
+		io.Copy(ioutil.Discard, server)
+	}()
+
+	req := &FlushRequest{FileId: &FileId{}}
+	req.CreditCharge = 1
+
+	rr, err := conn.send(req, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := conn.recv(rr); err == nil {
+		t.Fatal("expected the oversized message to fail the request")
+	} else if _, ok := err.(*InvalidResponseError); !ok {
+		t.Errorf("expected InvalidResponseError, got %v", err)
+	}
+
+	// the connection is closed instead of reading the message
+	if _, err := client.Write([]byte{0}); err == nil {
+		t.Error("expected the transport to be closed")
+	}
+}
+
+func TestCompatibilityMode(t *testing.T) {
+	n := &Negotiator{compatibilityMode: true}
+
+	req, err := n.makeRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(req.Dialects, []uint16{SMB300, SMB210}) {
+		t.Errorf("expected SMB 3.0 and 2.1 to be offered, got %#x", req.Dialects)
+	}
+
+	buf := make([]byte, req.Size())
+	req.Encode(buf)
+	if r := NegotiateRequestDecoder(PacketCodec(buf).Data()); r.NegotiateContextCount() != 0 || len(r.NegotiateContextList()) != 0 {
+		t.Errorf("expected no negotiate contexts, got %d", r.NegotiateContextCount())
+	}
+
+	n.SpecifiedDialect = SMB300
+	req, err = n.makeRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(req.Dialects, []uint16{SMB300}) {
+		t.Errorf("expected SMB 3.0 to be offered, got %#x", req.Dialects)
+	}
+
+	n.SpecifiedDialect = SMB311
+	if _, err := n.makeRequest(); err == nil {
+		t.Error("expected SMB 3.1.1 to be rejected")
+	}
+}
@@ -0,0 +1,274 @@
+package smb2
+
+import (
+	"io"
+	"os"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// maxCopyDepth is the max number of outstanding requests of a pipelined copy whose depth is derived from the credits.
+const maxCopyDepth = 16
+
+// CopyOptions configures the pipelined copies of func (*File) WriteToWithOptions and func (*File) ReadFromWithOptions.
+// On high-latency links, a single outstanding request can't saturate the bandwidth,
+// so several chunks of the file are read or written concurrently.
+type CopyOptions struct {
+	// Depth is the number of concurrent outstanding reads or writes.
+	// If it's zero, it's derived from the credits the server has granted, up to 16.
+	// Requests beyond the available credits wait for the responses of the previous ones.
+	Depth int
+
+	// ChunkSize is the size of each read or write request.
+	// If it's zero, it's the chunk size of the share (see func (*Share) SetIOChunkSize).
+	// It's clamped to the max read or write size negotiated with the server.
+	ChunkSize int
+}
+
+// ReadFromWithOptions is like ReadFrom, but the writes are pipelined as configured by opts.
+// A nil opts uses the defaults.
+func (f *File) ReadFromWithOptions(r io.Reader, opts *CopyOptions) (n int64, err error) {
+	rf, ok := r.(*File)
+	if ok && rf.fs.treeConn == f.fs.treeConn {
+		if supported, n, err := rf.copyTo(f); supported {
+			return n, err
+		}
+	}
+
+	if f.fs.shareType == SMB2_SHARE_TYPE_PIPE {
+		// writes to a pipe are messages, which mustn't be reordered
+		return copyBuffer(r, f, make([]byte, f.maxWriteSize()))
+	}
+
+	return f.readFrom(r, opts)
+}
+
+// WriteToWithOptions is like WriteTo, but the reads are pipelined as configured by opts.
+// A nil opts uses the defaults.
+func (f *File) WriteToWithOptions(w io.Writer, opts *CopyOptions) (n int64, err error) {
+	wf, ok := w.(*File)
+	if ok && wf.fs.treeConn == f.fs.treeConn {
+		if supported, n, err := f.copyTo(wf); supported {
+			return n, err
+		}
+	}
+
+	if f.fs.shareType == SMB2_SHARE_TYPE_PIPE {
+		// reads from a pipe are messages, which mustn't be reordered
+		return copyBuffer(f, w, make([]byte, f.maxReadSize()))
+	}
+
+	return f.writeTo(w, opts)
+}
+
+// copyParams returns the chunk size and the depth of a pipelined copy,
+// where maxSize is the max read or write size of the file.
+func (f *File) copyParams(opts *CopyOptions, maxSize int) (size, depth int) {
+	size = maxSize
+	if opts != nil {
+		if opts.ChunkSize > 0 && opts.ChunkSize < size {
+			size = opts.ChunkSize
+		}
+		depth = opts.Depth
+	}
+
+	if depth <= 0 {
+		granted, _ := f.fs.conn.account.credits()
+
+		depth = granted / int(f.fs.conn.creditCharge(size))
+		if depth > maxCopyDepth {
+			depth = maxCopyDepth
+		}
+		if depth < 1 {
+			depth = 1
+		}
+	}
+
+	return size, depth
+}
+
+// copyChunk is the result of a read or a write of a pipelined copy.
+type copyChunk struct {
+	buf []byte
+	n   int
+	err error
+}
+
+// writeTo writes the file from its offset to w. Up to depth chunks are read ahead concurrently,
+// and written to w in order as they complete.
+func (f *File) writeTo(w io.Writer, opts *CopyOptions) (n int64, err error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	off, err := f.seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, &os.PathError{Op: "read", Path: f.name, Err: err}
+	}
+
+	size, depth := f.copyParams(opts, f.maxReadSize())
+
+	pending := make([]chan copyChunk, 0, depth) // outstanding reads in the order of their offsets
+	next := off
+
+	read := func(buf []byte) {
+		ch := make(chan copyChunk, 1)
+		pending = append(pending, ch)
+
+		roff := next
+		next += int64(len(buf))
+
+		go func() {
+			var m int
+			err := f.fs.retry(true, func() (err error) {
+				m, err = f.readAtBuffer(buf, roff)
+				return
+			})
+			ch <- copyChunk{buf: buf, n: m, err: err}
+		}()
+	}
+
+	for i := 0; i < depth; i++ {
+		read(make([]byte, size))
+	}
+
+	eof := false
+
+	// the outstanding reads are waited for even after an error, so that none outlives the call
+	for len(pending) > 0 {
+		c := <-pending[0]
+		pending = pending[1:]
+
+		if eof || err != nil {
+			continue
+		}
+
+		if c.err != nil {
+			if rerr, ok := c.err.(*ResponseError); ok && NtStatus(rerr.Code) == STATUS_END_OF_FILE {
+				eof = true
+				continue
+			}
+			err = &os.PathError{Op: "read", Path: f.name, Err: c.err}
+			continue
+		}
+
+		if c.n > 0 {
+			nw, ew := w.Write(c.buf[:c.n])
+			if nw > 0 {
+				n += int64(nw)
+			}
+			if ew != nil {
+				err = ew
+				continue
+			}
+			if nw != c.n {
+				err = io.ErrShortWrite
+				continue
+			}
+		}
+
+		// a read returns fewer bytes than requested only at the end of file
+		if c.n < len(c.buf) {
+			eof = true
+			continue
+		}
+
+		read(c.buf)
+	}
+
+	if _, e := f.seek(off+n, io.SeekStart); err == nil {
+		err = e
+	}
+
+	return n, err
+}
+
+// readFrom writes r to the file from its offset. r is read into chunks, which are written concurrently,
+// up to depth outstanding writes at a time.
+func (f *File) readFrom(r io.Reader, opts *CopyOptions) (n int64, err error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	off, err := f.seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, &os.PathError{Op: "write", Path: f.name, Err: err}
+	}
+
+	size, depth := f.copyParams(opts, f.maxWriteSize())
+
+	pending := make([]chan copyChunk, 0, depth) // outstanding writes in the order of their offsets
+	next := off
+
+	var free [][]byte // buffers of the completed writes
+
+	failed := false // a write has failed, so the following ones aren't counted
+
+	// wait waits for the oldest outstanding write, and returns whether it succeeded.
+	wait := func() bool {
+		c := <-pending[0]
+		pending = pending[1:]
+
+		free = append(free, c.buf[:cap(c.buf)])
+
+		if failed {
+			return false
+		}
+		if c.err != nil {
+			failed = true
+			if err == nil {
+				err = &os.PathError{Op: "write", Path: f.name, Err: c.err}
+			}
+			return false
+		}
+		n += int64(c.n)
+		return true
+	}
+
+	for err == nil {
+		if len(pending) == depth && !wait() {
+			break
+		}
+
+		var buf []byte
+		if len(free) > 0 {
+			buf, free = free[len(free)-1], free[:len(free)-1]
+		} else {
+			buf = make([]byte, size)
+		}
+
+		nr, er := io.ReadFull(r, buf)
+		if nr > 0 {
+			ch := make(chan copyChunk, 1)
+			pending = append(pending, ch)
+
+			buf = buf[:nr]
+			woff := next
+			next += int64(nr)
+
+			go func() {
+				var m int
+				err := f.fs.retry(false, func() (err error) {
+					m, err = f.writeAt(buf, woff)
+					return
+				})
+				ch <- copyChunk{buf: buf, n: m, err: err}
+			}()
+		}
+		if er != nil {
+			if er != io.EOF && er != io.ErrUnexpectedEOF {
+				err = er
+			}
+			break
+		}
+	}
+
+	for len(pending) > 0 {
+		wait()
+	}
+
+	if _, e := f.seek(off+n, io.SeekStart); err == nil {
+		err = e
+	}
+
+	return n, err
+}
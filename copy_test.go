@@ -0,0 +1,119 @@
+package smb2
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// reorderRequests makes the handler of tr answer the requests of cmd in batches of n in reverse order,
+// so that a copy only completes if n requests are outstanding at a time.
+func reorderRequests(tr *fakeTransport, cmd uint16, n int) {
+	handle := tr.handler
+
+	var batch [][]byte
+
+	tr.handler = func(pkt []byte) {
+		if PacketCodec(pkt).Command() != cmd {
+			handle(pkt)
+			return
+		}
+
+		batch = append(batch, pkt)
+		if len(batch) < n {
+			return
+		}
+		for i := len(batch) - 1; i >= 0; i-- {
+			handle(batch[i])
+		}
+		batch = nil
+	}
+}
+
+func TestWriteToPipelined(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 8*256)
+
+	f, srv, tr := newFakeFile(data, 4096)
+	defer tr.Close()
+
+	if err := openCreditWindow(f.fs, tr, 8); err != nil {
+		t.Fatal(err)
+	}
+
+	// 8 chunks and 4 reads ahead past the end of file, which fail with STATUS_END_OF_FILE
+	reorderRequests(tr, SMB2_READ, 4)
+
+	var buf bytes.Buffer
+
+	n, err := f.WriteToWithOptions(&buf, &CopyOptions{Depth: 4, ChunkSize: 4096})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("unexpected content of %d bytes", n)
+	}
+	if f.offset != int64(len(data)) {
+		t.Errorf("expected offset %d, got %d", len(data), f.offset)
+	}
+	if srv.reads != 12 {
+		t.Errorf("expected 12 reads, got %d", srv.reads)
+	}
+}
+
+func TestReadFromPipelined(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 12*256)
+
+	f, srv, tr := newFakeFile(nil, 4096)
+	defer tr.Close()
+
+	if err := openCreditWindow(f.fs, tr, 8); err != nil {
+		t.Fatal(err)
+	}
+
+	reorderRequests(tr, SMB2_WRITE, 4)
+
+	n, err := f.ReadFromWithOptions(bytes.NewReader(data), &CopyOptions{Depth: 4, ChunkSize: 4096})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(data)) || !bytes.Equal(srv.data, data) {
+		t.Errorf("unexpected content of %d bytes", n)
+	}
+	if f.offset != int64(len(data)) {
+		t.Errorf("expected offset %d, got %d", len(data), f.offset)
+	}
+	if srv.reads != 12 {
+		t.Errorf("expected 12 writes, got %d", srv.reads)
+	}
+}
+
+func TestCopyParams(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	f := &File{fs: newFakeShare(tr)}
+
+	if err := openCreditWindow(f.fs, tr, 64); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		opts    *CopyOptions
+		maxSize int
+		size    int
+		depth   int
+	}{
+		{nil, 64 * 1024, 64 * 1024, 16},
+		{nil, 1024 * 1024, 1024 * 1024, 4}, // 64 credits of 16 credits each
+		{&CopyOptions{ChunkSize: 1024 * 1024}, 64 * 1024, 64 * 1024, 16},
+		{&CopyOptions{ChunkSize: 4096, Depth: 32}, 64 * 1024, 4096, 32},
+	}
+
+	for i, tt := range tests {
+		size, depth := f.copyParams(tt.opts, tt.maxSize)
+		if size != tt.size || depth != tt.depth {
+			t.Errorf("%d: expected %d, %d, got %d, %d", i, tt.size, tt.depth, size, depth)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package smb2
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// Access masks for CreateFileRequest.DesiredAccess. (See [MS-SMB2] 2.2.13.1)
+const (
+	AccessReadData        = FILE_READ_DATA
+	AccessWriteData       = FILE_WRITE_DATA
+	AccessAppendData      = FILE_APPEND_DATA
+	AccessReadEA          = FILE_READ_EA
+	AccessWriteEA         = FILE_WRITE_EA
+	AccessExecute         = FILE_EXECUTE
+	AccessDeleteChild     = FILE_DELETE_CHILD
+	AccessReadAttributes  = FILE_READ_ATTRIBUTES
+	AccessWriteAttributes = FILE_WRITE_ATTRIBUTES
+	AccessDelete          = DELETE
+	AccessReadControl     = READ_CONTROL
+	AccessWriteDAC        = WRITE_DAC
+	AccessWriteOwner      = WRITE_OWNER
+	AccessSynchronize     = SYNCHRONIZE
+	AccessSystemSecurity  = ACCESS_SYSTEM_SECURITY
+	AccessMaximumAllowed  = MAXIMUM_ALLOWED
+	AccessGenericAll      = GENERIC_ALL
+	AccessGenericExecute  = GENERIC_EXECUTE
+	AccessGenericWrite    = GENERIC_WRITE
+	AccessGenericRead     = GENERIC_READ
+)
+
+// Share access for CreateFileRequest.ShareAccess.
+const (
+	FileShareRead   = FILE_SHARE_READ
+	FileShareWrite  = FILE_SHARE_WRITE
+	FileShareDelete = FILE_SHARE_DELETE
+)
+
+// Create dispositions for CreateFileRequest.CreateDisposition.
+const (
+	FileSupersede   = FILE_SUPERSEDE
+	FileOpen        = FILE_OPEN
+	FileCreate      = FILE_CREATE
+	FileOpenIf      = FILE_OPEN_IF
+	FileOverwrite   = FILE_OVERWRITE
+	FileOverwriteIf = FILE_OVERWRITE_IF
+)
+
+// Create options for CreateFileRequest.CreateOptions.
+const (
+	FileDirectoryFile           = FILE_DIRECTORY_FILE
+	FileWriteThrough            = FILE_WRITE_THROUGH
+	FileSequentialOnly          = FILE_SEQUENTIAL_ONLY
+	FileNoIntermediateBuffering = FILE_NO_INTERMEDIATE_BUFFERING
+	FileSynchronousIoAlert      = FILE_SYNCHRONOUS_IO_ALERT
+	FileSynchronousIoNonalert   = FILE_SYNCHRONOUS_IO_NONALERT
+	FileNonDirectoryFile        = FILE_NON_DIRECTORY_FILE
+	FileCompleteIfOplocked      = FILE_COMPLETE_IF_OPLOCKED
+	FileNoEaKnowledge           = FILE_NO_EA_KNOWLEDGE
+	FileRandomAccess            = FILE_RANDOM_ACCESS
+	FileDeleteOnClose           = FILE_DELETE_ON_CLOSE
+	FileOpenByFileId            = FILE_OPEN_BY_FILE_ID
+	FileOpenForBackupIntent     = FILE_OPEN_FOR_BACKUP_INTENT
+	FileNoCompression           = FILE_NO_COMPRESSION
+	FileOpenRemoteInstance      = FILE_OPEN_REMOTE_INSTANCE
+	FileOpenRequiringOplock     = FILE_OPEN_REQUIRING_OPLOCK
+	FileDisallowExclusive       = FILE_DISALLOW_EXCLUSIVE
+	FileReserveOpfilter         = FILE_RESERVE_OPFILTER
+	FileOpenReparsePoint        = FILE_OPEN_REPARSE_POINT
+	FileOpenNoRecall            = FILE_OPEN_NO_RECALL
+	FileOpenForFreeSpaceQuery   = FILE_OPEN_FOR_FREE_SPACE_QUERY
+)
+
+// Impersonation levels for OpenOptions.ImpersonationLevel. (See [MS-SMB2] 2.2.13)
+const (
+	ImpersonationLevelAnonymous      = Anonymous
+	ImpersonationLevelIdentification = Identification
+	ImpersonationLevelImpersonation  = Impersonation
+	ImpersonationLevelDelegate       = Delegate
+)
+
+// OpenOptions contains optional parameters for func (*Share) OpenFileWithOptions.
+type OpenOptions struct {
+	// BackupIntent sets FILE_OPEN_FOR_BACKUP_INTENT.
+	// If the account holds SeBackupPrivilege or SeRestorePrivilege on the server,
+	// ACL checks are bypassed and directories can be opened like regular files.
+	BackupIntent bool
+
+	// QueryMaximalAccess attaches a SMB2_CREATE_QUERY_MAXIMAL_ACCESS_REQUEST context,
+	// so that the access granted to the user is returned by func (*File) MaximalAccess.
+	// It's a cheap way to check what can be done with a file without reading its ACL.
+	QueryMaximalAccess bool
+
+	// ImpersonationLevel points to the impersonation level of the CREATE request (e.g. ImpersonationLevelDelegate),
+	// which some servers check for delegated access. If it's nil, ImpersonationLevelImpersonation is used.
+	ImpersonationLevel *uint32
+
+	// SequentialScan sets FILE_SEQUENTIAL_ONLY, and RandomAccess sets FILE_RANDOM_ACCESS,
+	// hinting the server at the access pattern, e.g. so that it reads ahead for streaming reads of large files.
+	// They're exclusive; if both are set, SequentialScan takes precedence.
+	SequentialScan bool
+	RandomAccess   bool
+
+	// WriteThrough sets FILE_WRITE_THROUGH, so that the server completes writes only once they're on stable storage,
+	// bypassing its write cache.
+	WriteThrough bool
+
+	// NoBuffering sets FILE_NO_INTERMEDIATE_BUFFERING, so that the server doesn't cache the data of the file.
+	// The reads and writes must then be aligned: their offsets and lengths must be multiples of the sector size
+	// of the volume, which is queried on open. Misaligned ones fail with ErrMisaligned before being sent.
+	NoBuffering bool
+
+	// OplockLevel requests an oplock of the level (e.g. OplockLevelBatch), so that the server lets the client
+	// cache the file until another client opens it. The granted level is returned by func (*File) OplockLevel.
+	// The breaks of the oplock are acknowledged by the session, see Dialer.OnOplockBreak.
+	// Leases aren't supported. If it's zero, no oplock is requested.
+	OplockLevel uint8
+}
+
+func (opts *OpenOptions) createOptions() uint32 {
+	var options uint32
+	if opts.BackupIntent {
+		options |= FILE_OPEN_FOR_BACKUP_INTENT
+	}
+	if opts.WriteThrough {
+		options |= FILE_WRITE_THROUGH
+	}
+	if opts.NoBuffering {
+		options |= FILE_NO_INTERMEDIATE_BUFFERING
+	}
+	switch {
+	case opts.SequentialScan:
+		options |= FILE_SEQUENTIAL_ONLY
+	case opts.RandomAccess:
+		options |= FILE_RANDOM_ACCESS
+	}
+	return options
+}
+
+func (opts *OpenOptions) impersonationLevel() uint32 {
+	if opts.ImpersonationLevel == nil {
+		return Impersonation
+	}
+	return *opts.ImpersonationLevel
+}
+
+func (opts *OpenOptions) createContexts() []Encoder {
+	var contexts []Encoder
+	if opts.QueryMaximalAccess {
+		contexts = append(contexts, &CreateContextRequest{Name: []byte(createContextMaximalAccess)})
+	}
+	return contexts
+}
+
+// createContextMaximalAccess is the name of the SMB2_CREATE_QUERY_MAXIMAL_ACCESS_REQUEST and response contexts.
+const createContextMaximalAccess = "MxAc"
+
+// CreateFileRequest contains the raw parameters of a SMB2 CREATE request.
+// It is used by func (*Share) CreateFile. (See [MS-SMB2] 2.2.13)
+type CreateFileRequest struct {
+	DesiredAccess     uint32
+	FileAttributes    uint32
+	ShareAccess       uint32
+	CreateDisposition uint32
+	CreateOptions     uint32
+	Contexts          []CreateContext
+}
+
+// CreateContext represents a SMB2_CREATE_CONTEXT sent with a CREATE request.
+// Name is the context tag (e.g. "MxAc", "QFid") and Data is its raw payload.
+type CreateContext struct {
+	Name string
+	Data []byte
+}
+
+// CreateFile opens or creates a file with the raw parameters of req.
+// It's an escape hatch for cases which func (*Share) OpenFile can't express.
+// Symbolic links are followed unless req.CreateOptions contains FileOpenReparsePoint.
+func (fs *Share) CreateFile(name string, req CreateFileRequest) (*File, error) {
+	name, err := cleanPath("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []Encoder
+	for _, ctx := range req.Contexts {
+		contexts = append(contexts, &CreateContextRequest{
+			Name: []byte(ctx.Name),
+			Data: ctx.Data,
+		})
+	}
+
+	create := &CreateRequest{
+		SecurityFlags:        0,
+		RequestedOplockLevel: SMB2_OPLOCK_LEVEL_NONE,
+		ImpersonationLevel:   Impersonation,
+		SmbCreateFlags:       0,
+		DesiredAccess:        req.DesiredAccess,
+		FileAttributes:       req.FileAttributes,
+		ShareAccess:          req.ShareAccess,
+		CreateDisposition:    req.CreateDisposition,
+		CreateOptions:        req.CreateOptions,
+		Contexts:             contexts,
+	}
+
+	f, err := fs.createFile(name, create, req.CreateOptions&FILE_OPEN_REPARSE_POINT == 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	return f, nil
+}
+
+// CreateContexts returns the create contexts of the CREATE response which opened the file,
+// keyed by their names (e.g. "MxAc", "QFid"). The values are the raw payloads.
+// It's empty unless contexts were sent by func (*Share) CreateFile, or by func (*Share) OpenFileWithOptions
+// with OpenOptions.QueryMaximalAccess.
+func (f *File) CreateContexts() map[string][]byte {
+	return f.contexts
+}
+
+// MaximalAccess returns the access mask (e.g. AccessReadData|AccessWriteData) granted to the user on the file.
+// The server only returns it if the file was opened with OpenOptions.QueryMaximalAccess,
+// or created with a "MxAc" (SMB2_CREATE_QUERY_MAXIMAL_ACCESS_REQUEST) context. Otherwise, or if the server
+// failed to compute it, it's 0. (See [MS-SMB2] 2.2.14.2.5)
+func (f *File) MaximalAccess() uint32 {
+	data := f.contexts[createContextMaximalAccess]
+	if len(data) < 8 {
+		return 0
+	}
+	if NtStatus(binary.LittleEndian.Uint32(data[:4])) != STATUS_SUCCESS {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(data[4:8])
+}
+
+// parseCreateContexts returns the payloads of the chained create contexts bs, keyed by their names.
+func parseCreateContexts(bs []byte) (map[string][]byte, error) {
+	if len(bs) == 0 {
+		return nil, nil
+	}
+
+	contexts := make(map[string][]byte)
+
+	for {
+		ctx := CreateContextDecoder(bs)
+		if ctx.IsInvalid() {
+			return nil, &InvalidResponseError{"broken create context format"}
+		}
+
+		contexts[string(ctx.Name())] = ctx.Data()
+
+		next := ctx.Next()
+		if next == 0 {
+			return contexts, nil
+		}
+		if next < 16 || uint64(next) >= uint64(len(bs)) {
+			return nil, &InvalidResponseError{"broken create context format"}
+		}
+
+		bs = bs[next:]
+	}
+}
+
+// OpenFileID opens a file by its 64-bit file ID (e.g. the NTFS file reference number) instead of its path.
+// It uses FILE_OPEN_BY_FILE_ID, so the file can be reached even if it was renamed or moved.
+// If the server or the underlying file system doesn't support opening by file ID, ErrNotSupported is returned.
+func (fs *Share) OpenFileID(fileID uint64) (*File, error) {
+	name := fmt.Sprintf("fileid:%#x", fileID)
+
+	id := make([]byte, 8)
+	binary.LittleEndian.PutUint64(id, fileID)
+
+	req := &CreateRequest{
+		SecurityFlags:        0,
+		RequestedOplockLevel: SMB2_OPLOCK_LEVEL_NONE,
+		ImpersonationLevel:   Impersonation,
+		SmbCreateFlags:       0,
+		DesiredAccess:        GENERIC_READ,
+		FileAttributes:       FILE_ATTRIBUTE_NORMAL,
+		ShareAccess:          FILE_SHARE_READ | FILE_SHARE_WRITE | FILE_SHARE_DELETE,
+		CreateDisposition:    FILE_OPEN,
+		CreateOptions:        FILE_OPEN_BY_FILE_ID | FILE_SYNCHRONOUS_IO_NONALERT,
+		NameBytes:            id,
+	}
+
+	f, err := fs.createFile(name, req, false)
+	if err != nil {
+		if rerr, ok := err.(*ResponseError); ok {
+			switch NtStatus(rerr.Code) {
+			case STATUS_INVALID_PARAMETER, STATUS_NOT_SUPPORTED:
+				err = ErrNotSupported
+			}
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	return f, nil
+}
@@ -3,12 +3,37 @@ package smb2
 import (
 	"context"
 	"sync"
+	"time"
 )
 
+// creditWatchdogInterval is how often a request waiting for credits checks whether credits can still be granted.
+const creditWatchdogInterval = 100 * time.Millisecond
+
+// creditPayloadSize is the payload size covered by a credit of a multi-credit request.
+const creditPayloadSize = 64 * 1024
+
+// creditCharge returns the credits charged by a multi-credit request: one per started 64 KiB of payloadSize,
+// which is the larger of the payload sizes of the request and of its expected response, and at least one. (See [MS-SMB2] 3.1.5.2)
+// E.g. a READ of 64 KiB charges 1 credit, and a READ of 64 KiB + 1 byte charges 2.
+func creditCharge(payloadSize int) uint16 {
+	if payloadSize <= 0 {
+		return 1
+	}
+	return uint16((payloadSize-1)/creditPayloadSize + 1)
+}
+
 type account struct {
 	m        sync.Mutex
 	balance  chan struct{}
 	_opening uint16
+	_pending int // credits requested by the requests waiting for the responses
+	_charged int // credits charged by the requests waiting for the responses
+	_loaned  int // credits loaned to the requests which aren't sent yet
+
+	closed chan struct{} // closed by close when the connection is gone
+	err    error         // set before closed is closed
+
+	onWait func(time.Duration) // called after a request waited for credits, see Dialer.OnCreditWait
 }
 
 func openAccount(maxCreditBalance uint16) *account {
@@ -18,6 +43,7 @@ func openAccount(maxCreditBalance uint16) *account {
 
 	return &account{
 		balance: balance,
+		closed:  make(chan struct{}),
 	}
 }
 
@@ -28,30 +54,195 @@ func (a *account) initRequest() uint16 {
 func (a *account) loan(creditCharge uint16, ctx context.Context) (uint16, bool, error) {
 	select {
 	case <-a.balance:
-	case <-ctx.Done():
-		return 0, false, &ContextError{Err: ctx.Err()}
+	default:
+		if err := a.wait(ctx); err != nil {
+			return 0, false, err
+		}
 	}
 
-	for i := uint16(1); i < creditCharge; i++ {
+	n := uint16(1)
+	for ; n < creditCharge; n++ {
 		select {
 		case <-a.balance:
 		default:
-			return i, false, nil
+			a.lend(n)
+			return n, false, nil
 		}
 	}
 
-	return creditCharge, true, nil
+	a.lend(n)
+
+	return n, n == creditCharge, nil
 }
 
-func (a *account) opening() uint16 {
+// lend counts the credits loaned to a request until it's sent or gives them back by refund.
+func (a *account) lend(n uint16) {
 	a.m.Lock()
+	a._loaned += int(n)
+	a.m.Unlock()
+}
+
+// refund gives back the credits loaned to a request which isn't sent.
+// The credits of a request which is sent are settled by its response instead.
+func (a *account) refund(creditCharge uint16) {
+	// the balance is refilled before the credits stop being loaned,
+	// so that a waiting request never sees the account idle without them
+	a.deposit(creditCharge)
+
+	a.m.Lock()
+	a.repay(creditCharge)
+	a.m.Unlock()
+}
+
+func (a *account) repay(creditCharge uint16) {
+	a._loaned -= int(creditCharge)
+	if a._loaned < 0 {
+		a._loaned = 0
+	}
+}
+
+// wait blocks until a credit is available.
+// Credits are only granted by responses, or given back by the requests holding loaned credits,
+// so it fails with ErrNoCredits instead of blocking forever if there are neither.
+func (a *account) wait(ctx context.Context) error {
+	start := time.Now()
+
+	err := a.waitCredit(ctx)
+	if err == nil {
+		waited := time.Since(start)
+
+		logger.Println("waited for credits:", waited)
+
+		if a.onWait != nil {
+			a.onWait(waited)
+		}
+	}
+	return err
+}
+
+func (a *account) waitCredit(ctx context.Context) error {
+	t := time.NewTicker(creditWatchdogInterval)
+	defer t.Stop()
 
-	ret := a._opening
+	for {
+		select {
+		case <-a.balance:
+			return nil
+		case <-ctx.Done():
+			return &ContextError{Err: ctx.Err()}
+		case <-a.closed:
+			return a.err
+		case <-t.C:
+			if a.idle() {
+				select {
+				case <-a.balance:
+					return nil
+				default:
+					return ErrNoCredits
+				}
+			}
+		}
+	}
+}
+
+// close makes the requests waiting for credits fail with err, since no response will grant them anymore.
+// It's called once by the receiver of the connection.
+func (a *account) close(err error) {
+	a.err = err
+	close(a.closed)
+}
+
+// request returns the number of credits a request charging creditCharge credits asks for.
+// It's at least min and creditCharge, so the response always gives back the charged credits.
+// The credits which the server didn't grant before are asked again,
+// and the window grows by up to creditCharge credits until it reaches the max balance.
+// The charged credits are no longer loaned, since the request is being sent.
+func (a *account) request(creditCharge, min uint16) uint16 {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	n := creditCharge
+	if n < min {
+		n = min
+	}
+	if n == 0 {
+		n = 1
+	}
+
+	n += a._opening
 	a._opening = 0
 
+	if room := cap(a.balance) - len(a.balance) - a._pending - int(n); room > 0 {
+		grow := int(creditCharge)
+		if grow == 0 {
+			grow = 1
+		}
+		if grow > room {
+			grow = room
+		}
+		n += uint16(grow)
+	}
+
+	a._pending += int(n)
+	a._charged += int(creditCharge)
+
+	a.repay(creditCharge)
+
+	return n
+}
+
+// consume drops the credits charged by a request which the server has answered or which is abandoned.
+func (a *account) consume(creditCharge uint16) {
+	a.m.Lock()
+	a._charged -= int(creditCharge)
+	if a._charged < 0 {
+		a._charged = 0
+	}
 	a.m.Unlock()
+}
+
+// credits returns the credits granted by the server, which are the available credits
+// and the credits charged by the requests waiting for the responses, and the available credits.
+func (a *account) credits() (granted, available int) {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	available = len(a.balance)
 
-	return ret
+	return available + a._charged, available
+}
+
+// forget drops the credits charged and requested by a request whose response won't be charged.
+func (a *account) forget(creditCharge, requested uint16) {
+	a.m.Lock()
+	a.settle(requested)
+	a._charged -= int(creditCharge)
+	if a._charged < 0 {
+		a._charged = 0
+	}
+	a.m.Unlock()
+}
+
+// withdraw undoes request for a request which isn't sent after all, giving back its charged credits.
+func (a *account) withdraw(creditCharge, requested uint16) {
+	a.deposit(creditCharge)
+	a.forget(creditCharge, requested)
+}
+
+func (a *account) settle(requested uint16) {
+	a._pending -= int(requested)
+	if a._pending < 0 {
+		a._pending = 0
+	}
+}
+
+// idle reports whether no request is waiting for a response and no credit is loaned,
+// in which case no credit can be granted or given back anymore.
+func (a *account) idle() bool {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	return a._pending == 0 && a._loaned == 0
 }
 
 func (a *account) charge(granted, requested uint16) {
@@ -59,15 +250,24 @@ func (a *account) charge(granted, requested uint16) {
 		return
 	}
 
+	// the balance is refilled before the requested credits are settled,
+	// so that a waiting request never sees the account idle without them
+	a.deposit(granted)
+
 	a.m.Lock()
 
 	if granted < requested {
 		a._opening += requested - granted
 	}
 
+	a.settle(requested)
+
 	a.m.Unlock()
+}
 
-	for i := uint16(0); i < granted; i++ {
+// deposit adds n credits to the balance, up to the max balance.
+func (a *account) deposit(n uint16) {
+	for i := uint16(0); i < n; i++ {
 		select {
 		case a.balance <- struct{}{}:
 		default:
@@ -0,0 +1,392 @@
+package smb2
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+func sendTestLogoff(conn *conn, ctx context.Context) error {
+	req := new(LogoffRequest)
+
+	creditCharge, _, err := conn.loanCredit(0, ctx)
+	if err != nil {
+		return err
+	}
+
+	req.CreditCharge = creditCharge
+
+	_, err = conn.sendRecv(SMB2_LOGOFF, req, ctx)
+
+	return err
+}
+
+func TestCreditsNotGranted(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	// the server never grants credits
+	tr.handler = func(req []byte) {
+		tr.push(newFakeResponse(req, 0))
+	}
+
+	conn := newFakeConn(tr, clientMaxCreditBalance)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := sendTestLogoff(conn, ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// used to block forever
+	if err := sendTestLogoff(conn, ctx); err != ErrNoCredits {
+		t.Errorf("expected %v, got %v", ErrNoCredits, err)
+	}
+}
+
+func TestCreditsOfAbandonedRequest(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	reqs := make(chan []byte, 1)
+
+	// the server responds only after the first request has been abandoned
+	tr.handler = func(req []byte) {
+		if PacketCodec(req).MessageId() == 1 {
+			reqs <- req
+			return
+		}
+		tr.push(newFakeResponse(req, PacketCodec(req).CreditRequest()))
+	}
+
+	conn := newFakeConn(tr, clientMaxCreditBalance)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		req := <-reqs
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+		tr.push(newFakeResponse(req, PacketCodec(req).CreditRequest()))
+	}()
+
+	if err := sendTestLogoff(conn, ctx); err == nil {
+		t.Fatal("expected context error")
+	}
+
+	ctx, cancel2 := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel2()
+
+	// used to block forever because the credits granted to the abandoned request were lost
+	if err := sendTestLogoff(conn, ctx); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCreditsOfBadSignature(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	conn := newSignedTestConn(tr)
+
+	// the server grants the requested credits by responses whose signatures are broken
+	tr.handler = func(req []byte) {
+		pkt := newFakeResponse(req, PacketCodec(req).CreditRequest())
+		conn.session.sign(pkt)
+		pkt[len(pkt)-1] ^= 0xff
+		tr.push(pkt)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := sendTestLogoff(conn, ctx); err != ErrSignatureMismatch {
+		t.Fatalf("expected %v, got %v", ErrSignatureMismatch, err)
+	}
+
+	// the credits requested by the request aren't pending anymore, so the watchdog can tell that none will be granted
+	if !conn.account.idle() {
+		t.Error("expected an idle account")
+	}
+
+	if err := sendTestLogoff(conn, ctx); err != ErrNoCredits {
+		t.Errorf("expected %v, got %v", ErrNoCredits, err)
+	}
+}
+
+func TestCreditsConcurrent(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	var m sync.Mutex
+	var maxRequested uint16
+
+	tr.handler = func(req []byte) {
+		requested := PacketCodec(req).CreditRequest()
+
+		m.Lock()
+		if requested > maxRequested {
+			maxRequested = requested
+		}
+		m.Unlock()
+
+		// grant one credit less than requested, but at least the charged credits
+		granted := requested - 1
+		if granted < PacketCodec(req).CreditCharge() {
+			granted = PacketCodec(req).CreditCharge()
+		}
+
+		go tr.push(newFakeResponse(req, granted))
+	}
+
+	conn := newFakeConn(tr, 16)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 32)
+
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if err := sendTestLogoff(conn, ctx); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if maxRequested <= 1 {
+		t.Error("credit window doesn't grow")
+	}
+}
+
+func TestCreditsLoanedAcrossLimiterWait(t *testing.T) {
+	f, _, tr := newFakeFile(make([]byte, 4096), 64*1024)
+	defer tr.Close()
+
+	// the first read holds the only credit while the limiter delays it by 300ms
+	f.fs.readLimiter = newLimiter(1000)
+
+	errs := make(chan error, 2)
+
+	go func() {
+		_, err := f.ReadAt(make([]byte, 1300), 0)
+		errs <- err
+	}()
+
+	for {
+		if _, available := f.fs.conn.account.credits(); available == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// used to fail with ErrNoCredits by the watchdog, since no request was waiting for a response
+	go func() {
+		_, err := f.ReadAt(make([]byte, 10), 0)
+		errs <- err
+	}()
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCreditsWait(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	reqs := make(chan []byte, 1)
+
+	// the server holds the first request until the second one waits for credits
+	tr.handler = func(req []byte) {
+		if PacketCodec(req).MessageId() == 1 {
+			reqs <- req
+			return
+		}
+		tr.push(newFakeResponse(req, 1))
+	}
+
+	conn := newFakeConn(tr, clientMaxCreditBalance)
+
+	waits := make(chan time.Duration, 2)
+	conn.account.onWait = func(waited time.Duration) {
+		waits <- waited
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errs := make(chan error, 2)
+
+	go func() {
+		errs <- sendTestLogoff(conn, ctx)
+	}()
+
+	req := <-reqs
+
+	if granted, available := conn.account.credits(); granted != 1 || available != 0 {
+		t.Errorf("expected 1 granted and 0 available credits, got %d and %d", granted, available)
+	}
+
+	go func() {
+		errs <- sendTestLogoff(conn, ctx)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	tr.push(newFakeResponse(req, 2))
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case waited := <-waits:
+		if waited < 25*time.Millisecond {
+			t.Errorf("expected a wait of at least 25ms, got %v", waited)
+		}
+	default:
+		t.Error("the wait for credits isn't reported")
+	}
+	if len(waits) != 0 {
+		t.Error("the first request is reported to wait")
+	}
+
+	if granted, available := conn.account.credits(); granted != 2 || available != 2 {
+		t.Errorf("expected 2 granted and available credits, got %d and %d", granted, available)
+	}
+}
+
+func TestCreditCharge(t *testing.T) {
+	for size, expected := range map[int]uint16{
+		0:           1,
+		1:           1,
+		65535:       1,
+		65536:       1,
+		65537:       2,
+		131072:      2,
+		131073:      3,
+		1024 * 1024: 16,
+	} {
+		if charge := creditCharge(size); charge != expected {
+			t.Errorf("%d: expected %d, got %d", size, expected, charge)
+		}
+	}
+}
+
+func TestCreditsOfErrorResponses(t *testing.T) {
+	f, srv, tr := newFakeFile(nil, 4096)
+	defer tr.Close()
+
+	if err := openCreditWindow(f.fs, tr, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	// the responses grant the credits they charged, whether they succeed or not
+	srv.fail = 2
+	srv.failStatus = STATUS_BUFFER_OVERFLOW
+
+	for i := 0; i < 3; i++ {
+		f.readAtChunk(make([]byte, 16), 0, false)
+	}
+
+	// a request which isn't sent gives back its loaned credit
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := &FlushRequest{FileId: f.fd}
+	req.CreditCharge, _, _ = f.fs.loanCredit(0)
+	if _, err := f.fs.WithContext(ctx).sendRecv(SMB2_FLUSH, req); err == nil {
+		t.Error("expected the flush to fail")
+	}
+
+	// used to grant the credits of the error responses twice
+	if granted, available := f.fs.conn.account.credits(); granted != 4 || available != 4 {
+		t.Errorf("expected 4 credits granted and available, got %d, %d", granted, available)
+	}
+	if !f.fs.conn.account.idle() {
+		t.Error("expected no request waiting for a response or holding loaned credits")
+	}
+}
+
+func TestCreditChargeOfRequests(t *testing.T) {
+	f, srv, tr := newFakeFile(nil, 0)
+	defer tr.Close()
+
+	// the requests fail, only their credit charges matter
+	srv.fail = 1 << 30
+	srv.failStatus = STATUS_NOT_SUPPORTED
+
+	f.fs.conn.maxReadSize = 256 * 1024
+	f.fs.conn.maxWriteSize = 256 * 1024
+	f.fs.conn.account.charge(63, 0)
+
+	var charges []uint16
+
+	handle := tr.handler
+	tr.handler = func(pkt []byte) {
+		charges = append(charges, PacketCodec(pkt).CreditCharge())
+		handle(pkt)
+	}
+
+	requests := map[string]func(size int){
+		"read": func(size int) {
+			f.readAtChunk(make([]byte, size), 0, false)
+		},
+		"write": func(size int) {
+			f.writeAtChunk(make([]byte, size), 0)
+		},
+		"ioctl": func(size int) {
+			f.fsctlOnce(FSCTL_PIPE_PEEK, nil, size)
+		},
+		"query directory": func(size int) {
+			f.fs.conn.maxTransactSize = uint32(size)
+			f.readdirClass(f.fs, FileDirectoryInformation, 0)
+		},
+		"query info": func(size int) {
+			f.queryInfoOnce(&QueryInfoRequest{InfoType: SMB2_0_INFO_FILE, FileInfoClass: FileAllInformation, OutputBufferLength: uint32(size)})
+		},
+	}
+
+	for name, request := range requests {
+		for size, expected := range map[int]uint16{1: 1, 65536: 1, 65537: 2, 131072: 2, 131073: 3} {
+			f.fs.conn.maxTransactSize = 256 * 1024
+
+			charges = nil
+			request(size)
+			if len(charges) != 1 || charges[0] != expected {
+				t.Errorf("%s of %d bytes: expected a credit charge of %d, got %v", name, size, expected, charges)
+			}
+		}
+	}
+
+	// without multi-credit requests, every request charges a credit
+	f.fs.conn.capabilities &^= SMB2_GLOBAL_CAP_LARGE_MTU
+
+	s := &Session{s: f.fs.session}
+	if charge := s.CreditCharge(131073); charge != 1 {
+		t.Errorf("expected a credit charge of 1 without large MTU, got %d", charge)
+	}
+}
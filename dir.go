@@ -0,0 +1,139 @@
+package smb2
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// Dir is a directory opened by func (*Share) OpenDir, whose children are opened by their names.
+//
+// Unlike SMB1, SMB2 has no relative opens (a CREATE request has no root directory handle),
+// so the names are joined to the path of the directory and resolved from the root of the share by the server.
+// The directory is kept open without FILE_SHARE_DELETE though, so that it can't be renamed or removed
+// while it's open, and the names keep referring to its children.
+type Dir struct {
+	fs *Share
+	f  *File
+}
+
+// OpenDir opens the named directory, which must exist.
+func (fs *Share) OpenDir(name string) (*Dir, error) {
+	var f *File
+	err := fs.retry(true, func() (err error) {
+		f, err = fs.openDir(name)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Dir{fs: fs, f: f}, nil
+}
+
+func (fs *Share) openDir(name string) (*File, error) {
+	name, err := cleanPath("opendir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fs.checkDiskShare("opendir", name); err != nil {
+		return nil, err
+	}
+
+	create := &CreateRequest{
+		SecurityFlags:        0,
+		RequestedOplockLevel: SMB2_OPLOCK_LEVEL_NONE,
+		ImpersonationLevel:   Impersonation,
+		SmbCreateFlags:       0,
+		DesiredAccess:        FILE_LIST_DIRECTORY | FILE_READ_ATTRIBUTES | SYNCHRONIZE,
+		FileAttributes:       FILE_ATTRIBUTE_NORMAL,
+		ShareAccess:          FILE_SHARE_READ | FILE_SHARE_WRITE,
+		CreateDisposition:    FILE_OPEN,
+		CreateOptions:        FILE_DIRECTORY_FILE,
+	}
+
+	f, err := fs.createFile(name, create, true)
+	if err != nil {
+		return nil, &os.PathError{Op: "opendir", Path: name, Err: err}
+	}
+	return f, nil
+}
+
+// Name returns the name of the directory as presented to func (*Share) OpenDir,
+// or the target if it was opened through symbolic links.
+func (d *Dir) Name() string {
+	return d.f.name
+}
+
+// Open opens the named child of the directory for reading like func (*Share) Open.
+func (d *Dir) Open(name string) (*File, error) {
+	return d.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// OpenFile opens the named child of the directory like func (*Share) OpenFile.
+func (d *Dir) OpenFile(name string, flag int, perm os.FileMode) (*File, error) {
+	path, err := d.join("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return d.fs.OpenFile(path, flag, perm)
+}
+
+// Stat returns the FileInfo of the named child of the directory like func (*Share) Stat.
+func (d *Dir) Stat(name string) (os.FileInfo, error) {
+	path, err := d.join("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return d.fs.Stat(path)
+}
+
+// ReadDir reads the entries of the directory sorted by name like func (*Share) ReadDir.
+// The entries are enumerated on the open directory from the beginning each time,
+// so the directory isn't looked up by its path again.
+func (d *Dir) ReadDir() ([]os.FileInfo, error) {
+	d.f.m.Lock()
+	defer d.f.m.Unlock()
+
+	fis, err := d.f.readdirAll(d.f.fs, RESTART_SCANS)
+	if err != nil {
+		return nil, &os.PathError{Op: "readdir", Path: d.f.name, Err: err}
+	}
+
+	sort.Slice(fis, func(i, j int) bool { return fis[i].Name() < fis[j].Name() })
+
+	return fis, nil
+}
+
+// Close closes the directory. The files opened by it stay open.
+func (d *Dir) Close() error {
+	return d.f.Close()
+}
+
+// join returns the path of the named child relative to the root of the share.
+// The name must be relative, and must not contain "." or ".." which would leave the directory.
+func (d *Dir) join(op, name string) (string, error) {
+	name = normPath(name)
+
+	if err := validatePath(op, name, false); err != nil {
+		return "", err
+	}
+
+	if name == "" {
+		return d.f.name, nil
+	}
+
+	for _, elem := range strings.Split(name, `\`) {
+		if elem == "." || elem == ".." {
+			return "", &os.PathError{Op: op, Path: name, Err: errors.New("name must not contain '.' or '..' elements")}
+		}
+	}
+
+	if d.f.name == "" {
+		return name, nil
+	}
+	return d.f.name + `\` + name, nil
+}
@@ -0,0 +1,110 @@
+package smb2
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+func TestOpenDir(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`dir`:   {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\a`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+			`file`:  {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	if _, err := fs.OpenDir("file"); err == nil {
+		t.Error("a file is opened as a directory")
+	}
+
+	d, err := fs.OpenDir("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	srv.names = nil
+
+	f, err := d.Open("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if _, err := d.Open("b"); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+
+	for _, name := range []string{`..\file`, `x\..\..\file`, `.\..\file`, `\file`} {
+		if _, err := d.Open(name); err == nil || os.IsNotExist(err) {
+			t.Errorf("%s: expected an invalid name error, got %v", name, err)
+		}
+	}
+
+	if expected := []string{`dir\a`, `dir\b`}; !reflect.DeepEqual(srv.names, expected) {
+		t.Errorf("expected %q, got %q", expected, srv.names)
+	}
+}
+
+func TestDirReadDir(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`dir`:   {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\b`: {attrs: FILE_ATTRIBUTE_ARCHIVE, size: 2},
+			`dir\a`: {attrs: FILE_ATTRIBUTE_DIRECTORY},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	d, err := fs.OpenDir("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	srv.names = nil
+
+	// the entries are enumerated again on the same handle
+	for i := 0; i < 2; i++ {
+		fis, err := d.ReadDir()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fis) != 2 || fis[0].Name() != "a" || !fis[0].IsDir() || fis[1].Name() != "b" || fis[1].Size() != 2 {
+			t.Errorf("unexpected entries: %v", fis)
+		}
+	}
+
+	st, err := d.Stat("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Size() != 2 {
+		t.Errorf("expected size 2, got %d", st.Size())
+	}
+
+	if _, err := d.OpenFile(`..\b`, os.O_RDONLY, 0); err == nil {
+		t.Error("expected an invalid name error")
+	}
+
+	if expected := []string{`dir\b`}; !reflect.DeepEqual(srv.names, expected) {
+		t.Errorf("expected %q, got %q", expected, srv.names)
+	}
+}
@@ -0,0 +1,71 @@
+package smb2
+
+import (
+	"os"
+)
+
+// DirEntry is an entry of a directory read by func (*Share) ReadDirEntries or func (*File) ReadDir.
+// Its methods are the ones of fs.DirEntry, so it can be returned by the implementations of fs.ReadDirFS
+// (e.g. for fs.WalkDir) as it is.
+type DirEntry interface {
+	// Name returns the base name of the entry.
+	Name() string
+
+	// IsDir reports whether the entry is a directory.
+	IsDir() bool
+
+	// Type returns the type bits of the mode of the entry (os.ModeDir, os.ModeSymlink, etc.), like Info().Mode() & os.ModeType.
+	Type() os.FileMode
+
+	// Info returns the FileInfo of the entry, a *FileStat.
+	Info() (os.FileInfo, error)
+}
+
+// dirEntry is a DirEntry of a *FileStat filled from the enumeration of its directory,
+// so that neither Type nor Info takes a round trip.
+type dirEntry struct {
+	st os.FileInfo
+}
+
+func (e *dirEntry) Name() string {
+	return e.st.Name()
+}
+
+func (e *dirEntry) IsDir() bool {
+	return e.st.IsDir()
+}
+
+// Type maps the attributes and the reparse tag of the enumeration to the type bits, see func (*FileStat) Mode.
+func (e *dirEntry) Type() os.FileMode {
+	return e.st.Mode() & os.ModeType
+}
+
+func (e *dirEntry) Info() (os.FileInfo, error) {
+	return e.st, nil
+}
+
+func dirEntries(fis []os.FileInfo) []DirEntry {
+	entries := make([]DirEntry, len(fis))
+	for i, fi := range fis {
+		entries[i] = &dirEntry{st: fi}
+	}
+	return entries
+}
+
+// ReadDir reads the contents of the directory like func (*os.File) ReadDir,
+// i.e. like func (*File) Readdir, but the entries are DirEntry. They're in the order of the enumeration.
+func (f *File) ReadDir(n int) ([]DirEntry, error) {
+	fis, err := f.Readdir(n)
+	return dirEntries(fis), err
+}
+
+// ReadDirEntries reads the directory named by dirname and returns its entries sorted by name, like os.ReadDir.
+// The entries come from the enumeration like the ones of func (*Share) ReadDir, so that a walk
+// which only needs their types (e.g. fs.WalkDir) takes a single enumeration per directory.
+func (fs *Share) ReadDirEntries(dirname string) ([]DirEntry, error) {
+	fis, err := fs.ReadDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+	return dirEntries(fis), nil
+}
@@ -2,11 +2,93 @@ package smb2
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	. "github.com/nodauf/go-smb2/internal/erref"
 )
 
+// ErrNotSupported is returned when the server or the underlying file system doesn't support the requested operation.
+var ErrNotSupported = errors.New("operation not supported by the server")
+
+// ErrEncryptionRequired is returned when the server requires encryption on a session or a share,
+// but no cipher has been negotiated (e.g. the dialect is older than SMB 3.0 or the session is a guest session).
+var ErrEncryptionRequired = errors.New("server requires encryption, but no cipher was negotiated")
+
+// ErrSignatureMismatch is returned when a response which must be signed has a wrong signature or isn't signed at all.
+var ErrSignatureMismatch = errors.New("signature verification failed")
+
+// ErrNoCredits is returned when a request needs credits, but the server can't grant any more of them
+// because no request is waiting for a response.
+var ErrNoCredits = errors.New("no credits available")
+
+// ErrNegotiateMismatch is returned when the negotiation validated after the session setup differs from the one the client saw,
+// i.e. a man in the middle tampered with the negotiate request or response.
+var ErrNegotiateMismatch = errors.New("negotiate validation failed")
+
+// ErrStaleHandle is returned by the operations of a File whose handle isn't valid on the server anymore
+// (STATUS_FILE_CLOSED or STATUS_FILE_HANDLE_REVOKED), e.g. after a server restart. The file has to be reopened.
+var ErrStaleHandle = errors.New("stale file handle")
+
+// ErrPipeShare is returned by the file operations which can't be done on a named pipe share (e.g. IPC$),
+// such as creating, removing or listing files.
+var ErrPipeShare = errors.New("file operations aren't supported on a named pipe share")
+
+// ErrGuestSession is returned by the dial when Dialer.RejectGuest is set and the server granted a guest or anonymous session.
+var ErrGuestSession = errors.New("server granted a guest or anonymous session")
+
+// ErrTooManyOpenFiles is returned when a file is opened while Dialer.MaxOpenFiles files of the session are open.
+var ErrTooManyOpenFiles = errors.New("too many open files")
+
+// ErrAsyncTimeout is returned when the server doesn't send the final response of a request
+// it has answered with an interim response within Dialer.AsyncWaitTimeout. The request is canceled.
+var ErrAsyncTimeout = errors.New("timed out waiting for the final response of an async request")
+
+// ErrMisaligned is returned when a read or write of a file opened with OpenOptions.NoBuffering
+// isn't aligned to the sector size of the volume.
+var ErrMisaligned = errors.New("offset or length not aligned to the sector size")
+
+// ErrNameTooLong is returned when a path or one of its components is longer than MaxPathLength or MaxNameLength.
+var ErrNameTooLong = errors.New("file name too long")
+
+// ErrInvalidUTF16 is returned when a name received from the server isn't valid UTF-16 and UTF16Decoding is UTF16Strict.
+var ErrInvalidUTF16 = errors.New("malformed UTF-16 name")
+
+// ErrConnectionClosed is returned by the requests of a connection which the server has closed,
+// both the outstanding ones and the following ones, since the connection can't be used anymore.
+var ErrConnectionClosed = errors.New("connection closed by the server")
+
+// ErrSessionDeleted is returned by the requests of a session which the server has deleted, e.g. by an administrator,
+// both the outstanding ones and the following ones. It's also the reason of func (*Session) Err.
+var ErrSessionDeleted = errors.New("session deleted by the server")
+
+// ErrLoggedOff is the reason returned by func (*Session) Err once the session has been logged off by func (*Session) Logoff.
+var ErrLoggedOff = errors.New("session logged off")
+
+// ErrNotifyOverflow is returned by func (*File) ChangeNotify when more changes have occurred than fit in the response,
+// so the server has discarded them, and the directory must be enumerated again to find out what has changed.
+var ErrNotifyOverflow = errors.New("too many changes to notify")
+
+// ErrPrivilegeNotHeld is returned when an operation requires a privilege which the account doesn't hold
+// or hasn't enabled on the server, e.g. SeManageVolumePrivilege for func (*File) SetValidDataLength.
+var ErrPrivilegeNotHeld = errors.New("a required privilege is not held by the client")
+
+// BufferOverflowError is returned by func (*File) Fsctl when the output of a control doesn't fit in the max output size.
+// Required is the output size the server requires, or zero if it doesn't report it,
+// as with STATUS_BUFFER_OVERFLOW, which comes with a partial output instead.
+type BufferOverflowError struct {
+	Required int
+}
+
+func (err *BufferOverflowError) Error() string {
+	if err.Required > 0 {
+		return fmt.Sprintf("output exceeds the buffer, %d bytes required", err.Required)
+	}
+	return "output exceeds the buffer"
+}
+
 // TransportError represents a error come from net.Conn layer.
 type TransportError struct {
 	Err error
@@ -43,7 +125,90 @@ type ResponseError struct {
 }
 
 func (err *ResponseError) Error() string {
-	return fmt.Sprintf("response error: %v", NtStatus(err.Code))
+	return fmt.Sprintf("response error: %s", statusString(err.Code))
+}
+
+// statusString formats a NTSTATUS by its name and code followed by its description,
+// e.g. "STATUS_OBJECT_NAME_NOT_FOUND (0xC0000034): The object name is not found.", or by its code if it's unknown.
+func statusString(code uint32) string {
+	status := NtStatus(code)
+	if name := status.Name(); name != "" {
+		return fmt.Sprintf("%s (0x%08X): %s", name, code, status.Error())
+	}
+	return fmt.Sprintf("status 0x%08X", code)
+}
+
+// RPCError represents a fault or a failure status returned by a remote procedure call over a named pipe.
+// Status is either a NTSTATUS, a Win32 error code (NET_API_STATUS) or a DCE/RPC fault code.
+type RPCError struct {
+	Op     string
+	Status uint32
+}
+
+func (err *RPCError) Error() string {
+	return fmt.Sprintf("rpc error: %s: %s", err.Op, statusString(err.Status))
+}
+
+// InvalidSIDError represents a malformed string form of a SID.
+type InvalidSIDError struct {
+	SID string
+}
+
+func (err *InvalidSIDError) Error() string {
+	return fmt.Sprintf("invalid SID: %q", err.SID)
+}
+
+// NegotiateTimeoutError is returned when the server doesn't respond to the negotiate request in time.
+// Some firewalls and middleboxes silently drop negotiate requests offering dialects they don't know.
+type NegotiateTimeoutError struct {
+	Dialects []uint16 // offered dialects
+}
+
+func (err *NegotiateTimeoutError) Timeout() bool {
+	return true
+}
+
+func (err *NegotiateTimeoutError) Error() string {
+	names := make([]string, len(err.Dialects))
+	for i, d := range err.Dialects {
+		names[i] = dialectName(d)
+	}
+	return fmt.Sprintf("negotiate timed out, offered dialects: %s", strings.Join(names, ", "))
+}
+
+// AlgorithmMismatchError is returned by func (*Dialer) Dial when the signing algorithm or the cipher in use by the connection
+// isn't one of Dialer.SigningAlgorithms or Dialer.EncryptionCiphers.
+type AlgorithmMismatchError struct {
+	Dialect  uint16   // negotiated dialect
+	Signing  bool     // the signing algorithm isn't allowed, otherwise the cipher isn't
+	Selected uint16   // signing algorithm or cipher in use, a cipher of zero means no cipher is available
+	Allowed  []uint16 // allowed signing algorithms or ciphers
+}
+
+func (err *AlgorithmMismatchError) Error() string {
+	if err.Signing {
+		return fmt.Sprintf("signing algorithm %d of dialect %s isn't allowed, allowed signing algorithms: %v", err.Selected, dialectName(err.Dialect), err.Allowed)
+	}
+	if err.Selected == 0 {
+		return fmt.Sprintf("no cipher available with dialect %s, allowed ciphers: %v", dialectName(err.Dialect), err.Allowed)
+	}
+	return fmt.Sprintf("cipher %d of dialect %s isn't allowed, allowed ciphers: %v", err.Selected, dialectName(err.Dialect), err.Allowed)
+}
+
+// EncryptionMismatchError is the Err of the *os.PathError returned by func (*Session) Mount when the access to a share
+// is denied on a dialect older than SMB 3.0, which has no cipher, so the share may require encryption.
+// errors.Is(err, os.ErrPermission) reports true, but os.IsPermission doesn't, since it doesn't unwrap Err.
+type EncryptionMismatchError struct {
+	Dialect uint16 // negotiated dialect
+}
+
+func (err *EncryptionMismatchError) Error() string {
+	return fmt.Sprintf("permission denied, the share may require encryption, but negotiated dialect %s has no cipher", dialectName(err.Dialect))
+}
+
+// Unwrap returns os.ErrPermission, since the server denied the access.
+func (err *EncryptionMismatchError) Unwrap() error {
+	return os.ErrPermission
 }
 
 // ContextError wraps a context error to support os.IsTimeout function.
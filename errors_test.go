@@ -0,0 +1,23 @@
+package smb2
+
+import (
+	"testing"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+)
+
+func TestStatusString(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&ResponseError{Code: uint32(STATUS_OBJECT_NAME_NOT_FOUND)}, "response error: STATUS_OBJECT_NAME_NOT_FOUND (0xC0000034): The object name is not found."},
+		{&ResponseError{Code: 0xC0FFEE00}, "response error: status 0xC0FFEE00"},
+		{&RPCError{Op: "lookup", Status: uint32(STATUS_NONE_MAPPED)}, "rpc error: lookup: STATUS_NONE_MAPPED (0xC0000073): None of the information to be translated has been translated."},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,697 @@
+package smb2
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nodauf/go-smb2/internal/utf16le"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// fakeTransport is an in-memory transport. Packets passed to push are returned by ReadSize/Read
+// in order, and packets written by the client are handed to the handler, if any.
+type fakeTransport struct {
+	in      chan []byte
+	pending []byte
+
+	handler func(pkt []byte)
+
+	m      sync.Mutex
+	closed chan struct{}
+}
+
+func newFakeTransport() *fakeTransport {
+	return &fakeTransport{
+		in:     make(chan []byte, 16),
+		closed: make(chan struct{}),
+	}
+}
+
+func (t *fakeTransport) push(pkt []byte) {
+	t.in <- pkt
+}
+
+func (t *fakeTransport) Write(p []byte) (int, error) {
+	select {
+	case <-t.closed:
+		return -1, errors.New("closed")
+	default:
+	}
+	if t.handler != nil {
+		t.handler(append([]byte{}, p...))
+	}
+	return len(p), nil
+}
+
+func (t *fakeTransport) ReadSize() (int, error) {
+	select {
+	case <-t.closed:
+		return -1, errors.New("closed")
+	case t.pending = <-t.in:
+		return len(t.pending), nil
+	}
+}
+
+func (t *fakeTransport) Read(p []byte) (int, error) {
+	n := copy(p, t.pending)
+	t.pending = t.pending[n:]
+	return n, nil
+}
+
+func (t *fakeTransport) Close() error {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	select {
+	case <-t.closed:
+	default:
+		close(t.closed)
+	}
+	return nil
+}
+
+// newFakeConn returns a negotiated connection over tr without a session.
+func newFakeConn(tr transport, maxCreditBalance uint16) *conn {
+	conn := &conn{
+		t:                   tr,
+		outstandingRequests: newOutstandingRequests(),
+		account:             openAccount(maxCreditBalance),
+		rdone:               make(chan struct{}, 1),
+		wdone:               make(chan struct{}, 1),
+		write:               make(chan []byte, 1),
+		werr:                make(chan error, 1),
+		dialect:             SMB210,
+		capabilities:        SMB2_GLOBAL_CAP_LARGE_MTU,
+		sequenceWindow:      1,
+	}
+
+	go conn.runSender()
+	go conn.runReciever()
+
+	return conn
+}
+
+// newFakeResponse returns an empty successful response to req granting credits.
+func newFakeResponse(req []byte, credits uint16) []byte {
+	pkt := make([]byte, 64+4)
+
+	q := PacketCodec(req)
+	p := PacketCodec(pkt)
+	p.SetProtocolId()
+	p.SetStructureSize()
+	p.SetCommand(q.Command())
+	p.SetFlags(SMB2_FLAGS_SERVER_TO_REDIR)
+	p.SetMessageId(q.MessageId())
+	p.SetSessionId(q.SessionId())
+	p.SetTreeId(q.TreeId())
+	p.SetCreditResponse(credits)
+
+	pkt[64] = 4 // StructureSize
+
+	return pkt
+}
+
+// fakeFileServer serves READ requests from data.
+// It returns at most maxRead bytes per response, and STATUS_END_OF_FILE at or past the end of data,
+// or an empty response if emptyEOF is true.
+// WRITE requests are written into data, and FLUSH requests succeed.
+// The first fail requests fail with failStatus.
+type fakeFileServer struct {
+	tr       *fakeTransport
+	data     []byte
+	maxRead  int
+	emptyEOF bool
+
+	fail       int
+	failStatus NtStatus
+
+	m     sync.Mutex
+	reads int      // number of requests
+	cmds  []uint16 // commands of the requests
+}
+
+func (s *fakeFileServer) handle(req []byte) {
+	q := PacketCodec(req)
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.reads++
+	s.cmds = append(s.cmds, q.Command())
+	fail := s.reads <= s.fail
+
+	hdr := PacketHeader{
+		Command:               q.Command(),
+		CreditRequestResponse: q.CreditCharge(),
+		Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+		MessageId:             q.MessageId(),
+		TreeId:                q.TreeId(),
+		SessionId:             q.SessionId(),
+	}
+
+	if fail {
+		hdr.Status = uint32(s.failStatus)
+		s.push(&ErrorResponse{PacketHeader: hdr})
+		return
+	}
+
+	switch q.Command() {
+	case SMB2_WRITE:
+		r := WriteRequestDecoder(q.Data())
+		data := req[r.DataOffset() : uint32(r.DataOffset())+r.Length()]
+		end := int(r.Offset()) + len(data)
+		if end > len(s.data) {
+			s.data = append(s.data, make([]byte, end-len(s.data))...)
+		}
+		copy(s.data[r.Offset():], data)
+		s.push(&WriteResponse{PacketHeader: hdr, Count: uint32(len(data))})
+		return
+	case SMB2_FLUSH:
+		s.push(&FlushResponse{PacketHeader: hdr})
+		return
+	}
+
+	r := ReadRequestDecoder(q.Data())
+
+	off := int(r.Offset())
+	if off >= len(s.data) && !s.emptyEOF {
+		hdr.Status = uint32(STATUS_END_OF_FILE)
+		s.push(&ErrorResponse{PacketHeader: hdr})
+		return
+	}
+
+	var data []byte
+	if off < len(s.data) {
+		data = s.data[off:]
+	}
+
+	n := int(r.Length())
+	if n > s.maxRead {
+		n = s.maxRead
+	}
+	if n > len(data) {
+		n = len(data)
+	}
+
+	pkt := newTestReadResponse(hdr.MessageId, hdr.SessionId, data[:n])
+	p := PacketCodec(pkt)
+	p.SetTreeId(hdr.TreeId)
+	p.SetCreditResponse(hdr.CreditRequestResponse)
+	s.tr.push(pkt)
+}
+
+func (s *fakeFileServer) push(res Packet) {
+	pkt := make([]byte, res.Size())
+	res.Encode(pkt)
+	s.tr.push(pkt)
+}
+
+// newFakeShare returns a share of a guest session over tr.
+func newFakeShare(tr *fakeTransport) *Share {
+	conn := newFakeConn(tr, clientMaxCreditBalance)
+	conn.maxReadSize = 64 * 1024
+	conn.maxWriteSize = 64 * 1024
+	conn.maxTransactSize = 64 * 1024
+
+	s := &session{
+		conn:           conn,
+		treeConnTables: make(map[uint32]*treeConn),
+		sessionFlags:   SMB2_SESSION_FLAG_IS_GUEST,
+		sessionId:      1,
+	}
+	conn.session = s
+	conn.enableSession()
+
+	tc := &treeConn{session: s, treeId: 1}
+	s.treeConnTables[tc.treeId] = tc
+
+	return &Share{treeConn: tc, ctx: context.Background()}
+}
+
+// openCreditWindow has the server grant n credits by the response of an ECHO request, like the response
+// of a session setup would, so that fs can have n requests outstanding at a time. tr.handler is kept.
+func openCreditWindow(fs *Share, tr *fakeTransport, n uint16) error {
+	handle := tr.handler
+	defer func() { tr.handler = handle }()
+
+	tr.handler = func(pkt []byte) {
+		tr.push(newFakeResponse(pkt, n))
+	}
+
+	req := new(EchoRequest)
+
+	var err error
+
+	req.CreditCharge, _, err = fs.loanCredit(0)
+	if err != nil {
+		return err
+	}
+
+	_, err = fs.sendRecv(SMB2_ECHO, req)
+
+	return err
+}
+
+// newFakeFile returns a file opened on a guest session over a fakeFileServer serving data.
+func newFakeFile(data []byte, maxRead int) (*File, *fakeFileServer, *fakeTransport) {
+	tr := newFakeTransport()
+
+	srv := &fakeFileServer{tr: tr, data: data, maxRead: maxRead}
+	tr.handler = srv.handle
+
+	f := &File{
+		fs:        newFakeShare(tr),
+		fd:        &FileId{},
+		name:      "testFile",
+		_archived: 1,
+	}
+
+	return f, srv, tr
+}
+
+// fakeEntry is a file or a directory of a fakeTreeServer.
+type fakeEntry struct {
+	attrs uint32
+	size  int64
+	alloc int64  // allocation size
+	tag   uint32 // reparse tag
+
+	// access is returned in a "MxAc" create context if the CREATE request queries the maximal access.
+	access uint32
+
+	// link is the target of a symbolic link, which the client has to follow.
+	// junction is the target of a junction (mount point), which the server follows.
+	link     string
+	junction string
+
+	mtime time.Time // LastWriteTime returned by QUERY_DIRECTORY
+
+	basic []byte // FileBasicInformation of the last SET_INFO request
+}
+
+// fakeTreeServer serves CREATE, WRITE, QUERY_INFO (FileAllInformation and FileAttributeTagInformation),
+// QUERY_DIRECTORY (FileFullDirectoryInformation), SET_INFO (FileDispositionInformation and FileBasicInformation) and CLOSE requests
+// from entries keyed by name. The entries of a directory are the entries named by its name and a base name.
+// Like Windows, opening a symbolic link without FILE_OPEN_REPARSE_POINT fails with STATUS_STOPPED_ON_SYMLINK,
+// while a junction is followed by the server.
+// The requests of a compound request are answered one by one.
+type fakeTreeServer struct {
+	tr      *fakeTransport
+	entries map[string]*fakeEntry
+
+	// setAttrs is set if SET_INFO of FileBasicInformation sets the attributes, which are kept otherwise.
+	setAttrs bool
+
+	// dirClasses are the directory information classes of the QUERY_DIRECTORY requests,
+	// which fail with STATUS_INVALID_INFO_CLASS if they're in rejectedClasses.
+	dirClasses      []uint8
+	rejectedClasses map[uint8]bool
+
+	m         sync.Mutex
+	opens     map[byte]*fakeEntry // by the first byte of the persistent file id
+	names     []string            // names of the CREATE requests
+	deleting  map[*fakeEntry]bool // removed on close
+	compounds int                 // number of compound requests
+	cmds      []uint16            // commands of the requests
+	listed    map[byte]bool       // opens whose directory has been enumerated
+
+	related       byte   // file id of the last CREATE response, for related operations
+	relatedStatus uint32 // status of the last CREATE response
+
+	// The requested oplocks are granted. breaks are the levels the oplocks of the named entries are broken to
+	// right after their CREATE responses. acks are the levels of the acknowledgements,
+	// which aren't answered if dropAcks is set.
+	breaks   map[string]uint8
+	acks     []uint8
+	dropAcks bool
+
+	// brokenContexts is set if the CREATE responses carry a malformed create context.
+	brokenContexts bool
+
+	// fileIds are the names of the entries by their file ids, which CREATE requests with FILE_OPEN_BY_FILE_ID
+	// open. If it's nil, the requests fail with STATUS_NOT_SUPPORTED.
+	fileIds map[uint64]string
+}
+
+// fakeBrokenContext is a create context whose name exceeds the context.
+type fakeBrokenContext struct{}
+
+func (fakeBrokenContext) Size() int { return 16 }
+
+func (fakeBrokenContext) Encode(p []byte) {
+	binary.LittleEndian.PutUint16(p[4:6], 16)  // NameOffset
+	binary.LittleEndian.PutUint16(p[6:8], 100) // NameLength
+}
+
+func (srv *fakeTreeServer) handle(pkt []byte) {
+	if PacketCodec(pkt).NextCommand() != 0 {
+		srv.m.Lock()
+		srv.compounds++
+		srv.m.Unlock()
+	}
+
+	for {
+		p := PacketCodec(pkt)
+		next := p.NextCommand()
+		if next == 0 {
+			srv.handleOne(pkt)
+			return
+		}
+		srv.handleOne(pkt[:next])
+		pkt = pkt[next:]
+	}
+}
+
+// fileId returns the id of the file which a request refers to. For related operations, it may fail with the status of the CREATE request.
+func (srv *fakeTreeServer) fileId(q PacketCodec, fd FileIdDecoder) (byte, uint32) {
+	if q.Flags()&SMB2_FLAGS_RELATED_OPERATIONS != 0 && fd.Persistent()[0] == 0xff {
+		return srv.related, srv.relatedStatus
+	}
+	return fd.Persistent()[0], 0
+}
+
+func (srv *fakeTreeServer) handleOne(req []byte) {
+	q := PacketCodec(req)
+
+	hdr := PacketHeader{
+		Command:               q.Command(),
+		CreditRequestResponse: q.CreditCharge(),
+		Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+		MessageId:             q.MessageId(),
+		TreeId:                q.TreeId(),
+		SessionId:             q.SessionId(),
+	}
+	if hdr.CreditRequestResponse == 0 {
+		hdr.CreditRequestResponse = 1
+	}
+
+	srv.m.Lock()
+	defer srv.m.Unlock()
+
+	if srv.opens == nil {
+		srv.opens = make(map[byte]*fakeEntry)
+		srv.deleting = make(map[*fakeEntry]bool)
+		srv.listed = make(map[byte]bool)
+	}
+
+	srv.cmds = append(srv.cmds, q.Command())
+
+	var res Packet
+
+	switch q.Command() {
+	case SMB2_CREATE:
+		r := CreateRequestDecoder(q.Data())
+		nameBytes := req[r.NameOffset() : int(r.NameOffset())+int(r.NameLength())]
+
+		if r.CreateOptions()&FILE_OPEN_BY_FILE_ID != 0 {
+			if srv.fileIds == nil || len(nameBytes) != 8 {
+				hdr.Status = uint32(STATUS_NOT_SUPPORTED)
+				res = &ErrorResponse{PacketHeader: hdr}
+				break
+			}
+			name, ok := srv.fileIds[binary.LittleEndian.Uint64(nameBytes)]
+			if !ok {
+				hdr.Status = uint32(STATUS_INVALID_PARAMETER)
+				res = &ErrorResponse{PacketHeader: hdr}
+				break
+			}
+			nameBytes = utf16le.EncodeStringToBytes(name)
+		}
+
+		name := utf16le.DecodeToString(nameBytes)
+		srv.names = append(srv.names, name)
+
+		e, ok := srv.entries[name]
+		if ok && e.junction != "" && r.CreateOptions()&FILE_OPEN_REPARSE_POINT == 0 {
+			e, ok = srv.entries[e.junction]
+		}
+
+		switch {
+		case !ok:
+			hdr.Status = uint32(STATUS_OBJECT_NAME_NOT_FOUND)
+			res = &ErrorResponse{PacketHeader: hdr}
+		case e.attrs&FILE_ATTRIBUTE_DIRECTORY == 0 && r.CreateOptions()&FILE_DIRECTORY_FILE != 0:
+			hdr.Status = uint32(STATUS_NOT_A_DIRECTORY)
+			res = &ErrorResponse{PacketHeader: hdr}
+		case e.attrs&FILE_ATTRIBUTE_READONLY != 0 && r.DesiredAccess()&DELETE != 0:
+			hdr.Status = uint32(STATUS_ACCESS_DENIED)
+			res = &ErrorResponse{PacketHeader: hdr}
+		case e.link != "" && r.CreateOptions()&FILE_OPEN_REPARSE_POINT == 0:
+			hdr.Status = uint32(STATUS_STOPPED_ON_SYMLINK)
+			res = &ErrorResponse{
+				PacketHeader: hdr,
+				ErrorData: &SymbolicLinkErrorResponse{
+					SubstituteName: e.link,
+					PrintName:      e.link,
+				},
+			}
+		default:
+			fd := &FileId{}
+			fd.Persistent[0] = byte(len(srv.opens) + 1)
+			srv.opens[fd.Persistent[0]] = e
+			srv.related = fd.Persistent[0]
+
+			var contexts []Encoder
+			coff := r.CreateContextsOffset()
+			if reqContexts, _ := parseCreateContexts(req[coff : coff+r.CreateContextsLength()]); reqContexts != nil {
+				if _, ok := reqContexts[createContextMaximalAccess]; ok {
+					data := make([]byte, 8)
+					binary.LittleEndian.PutUint32(data[4:8], e.access)
+					contexts = append(contexts, &CreateContextRequest{Name: []byte(createContextMaximalAccess), Data: data})
+				}
+			}
+
+			if srv.brokenContexts {
+				contexts = append(contexts, fakeBrokenContext{})
+			}
+
+			res = &CreateResponse{
+				PacketHeader:   hdr,
+				CreationTime:   &Filetime{},
+				LastAccessTime: &Filetime{},
+				LastWriteTime:  &Filetime{},
+				ChangeTime:     &Filetime{},
+				EndofFile:      e.size,
+				FileAttributes: e.attrs,
+				FileId:         fd,
+				Contexts:       contexts,
+				OplockLevel:    r.RequestedOplockLevel(),
+			}
+
+			if level, ok := srv.breaks[name]; ok && r.RequestedOplockLevel() != SMB2_OPLOCK_LEVEL_NONE {
+				defer srv.breakOplock(fd, level)
+			}
+		}
+		srv.relatedStatus = hdr.Status
+	case SMB2_SET_INFO:
+		r := SetInfoRequestDecoder(q.Data())
+		id, status := srv.fileId(q, r.FileId())
+		e := srv.opens[id]
+		switch {
+		case status != 0:
+			hdr.Status = status
+			res = &ErrorResponse{PacketHeader: hdr}
+		case r.FileInfoClass() == FileDispositionInformation:
+			srv.deleting[e] = true
+			res = &SetInfoResponse{PacketHeader: hdr}
+		case r.FileInfoClass() == FileBasicInformation:
+			off := int(r.BufferOffset()) - 64
+			e.basic = append([]byte{}, q.Data()[off:off+int(r.BufferLength())]...)
+			if attrs := FileBasicInformationDecoder(e.basic).FileAttributes(); attrs != 0 && srv.setAttrs {
+				e.attrs = attrs &^ FILE_ATTRIBUTE_NORMAL
+			}
+			res = &SetInfoResponse{PacketHeader: hdr}
+		default:
+			hdr.Status = uint32(STATUS_NOT_SUPPORTED)
+			res = &ErrorResponse{PacketHeader: hdr}
+		}
+	case SMB2_QUERY_INFO:
+		r := QueryInfoRequestDecoder(q.Data())
+		e := srv.opens[r.FileId().Persistent()[0]]
+
+		var info []byte
+		switch {
+		case r.InfoType() == SMB2_0_INFO_FILESYSTEM:
+			// FileFsFullSizeInformation of 512-byte sectors
+			info = make([]byte, 32)
+			binary.LittleEndian.PutUint32(info[24:28], 8)
+			binary.LittleEndian.PutUint32(info[28:32], 512)
+		case r.FileInfoClass() == FileStandardInformation:
+			info = make([]byte, 24)
+			binary.LittleEndian.PutUint64(info[0:8], uint64(e.alloc))
+			binary.LittleEndian.PutUint64(info[8:16], uint64(e.size))
+		case r.FileInfoClass() == FileAttributeTagInformation:
+			info = make([]byte, 8)
+			binary.LittleEndian.PutUint32(info[:4], e.attrs)
+			binary.LittleEndian.PutUint32(info[4:8], e.tag)
+		default:
+			info = make([]byte, 104)
+			binary.LittleEndian.PutUint32(info[32:36], e.attrs)
+			binary.LittleEndian.PutUint64(info[40:48], uint64(e.alloc))
+			binary.LittleEndian.PutUint64(info[48:56], uint64(e.size))
+		}
+
+		res = &QueryInfoResponse{PacketHeader: hdr, Output: fakeBytes(info)}
+	case SMB2_WRITE:
+		// like Samba without "map archive", the archive bit isn't set by writes
+		r := WriteRequestDecoder(q.Data())
+		res = &WriteResponse{PacketHeader: hdr, Count: r.Length()}
+	case SMB2_QUERY_DIRECTORY:
+		r := QueryDirectoryRequestDecoder(q.Data())
+		srv.dirClasses = append(srv.dirClasses, r.FileInfoClass())
+		if srv.rejectedClasses[r.FileInfoClass()] {
+			hdr.Status = uint32(STATUS_INVALID_INFO_CLASS)
+			res = &ErrorResponse{PacketHeader: hdr}
+			break
+		}
+		id := r.FileId().Persistent()[0]
+		if r.Flags()&RESTART_SCANS != 0 {
+			srv.listed[id] = false
+		}
+		if srv.listed[id] {
+			hdr.Status = uint32(STATUS_NO_MORE_FILES)
+			res = &ErrorResponse{PacketHeader: hdr}
+			break
+		}
+		srv.listed[id] = true
+
+		var dir string
+		for name, e := range srv.entries {
+			if e == srv.opens[id] {
+				dir = name
+			}
+		}
+
+		output := srv.dirInfo(dir, r.FileInfoClass())
+		if len(output) == 0 {
+			hdr.Status = uint32(STATUS_NO_MORE_FILES)
+			res = &ErrorResponse{PacketHeader: hdr}
+			break
+		}
+
+		res = &QueryDirectoryResponse{PacketHeader: hdr, Output: output}
+	case SMB2_OPLOCK_BREAK:
+		r := OplockBreakAcknowledgementDecoder(q.Data())
+		srv.acks = append(srv.acks, r.OplockLevel())
+		if srv.dropAcks {
+			return
+		}
+		res = &OplockBreakResponse{PacketHeader: hdr, OplockLevel: r.OplockLevel(), FileId: r.FileId().Decode()}
+	case SMB2_CLOSE:
+		id, status := srv.fileId(q, CloseRequestDecoder(q.Data()).FileId())
+		if status != 0 {
+			hdr.Status = status
+			res = &ErrorResponse{PacketHeader: hdr}
+			break
+		}
+		if e := srv.opens[id]; srv.deleting[e] {
+			delete(srv.deleting, e)
+			for name, x := range srv.entries {
+				if x == e {
+					delete(srv.entries, name)
+				}
+			}
+		}
+		res = &CloseResponse{
+			PacketHeader:   hdr,
+			CreationTime:   &Filetime{},
+			LastAccessTime: &Filetime{},
+			LastWriteTime:  &Filetime{},
+			ChangeTime:     &Filetime{},
+		}
+	default:
+		hdr.Status = uint32(STATUS_NOT_SUPPORTED)
+		res = &ErrorResponse{PacketHeader: hdr}
+	}
+
+	pkt := make([]byte, res.Size())
+	res.Encode(pkt)
+	PacketCodec(pkt).SetCommand(q.Command())
+	srv.tr.push(pkt)
+}
+
+// breakOplock sends the notification breaking the oplock of fd to level.
+func (srv *fakeTreeServer) breakOplock(fd *FileId, level uint8) {
+	res := &OplockBreakResponse{
+		PacketHeader: PacketHeader{
+			Flags:     SMB2_FLAGS_SERVER_TO_REDIR,
+			MessageId: 0xFFFFFFFFFFFFFFFF,
+		},
+		OplockLevel: level,
+		FileId:      fd,
+	}
+
+	pkt := make([]byte, res.Size())
+	res.Encode(pkt)
+	srv.tr.push(pkt)
+}
+
+// dirInfo returns the FileFullDirectoryInformation of the entries of dir.
+func (srv *fakeTreeServer) dirInfo(dir string, class uint8) fakeBytes {
+	prefix := dir + `\`
+	if dir == "" {
+		prefix = ""
+	}
+
+	var names []string
+	for name := range srv.entries {
+		if name != dir && strings.HasPrefix(name, prefix) && !strings.Contains(name[len(prefix):], `\`) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var output []byte
+	last := 0
+	for _, name := range names {
+		e := srv.entries[name]
+		n := utf16le.EncodeStringToBytes(name[len(prefix):])
+
+		last = len(output)
+		// the offset of the name in the entries of the classes
+		off := map[uint8]int{
+			FileDirectoryInformation:       64,
+			FileFullDirectoryInformation:   68,
+			FileBothDirectoryInformation:   94,
+			FileIdBothDirectoryInformation: 104,
+		}[class]
+
+		b := make([]byte, off, off+len(n))
+		binary.LittleEndian.PutUint64(b[24:32], uint64(e.mtime.UnixNano()/100+116444736000000000))
+		binary.LittleEndian.PutUint64(b[40:48], uint64(e.size))
+		binary.LittleEndian.PutUint64(b[48:56], uint64(e.alloc))
+		binary.LittleEndian.PutUint32(b[56:60], e.attrs)
+		binary.LittleEndian.PutUint32(b[60:64], uint32(len(n)))
+		if off > 64 {
+			binary.LittleEndian.PutUint32(b[64:68], e.tag) // EaSize
+		}
+		b = append(b, n...)
+		for len(b)%8 != 0 {
+			b = append(b, 0)
+		}
+		binary.LittleEndian.PutUint32(b[:4], uint32(len(b))) // NextEntryOffset
+		output = append(output, b...)
+	}
+	if len(output) > 0 {
+		binary.LittleEndian.PutUint32(output[last:last+4], 0)
+	}
+	return output
+}
+
+// fakeBytes encodes itself as is.
+type fakeBytes []byte
+
+func (b fakeBytes) Size() int {
+	return len(b)
+}
+
+func (b fakeBytes) Encode(p []byte) {
+	copy(p, b)
+}
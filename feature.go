@@ -1,25 +1,44 @@
 package smb2
 
 import (
+	"time"
+
 	. "github.com/nodauf/go-smb2/internal/smb2"
 )
 
 // client
 
 const (
-	clientCapabilities = SMB2_GLOBAL_CAP_LARGE_MTU | SMB2_GLOBAL_CAP_ENCRYPTION
+	clientCapabilities = SMB2_GLOBAL_CAP_LARGE_MTU | SMB2_GLOBAL_CAP_ENCRYPTION | SMB2_GLOBAL_CAP_MULTI_CHANNEL
 )
 
 var (
 	clientHashAlgorithms = []uint16{SHA512}
 	clientCiphers        = []uint16{AES128GCM, AES128CCM}
+	clientSigningAlgs    = []uint16{AES_GMAC, AES_CMAC}
+	clientRDMATransforms = []uint16{SMB2_RDMA_TRANSFORM_ENCRYPTION, SMB2_RDMA_TRANSFORM_SIGNING} // only offered to detect the support of RDMA transforms
 	clientDialects       = []uint16{SMB311, SMB302, SMB300, SMB210, SMB202}
+
+	clientCompatibilityDialects = []uint16{SMB300, SMB210} // offered by Dialer.CompatibilityMode
 )
 
 const (
 	clientMaxCreditBalance = 128
 )
 
+const (
+	// clientOplockBreakAckTimeout is the default of Dialer.OplockBreakAckTimeout, the time servers wait for
+	// the acknowledgement of an oplock break before breaking the oplock anyway (e.g. 35 seconds on Windows).
+	clientOplockBreakAckTimeout = 35 * time.Second
+
+	// clientMaxEarlyBreaks is the number of oplock breaks kept for the handles which aren't registered yet.
+	clientMaxEarlyBreaks = 64
+)
+
+const (
+	clientMaxMessageSlack = 4 * 1024 * 1024 // allowed above the negotiated max sizes, see Dialer.MaxMessageSize
+)
+
 const (
 	clientMaxSymlinkDepth = 8
 )
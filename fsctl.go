@@ -0,0 +1,514 @@
+package smb2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// Fsctl sends the file system control ctlCode (e.g. 0x000900A8 for FSCTL_GET_REPARSE_POINT, see [MS-FSCC] 2.3)
+// with the raw input buffer, and returns the raw output buffer, so that the controls which aren't wrapped by the package can be issued.
+// maxOutput is the max output size of a request, or the max transact size if it isn't positive.
+// The input can't exceed the max transact size, since it can't be split without knowing the layout of the control.
+//
+// The outputs of the controls which can be continued are read by as many requests as needed, and returned as a single output:
+//
+//	FSCTL_GET_RETRIEVAL_POINTERS (0x00090073), continued from the NextVcn of the last extent returned
+//	FSCTL_QUERY_ALLOCATED_RANGES (0x000940CF), continued from the end of the last range returned
+//
+// The outputs of the other controls which don't fit in maxOutput fail with *BufferOverflowError.
+// The partial output of STATUS_BUFFER_OVERFLOW is returned with it, e.g. a part of a named pipe message of FSCTL_PIPE_TRANSCEIVE,
+// which is followed by reads of the pipe. Otherwise, the required size is reported, so that the request can be reissued
+// with a larger maxOutput (e.g. FSCTL_SRV_ENUMERATE_SNAPSHOTS reports it in its output instead).
+func (f *File) Fsctl(ctlCode uint32, input []byte, maxOutput int) ([]byte, error) {
+	if maxOutput <= 0 || maxOutput > f.maxTransactSize() {
+		maxOutput = f.maxTransactSize()
+	}
+
+	var output []byte
+	err := f.fs.retry(false, func() (err error) {
+		output, err = f.fsctl(ctlCode, input, maxOutput)
+		return
+	})
+	if err != nil {
+		return output, &os.PathError{Op: "fsctl", Path: f.name, Err: err}
+	}
+	return output, nil
+}
+
+func (f *File) fsctl(ctlCode uint32, input []byte, maxOutput int) ([]byte, error) {
+	switch ctlCode {
+	case FSCTL_GET_RETRIEVAL_POINTERS:
+		return f.fsctlContinued(ctlCode, input, maxOutput, continueRetrievalPointers)
+	case FSCTL_QUERY_ALLOCATED_RANGES:
+		return f.fsctlContinued(ctlCode, input, maxOutput, continueAllocatedRanges)
+	}
+	return f.fsctlOnce(ctlCode, input, maxOutput)
+}
+
+// fsctlOnce sends the control by a single request. The output is copied, since it refers to the response buffer.
+func (f *File) fsctlOnce(ctlCode uint32, input []byte, maxOutput int) ([]byte, error) {
+	req := &IoctlRequest{
+		CtlCode:           ctlCode,
+		OutputOffset:      0,
+		OutputCount:       0,
+		MaxInputResponse:  0,
+		MaxOutputResponse: uint32(maxOutput),
+		Flags:             SMB2_0_IOCTL_IS_FSCTL,
+	}
+	if len(input) > 0 {
+		req.Input = infoBuffer(input)
+	}
+
+	output, err := f.ioctl(req)
+	if err != nil {
+		if rerr, ok := err.(*ResponseError); ok {
+			switch NtStatus(rerr.Code) {
+			case STATUS_BUFFER_OVERFLOW:
+				return append([]byte{}, output...), &BufferOverflowError{}
+			case STATUS_BUFFER_TOO_SMALL:
+				return nil, &BufferOverflowError{Required: requiredBufferLength(rerr)}
+			}
+		}
+		return nil, err
+	}
+
+	return append([]byte{}, output...), nil
+}
+
+// fsctlContinuation merges the output of a control into the output read so far, which is nil for the first one,
+// and returns the merged output and the input continuing it.
+type fsctlContinuation func(merged, output, input []byte) ([]byte, []byte, error)
+
+// fsctlContinued sends the control again with the inputs returned by cont as long as the output overflows.
+// A continuation failing with STATUS_END_OF_FILE ends the output.
+func (f *File) fsctlContinued(ctlCode uint32, input []byte, maxOutput int, cont fsctlContinuation) (merged []byte, err error) {
+	for {
+		output, err := f.fsctlOnce(ctlCode, input, maxOutput)
+		if rerr, ok := err.(*ResponseError); ok && NtStatus(rerr.Code) == STATUS_END_OF_FILE && merged != nil {
+			// the output read so far ends where the data does, e.g. at the last extent of FSCTL_GET_RETRIEVAL_POINTERS
+			return merged, nil
+		}
+		_, more := err.(*BufferOverflowError)
+		if err != nil && !more {
+			return nil, err
+		}
+
+		if !more && merged == nil {
+			// the output fits in a single response
+			return output, nil
+		}
+
+		var next []byte
+		merged, next, err = cont(merged, output, input)
+		if err != nil {
+			return nil, err
+		}
+
+		if !more {
+			return merged, nil
+		}
+		if bytes.Equal(next, input) {
+			return nil, &InvalidResponseError{fmt.Sprintf("overflowing output of control code %#x can't be continued", ctlCode)}
+		}
+		input = next
+	}
+}
+
+// continueRetrievalPointers appends the extents of a RETRIEVAL_POINTERS_BUFFER to the merged one,
+// and continues from the NextVcn of the last extent.
+func continueRetrievalPointers(merged, output, input []byte) ([]byte, []byte, error) {
+	d := RetrievalPointersBufferDecoder(output)
+	if d.IsInvalid() || len(input) < 8 {
+		return nil, nil, &InvalidResponseError{"broken retrieval pointers format"}
+	}
+	if d.ExtentCount() == 0 {
+		return merged, input, nil
+	}
+
+	count := d.ExtentCount()
+	extents := output[16 : 16+16*count]
+
+	if merged == nil {
+		merged = append([]byte{}, output[:16]...)
+	} else {
+		count += binary.LittleEndian.Uint32(merged[:4])
+	}
+	merged = append(merged, extents...)
+	binary.LittleEndian.PutUint32(merged[:4], count)
+
+	next := append([]byte{}, input...)
+	binary.LittleEndian.PutUint64(next[:8], uint64(d.NextVcn(int(d.ExtentCount())-1)))
+
+	return merged, next, nil
+}
+
+// continueAllocatedRanges appends the FILE_ALLOCATED_RANGE_BUFFERs to the merged ones,
+// and continues from the end of the last range up to the end of the queried range.
+func continueAllocatedRanges(merged, output, input []byte) ([]byte, []byte, error) {
+	if len(input) < 16 {
+		return nil, nil, &InvalidResponseError{"broken allocated ranges format"}
+	}
+
+	ranges := output[:len(output)/16*16]
+	if len(ranges) == 0 {
+		return merged, input, nil
+	}
+
+	last := ranges[len(ranges)-16:]
+	off := int64(binary.LittleEndian.Uint64(last[:8])) + int64(binary.LittleEndian.Uint64(last[8:16]))
+	end := int64(binary.LittleEndian.Uint64(input[:8])) + int64(binary.LittleEndian.Uint64(input[8:16]))
+	if off <= int64(binary.LittleEndian.Uint64(input[:8])) || off > end {
+		return nil, nil, &InvalidResponseError{"broken allocated ranges format"}
+	}
+
+	next := append([]byte{}, input...)
+	binary.LittleEndian.PutUint64(next[:8], uint64(off))
+	binary.LittleEndian.PutUint64(next[8:16], uint64(end-off))
+
+	return append(merged, ranges...), next, nil
+}
+
+// NtfsVolumeData represents the NTFS_VOLUME_DATA_BUFFER returned by FSCTL_GET_NTFS_VOLUME_DATA.
+// Cluster numbers (LCN) are relative to the beginning of the volume.
+type NtfsVolumeData struct {
+	VolumeSerialNumber           uint64
+	NumberSectors                int64
+	TotalClusters                int64
+	FreeClusters                 int64
+	TotalReserved                int64
+	BytesPerSector               uint32
+	BytesPerCluster              uint32
+	BytesPerFileRecordSegment    uint32
+	ClustersPerFileRecordSegment uint32
+	MftValidDataLength           int64
+	MftStartLcn                  int64
+	Mft2StartLcn                 int64
+	MftZoneStart                 int64
+	MftZoneEnd                   int64
+
+	// NTFS version, zero if the server didn't return NTFS_EXTENDED_VOLUME_DATA.
+	MajorVersion uint16
+	MinorVersion uint16
+}
+
+// NtfsVolumeData returns the NTFS volume information of the volume backing the share.
+// If the volume isn't formatted with NTFS or the server doesn't support the request, ErrNotSupported is returned.
+func (fs *Share) NtfsVolumeData() (*NtfsVolumeData, error) {
+	create := &CreateRequest{
+		SecurityFlags:        0,
+		RequestedOplockLevel: SMB2_OPLOCK_LEVEL_NONE,
+		ImpersonationLevel:   Impersonation,
+		SmbCreateFlags:       0,
+		DesiredAccess:        FILE_READ_ATTRIBUTES | SYNCHRONIZE,
+		FileAttributes:       FILE_ATTRIBUTE_NORMAL,
+		ShareAccess:          FILE_SHARE_READ | FILE_SHARE_WRITE | FILE_SHARE_DELETE,
+		CreateDisposition:    FILE_OPEN,
+		CreateOptions:        FILE_DIRECTORY_FILE,
+	}
+
+	f, err := fs.createFile("", create, true)
+	if err != nil {
+		return nil, &os.PathError{Op: "ntfsvolumedata", Path: "", Err: err}
+	}
+
+	data, err := f.ntfsVolumeData()
+	if e := f.close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "ntfsvolumedata", Path: "", Err: err}
+	}
+	return data, nil
+}
+
+func (f *File) ntfsVolumeData() (*NtfsVolumeData, error) {
+	req := &IoctlRequest{
+		CtlCode:           FSCTL_GET_NTFS_VOLUME_DATA,
+		OutputOffset:      0,
+		OutputCount:       0,
+		MaxInputResponse:  0,
+		MaxOutputResponse: 96 + 32, // NTFS_VOLUME_DATA_BUFFER + NTFS_EXTENDED_VOLUME_DATA
+		Flags:             SMB2_0_IOCTL_IS_FSCTL,
+	}
+
+	output, err := f.ioctl(req)
+	if err != nil {
+		if rerr, ok := err.(*ResponseError); ok {
+			switch NtStatus(rerr.Code) {
+			case STATUS_INVALID_DEVICE_REQUEST, STATUS_NOT_SUPPORTED:
+				return nil, ErrNotSupported
+			}
+		}
+		return nil, err
+	}
+
+	d := NtfsVolumeDataBufferDecoder(output)
+	if d.IsInvalid() {
+		return nil, &InvalidResponseError{"broken ntfs volume data format"}
+	}
+
+	data := &NtfsVolumeData{
+		VolumeSerialNumber:           d.VolumeSerialNumber(),
+		NumberSectors:                d.NumberSectors(),
+		TotalClusters:                d.TotalClusters(),
+		FreeClusters:                 d.FreeClusters(),
+		TotalReserved:                d.TotalReserved(),
+		BytesPerSector:               d.BytesPerSector(),
+		BytesPerCluster:              d.BytesPerCluster(),
+		BytesPerFileRecordSegment:    d.BytesPerFileRecordSegment(),
+		ClustersPerFileRecordSegment: d.ClustersPerFileRecordSegment(),
+		MftValidDataLength:           d.MftValidDataLength(),
+		MftStartLcn:                  d.MftStartLcn(),
+		Mft2StartLcn:                 d.Mft2StartLcn(),
+		MftZoneStart:                 d.MftZoneStart(),
+		MftZoneEnd:                   d.MftZoneEnd(),
+	}
+
+	if ext := d.ExtendedVolumeData(); !ext.IsInvalid() {
+		data.MajorVersion = ext.MajorVersion()
+		data.MinorVersion = ext.MinorVersion()
+	}
+
+	return data, nil
+}
+
+// Extent represents a run of clusters of a file returned by func (*File) RetrievalPointers.
+// VCN is the cluster offset within the file and LCN is the cluster number on the volume.
+// LCN is -1 if the run isn't allocated on disk (e.g. a sparse or compressed range).
+type Extent struct {
+	VCN      int64
+	LCN      int64
+	Clusters int64
+}
+
+// RetrievalPointers returns the on-disk extent map of the file starting at the cluster startVcn
+// via FSCTL_GET_RETRIEVAL_POINTERS.
+// Files whose data is stored in the MFT record have no extents.
+// If the server or the underlying file system doesn't support the request, ErrNotSupported is returned.
+func (f *File) RetrievalPointers(startVcn int64) ([]Extent, error) {
+	extents, err := f.retrievalPointers(startVcn)
+	if err != nil {
+		return nil, &os.PathError{Op: "retrievalpointers", Path: f.name, Err: err}
+	}
+	return extents, nil
+}
+
+func (f *File) retrievalPointers(startVcn int64) ([]Extent, error) {
+	maxOutput := singleCreditMaxPayloadSize
+	if size := f.maxTransactSize(); size < maxOutput {
+		maxOutput = size
+	}
+
+	in := &StartingVcnInputBuffer{StartingVcn: startVcn}
+	input := make([]byte, in.Size())
+	in.Encode(input)
+
+	output, err := f.fsctlContinued(FSCTL_GET_RETRIEVAL_POINTERS, input, maxOutput, continueRetrievalPointers)
+	if err != nil {
+		if rerr, ok := err.(*ResponseError); ok {
+			switch NtStatus(rerr.Code) {
+			case STATUS_END_OF_FILE:
+				// startVcn is past the last extent, or the data is stored in the MFT record
+				return nil, nil
+			case STATUS_INVALID_DEVICE_REQUEST, STATUS_NOT_SUPPORTED:
+				return nil, ErrNotSupported
+			}
+		}
+		return nil, err
+	}
+
+	d := RetrievalPointersBufferDecoder(output)
+	if d.IsInvalid() {
+		return nil, &InvalidResponseError{"broken retrieval pointers format"}
+	}
+
+	var extents []Extent
+
+	vcn := d.StartingVcn()
+	for i := 0; i < int(d.ExtentCount()); i++ {
+		next := d.NextVcn(i)
+		if next <= vcn {
+			return nil, &InvalidResponseError{"broken retrieval pointers format"}
+		}
+		extents = append(extents, Extent{VCN: vcn, LCN: d.Lcn(i), Clusters: next - vcn})
+		vcn = next
+	}
+
+	return extents, nil
+}
+
+// SetValidDataLength sets the valid data length of the file to length via FSCTL_SET_VALID_DATA_LENGTH,
+// so that the data up to it is valid without being written, e.g. to create a preallocated file quickly.
+// The range past the previous valid data length then exposes whatever the clusters contained on disk,
+// which is why it requires SeManageVolumePrivilege; ErrPrivilegeNotHeld is returned if the account doesn't hold it.
+// The file must be opened for writing and allocated up to length (e.g. by Truncate), and the length can't be decreased.
+// If the server or the underlying file system doesn't support the request, ErrNotSupported is returned.
+func (f *File) SetValidDataLength(length int64) error {
+	if length < 0 {
+		return &os.PathError{Op: "setvaliddata", Path: f.name, Err: os.ErrInvalid}
+	}
+
+	err := f.setValidDataLength(length)
+	if err != nil {
+		return &os.PathError{Op: "setvaliddata", Path: f.name, Err: err}
+	}
+	return nil
+}
+
+func (f *File) setValidDataLength(length int64) error {
+	req := &IoctlRequest{
+		CtlCode:           FSCTL_SET_VALID_DATA_LENGTH,
+		OutputOffset:      0,
+		OutputCount:       0,
+		MaxInputResponse:  0,
+		MaxOutputResponse: 0,
+		Flags:             SMB2_0_IOCTL_IS_FSCTL,
+		Input:             &FileValidDataLengthInformationBuffer{ValidDataLength: length},
+	}
+
+	_, err := f.ioctl(req)
+	if err != nil {
+		if rerr, ok := err.(*ResponseError); ok {
+			switch NtStatus(rerr.Code) {
+			case STATUS_PRIVILEGE_NOT_HELD:
+				return ErrPrivilegeNotHeld
+			case STATUS_INVALID_DEVICE_REQUEST, STATUS_NOT_SUPPORTED:
+				return ErrNotSupported
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// CloneRange copies length bytes of src from srcOffset to f at dstOffset.
+// If src is on the same share, the blocks are cloned by FSCTL_DUPLICATE_EXTENTS_TO_FILE,
+// which shares the extents on file systems supporting block cloning (e.g. ReFS), so that even large copies are near instant.
+// The file system may require the offsets and length to be aligned to its cluster size.
+// f is extended first if the range ends past its end of file.
+// If block cloning isn't supported, the range is copied by server-side copy (FSCTL_SRV_COPYCHUNK),
+// or read and written by the client if that isn't supported either or src is on another share.
+// If the range ends past the end of src, the bytes up to it are copied and io.ErrUnexpectedEOF is returned.
+func (f *File) CloneRange(src *File, srcOffset, dstOffset, length int64) error {
+	if srcOffset < 0 || dstOffset < 0 || length < 0 {
+		return &os.LinkError{Op: "clone", Old: src.name, New: f.name, Err: os.ErrInvalid}
+	}
+
+	err := f.cloneRange(src, srcOffset, dstOffset, length)
+	if err != nil {
+		return &os.LinkError{Op: "clone", Old: src.name, New: f.name, Err: err}
+	}
+	return nil
+}
+
+func (f *File) cloneRange(src *File, srcOffset, dstOffset, length int64) error {
+	if length == 0 {
+		return nil
+	}
+
+	if src.fs.treeConn == f.fs.treeConn {
+		err := f.duplicateExtents(src, srcOffset, dstOffset, length)
+		if err != ErrNotSupported {
+			return err
+		}
+
+		supported, n, err := src.copyRange(f, srcOffset, dstOffset, length)
+		if supported {
+			if err == nil && n < length {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+
+	return f.streamRange(src, srcOffset, dstOffset, length)
+}
+
+// duplicateExtents clones the range of src into f by FSCTL_DUPLICATE_EXTENTS_TO_FILE.
+// If the server or the file system doesn't support block cloning, ErrNotSupported is returned.
+func (f *File) duplicateExtents(src *File, srcOffset, dstOffset, length int64) error {
+	// the target range must be within the end of file
+	size, err := f.size()
+	if err != nil {
+		return err
+	}
+	if size < dstOffset+length {
+		if err := f.truncate(dstOffset + length); err != nil {
+			return err
+		}
+	}
+
+	req := &IoctlRequest{
+		CtlCode:           FSCTL_DUPLICATE_EXTENTS_TO_FILE,
+		OutputOffset:      0,
+		OutputCount:       0,
+		MaxInputResponse:  0,
+		MaxOutputResponse: 0,
+		Flags:             SMB2_0_IOCTL_IS_FSCTL,
+		Input: &DuplicateExtentsData{
+			FileHandle:       src.fd,
+			SourceFileOffset: srcOffset,
+			TargetFileOffset: dstOffset,
+			ByteCount:        length,
+		},
+	}
+
+	_, err = f.ioctl(req)
+	if err != nil {
+		if rerr, ok := err.(*ResponseError); ok {
+			switch NtStatus(rerr.Code) {
+			case STATUS_INVALID_DEVICE_REQUEST, STATUS_NOT_SUPPORTED:
+				return ErrNotSupported
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// streamRange copies the range of src into f by reading and writing it through the client.
+func (f *File) streamRange(src *File, srcOffset, dstOffset, length int64) error {
+	size := src.maxReadSize()
+	if max := f.maxWriteSize(); max < size {
+		size = max
+	}
+	if int64(size) > length {
+		size = int(length)
+	}
+
+	buf := make([]byte, size)
+
+	for n := int64(0); n < length; {
+		b := buf
+		if remains := length - n; remains < int64(len(b)) {
+			b = b[:remains]
+		}
+
+		m, err := src.readAt(b, srcOffset+n)
+		if err != nil {
+			if rerr, ok := err.(*ResponseError); ok && NtStatus(rerr.Code) == STATUS_END_OF_FILE {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		if m == 0 {
+			return io.ErrUnexpectedEOF
+		}
+
+		if _, err := f.writeAt(b[:m], dstOffset+n); err != nil {
+			return err
+		}
+
+		n += int64(m)
+	}
+
+	return nil
+}
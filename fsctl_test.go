@@ -0,0 +1,433 @@
+package smb2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// fakeCloneServer serves the READ and WRITE requests of the files whose persistent file ids start with 1 and 2
+// by fakeFileServers, QUERY_INFO and SET_INFO requests of their end of file, and IOCTL requests,
+// which succeed if the control code is in supported and fail with STATUS_NOT_SUPPORTED otherwise.
+type fakeCloneServer struct {
+	tr        *fakeTransport
+	files     map[byte]*fakeFileServer
+	supported map[uint32]bool
+
+	ctlCodes []uint32
+	inputs   [][]byte
+}
+
+func (srv *fakeCloneServer) handle(req []byte) {
+	q := PacketCodec(req)
+
+	hdr := PacketHeader{
+		Command:               q.Command(),
+		CreditRequestResponse: q.CreditCharge(),
+		Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+		MessageId:             q.MessageId(),
+		TreeId:                q.TreeId(),
+		SessionId:             q.SessionId(),
+	}
+
+	var res Packet
+
+	switch q.Command() {
+	case SMB2_READ:
+		srv.files[ReadRequestDecoder(q.Data()).FileId().Persistent()[0]].handle(req)
+		return
+	case SMB2_WRITE:
+		srv.files[WriteRequestDecoder(q.Data()).FileId().Persistent()[0]].handle(req)
+		return
+	case SMB2_QUERY_INFO:
+		f := srv.files[QueryInfoRequestDecoder(q.Data()).FileId().Persistent()[0]]
+		info := make(fakeBytes, 24)
+		binary.LittleEndian.PutUint64(info[8:16], uint64(len(f.data))) // EndOfFile
+		res = &QueryInfoResponse{PacketHeader: hdr, Output: info}
+	case SMB2_SET_INFO:
+		r := SetInfoRequestDecoder(q.Data())
+		f := srv.files[r.FileId().Persistent()[0]]
+		if size := int(binary.LittleEndian.Uint64(req[r.BufferOffset():])); size > len(f.data) {
+			f.data = append(f.data, make([]byte, size-len(f.data))...)
+		}
+		res = &SetInfoResponse{PacketHeader: hdr}
+	case SMB2_IOCTL:
+		r := IoctlRequestDecoder(q.Data())
+		srv.ctlCodes = append(srv.ctlCodes, r.CtlCode())
+		srv.inputs = append(srv.inputs, append([]byte{}, req[r.InputOffset():r.InputOffset()+r.InputCount()]...))
+		if !srv.supported[r.CtlCode()] {
+			hdr.Status = uint32(STATUS_NOT_SUPPORTED)
+			res = &ErrorResponse{PacketHeader: hdr}
+		} else {
+			res = &IoctlResponse{PacketHeader: hdr, CtlCode: r.CtlCode(), FileId: &FileId{}}
+		}
+	}
+
+	pkt := make([]byte, res.Size())
+	res.Encode(pkt)
+	PacketCodec(pkt).SetCommand(q.Command())
+	srv.tr.push(pkt)
+}
+
+// newTestCloneFiles returns a source file of data and an empty target file on a share served by a fakeCloneServer.
+func newTestCloneFiles(data []byte, supported ...uint32) (src, dst *File, srv *fakeCloneServer) {
+	tr := newFakeTransport()
+
+	srv = &fakeCloneServer{
+		tr: tr,
+		files: map[byte]*fakeFileServer{
+			1: {tr: tr, data: data, maxRead: 64 * 1024},
+			2: {tr: tr, maxRead: 64 * 1024},
+		},
+		supported: make(map[uint32]bool),
+	}
+	for _, ctlCode := range supported {
+		srv.supported[ctlCode] = true
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	src = &File{fs: fs, fd: &FileId{Persistent: [8]byte{1}}, name: "src", _archived: 1}
+	dst = &File{fs: fs, fd: &FileId{Persistent: [8]byte{2}}, name: "dst", _archived: 1}
+
+	return src, dst, srv
+}
+
+func TestCloneRange(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 8*1024)
+
+	src, dst, srv := newTestCloneFiles(data, FSCTL_DUPLICATE_EXTENTS_TO_FILE)
+	defer srv.tr.Close()
+
+	if err := dst.CloneRange(src, 4096, 8192, 65536); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(srv.ctlCodes, []uint32{FSCTL_DUPLICATE_EXTENTS_TO_FILE}) {
+		t.Fatalf("unexpected control codes %#x", srv.ctlCodes)
+	}
+
+	expected := make([]byte, 40)
+	expected[0] = 1                                       // FileHandle of src
+	binary.LittleEndian.PutUint64(expected[16:24], 4096)  // SourceFileOffset
+	binary.LittleEndian.PutUint64(expected[24:32], 8192)  // TargetFileOffset
+	binary.LittleEndian.PutUint64(expected[32:40], 65536) // ByteCount
+	if !bytes.Equal(srv.inputs[0], expected) {
+		t.Errorf("unexpected duplicate extents data %x", srv.inputs[0])
+	}
+
+	// the target is extended to the end of the range
+	if size := len(srv.files[2].data); size != 8192+65536 {
+		t.Errorf("expected the target to be extended to %d bytes, got %d", 8192+65536, size)
+	}
+}
+
+func TestCloneRangeFallback(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 8*1024)
+
+	src, dst, srv := newTestCloneFiles(data)
+	defer srv.tr.Close()
+
+	if err := dst.CloneRange(src, 4096, 100, 100000); err != nil {
+		t.Fatal(err)
+	}
+
+	// neither block cloning nor server-side copy is supported, so the range is read and written
+	if !reflect.DeepEqual(srv.ctlCodes, []uint32{FSCTL_DUPLICATE_EXTENTS_TO_FILE, FSCTL_SRV_REQUEST_RESUME_KEY}) {
+		t.Fatalf("unexpected control codes %#x", srv.ctlCodes)
+	}
+	if !bytes.Equal(srv.files[2].data[100:], data[4096:4096+100000]) {
+		t.Error("unexpected content")
+	}
+
+	// the range ends past the end of the source
+	err := dst.CloneRange(src, int64(len(data))-10, 0, 100)
+	if lerr, ok := err.(*os.LinkError); !ok || lerr.Err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if !bytes.Equal(srv.files[2].data[:10], data[len(data)-10:]) {
+		t.Error("expected the bytes up to the end of the source to be copied")
+	}
+}
+
+// fakeFsctlServer answers FSCTL_QUERY_ALLOCATED_RANGES from ranges, as many as fit in the max output response,
+// overflowing the others with STATUS_BUFFER_OVERFLOW. Other controls fail with status and output
+// (STATUS_BUFFER_TOO_SMALL carrying required as the error data).
+type fakeFsctlServer struct {
+	tr      *fakeTransport
+	ranges  [][2]int64 // offsets and lengths
+	extents [][2]int64 // next VCNs and LCNs, the first extent starting at VCN 0
+
+	status   NtStatus
+	output   []byte
+	required uint32
+
+	inputs [][]byte
+}
+
+func (srv *fakeFsctlServer) handle(req []byte) {
+	q := PacketCodec(req)
+	r := IoctlRequestDecoder(q.Data())
+
+	input := append([]byte{}, req[r.InputOffset():r.InputOffset()+r.InputCount()]...)
+	srv.inputs = append(srv.inputs, input)
+
+	hdr := PacketHeader{
+		Command:               q.Command(),
+		CreditRequestResponse: q.CreditCharge(),
+		Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+		MessageId:             q.MessageId(),
+		TreeId:                q.TreeId(),
+		SessionId:             q.SessionId(),
+	}
+
+	var res Packet
+
+	switch r.CtlCode() {
+	case FSCTL_QUERY_ALLOCATED_RANGES:
+		off := int64(binary.LittleEndian.Uint64(input[:8]))
+		end := off + int64(binary.LittleEndian.Uint64(input[8:16]))
+
+		var output fakeBytes
+		for _, rg := range srv.ranges {
+			if rg[0]+rg[1] <= off || rg[0] >= end {
+				continue
+			}
+			if len(output)+16 > int(r.MaxOutputResponse()) {
+				hdr.Status = uint32(STATUS_BUFFER_OVERFLOW)
+				break
+			}
+			b := make([]byte, 16)
+			binary.LittleEndian.PutUint64(b[:8], uint64(rg[0]))
+			binary.LittleEndian.PutUint64(b[8:], uint64(rg[1]))
+			output = append(output, b...)
+		}
+		res = &IoctlResponse{PacketHeader: hdr, CtlCode: r.CtlCode(), FileId: &FileId{}, Output: output}
+	case FSCTL_GET_RETRIEVAL_POINTERS:
+		if srv.status != STATUS_SUCCESS {
+			hdr.Status = uint32(srv.status)
+			res = &ErrorResponse{PacketHeader: hdr}
+			break
+		}
+
+		start := int64(binary.LittleEndian.Uint64(input[:8]))
+
+		output := make(fakeBytes, 16)
+
+		var vcn int64
+		for _, ext := range srv.extents {
+			if ext[0] <= start {
+				vcn = ext[0]
+				continue
+			}
+			if len(output)+16 > int(r.MaxOutputResponse()) {
+				break
+			}
+			if len(output) == 16 {
+				binary.LittleEndian.PutUint64(output[8:16], uint64(vcn)) // StartingVcn
+			}
+			b := make([]byte, 16)
+			binary.LittleEndian.PutUint64(b[:8], uint64(ext[0]))
+			binary.LittleEndian.PutUint64(b[8:], uint64(ext[1]))
+			output = append(output, b...)
+		}
+		binary.LittleEndian.PutUint32(output[:4], uint32(len(output)/16-1)) // ExtentCount
+
+		// like NTFS, a full output overflows, even if it holds the last extent
+		if len(output)+16 > int(r.MaxOutputResponse()) {
+			hdr.Status = uint32(STATUS_BUFFER_OVERFLOW)
+		}
+
+		if len(output) == 16 {
+			hdr.Status = uint32(STATUS_END_OF_FILE)
+			res = &ErrorResponse{PacketHeader: hdr}
+		} else {
+			res = &IoctlResponse{PacketHeader: hdr, CtlCode: r.CtlCode(), FileId: &FileId{}, Output: output}
+		}
+	default:
+		hdr.Status = uint32(srv.status)
+		switch srv.status {
+		case STATUS_BUFFER_OVERFLOW:
+			res = &IoctlResponse{PacketHeader: hdr, CtlCode: r.CtlCode(), FileId: &FileId{}, Output: fakeBytes(srv.output)}
+		case STATUS_BUFFER_TOO_SMALL:
+			data := make(fakeBytes, 4)
+			binary.LittleEndian.PutUint32(data, srv.required)
+			res = &ErrorResponse{PacketHeader: hdr, ErrorData: data}
+		case STATUS_SUCCESS:
+			res = &IoctlResponse{PacketHeader: hdr, CtlCode: r.CtlCode(), FileId: &FileId{}, Output: fakeBytes(srv.output)}
+		default:
+			res = &ErrorResponse{PacketHeader: hdr}
+		}
+	}
+
+	pkt := make([]byte, res.Size())
+	res.Encode(pkt)
+	PacketCodec(pkt).SetCommand(q.Command())
+	srv.tr.push(pkt)
+}
+
+func TestFsctl(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeFsctlServer{tr: tr, ranges: [][2]int64{{0, 4096}, {65536, 8192}, {1 << 20, 4096}, {1 << 30, 65536}}}
+	tr.handler = srv.handle
+
+	f := &File{fs: newFakeShare(tr), fd: &FileId{}, name: "sparse"}
+
+	// the ranges are queried by 2 per request, and merged
+	input := make([]byte, 16)
+	binary.LittleEndian.PutUint64(input[8:], 1<<31) // Length
+
+	output, err := f.Fsctl(FSCTL_QUERY_ALLOCATED_RANGES, input, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output) != 64 {
+		t.Fatalf("expected 4 ranges, got %d bytes", len(output))
+	}
+	for i, rg := range srv.ranges {
+		if off := int64(binary.LittleEndian.Uint64(output[16*i:])); off != rg[0] {
+			t.Errorf("%d: expected offset %d, got %d", i, rg[0], off)
+		}
+	}
+	if len(srv.inputs) != 2 || binary.LittleEndian.Uint64(srv.inputs[1][:8]) != 65536+8192 {
+		t.Errorf("expected the query to continue from the end of the second range, got inputs %x", srv.inputs)
+	}
+
+	// a partial output is returned with the overflow
+	srv.status = STATUS_BUFFER_OVERFLOW
+	srv.output = []byte("partial")
+
+	output, err = f.Fsctl(FSCTL_PIPE_TRANSCEIVE, []byte("in"), 0)
+	if perr, ok := err.(*os.PathError); !ok {
+		t.Errorf("expected *BufferOverflowError, got %v", err)
+	} else if oerr, ok := perr.Err.(*BufferOverflowError); !ok || oerr.Required != 0 {
+		t.Errorf("expected *BufferOverflowError without a required size, got %v", perr.Err)
+	}
+	if string(output) != "partial" {
+		t.Errorf("unexpected output %q", output)
+	}
+
+	// the required size is returned
+	srv.status = STATUS_BUFFER_TOO_SMALL
+	srv.required = 100000
+
+	_, err = f.Fsctl(FSCTL_SRV_ENUMERATE_SNAPSHOTS, nil, 16)
+	if perr, ok := err.(*os.PathError); !ok {
+		t.Errorf("expected *BufferOverflowError, got %v", err)
+	} else if oerr, ok := perr.Err.(*BufferOverflowError); !ok || oerr.Required != 100000 {
+		t.Errorf("expected *BufferOverflowError requiring 100000 bytes, got %v", perr.Err)
+	}
+
+	// the input can't exceed the max transact size
+	if _, err := f.Fsctl(FSCTL_PIPE_TRANSCEIVE, make([]byte, 64*1024+1), 0); err == nil {
+		t.Error("expected an error for an input exceeding the max transact size")
+	}
+}
+
+func TestRetrievalPointersContinued(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	// more extents than fit in the output of a request
+	var extents [][2]int64
+	for vcn := int64(2); vcn <= 2*5000; vcn += 2 {
+		extents = append(extents, [2]int64{vcn, 100 * vcn})
+	}
+	extents[1][1] = -1 // a sparse run
+
+	srv := &fakeFsctlServer{tr: tr, extents: extents}
+	tr.handler = srv.handle
+
+	f := &File{fs: newFakeShare(tr), fd: &FileId{}, name: "fragmented"}
+
+	got, err := f.RetrievalPointers(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(extents)-1 {
+		t.Fatalf("expected %d extents, got %d", len(extents)-1, len(got))
+	}
+	if got[0] != (Extent{VCN: 2, LCN: -1, Clusters: 2}) || got[1] != (Extent{VCN: 4, LCN: 600, Clusters: 2}) {
+		t.Errorf("unexpected extents %v", got[:2])
+	}
+	if last := got[len(got)-1]; last != (Extent{VCN: 2*5000 - 2, LCN: 100 * 2 * 5000, Clusters: 2}) {
+		t.Errorf("unexpected last extent %v", last)
+	}
+	if len(srv.inputs) != 2 {
+		t.Errorf("expected the extents to be read by 2 requests, got %d", len(srv.inputs))
+	}
+
+	// a continuation past the last extent ends the output, and a start past it has no extents
+	srv.inputs = nil
+	srv.extents = extents[:4095]
+
+	got, err = f.RetrievalPointers(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4095 || len(srv.inputs) != 2 {
+		t.Errorf("expected 4095 extents read by 2 requests, got %d extents by %d", len(got), len(srv.inputs))
+	}
+
+	got, err = f.RetrievalPointers(2 * 4095)
+	if err != nil || got != nil {
+		t.Errorf("expected no extents, got %v, %v", got, err)
+	}
+
+	srv.status = STATUS_NOT_SUPPORTED
+
+	if _, err := f.RetrievalPointers(0); err == nil || err.(*os.PathError).Err != ErrNotSupported {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestSetValidDataLength(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeFsctlServer{tr: tr}
+	tr.handler = srv.handle
+
+	f := &File{fs: newFakeShare(tr), fd: &FileId{}, name: "db"}
+
+	if err := f.SetValidDataLength(1 << 30); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := make([]byte, 8)
+	binary.LittleEndian.PutUint64(expected, 1<<30)
+	if !bytes.Equal(srv.inputs[0], expected) {
+		t.Errorf("unexpected valid data length information %x", srv.inputs[0])
+	}
+
+	for _, tt := range []struct {
+		status NtStatus
+		err    error
+	}{
+		{STATUS_PRIVILEGE_NOT_HELD, ErrPrivilegeNotHeld},
+		{STATUS_INVALID_DEVICE_REQUEST, ErrNotSupported},
+	} {
+		srv.status = tt.status
+
+		err := f.SetValidDataLength(4096)
+		if perr, ok := err.(*os.PathError); !ok || perr.Err != tt.err {
+			t.Errorf("%v: expected %v, got %v", tt.status, tt.err, err)
+		}
+	}
+
+	if err := f.SetValidDataLength(-1); err == nil {
+		t.Error("expected an error for a negative length")
+	}
+	if len(srv.inputs) != 3 {
+		t.Errorf("expected a negative length not to be sent, got %d requests", len(srv.inputs))
+	}
+}
@@ -0,0 +1,313 @@
+// Package fsnotify adapts the change notifications of directories on a share (func (*smb2.File) ChangeNotify)
+// to events shaped like those of the github.com/fsnotify/fsnotify package,
+// so that code watching local directories can watch a remote share with few changes.
+//
+// The names of the events are paths relative to the root of the share with '\\' separators,
+// i.e. the watched directory as cleaned by smb2.CleanPath joined with the name of the changed entry.
+// Like fsnotify, a directory is watched non-recursively.
+package fsnotify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/nodauf/go-smb2"
+)
+
+// Op describes a set of file operations.
+type Op uint32
+
+// The operations of an event, with the bits of fsnotify.
+const (
+	Create Op = 1 << iota
+	Write
+	Remove
+	Rename
+	Chmod
+)
+
+func (op Op) String() string {
+	var ops []string
+	for _, o := range []struct {
+		op   Op
+		name string
+	}{
+		{Create, "CREATE"},
+		{Write, "WRITE"},
+		{Remove, "REMOVE"},
+		{Rename, "RENAME"},
+		{Chmod, "CHMOD"},
+	} {
+		if op&o.op != 0 {
+			ops = append(ops, o.name)
+		}
+	}
+	if len(ops) == 0 {
+		return "[no events]"
+	}
+	return strings.Join(ops, "|")
+}
+
+// Event is a change of an entry of a watched directory.
+type Event struct {
+	Name string // path of the entry relative to the root of the share
+	Op   Op
+}
+
+// Has reports whether the event has op.
+func (e Event) Has(op Op) bool {
+	return e.Op&op != 0
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%s %q", e.Op, e.Name)
+}
+
+var (
+	// ErrNonExistentWatch is returned by Remove for a directory which isn't watched.
+	ErrNonExistentWatch = errors.New("fsnotify: can't remove non-existent watch")
+
+	// ErrEventOverflow is sent to Errors when the server has discarded the changes of a directory,
+	// which must be enumerated again to find out what has changed.
+	ErrEventOverflow = errors.New("fsnotify: queue or buffer overflow")
+
+	// ErrClosed is returned by Add and Remove after Close.
+	ErrClosed = errors.New("fsnotify: watcher already closed")
+)
+
+// the changes of names and contents, and those of attributes and security descriptors,
+// are watched by handles of their own, so that a FILE_ACTION_MODIFIED can be told a Write or a Chmod
+const (
+	contentFilter = smb2.NotifyChangeFileName | smb2.NotifyChangeDirName | smb2.NotifyChangeSize | smb2.NotifyChangeLastWrite
+	attrFilter    = smb2.NotifyChangeAttributes | smb2.NotifyChangeSecurity
+)
+
+var filters = []uint32{contentFilter, attrFilter}
+
+// Watcher watches directories of a share, and sends their changes to Events.
+type Watcher struct {
+	// Events receives the changes of the watched directories.
+	Events chan Event
+
+	// Errors receives the errors of the watches. A watch stops after an error other than ErrEventOverflow.
+	Errors chan error
+
+	fs *smb2.Share
+
+	m       sync.Mutex
+	watches map[string]*watch
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// watch is a watched directory, opened by a handle per filter of filters.
+type watch struct {
+	files []*smb2.File
+	done  chan struct{} // closed by Remove or Close
+}
+
+// NewWatcher returns a watcher of directories of fs, which watches none until Add is called.
+func NewWatcher(fs *smb2.Share) (*Watcher, error) {
+	return &Watcher{
+		Events:  make(chan Event),
+		Errors:  make(chan error),
+		fs:      fs,
+		watches: make(map[string]*watch),
+	}, nil
+}
+
+// Add starts watching the named directory. Adding a watched directory again does nothing.
+func (w *Watcher) Add(name string) error {
+	name, err := smb2.CleanPath(name)
+	if err != nil {
+		return err
+	}
+
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+	if _, ok := w.watches[name]; ok {
+		return nil
+	}
+
+	wt := &watch{done: make(chan struct{})}
+
+	for range filters {
+		f, err := w.fs.Open(name)
+		if err != nil {
+			wt.close()
+			return err
+		}
+		wt.files = append(wt.files, f)
+	}
+
+	fi, err := wt.files[0].Stat()
+	if err != nil {
+		wt.close()
+		return err
+	}
+	if !fi.IsDir() {
+		wt.close()
+		return &os.PathError{Op: "watch", Path: name, Err: errors.New("not a directory")}
+	}
+
+	w.watches[name] = wt
+
+	for i, filter := range filters {
+		w.wg.Add(1)
+		go w.run(name, wt, wt.files[i], filter)
+	}
+
+	return nil
+}
+
+// Remove stops watching the named directory.
+func (w *Watcher) Remove(name string) error {
+	name, err := smb2.CleanPath(name)
+	if err != nil {
+		return err
+	}
+
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+
+	wt, ok := w.watches[name]
+	if !ok {
+		return ErrNonExistentWatch
+	}
+	delete(w.watches, name)
+
+	wt.close()
+
+	return nil
+}
+
+// WatchList returns the watched directories.
+func (w *Watcher) WatchList() []string {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	names := make([]string, 0, len(w.watches))
+	for name := range w.watches {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Close stops watching all the directories, and closes Events and Errors once no more are sent.
+func (w *Watcher) Close() error {
+	w.m.Lock()
+	if w.closed {
+		w.m.Unlock()
+		return nil
+	}
+	w.closed = true
+	for name, wt := range w.watches {
+		delete(w.watches, name)
+		wt.close()
+	}
+	w.m.Unlock()
+
+	w.wg.Wait()
+
+	close(w.Events)
+	close(w.Errors)
+
+	return nil
+}
+
+// close closes the handles of the watch, which unblocks their pending notifications with os.ErrClosed.
+func (wt *watch) close() {
+	select {
+	case <-wt.done:
+		return
+	default:
+		close(wt.done)
+	}
+	for _, f := range wt.files {
+		f.Close()
+	}
+}
+
+// run sends the changes of the directory matching filter until the watch is closed or fails.
+func (w *Watcher) run(dir string, wt *watch, f *smb2.File, filter uint32) {
+	defer w.wg.Done()
+
+	for {
+		changes, err := f.ChangeNotify(filter, false)
+		if err != nil {
+			select {
+			case <-wt.done:
+				return
+			default:
+			}
+
+			if perr, ok := err.(*os.PathError); ok && perr.Err == smb2.ErrNotifyOverflow {
+				err = ErrEventOverflow
+			}
+
+			select {
+			case w.Errors <- err:
+			case <-wt.done:
+				return
+			}
+
+			if err == ErrEventOverflow {
+				continue
+			}
+			return
+		}
+
+		for _, c := range changes {
+			op := actionOp(c.Action, filter == attrFilter)
+			if op == 0 {
+				continue
+			}
+
+			name := c.Name
+			if dir != "" {
+				name = dir + `\` + name
+			}
+
+			select {
+			case w.Events <- Event{Name: name, Op: op}:
+			case <-wt.done:
+				return
+			}
+		}
+	}
+}
+
+// actionOp returns the operation of a FILE_ACTION_* code, or 0 if it has none.
+// Any change reported by the handle watching the attributes is a Chmod.
+// Like fsnotify, the old name of a renamed entry is a Rename, and the new name is a Create.
+func actionOp(action uint32, attrs bool) Op {
+	if attrs {
+		if action == smb2.FileActionModified {
+			return Chmod
+		}
+		return 0
+	}
+
+	switch action {
+	case smb2.FileActionAdded, smb2.FileActionRenamedNewName:
+		return Create
+	case smb2.FileActionRemoved:
+		return Remove
+	case smb2.FileActionModified, smb2.FileActionAddedStream, smb2.FileActionRemovedStream, smb2.FileActionModifiedStream:
+		return Write
+	case smb2.FileActionRenamedOldName:
+		return Rename
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package fsnotify
+
+import (
+	"testing"
+
+	"github.com/nodauf/go-smb2"
+)
+
+func TestActionOp(t *testing.T) {
+	tests := []struct {
+		action uint32
+		attrs  bool
+		op     Op
+	}{
+		{smb2.FileActionAdded, false, Create},
+		{smb2.FileActionRemoved, false, Remove},
+		{smb2.FileActionModified, false, Write},
+		{smb2.FileActionRenamedOldName, false, Rename},
+		{smb2.FileActionRenamedNewName, false, Create},
+		{smb2.FileActionModifiedStream, false, Write},
+		{smb2.FileActionModified, true, Chmod},
+		{smb2.FileActionAdded, true, 0},
+		{0xff, false, 0},
+	}
+
+	for i, tt := range tests {
+		if op := actionOp(tt.action, tt.attrs); op != tt.op {
+			t.Errorf("%d: expected %v, got %v", i, tt.op, op)
+		}
+	}
+}
+
+func TestEvent(t *testing.T) {
+	e := Event{Name: `dir\a.txt`, Op: Create | Write}
+
+	if !e.Has(Create) || !e.Has(Write) || e.Has(Remove) {
+		t.Errorf("unexpected ops of %v", e)
+	}
+	if s := e.String(); s != `CREATE|WRITE "dir\\a.txt"` {
+		t.Errorf("unexpected string %s", s)
+	}
+	if s := Op(0).String(); s != "[no events]" {
+		t.Errorf("unexpected string %s", s)
+	}
+}
@@ -0,0 +1,31 @@
+package smb2
+
+import (
+	"crypto/sha256"
+	"hash"
+)
+
+// Hash writes the content of the named file to h, e.g. to verify a file after an upload without buffering it.
+// The file is read by pipelined reads of the max read size, as many at a time as the credits allow
+// (See func (*File) WriteToWithOptions). h isn't reset, so the content is appended to what it has hashed so far.
+func (fs *Share) Hash(name string, h hash.Hash) error {
+	f, err := fs.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteTo(h)
+	return err
+}
+
+// SHA256 returns the SHA-256 digest of the content of the named file. See func (*Share) Hash for more details.
+func (fs *Share) SHA256(name string) ([]byte, error) {
+	h := sha256.New()
+
+	err := fs.Hash(name, h)
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
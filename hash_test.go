@@ -0,0 +1,57 @@
+package smb2
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+func TestHash(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 8*1024)
+
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE, size: int64(len(data))},
+		},
+	}
+	fileSrv := &fakeFileServer{tr: tr, data: data, maxRead: 64 * 1024}
+	tr.handler = func(req []byte) {
+		if PacketCodec(req).Command() == SMB2_READ {
+			fileSrv.handle(req)
+			return
+		}
+		srv.handle(req)
+	}
+
+	fs := newFakeShare(tr)
+	fs.conn.account.charge(7, 0)
+
+	sum, err := fs.SHA256(`file`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := sha256.Sum256(data); !bytes.Equal(sum, expected[:]) {
+		t.Errorf("unexpected digest %x", sum)
+	}
+
+	// 2 chunks of the max read size, and 8 reads ahead (one per credit) past the end of file
+	if fileSrv.reads != 10 {
+		t.Errorf("expected 10 reads, got %d", fileSrv.reads)
+	}
+
+	if n := atomic.LoadInt32(&fs.session._openFiles); n != 0 {
+		t.Errorf("expected the file to be closed, %d files are open", n)
+	}
+
+	if _, err := fs.SHA256(`missing`); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
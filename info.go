@@ -0,0 +1,134 @@
+package smb2
+
+import (
+	"os"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// Info types of func (*File) QueryInfo and func (*File) SetInfo. (See [MS-SMB2] 2.2.37)
+const (
+	InfoTypeFile       = SMB2_0_INFO_FILE
+	InfoTypeFilesystem = SMB2_0_INFO_FILESYSTEM
+	InfoTypeSecurity   = SMB2_0_INFO_SECURITY
+	InfoTypeQuota      = SMB2_0_INFO_QUOTA
+)
+
+// QueryInfo returns the raw buffer of the information class infoClass (e.g. 16 for FileModeInformation, see [MS-FSCC] 2.4)
+// of the info type infoType (e.g. InfoTypeFile), so that the classes which aren't wrapped by the package can be queried.
+// additional is the AdditionalInformation of the request, such as the parts of a security descriptor to return.
+// maxLen is the length of the output buffer, or the max transact size if it isn't positive.
+// If the information doesn't fit in maxLen, the response error of STATUS_BUFFER_OVERFLOW or STATUS_BUFFER_TOO_SMALL is returned.
+func (f *File) QueryInfo(infoType, infoClass uint8, additional uint32, maxLen int) ([]byte, error) {
+	if maxLen <= 0 {
+		maxLen = f.maxTransactSize()
+	}
+
+	var infoBytes []byte
+	err := f.fs.retry(true, func() (err error) {
+		infoBytes, err = f.queryInfoOnce(&QueryInfoRequest{
+			InfoType:              infoType,
+			FileInfoClass:         infoClass,
+			AdditionalInformation: additional,
+			Flags:                 0,
+			OutputBufferLength:    uint32(maxLen),
+		})
+		return
+	})
+	if err != nil {
+		return nil, &os.PathError{Op: "queryinfo", Path: f.name, Err: err}
+	}
+
+	return append([]byte{}, infoBytes...), nil
+}
+
+// SetInfo sets the information class infoClass of the info type infoType to the raw buffer buf,
+// so that the classes which aren't wrapped by the package can be set.
+func (f *File) SetInfo(infoType, infoClass uint8, buf []byte) error {
+	err := f.fs.retry(false, func() error {
+		return f.setInfo(&SetInfoRequest{
+			InfoType:              infoType,
+			FileInfoClass:         infoClass,
+			AdditionalInformation: 0,
+			Input:                 infoBuffer(buf),
+		})
+	})
+	if err != nil {
+		return &os.PathError{Op: "setinfo", Path: f.name, Err: err}
+	}
+	return nil
+}
+
+// infoBuffer is a raw buffer set by func (*File) SetInfo, or sent by func (*File) Fsctl.
+type infoBuffer []byte
+
+func (b infoBuffer) Size() int {
+	return len(b)
+}
+
+func (b infoBuffer) Encode(p []byte) {
+	copy(p, b)
+}
+
+// SetCaseSensitive flags the named directory case-sensitive or not by FileCaseSensitiveInformation,
+// like `fsutil file setCaseSensitiveInfo` on Windows 10 1803 or later.
+// The names of a case-sensitive directory which differ only in case refer to different files.
+// If the server or the underlying file system doesn't support it, ErrNotSupported is returned.
+func (fs *Share) SetCaseSensitive(dir string, on bool) error {
+	return fs.retry(false, func() error {
+		return fs.setCaseSensitive(dir, on)
+	})
+}
+
+func (fs *Share) setCaseSensitive(dir string, on bool) error {
+	dir, err := cleanPath("setcasesensitive", dir)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.checkDiskShare("setcasesensitive", dir); err != nil {
+		return err
+	}
+
+	create := &CreateRequest{
+		SecurityFlags:        0,
+		RequestedOplockLevel: SMB2_OPLOCK_LEVEL_NONE,
+		ImpersonationLevel:   Impersonation,
+		SmbCreateFlags:       0,
+		DesiredAccess:        FILE_WRITE_ATTRIBUTES,
+		FileAttributes:       FILE_ATTRIBUTE_NORMAL,
+		ShareAccess:          FILE_SHARE_READ | FILE_SHARE_WRITE,
+		CreateDisposition:    FILE_OPEN,
+		CreateOptions:        FILE_DIRECTORY_FILE,
+	}
+
+	f, err := fs.createFile(dir, create, true)
+	if err != nil {
+		return &os.PathError{Op: "setcasesensitive", Path: dir, Err: err}
+	}
+
+	var flags uint32
+	if on {
+		flags = FILE_CS_FLAG_CASE_SENSITIVE_DIR
+	}
+
+	err = f.setInfo(&SetInfoRequest{
+		FileInfoClass:         FileCaseSensitiveInformation,
+		AdditionalInformation: 0,
+		Input:                 &FileCaseSensitiveInformationEncoder{Flags: flags},
+	})
+	if rerr, ok := err.(*ResponseError); ok {
+		switch NtStatus(rerr.Code) {
+		case STATUS_INVALID_INFO_CLASS, STATUS_NOT_SUPPORTED:
+			err = ErrNotSupported
+		}
+	}
+	if e := f.close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return &os.PathError{Op: "setcasesensitive", Path: dir, Err: err}
+	}
+	return nil
+}
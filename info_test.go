@@ -0,0 +1,90 @@
+package smb2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"reflect"
+	"testing"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+func TestQuerySetInfo(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE, tag: IO_REPARSE_TAG_SYMLINK},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	f, err := fs.OpenFile("file", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	info, err := f.QueryInfo(InfoTypeFile, FileAttributeTagInformation, 0, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info) != 8 {
+		t.Fatalf("expected 8 bytes, got %d", len(info))
+	}
+	if attrs := binary.LittleEndian.Uint32(info[:4]); attrs != FILE_ATTRIBUTE_ARCHIVE {
+		t.Errorf("attributes: expected %x, got %x", FILE_ATTRIBUTE_ARCHIVE, attrs)
+	}
+	if tag := binary.LittleEndian.Uint32(info[4:]); tag != IO_REPARSE_TAG_SYMLINK {
+		t.Errorf("reparse tag: expected %x, got %x", IO_REPARSE_TAG_SYMLINK, tag)
+	}
+
+	basic := make([]byte, 40)
+	binary.LittleEndian.PutUint64(basic[:8], 0x0123456789abcdef)
+
+	if err := f.SetInfo(InfoTypeFile, FileBasicInformation, basic); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(srv.entries[`file`].basic, basic) {
+		t.Errorf("expected %x to be set, got %x", basic, srv.entries[`file`].basic)
+	}
+
+	if err := f.SetInfo(InfoTypeFile, FileModeInformation, make([]byte, 4)); err == nil {
+		t.Error("expected the unsupported class to fail")
+	}
+}
+
+func TestSetCaseSensitiveNotSupported(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`dir`:  {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+
+	err := fs.SetCaseSensitive("dir", true)
+	if e, ok := err.(*os.PathError); !ok || e.Err != ErrNotSupported {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+
+	if err := fs.SetCaseSensitive("file", true); err == nil || err.(*os.PathError).Err == ErrNotSupported {
+		t.Errorf("expected a not a directory error, got %v", err)
+	}
+
+	expected := []uint16{SMB2_CREATE, SMB2_SET_INFO, SMB2_CLOSE, SMB2_CREATE}
+	if !reflect.DeepEqual(srv.cmds, expected) {
+		t.Errorf("expected commands %v, got %v", expected, srv.cmds)
+	}
+}
@@ -25,6 +25,15 @@ type NTLMInitiator struct {
 	Workstation string
 	TargetSPN   string
 
+	// ComputeResponse delegates the computation of the NTLMv2 response, e.g. to a separate security service
+	// holding the credentials, in which case Password and Hash are ignored. If it's nil, the response is computed
+	// from Password or Hash.
+	// challenge is the 8-byte server challenge, and targetInfo the AV pairs to include in the NTLMv2 client challenge,
+	// which already have the timestamp, the MIC flag and the target SPN.
+	// It returns the whole NtChallengeResponse (the NTProofStr followed by the client challenge)
+	// and the 16-byte session base key, from which the keys of the session are derived.
+	ComputeResponse func(challenge, targetInfo []byte) (ntResponse, sessionKey []byte, err error)
+
 	ntlm   *ntlm.Client
 	seqNum uint32
 }
@@ -48,6 +57,8 @@ func (i *NTLMInitiator) initSecContext() ([]byte, error) {
 		Domain:      i.Domain,
 		Workstation: i.Workstation,
 		TargetSPN:   i.TargetSPN,
+
+		ComputeResponse: i.ComputeResponse,
 	}
 	nmsg, err := i.ntlm.Negotiate()
 	if err != nil {
@@ -73,7 +84,11 @@ func (i *NTLMInitiator) sessionKey() []byte {
 	return i.ntlm.Session().SessionKey()
 }
 
+// infoMap returns the target information of the challenge, or nil if the authentication hasn't gone that far.
 func (i *NTLMInitiator) infoMap() *ntlm.InfoMap {
+	if i.ntlm == nil || i.ntlm.Session() == nil {
+		return nil
+	}
 	return i.ntlm.Session().InfoMap()
 }
 
@@ -48,6 +48,10 @@ func main() {
 	fmt.Println("\treturn ntStatusStrings[e]")
 	fmt.Println("}")
 
+	fmt.Println("func (e NtStatus) Name() string {")
+	fmt.Println("\treturn ntStatusNames[e]")
+	fmt.Println("}")
+
 	fmt.Println("const (")
 	for _, e := range entries {
 		fmt.Printf("\t%s\tNtStatus\t=\t%s\n", e.key, e.val)
@@ -63,4 +67,14 @@ func main() {
 		m[e.val] = true
 	}
 	fmt.Println("}")
+
+	fmt.Println("var ntStatusNames = map[NtStatus]string{")
+	m = make(map[string]bool)
+	for _, e := range entries {
+		if !m[e.val] {
+			fmt.Printf("\t%s:\t%q,\n", e.key, e.key)
+		}
+		m[e.val] = true
+	}
+	fmt.Println("}")
 }
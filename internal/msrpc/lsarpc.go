@@ -0,0 +1,261 @@
+package msrpc
+
+// LSARPC (See [MS-LSAT])
+
+const (
+	OP_LSAR_CLOSE         = 0
+	OP_LSAR_LOOKUP_NAMES  = 14
+	OP_LSAR_LOOKUP_SIDS   = 15
+	OP_LSAR_OPEN_POLICY_2 = 44
+)
+
+const (
+	POLICY_LOOKUP_NAMES = 0x00000800
+	MAXIMUM_ALLOWED     = 0x02000000
+)
+
+const (
+	LsapLookupWksta = 1
+)
+
+type LsarOpenPolicy2Request struct {
+	DesiredAccess uint32
+}
+
+func (r *LsarOpenPolicy2Request) Marshal() []byte {
+	var e ndrEncoder
+
+	e.pointer(false) // SystemName
+
+	// ObjectAttributes
+	e.uint32(24)     // Length
+	e.pointer(false) // RootDirectory
+	e.pointer(false) // ObjectName
+	e.uint32(0)      // Attributes
+	e.pointer(false) // SecurityDescriptor
+	e.pointer(false) // SecurityQualityOfService
+
+	e.uint32(r.DesiredAccess)
+
+	return e.b
+}
+
+type LsarOpenPolicy2Response struct {
+	PolicyHandle []byte
+	Status       uint32
+}
+
+func (r *LsarOpenPolicy2Response) Unmarshal(b []byte) bool {
+	d := ndrDecoder{b: b}
+
+	r.PolicyHandle = d.bytes(20)
+	r.Status = d.uint32()
+
+	return !d.err
+}
+
+type LsarCloseRequest struct {
+	PolicyHandle []byte
+}
+
+func (r *LsarCloseRequest) Marshal() []byte {
+	var e ndrEncoder
+
+	e.bytes(r.PolicyHandle)
+
+	return e.b
+}
+
+type LsarCloseResponse struct {
+	Status uint32
+}
+
+func (r *LsarCloseResponse) Unmarshal(b []byte) bool {
+	d := ndrDecoder{b: b}
+
+	d.bytes(20) // PolicyHandle
+	r.Status = d.uint32()
+
+	return !d.err
+}
+
+type LsarLookupSidsRequest struct {
+	PolicyHandle []byte
+	Sids         [][]byte // on-wire format
+	LookupLevel  uint16
+}
+
+func (r *LsarLookupSidsRequest) Marshal() []byte {
+	var e ndrEncoder
+
+	e.bytes(r.PolicyHandle)
+
+	// SidEnumBuffer
+	e.uint32(uint32(len(r.Sids))) // Entries
+	e.pointer(len(r.Sids) != 0)   // SidInfo
+	if len(r.Sids) != 0 {
+		e.uint32(uint32(len(r.Sids))) // max count
+		for range r.Sids {
+			e.pointer(true) // Sid
+		}
+		for _, sid := range r.Sids {
+			e.sid(sid)
+		}
+	}
+
+	// TranslatedNames
+	e.uint32(0)      // Entries
+	e.pointer(false) // Names
+
+	e.uint16(r.LookupLevel)
+	e.uint32(0) // MappedCount
+
+	return e.b
+}
+
+type LsaTrustInformation struct {
+	Name string
+	Sid  []byte // on-wire format
+}
+
+type LsaTranslatedName struct {
+	Use         uint16
+	Name        string
+	DomainIndex int32
+}
+
+type LsarLookupSidsResponse struct {
+	ReferencedDomains []LsaTrustInformation
+	TranslatedNames   []LsaTranslatedName
+	MappedCount       uint32
+	Status            uint32
+}
+
+func (r *LsarLookupSidsResponse) Unmarshal(b []byte) bool {
+	d := ndrDecoder{b: b}
+
+	r.ReferencedDomains = d.referencedDomains()
+
+	// TranslatedNames
+	d.uint32()           // Entries
+	if d.uint32() != 0 { // Names
+		n := d.count(16) // max count
+		r.TranslatedNames = make([]LsaTranslatedName, n)
+		hasName := make([]bool, n)
+		for i := range r.TranslatedNames {
+			r.TranslatedNames[i].Use = d.uint16()
+			hasName[i] = d.unicodeString()
+			r.TranslatedNames[i].DomainIndex = int32(d.uint32())
+		}
+		for i := range r.TranslatedNames {
+			if hasName[i] {
+				r.TranslatedNames[i].Name = d.unicodeStringBuffer()
+			}
+		}
+	}
+
+	r.MappedCount = d.uint32()
+	r.Status = d.uint32()
+
+	return !d.err
+}
+
+type LsarLookupNamesRequest struct {
+	PolicyHandle []byte
+	Names        []string
+	LookupLevel  uint16
+}
+
+func (r *LsarLookupNamesRequest) Marshal() []byte {
+	var e ndrEncoder
+
+	e.bytes(r.PolicyHandle)
+
+	e.uint32(uint32(len(r.Names))) // Count
+
+	// Names
+	e.uint32(uint32(len(r.Names))) // max count
+	for _, name := range r.Names {
+		e.unicodeString(name)
+	}
+	for _, name := range r.Names {
+		e.unicodeStringBuffer(name)
+	}
+
+	// TranslatedSids
+	e.uint32(0)      // Entries
+	e.pointer(false) // Sids
+
+	e.uint16(r.LookupLevel)
+	e.uint32(0) // MappedCount
+
+	return e.b
+}
+
+type LsaTranslatedSid struct {
+	Use         uint16
+	RelativeId  uint32
+	DomainIndex int32
+}
+
+type LsarLookupNamesResponse struct {
+	ReferencedDomains []LsaTrustInformation
+	TranslatedSids    []LsaTranslatedSid
+	MappedCount       uint32
+	Status            uint32
+}
+
+func (r *LsarLookupNamesResponse) Unmarshal(b []byte) bool {
+	d := ndrDecoder{b: b}
+
+	r.ReferencedDomains = d.referencedDomains()
+
+	// TranslatedSids
+	d.uint32()           // Entries
+	if d.uint32() != 0 { // Sids
+		n := d.count(12) // max count
+		r.TranslatedSids = make([]LsaTranslatedSid, n)
+		for i := range r.TranslatedSids {
+			r.TranslatedSids[i].Use = d.uint16()
+			r.TranslatedSids[i].RelativeId = d.uint32()
+			r.TranslatedSids[i].DomainIndex = int32(d.uint32())
+		}
+	}
+
+	r.MappedCount = d.uint32()
+	r.Status = d.uint32()
+
+	return !d.err
+}
+
+// referencedDomains decodes a pointer to LSAPR_REFERENCED_DOMAIN_LIST.
+func (d *ndrDecoder) referencedDomains() []LsaTrustInformation {
+	if d.uint32() == 0 { // ReferencedDomains
+		return nil
+	}
+
+	d.uint32()           // Entries
+	if d.uint32() == 0 { // Domains
+		d.uint32() // MaxEntries
+		return nil
+	}
+	d.uint32() // MaxEntries
+
+	n := d.count(12) // max count
+	domains := make([]LsaTrustInformation, n)
+	hasName := make([]bool, n)
+	hasSid := make([]bool, n)
+	for i := range domains {
+		hasName[i] = d.unicodeString()
+		hasSid[i] = d.uint32() != 0
+	}
+	for i := range domains {
+		if hasName[i] {
+			domains[i].Name = d.unicodeStringBuffer()
+		}
+		if hasSid[i] {
+			domains[i].Sid = d.sid()
+		}
+	}
+	return domains
+}
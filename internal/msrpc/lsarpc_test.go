@@ -0,0 +1,225 @@
+package msrpc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+var (
+	testPolicyHandle = bytes.Repeat([]byte{0xaa}, 20)
+	testAdminsSid    = []byte{1, 2, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0, 32, 2, 0, 0} // S-1-5-32-544
+	testBuiltinSid   = []byte{1, 1, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0}              // S-1-5-32
+)
+
+func TestLsarOpenPolicy2(t *testing.T) {
+	req := &LsarOpenPolicy2Request{DesiredAccess: POLICY_LOOKUP_NAMES}
+
+	expected := unhex(t, ""+
+		"00000000"+ // SystemName
+		"18000000 00000000 00000000 00000000 00000000 00000000"+ // ObjectAttributes
+		"00080000") // DesiredAccess
+
+	if b := req.Marshal(); !bytes.Equal(b, expected) {
+		t.Errorf("expected %x, got %x", expected, b)
+	}
+
+	var res LsarOpenPolicy2Response
+	if !res.Unmarshal(append(append([]byte{}, testPolicyHandle...), unhex(t, "22000000")...)) {
+		t.Fatal("broken response")
+	}
+	if !bytes.Equal(res.PolicyHandle, testPolicyHandle) || res.Status != 0x22 {
+		t.Errorf("unexpected handle %x, status %#x", res.PolicyHandle, res.Status)
+	}
+
+	if res.Unmarshal(testPolicyHandle) {
+		t.Error("expected an error of a truncated response")
+	}
+}
+
+func TestLsarLookupSidsRequest(t *testing.T) {
+	req := &LsarLookupSidsRequest{
+		PolicyHandle: testPolicyHandle,
+		Sids:         [][]byte{testAdminsSid, testBuiltinSid},
+		LookupLevel:  LsapLookupWksta,
+	}
+
+	expected := append(append([]byte{}, testPolicyHandle...), unhex(t, ""+
+		"02000000 04000200"+ // Entries, SidInfo
+		"02000000 08000200 0c000200"+ // max count, Sid pointers
+		"02000000 01020000000000052000000020020000"+ // Sid
+		"01000000 010100000000000520000000"+ // Sid
+		"00000000 00000000"+ // TranslatedNames
+		"0100 0000 00000000")...) // LookupLevel, MappedCount
+
+	if b := req.Marshal(); !bytes.Equal(b, expected) {
+		t.Errorf("expected %x, got %x", expected, b)
+	}
+
+	// no SidInfo array follows a null pointer
+	req.Sids = nil
+
+	expected = append(append([]byte{}, testPolicyHandle...), unhex(t, ""+
+		"00000000 00000000"+
+		"00000000 00000000"+
+		"0100 0000 00000000")...)
+
+	if b := req.Marshal(); !bytes.Equal(b, expected) {
+		t.Errorf("expected %x, got %x", expected, b)
+	}
+}
+
+func TestLsarLookupSidsResponse(t *testing.T) {
+	b := unhex(t, ""+
+		"00000200"+ // ReferencedDomains
+		"01000000 04000200 20000000"+ // Entries, Domains, MaxEntries
+		"01000000"+ // max count
+		"0e00 1000 08000200 0c000200"+ // Name, Sid
+		"08000000 00000000 07000000 4200550049004c00540049004e00"+ // "BUILTIN"
+		"0000 01000000 010100000000000520000000"+ // Sid
+		"02000000 10000200"+ // TranslatedNames: Entries, Names
+		"02000000"+ // max count
+		"0400 0000 1c00 1c00 14000200 00000000"+ // Use, Name, DomainIndex
+		"0800 0000 0000 0000 00000000 ffffffff"+ // an unknown sid without a name
+		"0e000000 00000000 0e000000 410064006d0069006e006900730074007200610074006f0072007300"+ // "Administrators"
+		"01000000 07010000") // MappedCount, Status
+
+	var res LsarLookupSidsResponse
+	if !res.Unmarshal(b) {
+		t.Fatal("broken response")
+	}
+
+	expected := LsarLookupSidsResponse{
+		ReferencedDomains: []LsaTrustInformation{{Name: "BUILTIN", Sid: testBuiltinSid}},
+		TranslatedNames: []LsaTranslatedName{
+			{Use: 4, Name: "Administrators", DomainIndex: 0},
+			{Use: 8, DomainIndex: -1},
+		},
+		MappedCount: 1,
+		Status:      0x107, // STATUS_SOME_NOT_MAPPED
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected %+v, got %+v", expected, res)
+	}
+
+	// a response without referenced domains and names
+	res = LsarLookupSidsResponse{}
+	if !res.Unmarshal(unhex(t, "00000000 00000000 00000000 00000000 730000c0")) {
+		t.Fatal("broken response")
+	}
+	if res.ReferencedDomains != nil || res.TranslatedNames != nil || res.Status != 0xc0000073 {
+		t.Errorf("unexpected response %+v", res)
+	}
+
+	for i := 0; i < len(b); i += 4 {
+		if res.Unmarshal(b[:i]) {
+			t.Errorf("expected an error of the response truncated to %d bytes", i)
+		}
+	}
+}
+
+func TestLsarLookupSidsResponseBroken(t *testing.T) {
+	tests := []struct {
+		name string
+		b    string
+	}{
+		{
+			name: "names count exceeding the response",
+			b:    "00000000 01000000 04000200 ffffff00 00000000 00000000",
+		},
+		{
+			name: "domains count exceeding the response",
+			b:    "00000200 01000000 04000200 20000000 10000000 00000000 00000000",
+		},
+		{
+			name: "name offset",
+			b: "00000000" +
+				"01000000 04000200 01000000" +
+				"0400 0000 0200 0200 08000200 00000000" +
+				"01000000 01000000 01000000 4100" +
+				"0000 01000000 00000000",
+		},
+		{
+			name: "domain sid count",
+			b: "00000200" +
+				"01000000 04000200 20000000" +
+				"01000000" +
+				"0000 0000 00000000 08000200" +
+				"02000000 010100000000000520000000 00000000" +
+				"00000000 00000000 00000000 00000000",
+		},
+	}
+
+	for _, tt := range tests {
+		var res LsarLookupSidsResponse
+		if res.Unmarshal(unhex(t, tt.b)) {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestLsarLookupNamesRequest(t *testing.T) {
+	req := &LsarLookupNamesRequest{
+		PolicyHandle: testPolicyHandle,
+		Names:        []string{`BUILTIN\Users`, ""},
+		LookupLevel:  LsapLookupWksta,
+	}
+
+	expected := append(append([]byte{}, testPolicyHandle...), unhex(t, ""+
+		"02000000"+ // Count
+		"02000000"+ // max count
+		"1a00 1a00 04000200"+ // Names
+		"0000 0000 00000000"+ // an empty name has a null buffer
+		"0d000000 00000000 0d000000 4200550049004c00540049004e005c00550073006500720073000000"+ // "BUILTIN\Users", padded
+		"00000000 00000000"+ // TranslatedSids
+		"0100 0000 00000000")...) // LookupLevel, MappedCount
+
+	if b := req.Marshal(); !bytes.Equal(b, expected) {
+		t.Errorf("expected %x, got %x", expected, b)
+	}
+}
+
+func TestLsarLookupNamesResponse(t *testing.T) {
+	b := unhex(t, ""+
+		"00000200"+ // ReferencedDomains
+		"01000000 04000200 20000000"+ // Entries, Domains, MaxEntries
+		"01000000"+ // max count
+		"0e00 1000 08000200 0c000200"+ // Name, Sid
+		"08000000 00000000 07000000 4200550049004c00540049004e00"+ // "BUILTIN"
+		"0000 01000000 010100000000000520000000"+ // Sid
+		"02000000 10000200"+ // TranslatedSids: Entries, Sids
+		"02000000"+ // max count
+		"0400 0000 21020000 00000000"+ // Use, RelativeId, DomainIndex
+		"0800 0000 00000000 ffffffff"+ // an unknown name
+		"01000000 07010000") // MappedCount, Status
+
+	var res LsarLookupNamesResponse
+	if !res.Unmarshal(b) {
+		t.Fatal("broken response")
+	}
+
+	expected := LsarLookupNamesResponse{
+		ReferencedDomains: []LsaTrustInformation{{Name: "BUILTIN", Sid: testBuiltinSid}},
+		TranslatedSids: []LsaTranslatedSid{
+			{Use: 4, RelativeId: 545, DomainIndex: 0},
+			{Use: 8, DomainIndex: -1},
+		},
+		MappedCount: 1,
+		Status:      0x107,
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected %+v, got %+v", expected, res)
+	}
+
+	for i := 0; i < len(b); i += 4 {
+		if res.Unmarshal(b[:i]) {
+			t.Errorf("expected an error of the response truncated to %d bytes", i)
+		}
+	}
+
+	// the count of the sids exceeds the response
+	res = LsarLookupNamesResponse{}
+	if res.Unmarshal(unhex(t, "00000000 01000000 04000200 00010000 00000000 00000000")) {
+		t.Error("expected an error of a broken count")
+	}
+}
@@ -25,9 +25,14 @@ const (
 	RPC_PACKET_FLAG_FIRST = 0x01
 	RPC_PACKET_FLAG_LAST  = 0x02
 
+	RPC_TYPE_FAULT = 3
+
 	SRVSVC_VERSION       = 3
 	SRVSVC_VERSION_MINOR = 0
 
+	LSARPC_VERSION       = 0
+	LSARPC_VERSION_MINOR = 0
+
 	NDR_VERSION = 2
 
 	OP_NET_SHARE_ENUM = 15
@@ -35,11 +40,16 @@ const (
 
 var (
 	SRVSVC_UUID = []byte("c84f324b7016d30112785a47bf6ee188")
+	LSARPC_UUID = []byte("785734123412cdabef000123456789ab")
 	NDR_UUID    = []byte("045d888aeb1cc9119fe808002b104860")
 )
 
+// Bind binds the interface identified by InterfaceId (hex encoded UUID) and its version.
 type Bind struct {
-	CallId uint32
+	CallId       uint32
+	InterfaceId  []byte
+	Version      uint16
+	VersionMinor uint16
 }
 
 func (r *Bind) Size() int {
@@ -68,9 +78,9 @@ func (r *Bind) Encode(b []byte) {
 	le.PutUint16(b[28:30], 0)        // ctx item[1] .context id
 	le.PutUint16(b[30:32], 1)        // ctx item[1] .num trans items
 
-	hex.Decode(b[32:48], SRVSVC_UUID)
-	le.PutUint16(b[48:50], SRVSVC_VERSION)
-	le.PutUint16(b[50:52], SRVSVC_VERSION_MINOR)
+	hex.Decode(b[32:48], r.InterfaceId)
+	le.PutUint16(b[48:50], r.Version)
+	le.PutUint16(b[50:52], r.VersionMinor)
 
 	hex.Decode(b[52:68], NDR_UUID)
 	le.PutUint32(b[68:72], NDR_VERSION)
@@ -138,6 +148,136 @@ func (c BindAckDecoder) AssocGroupId() uint32 {
 	return le.Uint32(c[20:24])
 }
 
+// Result returns the result of the first presentation context.
+// It returns false if the bind ack is too short to contain one.
+func (c BindAckDecoder) Result() (uint16, bool) {
+	if len(c) < 26 {
+		return 0, false
+	}
+	off := roundup(26+int(le.Uint16(c[24:26])), 4) // secondary address
+	if len(c) < off+8 || c[off] == 0 {             // num results
+		return 0, false
+	}
+	return le.Uint16(c[off+4 : off+6]), true
+}
+
+// Request is a request PDU carrying an already marshaled stub.
+// Flags and AllocHint are set by the caller when the stub is fragmented.
+type Request struct {
+	CallId    uint32
+	Flags     uint8
+	AllocHint uint32
+	Opnum     uint16
+	Stub      []byte
+}
+
+func (r *Request) Size() int {
+	return 24 + len(r.Stub)
+}
+
+func (r *Request) Encode(b []byte) {
+	b[0] = RPC_VERSION
+	b[1] = RPC_VERSION_MINOR
+	b[2] = RPC_TYPE_REQUEST
+	b[3] = r.Flags
+
+	// order = Little-Endian, float = IEEE, char = ASCII
+	b[4] = 0x10
+	b[5] = 0
+	b[6] = 0
+	b[7] = 0
+
+	le.PutUint16(b[8:10], uint16(24+len(r.Stub))) // frag length
+	le.PutUint16(b[10:12], 0)                     // auth length
+	le.PutUint32(b[12:16], r.CallId)              // call id
+	le.PutUint32(b[16:20], r.AllocHint)           // alloc hint
+	le.PutUint16(b[20:22], 0)                     // context id
+	le.PutUint16(b[22:24], r.Opnum)               // opnum
+
+	copy(b[24:], r.Stub)
+}
+
+// ResponseDecoder decodes a response or fault PDU.
+type ResponseDecoder []byte
+
+func (c ResponseDecoder) IsInvalid() bool {
+	if len(c) < 24 {
+		return true
+	}
+	if c.Version() != RPC_VERSION {
+		return true
+	}
+	if c.VersionMinor() != RPC_VERSION_MINOR {
+		return true
+	}
+	switch c.PacketType() {
+	case RPC_TYPE_RESPONSE:
+	case RPC_TYPE_FAULT:
+		if len(c) < 28 {
+			return true
+		}
+	default:
+		return true
+	}
+	if int(c.FragLength()) < 24 {
+		return true
+	}
+	return false
+}
+
+// IsIncomplete reports whether the whole fragment hasn't been received yet.
+func (c ResponseDecoder) IsIncomplete() bool {
+	return len(c) < int(c.FragLength())
+}
+
+func (c ResponseDecoder) Version() uint8 {
+	return c[0]
+}
+
+func (c ResponseDecoder) VersionMinor() uint8 {
+	return c[1]
+}
+
+func (c ResponseDecoder) PacketType() uint8 {
+	return c[2]
+}
+
+func (c ResponseDecoder) PacketFlags() uint8 {
+	return c[3]
+}
+
+func (c ResponseDecoder) FragLength() uint16 {
+	return le.Uint16(c[8:10])
+}
+
+func (c ResponseDecoder) AuthLength() uint16 {
+	return le.Uint16(c[10:12])
+}
+
+func (c ResponseDecoder) CallId() uint32 {
+	return le.Uint32(c[12:16])
+}
+
+func (c ResponseDecoder) AllocHint() uint32 {
+	return le.Uint32(c[16:20])
+}
+
+// FaultStatus returns the status code of a fault PDU.
+func (c ResponseDecoder) FaultStatus() uint32 {
+	return le.Uint32(c[24:28])
+}
+
+func (c ResponseDecoder) Stub() []byte {
+	end := int(c.FragLength())
+	if c.AuthLength() != 0 {
+		end -= 8 + int(c.AuthLength())
+	}
+	if end < 24 {
+		return nil
+	}
+	return c[24:end]
+}
+
 type NetShareEnumAllRequest struct {
 	CallId     uint32
 	ServerName string
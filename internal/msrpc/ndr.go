@@ -0,0 +1,166 @@
+package msrpc
+
+import (
+	"github.com/nodauf/go-smb2/internal/utf16le"
+)
+
+// ndrEncoder builds a NDR (transfer syntax version 2) stub.
+// Embedded pointers are encoded as unique pointers with non-zero referent IDs.
+type ndrEncoder struct {
+	b   []byte
+	ref uint32
+}
+
+func (e *ndrEncoder) align(n int) {
+	for len(e.b)%n != 0 {
+		e.b = append(e.b, 0)
+	}
+}
+
+func (e *ndrEncoder) uint16(v uint16) {
+	e.align(2)
+	e.b = append(e.b, byte(v), byte(v>>8))
+}
+
+func (e *ndrEncoder) uint32(v uint32) {
+	e.align(4)
+	e.b = append(e.b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func (e *ndrEncoder) bytes(p []byte) {
+	e.b = append(e.b, p...)
+}
+
+func (e *ndrEncoder) pointer(nonNull bool) {
+	if !nonNull {
+		e.uint32(0)
+		return
+	}
+	e.ref += 4
+	e.uint32(0x20000 + e.ref)
+}
+
+// unicodeString encodes the header part of RPC_UNICODE_STRING.
+// The buffer must be encoded by unicodeStringBuffer later.
+func (e *ndrEncoder) unicodeString(s string) {
+	n := utf16le.EncodedStringLen(s)
+	e.align(4)          // the structure is aligned by its pointer
+	e.uint16(uint16(n)) // Length
+	e.uint16(uint16(n)) // MaximumLength
+	e.pointer(n != 0)   // Buffer
+}
+
+func (e *ndrEncoder) unicodeStringBuffer(s string) {
+	n := utf16le.EncodedStringLen(s)
+	if n == 0 {
+		return
+	}
+	e.uint32(uint32(n / 2)) // max count
+	e.uint32(0)             // offset
+	e.uint32(uint32(n / 2)) // actual count
+	e.bytes(utf16le.EncodeStringToBytes(s))
+}
+
+// wideString encodes a null-terminated string of a [string] wchar_t* pointer.
+func (e *ndrEncoder) wideString(s string) {
+	n := utf16le.EncodedStringLen(s)/2 + 1
+	e.uint32(uint32(n)) // max count
+	e.uint32(0)         // offset
+	e.uint32(uint32(n)) // actual count
+	e.bytes(utf16le.EncodeStringToBytes(s))
+	e.uint16(0)
+}
+
+// sid encodes a RPC_SID given in the on-wire format.
+func (e *ndrEncoder) sid(sid []byte) {
+	e.uint32(uint32(sid[1])) // max count (SubAuthorityCount)
+	e.bytes(sid)
+}
+
+// ndrDecoder parses a NDR stub. Once it hits the end of the buffer,
+// all subsequent reads return zero values and err is set.
+type ndrDecoder struct {
+	b   []byte
+	off int
+	err bool
+}
+
+func (d *ndrDecoder) align(n int) {
+	d.off = roundup(d.off, n)
+}
+
+func (d *ndrDecoder) bytes(n int) []byte {
+	if d.err || n < 0 || len(d.b) < d.off+n {
+		d.err = true
+		return nil
+	}
+	bs := d.b[d.off : d.off+n]
+	d.off += n
+	return bs
+}
+
+func (d *ndrDecoder) uint16() uint16 {
+	d.align(2)
+	bs := d.bytes(2)
+	if bs == nil {
+		return 0
+	}
+	return le.Uint16(bs)
+}
+
+func (d *ndrDecoder) uint32() uint32 {
+	d.align(4)
+	bs := d.bytes(4)
+	if bs == nil {
+		return 0
+	}
+	return le.Uint32(bs)
+}
+
+// count reads a conformance or variance value and checks that
+// at least size*count bytes could follow.
+func (d *ndrDecoder) count(size int) int {
+	n := int(d.uint32())
+	if n < 0 || len(d.b)-d.off < n*size {
+		d.err = true
+		return 0
+	}
+	return n
+}
+
+// unicodeString decodes the header part of RPC_UNICODE_STRING.
+// It returns whether the buffer pointer is non-null.
+func (d *ndrDecoder) unicodeString() bool {
+	d.align(4)             // the structure is aligned by its pointer
+	d.uint16()             // Length
+	d.uint16()             // MaximumLength
+	return d.uint32() != 0 // Buffer
+}
+
+func (d *ndrDecoder) unicodeStringBuffer() string {
+	max := d.count(0) // max count
+	off := d.uint32() // offset
+	n := d.count(2)   // actual count
+	if off != 0 || n > max {
+		d.err = true
+		return ""
+	}
+	bs := d.bytes(2 * n) // characters
+	return utf16le.DecodeToString(bs)
+}
+
+// wideString decodes a null-terminated string of a [string] wchar_t* pointer.
+func (d *ndrDecoder) wideString() string {
+	return d.unicodeStringBuffer()
+}
+
+// sid decodes a RPC_SID and returns it in the on-wire format.
+func (d *ndrDecoder) sid() []byte {
+	n := d.count(4) // max count (SubAuthorityCount)
+	bs := d.bytes(8 + 4*n)
+	if bs == nil || int(bs[1]) != n {
+		d.err = true
+		return nil
+	}
+	return bs
+}
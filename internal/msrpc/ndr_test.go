@@ -0,0 +1,131 @@
+package msrpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// unhex decodes s, whose bytes may be separated by spaces.
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	bs, err := hex.DecodeString(strings.Replace(s, " ", "", -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bs
+}
+
+func TestNDREncoder(t *testing.T) {
+	var e ndrEncoder
+
+	e.uint16(1)
+	e.uint32(2)      // aligned to 4
+	e.pointer(true)  // referent ids are unique
+	e.pointer(false) // null
+	e.pointer(true)
+	e.unicodeString("ab")
+	e.unicodeStringBuffer("ab")
+	e.unicodeString("") // empty strings have null buffers
+	e.unicodeStringBuffer("")
+	e.wideString("ab") // null-terminated
+	e.sid([]byte{1, 1, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0})
+
+	expected := unhex(t, ""+
+		"0100 0000 02000000"+
+		"04000200 00000000 08000200"+
+		"0400 0400 0c000200"+
+		"02000000 00000000 02000000 61006200"+
+		"0000 0000 00000000"+
+		"03000000 00000000 03000000 61006200 0000"+
+		"0000 01000000 010100000000000520000000")
+
+	if !bytes.Equal(e.b, expected) {
+		t.Errorf("expected %x, got %x", expected, e.b)
+	}
+}
+
+func TestNDRDecoder(t *testing.T) {
+	d := ndrDecoder{b: unhex(t, ""+
+		"0100 0000 02000000"+
+		"0400 0400 0c000200"+
+		"02000000 00000000 02000000 61006200"+
+		"03000000 00000000 03000000 61006200 0000"+
+		"0000 01000000 010100000000000520000000")}
+
+	if v := d.uint16(); v != 1 {
+		t.Errorf("expected 1, got %d", v)
+	}
+	if v := d.uint32(); v != 2 {
+		t.Errorf("expected 2, got %d", v)
+	}
+	if !d.unicodeString() {
+		t.Error("expected a non-null buffer")
+	}
+	if s := d.unicodeStringBuffer(); s != "ab" {
+		t.Errorf("expected %q, got %q", "ab", s)
+	}
+	if s := d.wideString(); s != "ab" {
+		t.Errorf("expected %q, got %q", "ab", s)
+	}
+	if sid := d.sid(); !bytes.Equal(sid, []byte{1, 1, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0}) {
+		t.Errorf("unexpected sid %x", sid)
+	}
+	if d.err {
+		t.Error("unexpected error")
+	}
+
+	// reads past the end fail and return zero values
+	if v := d.uint32(); v != 0 || !d.err {
+		t.Errorf("expected an error, got %d", v)
+	}
+}
+
+func TestNDRDecoderBroken(t *testing.T) {
+	tests := []struct {
+		name   string
+		b      string
+		decode func(d *ndrDecoder)
+	}{
+		{
+			name:   "offset",
+			b:      "02000000 01000000 01000000 6100",
+			decode: func(d *ndrDecoder) { d.unicodeStringBuffer() },
+		},
+		{
+			name:   "actual count exceeding max count",
+			b:      "01000000 00000000 02000000 61006200",
+			decode: func(d *ndrDecoder) { d.unicodeStringBuffer() },
+		},
+		{
+			name:   "actual count exceeding buffer",
+			b:      "08000000 00000000 08000000 61006200",
+			decode: func(d *ndrDecoder) { d.unicodeStringBuffer() },
+		},
+		{
+			name:   "huge count",
+			b:      "ffffffff 00000000 ffffffff",
+			decode: func(d *ndrDecoder) { d.unicodeStringBuffer() },
+		},
+		{
+			name:   "sid count mismatch",
+			b:      "02000000 010100000000000520000000 00000000",
+			decode: func(d *ndrDecoder) { d.sid() },
+		},
+		{
+			name:   "truncated sid",
+			b:      "01000000 0101000000000005",
+			decode: func(d *ndrDecoder) { d.sid() },
+		},
+	}
+
+	for _, tt := range tests {
+		d := ndrDecoder{b: unhex(t, tt.b)}
+		tt.decode(&d)
+		if !d.err {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package msrpc
+
+// SRVSVC (See [MS-SRVS])
+
+const (
+	OP_NETR_SERVER_GET_INFO = 21
+)
+
+type NetrServerGetInfoRequest struct {
+	ServerName string
+	Level      uint32
+}
+
+func (r *NetrServerGetInfoRequest) Marshal() []byte {
+	var e ndrEncoder
+
+	e.pointer(true) // ServerName
+	e.wideString(r.ServerName)
+
+	e.uint32(r.Level)
+
+	return e.b
+}
+
+// ServerInfo101 is SERVER_INFO_101.
+type ServerInfo101 struct {
+	PlatformId   uint32
+	Name         string
+	VersionMajor uint32
+	VersionMinor uint32
+	Type         uint32
+	Comment      string
+}
+
+// NetrServerGetInfoResponse is the response of level 101.
+type NetrServerGetInfoResponse struct {
+	Info   *ServerInfo101
+	Status uint32
+}
+
+func (r *NetrServerGetInfoResponse) Unmarshal(b []byte) bool {
+	d := ndrDecoder{b: b}
+
+	level := d.uint32()  // InfoStruct (switch)
+	if d.uint32() != 0 { // ServerInfo101
+		if level != 101 {
+			return false
+		}
+
+		info := new(ServerInfo101)
+		info.PlatformId = d.uint32()
+		hasName := d.uint32() != 0
+		info.VersionMajor = d.uint32()
+		info.VersionMinor = d.uint32()
+		info.Type = d.uint32()
+		hasComment := d.uint32() != 0
+		if hasName {
+			info.Name = d.wideString()
+		}
+		if hasComment {
+			info.Comment = d.wideString()
+		}
+		r.Info = info
+	}
+
+	r.Status = d.uint32()
+
+	return !d.err
+}
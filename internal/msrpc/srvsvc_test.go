@@ -0,0 +1,74 @@
+package msrpc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestNetrServerGetInfoRequest(t *testing.T) {
+	req := &NetrServerGetInfoRequest{ServerName: "srv", Level: 101}
+
+	expected := unhex(t, ""+
+		"04000200"+ // ServerName
+		"04000000 00000000 04000000 7300720076000000"+ // "srv"
+		"65000000") // Level
+
+	if b := req.Marshal(); !bytes.Equal(b, expected) {
+		t.Errorf("expected %x, got %x", expected, b)
+	}
+}
+
+func TestNetrServerGetInfoResponse(t *testing.T) {
+	b := unhex(t, ""+
+		"65000000 00000200"+ // InfoStruct
+		"f4010000 04000200 0a000000 00000000 03100100 08000200"+ // PlatformId, Name, VersionMajor, VersionMinor, Type, Comment
+		"04000000 00000000 04000000 5300520056000000"+ // "SRV"
+		"01000000 00000000 01000000 0000"+ // ""
+		"0000 00000000") // Status
+
+	var res NetrServerGetInfoResponse
+	if !res.Unmarshal(b) {
+		t.Fatal("broken response")
+	}
+
+	expected := &ServerInfo101{
+		PlatformId:   500,
+		Name:         "SRV",
+		VersionMajor: 10,
+		VersionMinor: 0,
+		Type:         0x11003,
+	}
+	if !reflect.DeepEqual(res.Info, expected) || res.Status != 0 {
+		t.Errorf("expected %+v, got %+v, status %#x", expected, res.Info, res.Status)
+	}
+
+	for i := 0; i < len(b); i += 4 {
+		if res.Unmarshal(b[:i]) {
+			t.Errorf("expected an error of the response truncated to %d bytes", i)
+		}
+	}
+
+	// a failed call has a null info pointer
+	res = NetrServerGetInfoResponse{}
+	if !res.Unmarshal(unhex(t, "65000000 00000000 05000000")) {
+		t.Fatal("broken response")
+	}
+	if res.Info != nil || res.Status != 5 { // ERROR_ACCESS_DENIED
+		t.Errorf("unexpected info %+v, status %#x", res.Info, res.Status)
+	}
+
+	// the info of another level isn't decoded
+	if res.Unmarshal(unhex(t, "66000000 00000200 7c000000")) {
+		t.Error("expected an error of an unexpected level")
+	}
+
+	// the name's offset must be zero
+	if res.Unmarshal(unhex(t, ""+
+		"65000000 00000200"+
+		"f4010000 04000200 0a000000 00000000 03100100 00000000"+
+		"04000000 01000000 03000000 53005200560000000000"+
+		"00000000")) {
+		t.Error("expected an error of a broken offset")
+	}
+}
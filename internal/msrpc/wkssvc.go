@@ -0,0 +1,74 @@
+package msrpc
+
+// WKSSVC (See [MS-WKST])
+
+const (
+	WKSSVC_VERSION       = 1
+	WKSSVC_VERSION_MINOR = 0
+
+	OP_NETR_WKSTA_GET_INFO = 0
+)
+
+var (
+	WKSSVC_UUID = []byte("98d0ff6b12a11036983346c3f87e345a")
+)
+
+type NetrWkstaGetInfoRequest struct {
+	ServerName string
+	Level      uint32
+}
+
+func (r *NetrWkstaGetInfoRequest) Marshal() []byte {
+	var e ndrEncoder
+
+	e.pointer(true) // ServerName
+	e.wideString(r.ServerName)
+
+	e.uint32(r.Level)
+
+	return e.b
+}
+
+// WkstaInfo100 is WKSTA_INFO_100.
+type WkstaInfo100 struct {
+	PlatformId   uint32
+	ComputerName string
+	LanGroup     string
+	VersionMajor uint32
+	VersionMinor uint32
+}
+
+// NetrWkstaGetInfoResponse is the response of level 100.
+type NetrWkstaGetInfoResponse struct {
+	Info   *WkstaInfo100
+	Status uint32
+}
+
+func (r *NetrWkstaGetInfoResponse) Unmarshal(b []byte) bool {
+	d := ndrDecoder{b: b}
+
+	level := d.uint32()  // WkstaInfo (switch)
+	if d.uint32() != 0 { // WkstaInfo100
+		if level != 100 {
+			return false
+		}
+
+		info := new(WkstaInfo100)
+		info.PlatformId = d.uint32()
+		hasComputerName := d.uint32() != 0
+		hasLanGroup := d.uint32() != 0
+		info.VersionMajor = d.uint32()
+		info.VersionMinor = d.uint32()
+		if hasComputerName {
+			info.ComputerName = d.wideString()
+		}
+		if hasLanGroup {
+			info.LanGroup = d.wideString()
+		}
+		r.Info = info
+	}
+
+	r.Status = d.uint32()
+
+	return !d.err
+}
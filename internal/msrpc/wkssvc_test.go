@@ -0,0 +1,74 @@
+package msrpc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestNetrWkstaGetInfoRequest(t *testing.T) {
+	req := &NetrWkstaGetInfoRequest{ServerName: "srv", Level: 100}
+
+	expected := unhex(t, ""+
+		"04000200"+ // ServerName
+		"04000000 00000000 04000000 7300720076000000"+ // "srv"
+		"64000000") // Level
+
+	if b := req.Marshal(); !bytes.Equal(b, expected) {
+		t.Errorf("expected %x, got %x", expected, b)
+	}
+}
+
+func TestNetrWkstaGetInfoResponse(t *testing.T) {
+	b := unhex(t, ""+
+		"64000000 00000200"+ // WkstaInfo
+		"f4010000 04000200 08000200 0a000000 00000000"+ // PlatformId, ComputerName, LanGroup, VersionMajor, VersionMinor
+		"03000000 00000000 03000000 500043000000 0000"+ // "PC"
+		"0a000000 00000000 0a000000 57004f0052004b00470052004f00550050000000"+ // "WORKGROUP"
+		"00000000") // Status
+
+	var res NetrWkstaGetInfoResponse
+	if !res.Unmarshal(b) {
+		t.Fatal("broken response")
+	}
+
+	expected := &WkstaInfo100{
+		PlatformId:   500,
+		ComputerName: "PC",
+		LanGroup:     "WORKGROUP",
+		VersionMajor: 10,
+		VersionMinor: 0,
+	}
+	if !reflect.DeepEqual(res.Info, expected) || res.Status != 0 {
+		t.Errorf("expected %+v, got %+v, status %#x", expected, res.Info, res.Status)
+	}
+
+	for i := 0; i < len(b); i += 4 {
+		if res.Unmarshal(b[:i]) {
+			t.Errorf("expected an error of the response truncated to %d bytes", i)
+		}
+	}
+
+	// a failed call has a null info pointer
+	res = NetrWkstaGetInfoResponse{}
+	if !res.Unmarshal(unhex(t, "64000000 00000000 05000000")) {
+		t.Fatal("broken response")
+	}
+	if res.Info != nil || res.Status != 5 { // ERROR_ACCESS_DENIED
+		t.Errorf("unexpected info %+v, status %#x", res.Info, res.Status)
+	}
+
+	// the info of another level isn't decoded
+	if res.Unmarshal(unhex(t, "65000000 00000200 7c000000")) {
+		t.Error("expected an error of an unexpected level")
+	}
+
+	// the actual count of the computer name exceeds its max count
+	if res.Unmarshal(unhex(t, ""+
+		"64000000 00000200"+
+		"f4010000 04000200 00000000 0a000000 00000000"+
+		"02000000 00000000 03000000 500043000000 0000"+
+		"00000000")) {
+		t.Error("expected an error of a broken count")
+	}
+}
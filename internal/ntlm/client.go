@@ -25,6 +25,12 @@ type Client struct {
 	TargetSPN       string           // SPN ::= "service/hostname[:port]"; e.g "cifs/remotehost:1020"
 	channelBindings *channelBindings // reserved for future implementation
 
+	// ComputeResponse computes the NTLMv2 response instead of the credentials above, if it's non-nil.
+	// challenge is the server challenge, and targetInfo the AV pairs of the NTLMv2 client challenge.
+	// It returns the whole NtChallengeResponse (NTProofStr followed by the client challenge)
+	// and the session base key.
+	ComputeResponse func(challenge, targetInfo []byte) (ntResponse, sessionKey []byte, err error)
+
 	nmsg    []byte
 	session *Session
 }
@@ -142,6 +148,25 @@ func (c *Client) Authenticate(cmsg []byte) (amsg []byte, err error) {
 		domain = targetName
 	}
 
+	var ntResponse, sessionBaseKey []byte
+
+	ntResponseLen := 16 + (28 + info.size() + 4)
+
+	if c.ComputeResponse != nil {
+		avPairs := make([]byte, info.size())
+		info.encode(avPairs)
+
+		ntResponse, sessionBaseKey, err = c.ComputeResponse(cmsg[24:32], avPairs)
+		if err != nil {
+			return nil, err
+		}
+		if len(ntResponse) < 16+28 || len(sessionBaseKey) != 16 {
+			return nil, errors.New("invalid computed response")
+		}
+
+		ntResponseLen = len(ntResponse)
+	}
+
 	// LmChallengeResponseLen = 24
 	// NtChallengeResponseLen =
 	//   len(Response) = 16
@@ -153,7 +178,7 @@ func (c *Client) Authenticate(cmsg []byte) (amsg []byte, err error) {
 
 	amsg = make([]byte, off+len(domain)+len(user)+len(workstation)+
 		24+
-		(16+(28+info.size()+4))+
+		ntResponseLen+
 		16)
 
 	copy(amsg[:8], signature)
@@ -183,15 +208,18 @@ func (c *Client) Authenticate(cmsg []byte) (amsg []byte, err error) {
 		off += len
 	}
 
-	if c.User != "" || c.Password != "" || c.Hash != nil {
+	if c.User != "" || c.Password != "" || c.Hash != nil || c.ComputeResponse != nil {
 		var err error
 		var h hash.Hash
 
-		if c.Hash != nil {
+		switch {
+		case ntResponse != nil:
+			// the response and the session base key are computed by ComputeResponse
+		case c.Hash != nil:
 			USER := utf16le.EncodeStringToBytes(strings.ToUpper(c.User))
 
 			h = hmac.New(md5.New, ntowfv2Hash(USER, c.Hash, domain))
-		} else {
+		default:
 			USER := utf16le.EncodeStringToBytes(strings.ToUpper(c.User))
 			password := utf16le.EncodeStringToBytes(c.Password)
 
@@ -216,7 +244,15 @@ func (c *Client) Authenticate(cmsg []byte) (amsg []byte, err error) {
 		//   16-: NTLMv2ClientChallenge
 
 		ntChallengeResponse := amsg[off : len(amsg)-16]
-		{
+		if ntResponse != nil {
+			copy(ntChallengeResponse, ntResponse)
+
+			le.PutUint16(amsg[20:22], uint16(len(ntChallengeResponse)))
+			le.PutUint16(amsg[22:24], uint16(len(ntChallengeResponse)))
+			le.PutUint32(amsg[24:28], uint32(off))
+
+			off = len(amsg) - 16
+		} else {
 			ntlmv2ClientChallenge := ntChallengeResponse[16:]
 
 			//        NTLMv2ClientChallenge
@@ -264,9 +300,11 @@ func (c *Client) Authenticate(cmsg []byte) (amsg []byte, err error) {
 		session.setTargetInfo(info)
 
 
-		h.Reset()
-		h.Write(ntChallengeResponse[:16])
-		sessionBaseKey := h.Sum(nil)
+		if sessionBaseKey == nil {
+			h.Reset()
+			h.Write(ntChallengeResponse[:16])
+			sessionBaseKey = h.Sum(nil)
+		}
 
 		keyExchangeKey := sessionBaseKey // if ntlm version == 2
 
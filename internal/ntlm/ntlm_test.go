@@ -247,3 +247,59 @@ func TestClientServer(t *testing.T) {
 		t.Error("error")
 	}
 }
+
+func TestClientServerComputeResponse(t *testing.T) {
+	var called bool
+
+	c := &Client{
+		User: "user",
+		ComputeResponse: func(challenge, targetInfo []byte) ([]byte, []byte, error) {
+			called = true
+
+			USER := utf16le.EncodeStringToBytes("USER")
+			password := utf16le.EncodeStringToBytes("password")
+			h := hmac.New(md5.New, ntowfv2(USER, password, utf16le.EncodeStringToBytes("server")))
+
+			clientChallenge := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+			timestamp := make([]byte, 8)
+
+			ntResponse := make([]byte, 16+28+len(targetInfo)+4)
+			encodeNtlmv2Response(ntResponse, h, challenge, clientChallenge, timestamp, simpleEncoder(targetInfo))
+
+			h.Reset()
+			h.Write(ntResponse[:16])
+
+			return ntResponse, h.Sum(nil), nil
+		},
+	}
+
+	s := NewServer("server")
+
+	s.AddAccount("user", "password")
+
+	nmsg, err := c.Negotiate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmsg, err := s.Challenge(nmsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	amsg, err := c.Authenticate(cmsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Fatal("ComputeResponse isn't called")
+	}
+
+	err = s.Authenticate(amsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(c.Session().SessionKey(), s.Session().SessionKey()) {
+		t.Error("session keys don't match")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"crypto/rc4"
 	"errors"
-	"unicode/utf16"
 
 	"github.com/nodauf/go-smb2/internal/utf16le"
 )
@@ -200,29 +199,8 @@ func indexNullTerminator(b []byte) int {
 }
 
 // UTF16BytesToString returns a string that is decoded from the UTF-16 bytes.
-// The byte slice must be of even length otherwise an error will be returned.
-// The integer returned is the offset to the start of the next string with
-// buffer if it exists, otherwise -1 is returned.
+// Malformed UTF-16 (unpaired surrogates and an odd trailing byte) is decoded as U+FFFD.
 func UTF16BytesToString(b []byte) string {
-	if len(b)%2 != 0 {
-		return "" //, 0, fmt.Errorf("Slice must have an even length (length=%d)", len(b))
-	}
-
-	/*offset := -1
-
-	// Find the null terminator if it exists and re-slice the b.
-	if nullIndex := indexNullTerminator(b); nullIndex > -1 {
-		if len(b) > nullIndex+2 {
-			offset = nullIndex + 2
-		}
-
-		b = b[:nullIndex]
-	}*/
-
-	s := make([]uint16, len(b)/2)
-	for i := range s {
-		s[i] = uint16(b[i*2]) + uint16(b[(i*2)+1])<<8
-	}
-
-	return string(utf16.Decode(s))
+	s, _ := utf16le.DecodeBytes(b, utf16le.Replace)
+	return s
 }
@@ -151,6 +151,14 @@ const (
 	SMB2_ENCRYPTION_CAPABILITIES
 )
 
+const (
+	SMB2_COMPRESSION_CAPABILITIES     = 0x3
+	SMB2_NETNAME_NEGOTIATE_CONTEXT_ID = 0x5
+	SMB2_TRANSPORT_CAPABILITIES       = 0x6
+	SMB2_RDMA_TRANSFORM_CAPABILITIES  = 0x7
+	SMB2_SIGNING_CAPABILITIES         = 0x8
+)
+
 // HashAlgorithms
 const (
 	SHA512 = 0x1
@@ -158,8 +166,24 @@ const (
 
 // Ciphers
 const (
-	AES128CCM = 1 << iota
-	AES128GCM
+	AES128CCM = 0x1
+	AES128GCM = 0x2
+	AES256CCM = 0x3
+	AES256GCM = 0x4
+)
+
+// SigningAlgorithms
+const (
+	HMAC_SHA256 = iota
+	AES_CMAC
+	AES_GMAC
+)
+
+// RDMATransformIds
+const (
+	SMB2_RDMA_TRANSFORM_NONE = iota
+	SMB2_RDMA_TRANSFORM_ENCRYPTION
+	SMB2_RDMA_TRANSFORM_SIGNING
 )
 
 // ----------------------------------------------------------------------------
@@ -601,6 +625,27 @@ const (
 // SMB2 CHANGE_NOTIFY Request and Response
 //
 
+// Flags
+const (
+	SMB2_WATCH_TREE = 1 << iota
+)
+
+// CompletionFilter
+const (
+	FILE_NOTIFY_CHANGE_FILE_NAME = 1 << iota
+	FILE_NOTIFY_CHANGE_DIR_NAME
+	FILE_NOTIFY_CHANGE_ATTRIBUTES
+	FILE_NOTIFY_CHANGE_SIZE
+	FILE_NOTIFY_CHANGE_LAST_WRITE
+	FILE_NOTIFY_CHANGE_LAST_ACCESS
+	FILE_NOTIFY_CHANGE_CREATION
+	FILE_NOTIFY_CHANGE_EA
+	FILE_NOTIFY_CHANGE_SECURITY
+	FILE_NOTIFY_CHANGE_STREAM_NAME
+	FILE_NOTIFY_CHANGE_STREAM_SIZE
+	FILE_NOTIFY_CHANGE_STREAM_WRITE
+)
+
 //
 
 // ----------------------------------------------------------------------------
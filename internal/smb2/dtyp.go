@@ -3,6 +3,7 @@
 package smb2
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 )
@@ -75,7 +76,7 @@ func (sid *Sid) String() string {
 	if sid.IdentifierAuthority < uint64(1<<32) {
 		list = append(list, strconv.FormatUint(sid.IdentifierAuthority, 10))
 	} else {
-		list = append(list, "0x"+strconv.FormatUint(sid.IdentifierAuthority, 16))
+		list = append(list, fmt.Sprintf("0x%012X", sid.IdentifierAuthority))
 	}
 	for _, a := range sid.SubAuthority {
 		list = append(list, strconv.FormatUint(uint64(a), 10))
@@ -91,7 +92,7 @@ func (sid *Sid) Encode(p []byte) {
 	p[0] = sid.Revision
 	p[1] = uint8(len(sid.SubAuthority))
 	for j := 0; j < 6; j++ {
-		p[2+j] = byte(sid.IdentifierAuthority >> uint64(8*(6-j)))
+		p[2+j] = byte(sid.IdentifierAuthority >> uint64(8*(5-j)))
 	}
 	off := 8
 	for _, u := range sid.SubAuthority {
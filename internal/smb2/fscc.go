@@ -37,6 +37,11 @@ const (
 	FSCTL_DFS_GET_REFERRALS_EX         = 0x000601B0
 	FSCTL_FILE_LEVEL_TRIM              = 0x00098208
 	FSCTL_VALIDATE_NEGOTIATE_INFO      = 0x00140204
+	FSCTL_GET_NTFS_VOLUME_DATA         = 0x00090064
+	FSCTL_GET_RETRIEVAL_POINTERS       = 0x00090073
+	FSCTL_QUERY_ALLOCATED_RANGES       = 0x000940CF
+	FSCTL_DUPLICATE_EXTENTS_TO_FILE    = 0x00098344
+	FSCTL_SET_VALID_DATA_LENGTH        = 0x000980F4
 )
 
 type SymbolicLinkReparseDataBuffer struct {
@@ -193,6 +198,25 @@ func (c *SrvCopychunk) Encode(p []byte) {
 	le.PutUint32(p[16:20], c.Length)
 }
 
+// DuplicateExtentsData is the DUPLICATE_EXTENTS_DATA of FSCTL_DUPLICATE_EXTENTS_TO_FILE. (See [MS-FSCC] 2.3.8)
+type DuplicateExtentsData struct {
+	FileHandle       *FileId // source file
+	SourceFileOffset int64
+	TargetFileOffset int64
+	ByteCount        int64
+}
+
+func (c *DuplicateExtentsData) Size() int {
+	return 40
+}
+
+func (c *DuplicateExtentsData) Encode(p []byte) {
+	c.FileHandle.Encode(p[:16])
+	le.PutUint64(p[16:24], uint64(c.SourceFileOffset))
+	le.PutUint64(p[24:32], uint64(c.TargetFileOffset))
+	le.PutUint64(p[32:40], uint64(c.ByteCount))
+}
+
 type SrvCopychunkResponseDecoder []byte
 
 func (c SrvCopychunkResponseDecoder) IsInvalid() bool {
@@ -211,9 +235,243 @@ func (c SrvCopychunkResponseDecoder) TotalBytesWritten() uint32 {
 	return le.Uint32(c[8:12])
 }
 
+type ValidateNegotiateInfoRequest struct {
+	Capabilities uint32
+	Guid         [16]byte
+	SecurityMode uint16
+	Dialects     []uint16
+}
+
+func (c *ValidateNegotiateInfoRequest) Size() int {
+	return 24 + len(c.Dialects)*2
+}
+
+func (c *ValidateNegotiateInfoRequest) Encode(p []byte) {
+	le.PutUint32(p[:4], c.Capabilities)
+	copy(p[4:20], c.Guid[:])
+	le.PutUint16(p[20:22], c.SecurityMode)
+	le.PutUint16(p[22:24], uint16(len(c.Dialects)))
+	off := 24
+	for i, d := range c.Dialects {
+		le.PutUint16(p[off+2*i:off+2*i+2], d)
+	}
+}
+
+type ValidateNegotiateInfoResponseDecoder []byte
+
+func (c ValidateNegotiateInfoResponseDecoder) IsInvalid() bool {
+	return len(c) < 24
+}
+
+func (c ValidateNegotiateInfoResponseDecoder) Capabilities() uint32 {
+	return le.Uint32(c[:4])
+}
+
+func (c ValidateNegotiateInfoResponseDecoder) Guid() []byte {
+	return c[4:20]
+}
+
+func (c ValidateNegotiateInfoResponseDecoder) SecurityMode() uint16 {
+	return le.Uint16(c[20:22])
+}
+
+func (c ValidateNegotiateInfoResponseDecoder) Dialect() uint16 {
+	return le.Uint16(c[22:24])
+}
+
+type NtfsVolumeDataBufferDecoder []byte
+
+func (c NtfsVolumeDataBufferDecoder) IsInvalid() bool {
+	return len(c) < 96
+}
+
+func (c NtfsVolumeDataBufferDecoder) VolumeSerialNumber() uint64 {
+	return le.Uint64(c[:8])
+}
+
+func (c NtfsVolumeDataBufferDecoder) NumberSectors() int64 {
+	return int64(le.Uint64(c[8:16]))
+}
+
+func (c NtfsVolumeDataBufferDecoder) TotalClusters() int64 {
+	return int64(le.Uint64(c[16:24]))
+}
+
+func (c NtfsVolumeDataBufferDecoder) FreeClusters() int64 {
+	return int64(le.Uint64(c[24:32]))
+}
+
+func (c NtfsVolumeDataBufferDecoder) TotalReserved() int64 {
+	return int64(le.Uint64(c[32:40]))
+}
+
+func (c NtfsVolumeDataBufferDecoder) BytesPerSector() uint32 {
+	return le.Uint32(c[40:44])
+}
+
+func (c NtfsVolumeDataBufferDecoder) BytesPerCluster() uint32 {
+	return le.Uint32(c[44:48])
+}
+
+func (c NtfsVolumeDataBufferDecoder) BytesPerFileRecordSegment() uint32 {
+	return le.Uint32(c[48:52])
+}
+
+func (c NtfsVolumeDataBufferDecoder) ClustersPerFileRecordSegment() uint32 {
+	return le.Uint32(c[52:56])
+}
+
+func (c NtfsVolumeDataBufferDecoder) MftValidDataLength() int64 {
+	return int64(le.Uint64(c[56:64]))
+}
+
+func (c NtfsVolumeDataBufferDecoder) MftStartLcn() int64 {
+	return int64(le.Uint64(c[64:72]))
+}
+
+func (c NtfsVolumeDataBufferDecoder) Mft2StartLcn() int64 {
+	return int64(le.Uint64(c[72:80]))
+}
+
+func (c NtfsVolumeDataBufferDecoder) MftZoneStart() int64 {
+	return int64(le.Uint64(c[80:88]))
+}
+
+func (c NtfsVolumeDataBufferDecoder) MftZoneEnd() int64 {
+	return int64(le.Uint64(c[88:96]))
+}
+
+// ExtendedVolumeData returns NTFS_EXTENDED_VOLUME_DATA following the buffer if any.
+func (c NtfsVolumeDataBufferDecoder) ExtendedVolumeData() NtfsExtendedVolumeDataDecoder {
+	return NtfsExtendedVolumeDataDecoder(c[96:])
+}
+
+type NtfsExtendedVolumeDataDecoder []byte
+
+func (c NtfsExtendedVolumeDataDecoder) IsInvalid() bool {
+	return len(c) < 8 || c.ByteCount() < 8
+}
+
+func (c NtfsExtendedVolumeDataDecoder) ByteCount() uint32 {
+	return le.Uint32(c[:4])
+}
+
+func (c NtfsExtendedVolumeDataDecoder) MajorVersion() uint16 {
+	return le.Uint16(c[4:6])
+}
+
+func (c NtfsExtendedVolumeDataDecoder) MinorVersion() uint16 {
+	return le.Uint16(c[6:8])
+}
+
+type StartingVcnInputBuffer struct {
+	StartingVcn int64
+}
+
+func (c *StartingVcnInputBuffer) Size() int {
+	return 8
+}
+
+func (c *StartingVcnInputBuffer) Encode(p []byte) {
+	le.PutUint64(p[:8], uint64(c.StartingVcn))
+}
+
+type FileValidDataLengthInformationBuffer struct {
+	ValidDataLength int64
+}
+
+func (c *FileValidDataLengthInformationBuffer) Size() int {
+	return 8
+}
+
+func (c *FileValidDataLengthInformationBuffer) Encode(p []byte) {
+	le.PutUint64(p[:8], uint64(c.ValidDataLength))
+}
+
+type RetrievalPointersBufferDecoder []byte
+
+func (c RetrievalPointersBufferDecoder) IsInvalid() bool {
+	if len(c) < 16 {
+		return true
+	}
+	if uint64(len(c)-16)/16 < uint64(c.ExtentCount()) {
+		return true
+	}
+	return false
+}
+
+func (c RetrievalPointersBufferDecoder) ExtentCount() uint32 {
+	return le.Uint32(c[:4])
+}
+
+func (c RetrievalPointersBufferDecoder) StartingVcn() int64 {
+	return int64(le.Uint64(c[8:16]))
+}
+
+func (c RetrievalPointersBufferDecoder) NextVcn(i int) int64 {
+	off := 16 + 16*i
+	return int64(le.Uint64(c[off : off+8]))
+}
+
+func (c RetrievalPointersBufferDecoder) Lcn(i int) int64 {
+	off := 16 + 16*i
+	return int64(le.Uint64(c[off+8 : off+16]))
+}
+
+type SrvSnapshotArrayDecoder []byte
+
+func (c SrvSnapshotArrayDecoder) IsInvalid() bool {
+	if len(c) < 12 {
+		return true
+	}
+	if c.NumberOfSnapShotsReturned() != 0 && uint32(len(c)-12) < c.SnapShotArraySize() {
+		return true
+	}
+	return false
+}
+
+func (c SrvSnapshotArrayDecoder) NumberOfSnapShots() uint32 {
+	return le.Uint32(c[:4])
+}
+
+func (c SrvSnapshotArrayDecoder) NumberOfSnapShotsReturned() uint32 {
+	return le.Uint32(c[4:8])
+}
+
+func (c SrvSnapshotArrayDecoder) SnapShotArraySize() uint32 {
+	return le.Uint32(c[8:12])
+}
+
+// SnapShots returns the @GMT tokens of the returned snapshots.
+func (c SrvSnapshotArrayDecoder) SnapShots() []string {
+	if c.NumberOfSnapShotsReturned() == 0 {
+		return nil
+	}
+
+	bs := c[12 : 12+c.SnapShotArraySize()]
+
+	var ss []string
+	for len(bs) >= 2 {
+		i := 0
+		for i+2 <= len(bs) && (bs[i] != 0 || bs[i+1] != 0) {
+			i += 2
+		}
+		if i == 0 {
+			break
+		}
+		ss = append(ss, utf16le.DecodeToString(bs[:i]))
+		if i+2 > len(bs) {
+			break
+		}
+		bs = bs[i+2:]
+	}
+	return ss
+}
+
 const (
 	FILE_ATTRIBUTE_ARCHIVE             = 0x20
 	FILE_ATTRIBUTE_COMPRESSED          = 0x800
+	FILE_ATTRIBUTE_DEVICE              = 0x40
 	FILE_ATTRIBUTE_DIRECTORY           = 0x10
 	FILE_ATTRIBUTE_ENCRYPTED           = 0x4000
 	FILE_ATTRIBUTE_HIDDEN              = 0x2
@@ -286,6 +544,15 @@ const (
 	FileStardardLinkInformation                   // 54
 )
 
+const (
+	FileCaseSensitiveInformation = 71
+)
+
+// FileCaseSensitiveInformation Flags
+const (
+	FILE_CS_FLAG_CASE_SENSITIVE_DIR = 0x1
+)
+
 const (
 	FileFsVolumeInformation = 1 + iota
 	FileFsLabelInformation
@@ -300,10 +567,64 @@ const (
 	FileFsSectorSizeInformation
 )
 
+// FilePipeInformation ReadMode
+const (
+	FILE_PIPE_BYTE_STREAM_MODE = 0
+	FILE_PIPE_MESSAGE_MODE     = 1
+)
+
+// FilePipeInformation CompletionMode
+const (
+	FILE_PIPE_QUEUE_OPERATION    = 0
+	FILE_PIPE_COMPLETE_OPERATION = 1
+)
+
+// FilePipeLocalInformation NamedPipeType
+const (
+	FILE_PIPE_BYTE_STREAM_TYPE = 0
+	FILE_PIPE_MESSAGE_TYPE     = 1
+)
+
+// FilePipeLocalInformation NamedPipeConfiguration
+const (
+	FILE_PIPE_INBOUND     = 0
+	FILE_PIPE_OUTBOUND    = 1
+	FILE_PIPE_FULL_DUPLEX = 2
+)
+
+// FilePipeLocalInformation NamedPipeState
+const (
+	FILE_PIPE_DISCONNECTED_STATE = 1 + iota
+	FILE_PIPE_LISTENING_STATE
+	FILE_PIPE_CONNECTED_STATE
+	FILE_PIPE_CLOSING_STATE
+)
+
+// FilePipeLocalInformation NamedPipeEnd
+const (
+	FILE_PIPE_CLIENT_END = 0
+	FILE_PIPE_SERVER_END = 1
+)
+
+// FileNotifyInformation Action
+const (
+	FILE_ACTION_ADDED = 1 + iota
+	FILE_ACTION_REMOVED
+	FILE_ACTION_MODIFIED
+	FILE_ACTION_RENAMED_OLD_NAME
+	FILE_ACTION_RENAMED_NEW_NAME
+	FILE_ACTION_ADDED_STREAM
+	FILE_ACTION_REMOVED_STREAM
+	FILE_ACTION_MODIFIED_STREAM
+	FILE_ACTION_REMOVED_BY_DELETE
+	FILE_ACTION_ID_NOT_TUNNELLED
+	FILE_ACTION_TUNNELLED_ID_COLLISION
+)
+
 type FileDirectoryInformationDecoder []byte
 
 func (c FileDirectoryInformationDecoder) IsInvalid() bool {
-	return len(c) < int(64+c.FileNameLength())
+	return len(c) < 64 || len(c) < int(64+c.FileNameLength()) || c.FileNameLength()%2 != 0
 }
 
 func (c FileDirectoryInformationDecoder) NextEntryOffset() uint32 {
@@ -350,6 +671,246 @@ func (c FileDirectoryInformationDecoder) FileName() string {
 	return utf16le.DecodeToString(c[64 : 64+c.FileNameLength()])
 }
 
+type FileFullDirectoryInformationDecoder []byte
+
+func (c FileFullDirectoryInformationDecoder) IsInvalid() bool {
+	return len(c) < 68 || len(c) < int(68+c.FileNameLength()) || c.FileNameLength()%2 != 0
+}
+
+func (c FileFullDirectoryInformationDecoder) NextEntryOffset() uint32 {
+	return le.Uint32(c[:4])
+}
+
+func (c FileFullDirectoryInformationDecoder) FileIndex() uint32 {
+	return le.Uint32(c[4:8])
+}
+
+func (c FileFullDirectoryInformationDecoder) CreationTime() FiletimeDecoder {
+	return FiletimeDecoder(c[8:16])
+}
+
+func (c FileFullDirectoryInformationDecoder) LastAccessTime() FiletimeDecoder {
+	return FiletimeDecoder(c[16:24])
+}
+
+func (c FileFullDirectoryInformationDecoder) LastWriteTime() FiletimeDecoder {
+	return FiletimeDecoder(c[24:32])
+}
+
+func (c FileFullDirectoryInformationDecoder) ChangeTime() FiletimeDecoder {
+	return FiletimeDecoder(c[32:40])
+}
+
+func (c FileFullDirectoryInformationDecoder) EndOfFile() int64 {
+	return int64(le.Uint64(c[40:48]))
+}
+
+func (c FileFullDirectoryInformationDecoder) AllocationSize() int64 {
+	return int64(le.Uint64(c[48:56]))
+}
+
+func (c FileFullDirectoryInformationDecoder) FileAttributes() uint32 {
+	return le.Uint32(c[56:60])
+}
+
+func (c FileFullDirectoryInformationDecoder) FileNameLength() uint32 {
+	return le.Uint32(c[60:64])
+}
+
+// EaSize is the reparse tag if FileAttributes contains FILE_ATTRIBUTE_REPARSE_POINT.
+func (c FileFullDirectoryInformationDecoder) EaSize() uint32 {
+	return le.Uint32(c[64:68])
+}
+
+func (c FileFullDirectoryInformationDecoder) FileName() string {
+	return utf16le.DecodeToString(c[68 : 68+c.FileNameLength()])
+}
+
+type FileBothDirectoryInformationDecoder []byte
+
+func (c FileBothDirectoryInformationDecoder) IsInvalid() bool {
+	return len(c) < 94 || len(c) < int(94+c.FileNameLength()) || c.FileNameLength()%2 != 0 || c.ShortNameLength() > 24
+}
+
+func (c FileBothDirectoryInformationDecoder) NextEntryOffset() uint32 {
+	return le.Uint32(c[:4])
+}
+
+func (c FileBothDirectoryInformationDecoder) FileIndex() uint32 {
+	return le.Uint32(c[4:8])
+}
+
+func (c FileBothDirectoryInformationDecoder) CreationTime() FiletimeDecoder {
+	return FiletimeDecoder(c[8:16])
+}
+
+func (c FileBothDirectoryInformationDecoder) LastAccessTime() FiletimeDecoder {
+	return FiletimeDecoder(c[16:24])
+}
+
+func (c FileBothDirectoryInformationDecoder) LastWriteTime() FiletimeDecoder {
+	return FiletimeDecoder(c[24:32])
+}
+
+func (c FileBothDirectoryInformationDecoder) ChangeTime() FiletimeDecoder {
+	return FiletimeDecoder(c[32:40])
+}
+
+func (c FileBothDirectoryInformationDecoder) EndOfFile() int64 {
+	return int64(le.Uint64(c[40:48]))
+}
+
+func (c FileBothDirectoryInformationDecoder) AllocationSize() int64 {
+	return int64(le.Uint64(c[48:56]))
+}
+
+func (c FileBothDirectoryInformationDecoder) FileAttributes() uint32 {
+	return le.Uint32(c[56:60])
+}
+
+func (c FileBothDirectoryInformationDecoder) FileNameLength() uint32 {
+	return le.Uint32(c[60:64])
+}
+
+// EaSize is the reparse tag if FileAttributes contains FILE_ATTRIBUTE_REPARSE_POINT.
+func (c FileBothDirectoryInformationDecoder) EaSize() uint32 {
+	return le.Uint32(c[64:68])
+}
+
+func (c FileBothDirectoryInformationDecoder) ShortNameLength() uint8 {
+	return c[68]
+}
+
+func (c FileBothDirectoryInformationDecoder) ShortName() string {
+	return utf16le.DecodeToString(c[70 : 70+c.ShortNameLength()])
+}
+
+func (c FileBothDirectoryInformationDecoder) FileName() string {
+	return utf16le.DecodeToString(c[94 : 94+c.FileNameLength()])
+}
+
+type FileIdBothDirectoryInformationDecoder []byte
+
+func (c FileIdBothDirectoryInformationDecoder) IsInvalid() bool {
+	return len(c) < 104 || len(c) < int(104+c.FileNameLength()) || c.FileNameLength()%2 != 0 || c.ShortNameLength() > 24
+}
+
+func (c FileIdBothDirectoryInformationDecoder) NextEntryOffset() uint32 {
+	return le.Uint32(c[:4])
+}
+
+func (c FileIdBothDirectoryInformationDecoder) FileIndex() uint32 {
+	return le.Uint32(c[4:8])
+}
+
+func (c FileIdBothDirectoryInformationDecoder) CreationTime() FiletimeDecoder {
+	return FiletimeDecoder(c[8:16])
+}
+
+func (c FileIdBothDirectoryInformationDecoder) LastAccessTime() FiletimeDecoder {
+	return FiletimeDecoder(c[16:24])
+}
+
+func (c FileIdBothDirectoryInformationDecoder) LastWriteTime() FiletimeDecoder {
+	return FiletimeDecoder(c[24:32])
+}
+
+func (c FileIdBothDirectoryInformationDecoder) ChangeTime() FiletimeDecoder {
+	return FiletimeDecoder(c[32:40])
+}
+
+func (c FileIdBothDirectoryInformationDecoder) EndOfFile() int64 {
+	return int64(le.Uint64(c[40:48]))
+}
+
+func (c FileIdBothDirectoryInformationDecoder) AllocationSize() int64 {
+	return int64(le.Uint64(c[48:56]))
+}
+
+func (c FileIdBothDirectoryInformationDecoder) FileAttributes() uint32 {
+	return le.Uint32(c[56:60])
+}
+
+func (c FileIdBothDirectoryInformationDecoder) FileNameLength() uint32 {
+	return le.Uint32(c[60:64])
+}
+
+// EaSize is the reparse tag if FileAttributes contains FILE_ATTRIBUTE_REPARSE_POINT.
+func (c FileIdBothDirectoryInformationDecoder) EaSize() uint32 {
+	return le.Uint32(c[64:68])
+}
+
+func (c FileIdBothDirectoryInformationDecoder) ShortNameLength() uint8 {
+	return c[68]
+}
+
+func (c FileIdBothDirectoryInformationDecoder) ShortName() string {
+	return utf16le.DecodeToString(c[70 : 70+c.ShortNameLength()])
+}
+
+func (c FileIdBothDirectoryInformationDecoder) FileId() uint64 {
+	return le.Uint64(c[96:104])
+}
+
+func (c FileIdBothDirectoryInformationDecoder) FileName() string {
+	return utf16le.DecodeToString(c[104 : 104+c.FileNameLength()])
+}
+
+type FileAttributeTagInformationDecoder []byte
+
+func (c FileAttributeTagInformationDecoder) IsInvalid() bool {
+	return len(c) < 8
+}
+
+func (c FileAttributeTagInformationDecoder) FileAttributes() uint32 {
+	return le.Uint32(c[:4])
+}
+
+func (c FileAttributeTagInformationDecoder) ReparseTag() uint32 {
+	return le.Uint32(c[4:8])
+}
+
+type FileNotifyInformation struct {
+	NextEntryOffset uint32
+	Action          uint32
+	FileName        string
+}
+
+func (c *FileNotifyInformation) Size() int {
+	return 12 + utf16le.EncodedStringLen(c.FileName)
+}
+
+func (c *FileNotifyInformation) Encode(p []byte) {
+	fname := p[12:]
+	fnlen := utf16le.EncodeString(fname, c.FileName)
+
+	le.PutUint32(p[:4], c.NextEntryOffset)
+	le.PutUint32(p[4:8], c.Action)
+	le.PutUint32(p[8:12], uint32(fnlen))
+}
+
+type FileNotifyInformationDecoder []byte
+
+func (c FileNotifyInformationDecoder) IsInvalid() bool {
+	return len(c) < 12 || len(c) < int(12+c.FileNameLength()) || c.FileNameLength()%2 != 0
+}
+
+func (c FileNotifyInformationDecoder) NextEntryOffset() uint32 {
+	return le.Uint32(c[:4])
+}
+
+func (c FileNotifyInformationDecoder) Action() uint32 {
+	return le.Uint32(c[4:8])
+}
+
+func (c FileNotifyInformationDecoder) FileNameLength() uint32 {
+	return le.Uint32(c[8:12])
+}
+
+func (c FileNotifyInformationDecoder) FileName() string {
+	return utf16le.DecodeToString(c[12 : 12+c.FileNameLength()])
+}
+
 type FileRenameInformationType2Encoder struct {
 	ReplaceIfExists uint8
 	RootDirectory   uint64
@@ -398,6 +959,18 @@ func (c *FileDispositionInformationEncoder) Encode(p []byte) {
 	p[0] = c.DeletePending
 }
 
+type FileCaseSensitiveInformationEncoder struct {
+	Flags uint32
+}
+
+func (c *FileCaseSensitiveInformationEncoder) Size() int {
+	return 4
+}
+
+func (c *FileCaseSensitiveInformationEncoder) Encode(p []byte) {
+	le.PutUint32(p[:4], c.Flags)
+}
+
 type FilePositionInformationEncoder struct {
 	CurrentByteOffset int64
 }
@@ -695,3 +1268,82 @@ func (c FileNameInformationDecoder) FileNameLength() uint32 {
 func (c FileNameInformationDecoder) FileName() string {
 	return utf16le.DecodeToString(c[4 : 4+c.FileNameLength()])
 }
+
+type FilePipeInformationDecoder []byte
+
+func (c FilePipeInformationDecoder) IsInvalid() bool {
+	return len(c) < 8
+}
+
+func (c FilePipeInformationDecoder) ReadMode() uint32 {
+	return le.Uint32(c[:4])
+}
+
+func (c FilePipeInformationDecoder) CompletionMode() uint32 {
+	return le.Uint32(c[4:8])
+}
+
+type FilePipeLocalInformationDecoder []byte
+
+func (c FilePipeLocalInformationDecoder) IsInvalid() bool {
+	return len(c) < 40
+}
+
+func (c FilePipeLocalInformationDecoder) NamedPipeType() uint32 {
+	return le.Uint32(c[:4])
+}
+
+func (c FilePipeLocalInformationDecoder) NamedPipeConfiguration() uint32 {
+	return le.Uint32(c[4:8])
+}
+
+func (c FilePipeLocalInformationDecoder) MaximumInstances() uint32 {
+	return le.Uint32(c[8:12])
+}
+
+func (c FilePipeLocalInformationDecoder) CurrentInstances() uint32 {
+	return le.Uint32(c[12:16])
+}
+
+func (c FilePipeLocalInformationDecoder) InboundQuota() uint32 {
+	return le.Uint32(c[16:20])
+}
+
+func (c FilePipeLocalInformationDecoder) ReadDataAvailable() uint32 {
+	return le.Uint32(c[20:24])
+}
+
+func (c FilePipeLocalInformationDecoder) OutboundQuota() uint32 {
+	return le.Uint32(c[24:28])
+}
+
+func (c FilePipeLocalInformationDecoder) WriteQuotaAvailable() uint32 {
+	return le.Uint32(c[28:32])
+}
+
+func (c FilePipeLocalInformationDecoder) NamedPipeState() uint32 {
+	return le.Uint32(c[32:36])
+}
+
+func (c FilePipeLocalInformationDecoder) NamedPipeEnd() uint32 {
+	return le.Uint32(c[36:40])
+}
+
+type PipeWaitRequest struct {
+	Timeout          int64
+	TimeoutSpecified bool
+	Name             string
+}
+
+func (c *PipeWaitRequest) Size() int {
+	return 14 + utf16le.EncodedStringLen(c.Name)
+}
+
+func (c *PipeWaitRequest) Encode(p []byte) {
+	le.PutUint64(p[:8], uint64(c.Timeout))
+	if c.TimeoutSpecified {
+		p[12] = 1
+	}
+	nlen := utf16le.EncodeString(p[14:], c.Name)
+	le.PutUint32(p[8:12], uint32(nlen))
+}
@@ -85,7 +85,7 @@ func (r NegotiateRequestDecoder) IsInvalid() bool {
 		return true
 	}
 
-	if len(r) < int(noff)-36 {
+	if len(r) < int(noff)-64 {
 		return true
 	}
 
@@ -137,10 +137,10 @@ func (r NegotiateRequestDecoder) NegotiateContextCount() uint16 {
 
 func (r NegotiateRequestDecoder) NegotiateContextList() []byte {
 	off := r.NegotiateContextOffset()
-	if off < 36 {
+	if off < 64+36 || len(r) < int(off)-64 {
 		return nil
 	}
-	return r[off-36:]
+	return r[off-64:]
 }
 
 // ----------------------------------------------------------------------------
@@ -434,6 +434,7 @@ type CreateRequest struct {
 	CreateDisposition    uint32
 	CreateOptions        uint32
 	Name                 string
+	NameBytes            []byte // raw name buffer; it takes precedence over Name (e.g. a file id for FILE_OPEN_BY_FILE_ID)
 
 	Contexts []Encoder
 }
@@ -443,11 +444,16 @@ func (c *CreateRequest) Header() *PacketHeader {
 }
 
 func (c *CreateRequest) Size() int {
-	if len(c.Name) == 0 && len(c.Contexts) == 0 {
+	nlen := len(c.NameBytes)
+	if nlen == 0 {
+		nlen = utf16le.EncodedStringLen(c.Name)
+	}
+
+	if nlen == 0 && len(c.Contexts) == 0 {
 		return 64 + 56 + 1
 	}
 
-	size := 64 + 56 + utf16le.EncodedStringLen(c.Name)
+	size := 64 + 56 + nlen
 
 	for _, ctx := range c.Contexts {
 		size = Roundup(size, 8)
@@ -474,35 +480,41 @@ func (c *CreateRequest) Encode(pkt []byte) {
 	le.PutUint32(req[40:44], c.CreateOptions)
 
 	// Name
-	nlen := utf16le.EncodeString(req[56:], c.Name)
+	var nlen int
+	if len(c.NameBytes) != 0 {
+		nlen = copy(req[56:], c.NameBytes)
+	} else {
+		nlen = utf16le.EncodeString(req[56:], c.Name)
+	}
 
 	le.PutUint16(req[44:46], 56+64)
 	le.PutUint16(req[46:48], uint16(nlen))
 
 	off := 56 + nlen
 
-	var ctx []byte
-	var next int
+	var coff int
+	var prev int
 
 	for i, c := range c.Contexts {
 		off = Roundup(off, 8)
 
 		if i == 0 {
+			coff = off
 			le.PutUint32(req[48:52], uint32(64+off)) // CreateContextsOffset
 		} else {
-			le.PutUint32(ctx[:4], uint32(next)) // Next
+			le.PutUint32(req[prev:prev+4], uint32(off-prev)) // Next
 		}
 
-		ctx = req[off:]
-
-		c.Encode(ctx)
+		c.Encode(req[off:])
 
-		next = c.Size()
+		prev = off
 
-		off += next
+		off += c.Size()
 	}
 
-	le.PutUint32(req[52:56], uint32(off-(56+nlen))) // CreateContextsLength
+	if len(c.Contexts) > 0 {
+		le.PutUint32(req[52:56], uint32(off-coff)) // CreateContextsLength
+	}
 }
 
 type CreateRequestDecoder []byte
@@ -960,6 +972,57 @@ func (r WriteRequestDecoder) Flags() uint32 {
 // SMB2 OPLOCK_BREAK Acknowledgement
 //
 
+type OplockBreakAcknowledgement struct {
+	PacketHeader
+
+	OplockLevel uint8
+	FileId      *FileId
+}
+
+func (c *OplockBreakAcknowledgement) Header() *PacketHeader {
+	return &c.PacketHeader
+}
+
+func (c *OplockBreakAcknowledgement) Size() int {
+	return 64 + 24
+}
+
+func (c *OplockBreakAcknowledgement) Encode(pkt []byte) {
+	c.Command = SMB2_OPLOCK_BREAK
+	c.encodeHeader(pkt)
+
+	req := pkt[64:]
+	le.PutUint16(req[:2], 24) // StructureSize
+	req[2] = c.OplockLevel
+	c.FileId.Encode(req[8:24])
+}
+
+type OplockBreakAcknowledgementDecoder []byte
+
+func (r OplockBreakAcknowledgementDecoder) IsInvalid() bool {
+	if len(r) < 24 {
+		return true
+	}
+
+	if r.StructureSize() != 24 {
+		return true
+	}
+
+	return false
+}
+
+func (r OplockBreakAcknowledgementDecoder) StructureSize() uint16 {
+	return le.Uint16(r[:2])
+}
+
+func (r OplockBreakAcknowledgementDecoder) OplockLevel() uint8 {
+	return r[2]
+}
+
+func (r OplockBreakAcknowledgementDecoder) FileId() FileIdDecoder {
+	return FileIdDecoder(r[8:24])
+}
+
 // ----------------------------------------------------------------------------
 // SMB2 LOCK Request Packet
 //
@@ -968,6 +1031,44 @@ func (r WriteRequestDecoder) Flags() uint32 {
 // SMB2 ECHO Request Packet
 //
 
+type EchoRequest struct {
+	PacketHeader
+}
+
+func (c *EchoRequest) Header() *PacketHeader {
+	return &c.PacketHeader
+}
+
+func (c *EchoRequest) Size() int {
+	return 64 + 4
+}
+
+func (c *EchoRequest) Encode(pkt []byte) {
+	c.Command = SMB2_ECHO
+	c.encodeHeader(pkt)
+
+	req := pkt[64:]
+	le.PutUint16(req[:2], 4) // StructureSize
+}
+
+type EchoRequestDecoder []byte
+
+func (r EchoRequestDecoder) IsInvalid() bool {
+	if len(r) < 4 {
+		return true
+	}
+
+	if r.StructureSize() != 4 {
+		return true
+	}
+
+	return false
+}
+
+func (r EchoRequestDecoder) StructureSize() uint16 {
+	return le.Uint16(r[:2])
+}
+
 // ----------------------------------------------------------------------------
 // SMB2 CANCEL Request Packet
 //
@@ -1224,6 +1325,69 @@ func (r QueryDirectoryRequestDecoder) OutputBufferLength() uint32 {
 // SMB2 CHANGE_NOTIFY Request Packet
 //
 
+type ChangeNotifyRequest struct {
+	PacketHeader
+
+	Flags              uint16
+	OutputBufferLength uint32
+	FileId             *FileId
+	CompletionFilter   uint32
+}
+
+func (c *ChangeNotifyRequest) Header() *PacketHeader {
+	return &c.PacketHeader
+}
+
+func (c *ChangeNotifyRequest) Size() int {
+	return 64 + 32
+}
+
+func (c *ChangeNotifyRequest) Encode(pkt []byte) {
+	c.Command = SMB2_CHANGE_NOTIFY
+	c.encodeHeader(pkt)
+
+	req := pkt[64:]
+	le.PutUint16(req[:2], 32) // StructureSize
+	le.PutUint16(req[2:4], c.Flags)
+	le.PutUint32(req[4:8], c.OutputBufferLength)
+	c.FileId.Encode(req[8:24])
+	le.PutUint32(req[24:28], c.CompletionFilter)
+}
+
+type ChangeNotifyRequestDecoder []byte
+
+func (r ChangeNotifyRequestDecoder) IsInvalid() bool {
+	if len(r) < 32 {
+		return true
+	}
+
+	if r.StructureSize() != 32 {
+		return true
+	}
+
+	return false
+}
+
+func (r ChangeNotifyRequestDecoder) StructureSize() uint16 {
+	return le.Uint16(r[:2])
+}
+
+func (r ChangeNotifyRequestDecoder) Flags() uint16 {
+	return le.Uint16(r[2:4])
+}
+
+func (r ChangeNotifyRequestDecoder) OutputBufferLength() uint32 {
+	return le.Uint32(r[4:8])
+}
+
+func (r ChangeNotifyRequestDecoder) FileId() FileIdDecoder {
+	return FileIdDecoder(r[8:24])
+}
+
+func (r ChangeNotifyRequestDecoder) CompletionFilter() uint32 {
+	return le.Uint32(r[24:28])
+}
+
 // ----------------------------------------------------------------------------
 // SMB2 QUERY_INFO Request Packet
 //
@@ -30,7 +30,7 @@ func (c *ErrorResponse) Encode(pkt []byte) {
 	res := pkt[64:]
 	le.PutUint16(res[:2], 9) // StructureSize
 	if c.ErrorData != nil {
-		le.PutUint16(res[2:4], uint16(c.ErrorData.Size()))
+		le.PutUint32(res[4:8], uint32(c.ErrorData.Size())) // ByteCount
 		c.ErrorData.Encode(res[8:])
 
 		if e, ok := c.ErrorData.(ErrorContextListResponse); ok {
@@ -187,15 +187,15 @@ func (c *SymbolicLinkErrorResponse) Size() int {
 }
 
 func (c *SymbolicLinkErrorResponse) Encode(p []byte) {
-	slen := utf16le.EncodeString(p[24:], c.SubstituteName)
-	plen := utf16le.EncodeString(p[24+slen:], c.PrintName)
+	slen := utf16le.EncodeString(p[28:], c.SubstituteName)
+	plen := utf16le.EncodeString(p[28+slen:], c.PrintName)
 
 	le.PutUint32(p[:4], uint32(len(p)-4)) // SymLinkLength
 	le.PutUint32(p[4:8], 0x4c4d5953)
 	le.PutUint32(p[8:12], IO_REPARSE_TAG_SYMLINK)
 	le.PutUint16(p[14:16], c.UnparsedPathLength)
 	le.PutUint32(p[24:28], c.Flags)
-	le.PutUint16(p[12:14], uint16(len(p)-12)) // ReparseDataLength
+	le.PutUint16(p[12:14], uint16(len(p)-16)) // ReparseDataLength
 	le.PutUint16(p[16:18], 0)                 // SubstituteNameOffset
 	le.PutUint16(p[18:20], uint16(slen))      // SubstituteNameLength
 	le.PutUint16(p[20:22], uint16(slen))      // PrintNameOffset
@@ -710,7 +710,7 @@ func (c *TreeDisconnectResponse) Header() *PacketHeader {
 }
 
 func (c *TreeDisconnectResponse) Size() int {
-	return 4
+	return 64 + 4
 }
 
 func (c *TreeDisconnectResponse) Encode(pkt []byte) {
@@ -800,8 +800,7 @@ func (c *CreateResponse) Encode(pkt []byte) {
 
 	off := 88
 
-	var ctx []byte
-	var next int
+	var prev int
 
 	for i, c := range c.Contexts {
 		off = Roundup(off, 8)
@@ -809,16 +808,14 @@ func (c *CreateResponse) Encode(pkt []byte) {
 		if i == 0 {
 			le.PutUint32(res[80:84], uint32(64+off)) // CreateContextsOffset
 		} else {
-			le.PutUint32(ctx[:4], uint32(next)) // Next
+			le.PutUint32(res[prev:prev+4], uint32(off-prev)) // Next
 		}
 
-		ctx = res[off:]
-
-		c.Encode(ctx)
+		c.Encode(res[off:])
 
-		next = c.Size()
+		prev = off
 
-		off += next
+		off += c.Size()
 	}
 
 	le.PutUint32(res[84:88], uint32(off-88)) // CreateContextsLength
@@ -1200,6 +1197,59 @@ func (r WriteResponseDecoder) WriteChannelInfoLength() uint16 {
 // SMB2 OPLOCK_BREAK Notification and Response
 //
 
+// OplockBreakResponse is the notification sent by the server to break an oplock,
+// and the response to the acknowledgement of the client, which have the same format.
+type OplockBreakResponse struct {
+	PacketHeader
+
+	OplockLevel uint8
+	FileId      *FileId
+}
+
+func (c *OplockBreakResponse) Header() *PacketHeader {
+	return &c.PacketHeader
+}
+
+func (c *OplockBreakResponse) Size() int {
+	return 64 + 24
+}
+
+func (c *OplockBreakResponse) Encode(pkt []byte) {
+	c.Command = SMB2_OPLOCK_BREAK
+	c.encodeHeader(pkt)
+
+	res := pkt[64:]
+	le.PutUint16(res[:2], 24) // StructureSize
+	res[2] = c.OplockLevel
+	c.FileId.Encode(res[8:24])
+}
+
+type OplockBreakResponseDecoder []byte
+
+func (r OplockBreakResponseDecoder) IsInvalid() bool {
+	if len(r) < 24 {
+		return true
+	}
+
+	if r.StructureSize() != 24 {
+		return true
+	}
+
+	return false
+}
+
+func (r OplockBreakResponseDecoder) StructureSize() uint16 {
+	return le.Uint16(r[:2])
+}
+
+func (r OplockBreakResponseDecoder) OplockLevel() uint8 {
+	return r[2]
+}
+
+func (r OplockBreakResponseDecoder) FileId() FileIdDecoder {
+	return FileIdDecoder(r[8:24])
+}
+
 // ----------------------------------------------------------------------------
 // SMB2 LOCK Response
 //
@@ -1208,6 +1258,44 @@ func (r WriteResponseDecoder) WriteChannelInfoLength() uint16 {
 // SMB2 ECHO Response
 //
 
+type EchoResponse struct {
+	PacketHeader
+}
+
+func (c *EchoResponse) Header() *PacketHeader {
+	return &c.PacketHeader
+}
+
+func (c *EchoResponse) Size() int {
+	return 64 + 4
+}
+
+func (c *EchoResponse) Encode(pkt []byte) {
+	c.Command = SMB2_ECHO
+	c.encodeHeader(pkt)
+
+	res := pkt[64:]
+	le.PutUint16(res[:2], 4) // StructureSize
+}
+
+type EchoResponseDecoder []byte
+
+func (r EchoResponseDecoder) IsInvalid() bool {
+	if len(r) < 4 {
+		return true
+	}
+
+	if r.StructureSize() != 4 {
+		return true
+	}
+
+	return false
+}
+
+func (r EchoResponseDecoder) StructureSize() uint16 {
+	return le.Uint16(r[:2])
+}
+
 // ----------------------------------------------------------------------------
 // SMB2 IOCTL Response
 //
@@ -1230,7 +1318,14 @@ func (c *IoctlResponse) Size() int {
 	if c.Input == nil && c.Output == nil {
 		return 64 + 48 + 1
 	}
-	return 64 + 48 + c.Input.Size() + c.Output.Size()
+	size := 64 + 48
+	if c.Input != nil {
+		size += c.Input.Size()
+	}
+	if c.Output != nil {
+		size += c.Output.Size()
+	}
+	return size
 }
 
 func (c *IoctlResponse) Encode(pkt []byte) {
@@ -1425,6 +1520,79 @@ func (r QueryDirectoryResponseDecoder) OutputBuffer() []byte {
 // SMB2 CHANGE_NOTIFY Response
 //
 
+type ChangeNotifyResponse struct {
+	PacketHeader
+
+	Output Encoder
+}
+
+func (c *ChangeNotifyResponse) Header() *PacketHeader {
+	return &c.PacketHeader
+}
+
+func (c *ChangeNotifyResponse) Size() int {
+	if c.Output == nil {
+		return 64 + 8 + 1
+	}
+	return 64 + 8 + c.Output.Size()
+}
+
+func (c *ChangeNotifyResponse) Encode(pkt []byte) {
+	c.Command = SMB2_CHANGE_NOTIFY
+	c.encodeHeader(pkt)
+
+	res := pkt[64:]
+	le.PutUint16(res[:2], 9) // StructureSize
+
+	off := 8
+
+	if c.Output != nil {
+		le.PutUint16(res[2:4], uint16(off+64))
+		c.Output.Encode(res[8:])
+		le.PutUint32(res[4:8], uint32(c.Output.Size()))
+	}
+}
+
+type ChangeNotifyResponseDecoder []byte
+
+func (r ChangeNotifyResponseDecoder) IsInvalid() bool {
+	if len(r) < 8 {
+		return true
+	}
+
+	if r.StructureSize() != 9 {
+		return true
+	}
+
+	if len(r) < int(uint32(r.OutputBufferOffset())+r.OutputBufferLength())-64 {
+		return true
+	}
+
+	return false
+}
+
+func (r ChangeNotifyResponseDecoder) StructureSize() uint16 {
+	return le.Uint16(r[:2])
+}
+
+func (r ChangeNotifyResponseDecoder) OutputBufferOffset() uint16 {
+	return le.Uint16(r[2:4])
+}
+
+func (r ChangeNotifyResponseDecoder) OutputBufferLength() uint32 {
+	return le.Uint32(r[4:8])
+}
+
+func (r ChangeNotifyResponseDecoder) OutputBuffer() []byte {
+	off := r.OutputBufferOffset()
+	if off < 64+8 {
+		return nil
+	}
+	off -= 64
+	len := r.OutputBufferLength()
+	return r[off : uint32(off)+len]
+}
+
 // ----------------------------------------------------------------------------
 // SMB2 QUERY_INFO Response
 //
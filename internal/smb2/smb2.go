@@ -124,6 +124,56 @@ func (c *CipherContext) Encode(p []byte) {
 	}
 }
 
+type SigningContext struct {
+	SigningAlgorithms []uint16
+}
+
+func (c *SigningContext) Size() int {
+	return 8 + 2 + len(c.SigningAlgorithms)*2
+}
+
+func (c *SigningContext) Encode(p []byte) {
+	le.PutUint16(p[:2], SMB2_SIGNING_CAPABILITIES)             // ContextType
+	le.PutUint16(p[2:4], uint16(2+len(c.SigningAlgorithms)*2)) // DataLength
+
+	{
+		d := NegotiateContextDecoder(p).Data()
+
+		{ // SigningAlgorithms
+			bs := d[2:]
+			for i, a := range c.SigningAlgorithms {
+				le.PutUint16(bs[2*i:2*i+2], a)
+			}
+			le.PutUint16(d[:2], uint16(len(c.SigningAlgorithms))) // SigningAlgorithmCount
+		}
+	}
+}
+
+type RDMATransformContext struct {
+	RDMATransformIds []uint16
+}
+
+func (c *RDMATransformContext) Size() int {
+	return 8 + 8 + len(c.RDMATransformIds)*2
+}
+
+func (c *RDMATransformContext) Encode(p []byte) {
+	le.PutUint16(p[:2], SMB2_RDMA_TRANSFORM_CAPABILITIES)     // ContextType
+	le.PutUint16(p[2:4], uint16(8+len(c.RDMATransformIds)*2)) // DataLength
+
+	{
+		d := NegotiateContextDecoder(p).Data()
+
+		{ // RDMATransformIds
+			bs := d[8:]
+			for i, id := range c.RDMATransformIds {
+				le.PutUint16(bs[2*i:2*i+2], id)
+			}
+			le.PutUint16(d[:2], uint16(len(c.RDMATransformIds))) // TransformCount
+		}
+	}
+}
+
 // From SMB311
 
 type NegotiateContextDecoder []byte
@@ -223,6 +273,33 @@ func (c CipherContextDataDecoder) Ciphers() []uint16 {
 	return cs
 }
 
+type SigningContextDataDecoder []byte
+
+func (c SigningContextDataDecoder) IsInvalid() bool {
+	if len(c) < 2 {
+		return true
+	}
+
+	if len(c) < 2+int(c.SigningAlgorithmCount())*2 {
+		return true
+	}
+
+	return false
+}
+
+func (c SigningContextDataDecoder) SigningAlgorithmCount() uint16 {
+	return le.Uint16(c[:2])
+}
+
+func (c SigningContextDataDecoder) SigningAlgorithms() []uint16 {
+	bs := c[2:]
+	as := make([]uint16, c.SigningAlgorithmCount())
+	for i := range as {
+		as[i] = le.Uint16(bs[2*i : 2*i+2])
+	}
+	return as
+}
+
 type QueryQuotaInfo struct {
 	ReturnSingle bool
 	RestartScan  bool
@@ -269,3 +346,153 @@ func (q *QueryQuotaInfo) Encode(p []byte) {
 		}
 	}
 }
+
+// ----------------------------------------------------------------------------
+// SMB2 CREATE Contexts
+//
+
+type CreateContextRequest struct {
+	Name []byte
+	Data []byte
+}
+
+func (c *CreateContextRequest) Size() int {
+	if len(c.Data) == 0 {
+		return 16 + len(c.Name)
+	}
+	return Roundup(16+len(c.Name), 8) + len(c.Data)
+}
+
+func (c *CreateContextRequest) Encode(p []byte) {
+	le.PutUint16(p[4:6], 16)                  // NameOffset
+	le.PutUint16(p[6:8], uint16(len(c.Name))) // NameLength
+	copy(p[16:], c.Name)
+
+	if len(c.Data) != 0 {
+		off := Roundup(16+len(c.Name), 8)
+		copy(p[off:], c.Data)
+		le.PutUint16(p[10:12], uint16(off))         // DataOffset
+		le.PutUint32(p[12:16], uint32(len(c.Data))) // DataLength
+	}
+}
+
+type CreateContextDecoder []byte
+
+func (ctx CreateContextDecoder) IsInvalid() bool {
+	if len(ctx) < 16 {
+		return true
+	}
+
+	if len(ctx) < int(ctx.NameOffset())+int(ctx.NameLength()) {
+		return true
+	}
+
+	if ctx.DataLength() != 0 && len(ctx) < int(ctx.DataOffset())+int(ctx.DataLength()) {
+		return true
+	}
+
+	return false
+}
+
+func (ctx CreateContextDecoder) Next() uint32 {
+	return le.Uint32(ctx[:4])
+}
+
+func (ctx CreateContextDecoder) NameOffset() uint16 {
+	return le.Uint16(ctx[4:6])
+}
+
+func (ctx CreateContextDecoder) NameLength() uint16 {
+	return le.Uint16(ctx[6:8])
+}
+
+func (ctx CreateContextDecoder) DataOffset() uint16 {
+	return le.Uint16(ctx[10:12])
+}
+
+func (ctx CreateContextDecoder) DataLength() uint32 {
+	return le.Uint32(ctx[12:16])
+}
+
+func (ctx CreateContextDecoder) Name() []byte {
+	off := ctx.NameOffset()
+	len := ctx.NameLength()
+	return ctx[off : off+len]
+}
+
+func (ctx CreateContextDecoder) Data() []byte {
+	if ctx.DataLength() == 0 {
+		return nil
+	}
+	off := uint32(ctx.DataOffset())
+	len := ctx.DataLength()
+	return ctx[off : off+len]
+}
+
+type CompressionContextDataDecoder []byte
+
+func (c CompressionContextDataDecoder) IsInvalid() bool {
+	if len(c) < 8 {
+		return true
+	}
+
+	if len(c) < 8+int(c.CompressionAlgorithmCount())*2 {
+		return true
+	}
+
+	return false
+}
+
+func (c CompressionContextDataDecoder) CompressionAlgorithmCount() uint16 {
+	return le.Uint16(c[:2])
+}
+
+func (c CompressionContextDataDecoder) Flags() uint32 {
+	return le.Uint32(c[4:8])
+}
+
+func (c CompressionContextDataDecoder) CompressionAlgorithms() []uint16 {
+	bs := c[8:]
+	algs := make([]uint16, c.CompressionAlgorithmCount())
+	for i := range algs {
+		algs[i] = le.Uint16(bs[2*i : 2*i+2])
+	}
+	return algs
+}
+
+type TransportContextDataDecoder []byte
+
+func (c TransportContextDataDecoder) IsInvalid() bool {
+	return len(c) < 4
+}
+
+func (c TransportContextDataDecoder) Flags() uint32 {
+	return le.Uint32(c[:4])
+}
+
+type RDMATransformContextDataDecoder []byte
+
+func (c RDMATransformContextDataDecoder) IsInvalid() bool {
+	if len(c) < 8 {
+		return true
+	}
+
+	if len(c) < 8+int(c.TransformCount())*2 {
+		return true
+	}
+
+	return false
+}
+
+func (c RDMATransformContextDataDecoder) TransformCount() uint16 {
+	return le.Uint16(c[:2])
+}
+
+func (c RDMATransformContextDataDecoder) RDMATransformIds() []uint16 {
+	bs := c[8:]
+	ids := make([]uint16, c.TransformCount())
+	for i := range ids {
+		ids[i] = le.Uint16(bs[2*i : 2*i+2])
+	}
+	return ids
+}
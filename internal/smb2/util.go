@@ -2,7 +2,8 @@ package smb2
 
 import (
 	"encoding/binary"
-	"unicode/utf16"
+
+	"github.com/nodauf/go-smb2/internal/utf16le"
 )
 
 var (
@@ -14,9 +15,10 @@ func Roundup(x, align int) int {
 }
 
 func UTF16FromString(s string) []uint16 {
-	return utf16.Encode([]rune(s))
+	return utf16le.Encode(s)
 }
 
 func UTF16ToString(s []uint16) string {
-	return string(utf16.Decode(s))
+	str, _ := utf16le.Decode(s, utf16le.Preserve)
+	return str
 }
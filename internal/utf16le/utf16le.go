@@ -2,27 +2,150 @@ package utf16le
 
 import (
 	"encoding/binary"
+	"errors"
 	"unicode/utf16"
+	"unicode/utf8"
 )
 
 var (
 	le = binary.LittleEndian
 )
 
+// DecodeMode selects how malformed UTF-16 (unpaired surrogates and odd lengths) is decoded.
+type DecodeMode int
+
+const (
+	// Preserve keeps unpaired surrogates as their 3-byte generalized UTF-8 (WTF-8) sequences,
+	// which the encoding functions turn back into the same surrogates, so that names round-trip.
+	// The decoded strings aren't valid UTF-8 then.
+	Preserve DecodeMode = iota
+
+	// Replace substitutes U+FFFD for unpaired surrogates.
+	Replace
+
+	// Strict fails with ErrMalformed.
+	Strict
+)
+
+var ErrMalformed = errors.New("malformed UTF-16")
+
+const (
+	surr1    = 0xd800
+	surr2    = 0xdc00
+	surr3    = 0xe000
+	replChar = '\uFFFD'
+)
+
+// decodeSurrogate returns the unpaired surrogate encoded by Decode at the start of s.
+func decodeSurrogate(s string) (uint16, bool) {
+	if len(s) < 3 || s[0] != 0xed || s[1]&0xe0 != 0xa0 || s[2]&0xc0 != 0x80 {
+		return 0, false
+	}
+	return 0xd000 | uint16(s[1]&0x3f)<<6 | uint16(s[2]&0x3f), true
+}
+
+// Encode returns the UTF-16 code units of s. Unpaired surrogates decoded by Preserve are encoded as is.
+func Encode(s string) []uint16 {
+	ws := make([]uint16, 0, len(s))
+	for len(s) > 0 {
+		if w, ok := decodeSurrogate(s); ok {
+			ws = append(ws, w)
+			s = s[3:]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		if r >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(r)
+			ws = append(ws, uint16(r1), uint16(r2))
+		} else {
+			ws = append(ws, uint16(r))
+		}
+		s = s[size:]
+	}
+	return ws
+}
+
+// Decode returns the string of the UTF-16 code units ws, decoding unpaired surrogates by mode.
+func Decode(ws []uint16, mode DecodeMode) (string, error) {
+	bs := make([]byte, 0, len(ws))
+	var buf [utf8.UTFMax]byte
+	for i := 0; i < len(ws); i++ {
+		w := ws[i]
+		switch {
+		case w < surr1 || surr3 <= w:
+			n := utf8.EncodeRune(buf[:], rune(w))
+			bs = append(bs, buf[:n]...)
+		case w < surr2 && i+1 < len(ws) && surr2 <= ws[i+1] && ws[i+1] < surr3:
+			n := utf8.EncodeRune(buf[:], utf16.DecodeRune(rune(w), rune(ws[i+1])))
+			bs = append(bs, buf[:n]...)
+			i++
+		default:
+			switch mode {
+			case Preserve:
+				bs = append(bs, 0xe0|byte(w>>12), 0x80|byte(w>>6)&0x3f, 0x80|byte(w)&0x3f)
+			case Replace:
+				n := utf8.EncodeRune(buf[:], replChar)
+				bs = append(bs, buf[:n]...)
+			default:
+				return "", ErrMalformed
+			}
+		}
+	}
+	return string(bs), nil
+}
+
+// Sanitize applies mode to a string decoded by Preserve.
+func Sanitize(s string, mode DecodeMode) (string, error) {
+	if mode == Preserve {
+		return s, nil
+	}
+
+	var bs []byte
+	for i := 0; i < len(s); {
+		if _, ok := decodeSurrogate(s[i:]); !ok {
+			if bs != nil {
+				bs = append(bs, s[i])
+			}
+			i++
+			continue
+		}
+
+		if mode != Replace {
+			return "", ErrMalformed
+		}
+		if bs == nil {
+			bs = append(make([]byte, 0, len(s)), s[:i]...)
+		}
+		bs = append(bs, string(replChar)...)
+		i += 3
+	}
+	if bs == nil {
+		return s, nil
+	}
+	return string(bs), nil
+}
+
 func EncodedStringLen(s string) int {
 	l := 0
-	for _, r := range s {
+	for len(s) > 0 {
+		if _, ok := decodeSurrogate(s); ok {
+			l += 2
+			s = s[3:]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s)
 		if 0x10000 <= r && r <= '\U0010FFFF' {
 			l += 4
 		} else {
 			l += 2
 		}
+		s = s[size:]
 	}
 	return l
 }
 
 func EncodeString(dst []byte, src string) int {
-	ws := utf16.Encode([]rune(src))
+	ws := Encode(src)
 	for i, w := range ws {
 		le.PutUint16(dst[2*i:2*i+2], w)
 	}
@@ -33,7 +156,7 @@ func EncodeStringToBytes(s string) []byte {
 	if len(s) == 0 {
 		return nil
 	}
-	ws := utf16.Encode([]rune(s))
+	ws := Encode(s)
 	bs := make([]byte, len(ws)*2)
 	for i, w := range ws {
 		le.PutUint16(bs[2*i:2*i+2], w)
@@ -41,9 +164,20 @@ func EncodeStringToBytes(s string) []byte {
 	return bs
 }
 
+// DecodeToString decodes bs by Preserve. A trailing NUL is dropped.
 func DecodeToString(bs []byte) string {
+	s, _ := DecodeBytes(bs, Preserve)
+	return s
+}
+
+// DecodeBytes decodes bs by mode. A trailing NUL is dropped.
+// An odd trailing byte is decoded as U+FFFD, or fails with ErrMalformed by Strict.
+func DecodeBytes(bs []byte, mode DecodeMode) (string, error) {
 	if len(bs) == 0 {
-		return ""
+		return "", nil
+	}
+	if len(bs)%2 != 0 && mode == Strict {
+		return "", ErrMalformed
 	}
 	ws := make([]uint16, len(bs)/2)
 	for i := range ws {
@@ -52,5 +186,12 @@ func DecodeToString(bs []byte) string {
 	if len(ws) > 0 && ws[len(ws)-1] == 0 {
 		ws = ws[:len(ws)-1]
 	}
-	return string(utf16.Decode(ws))
+	s, err := Decode(ws, mode)
+	if err != nil {
+		return "", err
+	}
+	if len(bs)%2 != 0 {
+		s += string(replChar)
+	}
+	return s, nil
 }
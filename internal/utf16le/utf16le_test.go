@@ -0,0 +1,92 @@
+package utf16le
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, s := range []string{"", "abc", "日本語", "😀 emoji", "𠀋"} {
+		bs := EncodeStringToBytes(s)
+		if len(bs) != EncodedStringLen(s) {
+			t.Errorf("%q: expected length %d, got %d", s, EncodedStringLen(s), len(bs))
+		}
+		if got := DecodeToString(bs); got != s {
+			t.Errorf("expected %q, got %q", s, got)
+		}
+	}
+}
+
+func TestUnpairedSurrogates(t *testing.T) {
+	tests := []struct {
+		ws      []uint16
+		replace string
+	}{
+		{[]uint16{'a', 0xd800, 'b'}, "a�b"}, // lone high surrogate
+		{[]uint16{0xdc00}, "�"},             // lone low surrogate
+		{[]uint16{0xdbff, 0xd800, 0xdc00}, "�\U00010000"},
+		{[]uint16{0xdc00, 0xd800}, "��"}, // reversed pair
+	}
+
+	for _, tt := range tests {
+		s, err := Decode(tt.ws, Preserve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if utf8.ValidString(s) {
+			t.Errorf("%x: preserved surrogates must not be valid UTF-8: %q", tt.ws, s)
+		}
+		if ws := Encode(s); !reflect.DeepEqual(ws, tt.ws) {
+			t.Errorf("%x doesn't round-trip: %x", tt.ws, ws)
+		}
+		if n := EncodedStringLen(s); n != 2*len(tt.ws) {
+			t.Errorf("%x: expected encoded length %d, got %d", tt.ws, 2*len(tt.ws), n)
+		}
+
+		r, err := Decode(tt.ws, Replace)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r != tt.replace {
+			t.Errorf("%x: expected %q, got %q", tt.ws, tt.replace, r)
+		}
+		if r, _ := Sanitize(s, Replace); r != tt.replace {
+			t.Errorf("%x: expected sanitized %q, got %q", tt.ws, tt.replace, r)
+		}
+
+		if _, err := Decode(tt.ws, Strict); err != ErrMalformed {
+			t.Errorf("%x: expected ErrMalformed, got %v", tt.ws, err)
+		}
+		if _, err := Sanitize(s, Strict); err != ErrMalformed {
+			t.Errorf("%x: expected sanitized ErrMalformed, got %v", tt.ws, err)
+		}
+	}
+}
+
+func TestDecodeBytes(t *testing.T) {
+	bs := []byte{'a', 0, 'b'} // odd length
+
+	if s, err := DecodeBytes(bs, Replace); err != nil || s != "a�" {
+		t.Errorf("unexpected result: %q, %v", s, err)
+	}
+	if _, err := DecodeBytes(bs, Strict); err != ErrMalformed {
+		t.Errorf("expected ErrMalformed, got %v", err)
+	}
+
+	// the trailing NUL is dropped
+	if s := DecodeToString([]byte{'a', 0, 0, 0}); s != "a" {
+		t.Errorf("unexpected result: %q", s)
+	}
+
+	// valid strings are left as is
+	if s, err := Sanitize("abc😀", Strict); err != nil || s != "abc😀" {
+		t.Errorf("unexpected result: %q, %v", s, err)
+	}
+
+	// invalid UTF-8 which isn't a surrogate is encoded as U+FFFD
+	if bs := EncodeStringToBytes("\xff"); !bytes.Equal(bs, []byte{0xfd, 0xff}) {
+		t.Errorf("unexpected encoding: %x", bs)
+	}
+}
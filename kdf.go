@@ -5,17 +5,78 @@ package smb2
 import (
 	"crypto/hmac"
 	"crypto/sha256"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
 )
 
 // KDF in Counter Mode with h = 256, r = 32, L = 128
 func kdf(ki, label, context []byte) []byte {
+	return kdfN(ki, label, context, 16)
+}
+
+// KDF in Counter Mode with h = 256, r = 32, L = 8 * n, where n is 16 or 32
+func kdfN(ki, label, context []byte, n int) []byte {
 	h := hmac.New(sha256.New, ki)
 
+	l := uint32(n * 8)
+
 	h.Write([]byte{0x00, 0x00, 0x00, 0x01})
 	h.Write(label)
 	h.Write([]byte{0x00})
 	h.Write(context)
-	h.Write([]byte{0x00, 0x00, 0x00, 0x80})
+	h.Write([]byte{byte(l >> 24), byte(l >> 16), byte(l >> 8), byte(l)})
+
+	return h.Sum(nil)[:n]
+}
+
+// sessionKeys contains the keys derived from the session key. (See [MS-SMB2] 3.2.5.3.1)
+type sessionKeys struct {
+	signingKey     []byte
+	encryptionKey  []byte // client to server
+	decryptionKey  []byte // server to client
+	applicationKey []byte
+}
+
+// deriveSessionKeys derives the keys for dialect from the cryptographic key of the authentication.
+// preauthHash is the preauth integrity hash value of the session and cipherId is the negotiated cipher,
+// they're only used by SMB 3.1.1. SMB 2.x doesn't support encryption, so the encryption and decryption keys are nil.
+func deriveSessionKeys(dialect uint16, cryptoKey []byte, preauthHash []byte, cipherId uint16) *sessionKeys {
+	// Session.SessionKey is the first 16 bytes of the cryptographic key, right-padded with zeros if it's shorter.
+	sessionKey := make([]byte, 16)
+	copy(sessionKey, cryptoKey)
 
-	return h.Sum(nil)[:16]
+	switch dialect {
+	case SMB202, SMB210:
+		return &sessionKeys{
+			signingKey:     sessionKey,
+			applicationKey: sessionKey,
+		}
+	case SMB300, SMB302:
+		return &sessionKeys{
+			signingKey:     kdf(sessionKey, []byte("SMB2AESCMAC\x00"), []byte("SmbSign\x00")),
+			encryptionKey:  kdf(sessionKey, []byte("SMB2AESCCM\x00"), []byte("ServerIn \x00")),
+			decryptionKey:  kdf(sessionKey, []byte("SMB2AESCCM\x00"), []byte("ServerOut\x00")),
+			applicationKey: kdf(sessionKey, []byte("SMB2APP\x00"), []byte("SmbRpc\x00")),
+		}
+	default: // SMB311
+		if cipherId == AES256CCM || cipherId == AES256GCM {
+			// the 256-bit keys are derived from the full cryptographic key
+			fullKey := sessionKey
+			if len(cryptoKey) > len(sessionKey) {
+				fullKey = cryptoKey
+			}
+			return &sessionKeys{
+				signingKey:     kdf(sessionKey, []byte("SMBSigningKey\x00"), preauthHash),
+				encryptionKey:  kdfN(fullKey, []byte("SMBC2SCipherKey\x00"), preauthHash, 32),
+				decryptionKey:  kdfN(fullKey, []byte("SMBS2CCipherKey\x00"), preauthHash, 32),
+				applicationKey: kdf(sessionKey, []byte("SMBAppKey\x00"), preauthHash),
+			}
+		}
+		return &sessionKeys{
+			signingKey:     kdf(sessionKey, []byte("SMBSigningKey\x00"), preauthHash),
+			encryptionKey:  kdf(sessionKey, []byte("SMBC2SCipherKey\x00"), preauthHash),
+			decryptionKey:  kdf(sessionKey, []byte("SMBS2CCipherKey\x00"), preauthHash),
+			applicationKey: kdf(sessionKey, []byte("SMBAppKey\x00"), preauthHash),
+		}
+	}
 }
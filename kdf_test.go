@@ -2,7 +2,11 @@ package smb2
 
 import (
 	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
 	"testing"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
 )
 
 func TestKDF(t *testing.T) {
@@ -11,3 +15,87 @@ func TestKDF(t *testing.T) {
 		t.Error("fail")
 	}
 }
+
+func TestDeriveSessionKeys(t *testing.T) {
+	// expected values are computed by an independent implementation of SP800-108
+	cryptoKey := make([]byte, 32) // e.g. Kerberos, only the first 16 bytes are used but by AES-256
+	for i := range cryptoKey {
+		cryptoKey[i] = byte(0x10 + i)
+	}
+	preauthHash := sha512.Sum512([]byte("preauth"))
+
+	h := func(s string) []byte {
+		bs, err := hex.DecodeString(s)
+		if err != nil {
+			panic(err)
+		}
+		return bs
+	}
+
+	tests := []struct {
+		dialect  uint16
+		cipherId uint16
+		expected sessionKeys
+	}{
+		{
+			SMB210,
+			0,
+			sessionKeys{
+				signingKey:     cryptoKey[:16],
+				applicationKey: cryptoKey[:16],
+			},
+		},
+		{
+			SMB302,
+			0,
+			sessionKeys{
+				signingKey:     h("24f1f0fdb269db9836d70efbbb97413f"),
+				encryptionKey:  h("fe85e20ea4633a2437828b14d1833584"),
+				decryptionKey:  h("fa192f82747e0174f720cdaea0e2f537"),
+				applicationKey: h("fcdcc2c713f38db23189c653589890c7"),
+			},
+		},
+		{
+			SMB311,
+			AES128GCM,
+			sessionKeys{
+				signingKey:     h("cbe49aed9ebe4c51a8ab1259e985b71d"),
+				encryptionKey:  h("124db44f7e48c3109c5c92e5886fa287"),
+				decryptionKey:  h("f98d13037cdbbd8ec3581111464c4634"),
+				applicationKey: h("021a72547824df3dfe7b0c598c80fa8b"),
+			},
+		},
+		{
+			SMB311,
+			AES256GCM,
+			sessionKeys{
+				signingKey:     h("cbe49aed9ebe4c51a8ab1259e985b71d"),
+				encryptionKey:  h("e5c034cd3fe2f7bdf61330f550343aa2935ed81c58a5e64fccca27d87f582245"),
+				decryptionKey:  h("b77188da9128542d7ddd46e238b196d7916e26e35c2fb85f441677bd028654e9"),
+				applicationKey: h("021a72547824df3dfe7b0c598c80fa8b"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		keys := deriveSessionKeys(test.dialect, cryptoKey, preauthHash[:], test.cipherId)
+		if !bytes.Equal(keys.signingKey, test.expected.signingKey) {
+			t.Errorf("dialect %#x, cipher %d: signing key: expected %x, got %x", test.dialect, test.cipherId, test.expected.signingKey, keys.signingKey)
+		}
+		if !bytes.Equal(keys.encryptionKey, test.expected.encryptionKey) {
+			t.Errorf("dialect %#x, cipher %d: encryption key: expected %x, got %x", test.dialect, test.cipherId, test.expected.encryptionKey, keys.encryptionKey)
+		}
+		if !bytes.Equal(keys.decryptionKey, test.expected.decryptionKey) {
+			t.Errorf("dialect %#x, cipher %d: decryption key: expected %x, got %x", test.dialect, test.cipherId, test.expected.decryptionKey, keys.decryptionKey)
+		}
+		if !bytes.Equal(keys.applicationKey, test.expected.applicationKey) {
+			t.Errorf("dialect %#x, cipher %d: application key: expected %x, got %x", test.dialect, test.cipherId, test.expected.applicationKey, keys.applicationKey)
+		}
+	}
+
+	// shorter keys are right-padded with zeros
+	keys := deriveSessionKeys(SMB202, h("0102030405060708"), nil, 0)
+	if !bytes.Equal(keys.signingKey, h("01020304050607080000000000000000")) {
+		t.Errorf("unexpected signing key: %x", keys.signingKey)
+	}
+}
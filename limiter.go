@@ -0,0 +1,66 @@
+package smb2
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket which limits the throughput to rate bytes per second.
+// It allows a burst of one second worth of bytes. Requests larger than the bucket
+// put it into debt, so that the average rate is still respected.
+type limiter struct {
+	m      sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter returns nil if bytesPerSec isn't positive, which means unlimited.
+func newLimiter(bytesPerSec int) *limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return &limiter{
+		rate:   float64(bytesPerSec),
+		tokens: float64(bytesPerSec),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until n bytes are allowed to be transferred or ctx is done.
+func (l *limiter) wait(n int, ctx context.Context) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+
+	l.m.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.m.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		// give back the bytes which weren't transferred
+		l.m.Lock()
+		l.tokens += float64(n)
+		l.m.Unlock()
+
+		return &ContextError{Err: ctx.Err()}
+	}
+}
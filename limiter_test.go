@@ -0,0 +1,35 @@
+package smb2
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	var l *limiter
+	if err := l.wait(1<<30, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	l = newLimiter(100 * 1024)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.wait(30*1024, context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 150KiB with 100KiB burst at 100KiB/s takes 0.5 seconds
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || 2*time.Second < elapsed {
+		t.Errorf("unexpected elapsed time: %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := l.wait(1024*1024, ctx)
+	if _, ok := err.(*ContextError); !ok {
+		t.Errorf("expected context error, got %v", err)
+	}
+}
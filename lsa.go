@@ -0,0 +1,259 @@
+package smb2
+
+import (
+	"os"
+	"strings"
+
+	"github.com/nodauf/go-smb2/internal/msrpc"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+)
+
+// SIDNameUse represents the type of the account a SID refers to. (See [MS-LSAT] 2.2.13)
+type SIDNameUse uint16
+
+const (
+	SIDTypeUser SIDNameUse = 1 + iota
+	SIDTypeGroup
+	SIDTypeDomain
+	SIDTypeAlias
+	SIDTypeWellKnownGroup
+	SIDTypeDeletedAccount
+	SIDTypeInvalid
+	SIDTypeUnknown
+	SIDTypeComputer
+	SIDTypeLabel
+)
+
+var sidNameUseStrings = map[SIDNameUse]string{
+	SIDTypeUser:           "user",
+	SIDTypeGroup:          "group",
+	SIDTypeDomain:         "domain",
+	SIDTypeAlias:          "alias",
+	SIDTypeWellKnownGroup: "well-known group",
+	SIDTypeDeletedAccount: "deleted account",
+	SIDTypeInvalid:        "invalid",
+	SIDTypeUnknown:        "unknown",
+	SIDTypeComputer:       "computer",
+	SIDTypeLabel:          "label",
+}
+
+func (u SIDNameUse) String() string {
+	if s, ok := sidNameUseStrings[u]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// Account represents a result of func (*Session) LookupSIDs or func (*Session) LookupNames.
+// If the server couldn't resolve an entry, Use is SIDTypeUnknown.
+type Account struct {
+	SID    SID
+	Domain string
+	Name   string
+	Use    SIDNameUse
+}
+
+// String returns the account name qualified by the domain (e.g. `CORP\alice`).
+// If the account has no name, the string form of the SID is returned instead.
+func (a Account) String() string {
+	switch {
+	case a.Name == "":
+		return a.SID.String()
+	case a.Domain == "" || a.Use == SIDTypeDomain:
+		return a.Name
+	default:
+		return a.Domain + `\` + a.Name
+	}
+}
+
+// LookupSIDs resolves sids to account names by calling LsarLookupSids over the LSARPC named pipe.
+// The result has an entry for each of sids in the same order.
+// SIDs which couldn't be resolved are reported as SIDTypeUnknown instead of an error.
+func (c *Session) LookupSIDs(sids []SID) ([]Account, error) {
+	if len(sids) == 0 {
+		return nil, nil
+	}
+
+	bs := make([][]byte, len(sids))
+	for i, sid := range sids {
+		b, err := sid.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		bs[i] = b
+	}
+
+	var res msrpc.LsarLookupSidsResponse
+
+	err := c.withLSAPolicy("lookupSids", func(r *rpcClient, handle []byte) error {
+		req := &msrpc.LsarLookupSidsRequest{
+			PolicyHandle: handle,
+			Sids:         bs,
+			LookupLevel:  msrpc.LsapLookupWksta,
+		}
+
+		stub, err := r.call(msrpc.OP_LSAR_LOOKUP_SIDS, req.Marshal())
+		if err != nil {
+			return err
+		}
+
+		if !res.Unmarshal(stub) {
+			return &InvalidResponseError{"broken lookup sids response format"}
+		}
+
+		switch NtStatus(res.Status) {
+		case STATUS_SUCCESS, STATUS_SOME_NOT_MAPPED:
+		case STATUS_NONE_MAPPED:
+			if res.TranslatedNames == nil {
+				res.TranslatedNames = make([]msrpc.LsaTranslatedName, len(sids))
+			}
+		default:
+			return &RPCError{Op: "LsarLookupSids", Status: res.Status}
+		}
+
+		if len(res.TranslatedNames) != len(sids) {
+			return &InvalidResponseError{"broken lookup sids response format"}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]Account, len(sids))
+	for i, name := range res.TranslatedNames {
+		a := Account{
+			SID:  sids[i],
+			Name: name.Name,
+			Use:  SIDNameUse(name.Use),
+		}
+		if a.Use == 0 {
+			a.Use = SIDTypeUnknown
+		}
+		if d := int(name.DomainIndex); 0 <= d && d < len(res.ReferencedDomains) {
+			a.Domain = res.ReferencedDomains[d].Name
+		}
+		accounts[i] = a
+	}
+
+	return accounts, nil
+}
+
+// LookupNames resolves account names (e.g. `CORP\alice` or `alice`) to SIDs
+// by calling LsarLookupNames over the LSARPC named pipe.
+// The result has an entry for each of names in the same order.
+// Names which couldn't be resolved are reported as SIDTypeUnknown instead of an error.
+func (c *Session) LookupNames(names []string) ([]Account, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var res msrpc.LsarLookupNamesResponse
+
+	err := c.withLSAPolicy("lookupNames", func(r *rpcClient, handle []byte) error {
+		req := &msrpc.LsarLookupNamesRequest{
+			PolicyHandle: handle,
+			Names:        names,
+			LookupLevel:  msrpc.LsapLookupWksta,
+		}
+
+		stub, err := r.call(msrpc.OP_LSAR_LOOKUP_NAMES, req.Marshal())
+		if err != nil {
+			return err
+		}
+
+		if !res.Unmarshal(stub) {
+			return &InvalidResponseError{"broken lookup names response format"}
+		}
+
+		switch NtStatus(res.Status) {
+		case STATUS_SUCCESS, STATUS_SOME_NOT_MAPPED:
+		case STATUS_NONE_MAPPED:
+			if res.TranslatedSids == nil {
+				res.TranslatedSids = make([]msrpc.LsaTranslatedSid, len(names))
+			}
+		default:
+			return &RPCError{Op: "LsarLookupNames", Status: res.Status}
+		}
+
+		if len(res.TranslatedSids) != len(names) {
+			return &InvalidResponseError{"broken lookup names response format"}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]Account, len(names))
+	for i, tsid := range res.TranslatedSids {
+		a := Account{
+			Name: names[i],
+			Use:  SIDNameUse(tsid.Use),
+		}
+
+		d := int(tsid.DomainIndex)
+		if a.Use == 0 || d < 0 || len(res.ReferencedDomains) <= d {
+			a.Use = SIDTypeUnknown
+			accounts[i] = a
+			continue
+		}
+
+		domain := res.ReferencedDomains[d]
+
+		var sid SID
+		if err := sid.UnmarshalBinary(domain.Sid); err != nil {
+			return nil, &InvalidResponseError{"broken lookup names response format"}
+		}
+		if a.Use != SIDTypeDomain {
+			sid.SubAuthority = append(sid.SubAuthority, tsid.RelativeId)
+		}
+
+		a.SID = sid
+		a.Domain = domain.Name
+		if j := strings.LastIndexByte(a.Name, '\\'); j >= 0 {
+			a.Name = a.Name[j+1:]
+		}
+
+		accounts[i] = a
+	}
+
+	return accounts, nil
+}
+
+// withLSAPolicy opens a LSA policy handle and calls fn with it.
+func (c *Session) withLSAPolicy(op string, fn func(r *rpcClient, handle []byte) error) error {
+	r, err := c.openRPC("lsarpc", msrpc.LSARPC_UUID, msrpc.LSARPC_VERSION, msrpc.LSARPC_VERSION_MINOR)
+	if err != nil {
+		return err
+	}
+	defer r.close()
+
+	open := &msrpc.LsarOpenPolicy2Request{
+		DesiredAccess: msrpc.MAXIMUM_ALLOWED | msrpc.POLICY_LOOKUP_NAMES,
+	}
+
+	stub, err := r.call(msrpc.OP_LSAR_OPEN_POLICY_2, open.Marshal())
+	if err != nil {
+		return &os.PathError{Op: op, Path: r.f.name, Err: err}
+	}
+
+	var res msrpc.LsarOpenPolicy2Response
+	if !res.Unmarshal(stub) {
+		return &os.PathError{Op: op, Path: r.f.name, Err: &InvalidResponseError{"broken open policy response format"}}
+	}
+	if res.Status != 0 {
+		return &os.PathError{Op: op, Path: r.f.name, Err: &RPCError{Op: "LsarOpenPolicy2", Status: res.Status}}
+	}
+
+	defer r.call(msrpc.OP_LSAR_CLOSE, (&msrpc.LsarCloseRequest{PolicyHandle: res.PolicyHandle}).Marshal())
+
+	if err := fn(r, res.PolicyHandle); err != nil {
+		return &os.PathError{Op: op, Path: r.f.name, Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package smb2
+
+import (
+	"github.com/nodauf/go-smb2/internal/utf16le"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// Context types of NegotiateContext. (See [MS-SMB2] 2.2.3.1)
+const (
+	NegotiateContextPreauthIntegrity      = SMB2_PREAUTH_INTEGRITY_CAPABILITIES
+	NegotiateContextEncryption            = SMB2_ENCRYPTION_CAPABILITIES
+	NegotiateContextCompression           = SMB2_COMPRESSION_CAPABILITIES
+	NegotiateContextNetName               = SMB2_NETNAME_NEGOTIATE_CONTEXT_ID
+	NegotiateContextTransportCapabilities = SMB2_TRANSPORT_CAPABILITIES
+	NegotiateContextRDMATransform         = SMB2_RDMA_TRANSFORM_CAPABILITIES
+	NegotiateContextSigning               = SMB2_SIGNING_CAPABILITIES
+)
+
+// RDMA transform ids of NegotiateContext and ConnInfo. (See [MS-SMB2] 2.2.3.1.6)
+const (
+	RDMATransformNone       = SMB2_RDMA_TRANSFORM_NONE
+	RDMATransformEncryption = SMB2_RDMA_TRANSFORM_ENCRYPTION
+	RDMATransformSigning    = SMB2_RDMA_TRANSFORM_SIGNING
+)
+
+// Signing algorithms of ConnInfo.SigningAlgorithm and NegotiateContext.SigningAlgorithms. (See [MS-SMB2] 2.2.3.1.7)
+const (
+	SigningAlgorithmHMACSHA256 = HMAC_SHA256
+	SigningAlgorithmAESCMAC    = AES_CMAC
+	SigningAlgorithmAESGMAC    = AES_GMAC
+)
+
+// Ciphers of ConnInfo.Cipher and NegotiateContext.Ciphers. (See [MS-SMB2] 2.2.3.1.2)
+const (
+	CipherAES128CCM = AES128CCM
+	CipherAES128GCM = AES128GCM
+	CipherAES256CCM = AES256CCM
+	CipherAES256GCM = AES256GCM
+)
+
+// NegotiateContext represents a negotiate context of the SMB 3.1.1 negotiate response returned by func (*Session) NegotiateContexts.
+// Data is the raw data of the context, the other fields are parsed from it according to Type,
+// and are left empty if the type is unknown or the data is malformed.
+type NegotiateContext struct {
+	Type uint16 // NegotiateContext* constant
+	Data []byte
+
+	HashAlgorithms        []uint16 // NegotiateContextPreauthIntegrity, e.g. 1 for SHA-512
+	Ciphers               []uint16 // NegotiateContextEncryption, e.g. 2 for AES-128-GCM
+	CompressionAlgorithms []uint16 // NegotiateContextCompression
+	CompressionFlags      uint32   // NegotiateContextCompression
+	NetName               string   // NegotiateContextNetName
+	TransportFlags        uint32   // NegotiateContextTransportCapabilities, e.g. 1 for accepting transport level security
+	RDMATransformIds      []uint16 // NegotiateContextRDMATransform, e.g. 1 for encryption
+	SigningAlgorithms     []uint16 // NegotiateContextSigning, e.g. 2 for AES-GMAC
+}
+
+// ConnInfo contains the capabilities of the connection of a session returned by func (*Session) ConnInfo.
+type ConnInfo struct {
+	Dialect      uint16 // negotiated dialect, e.g. 0x0311 for SMB 3.1.1
+	Capabilities uint32 // global capabilities advertised by the server, e.g. 0x8 for multichannel
+
+	// SigningAlgorithm is the algorithm signing the packets, a SigningAlgorithm* constant:
+	// HMAC-SHA256 for SMB 2.x, AES-CMAC for SMB 3.0 and 3.0.2, and the one selected by SMB2_SIGNING_CAPABILITIES for SMB 3.1.1,
+	// which is AES-CMAC if the server doesn't send the context.
+	SigningAlgorithm uint16
+
+	// Cipher is the algorithm encrypting the packets, a Cipher* constant: AES-128-CCM for SMB 3.0 and 3.0.2
+	// if the server supports encryption, and the one selected by SMB2_ENCRYPTION_CAPABILITIES for SMB 3.1.1.
+	// It's zero if encryption isn't available.
+	Cipher uint16
+
+	MultiChannel     bool     // the server supports multichannel (SMB2_GLOBAL_CAP_MULTI_CHANNEL)
+	RDMATransformIds []uint16 // RDMA transforms accepted by the server, RDMATransform* constants
+
+	// RDMATransformsSupported reports whether the server supports multichannel, by which SMB Direct connections
+	// are bound to a session, and accepted an RDMA transform. It's false for dialects older than SMB 3.1.1.
+	// It doesn't tell whether the server has an RDMA-capable interface, which is reported by the RDMA_CAPABLE flag
+	// of FSCTL_QUERY_NETWORK_INTERFACE_INFO. The package doesn't implement SMB Direct, so the connections stay on TCP.
+	RDMATransformsSupported bool
+}
+
+// ConnInfo returns the capabilities the server advertised by the negotiation of the connection of the session,
+// and the algorithms selected, so that callers know whether the server accepts RDMA transforms, or which signing and encryption are used.
+func (c *Session) ConnInfo() ConnInfo {
+	conn := c.s.conn
+
+	info := ConnInfo{
+		Dialect:      conn.dialect,
+		Capabilities: conn.serverCapabilities,
+		MultiChannel: conn.serverCapabilities&SMB2_GLOBAL_CAP_MULTI_CHANNEL != 0,
+	}
+
+	info.SigningAlgorithm, info.Cipher = conn.algorithms()
+
+	for _, ctx := range conn.negotiateContexts {
+		if ctx.Type == SMB2_RDMA_TRANSFORM_CAPABILITIES {
+			info.RDMATransformIds = append(info.RDMATransformIds, ctx.RDMATransformIds...)
+		}
+	}
+
+	info.RDMATransformsSupported = info.MultiChannel && len(info.RDMATransformIds) > 0
+
+	return info
+}
+
+// algorithms returns the signing algorithm and the cipher in use by the connection.
+// The cipher is zero if encryption isn't available.
+func (conn *conn) algorithms() (signingAlg, cipher uint16) {
+	switch conn.dialect {
+	case SMB202, SMB210:
+		return HMAC_SHA256, 0
+	case SMB300, SMB302:
+		if conn.capabilities&SMB2_GLOBAL_CAP_ENCRYPTION != 0 {
+			return AES_CMAC, AES128CCM
+		}
+		return AES_CMAC, 0
+	default:
+		return conn.signingId, conn.cipherId
+	}
+}
+
+// checkAlgorithms returns an AlgorithmMismatchError if the signing algorithm or the cipher in use by the connection
+// isn't one of signingAlgs or ciphers. An empty list allows any algorithm.
+func (conn *conn) checkAlgorithms(signingAlgs, ciphers []uint16) error {
+	signingAlg, cipher := conn.algorithms()
+
+	if len(signingAlgs) > 0 && !containsAlgorithm(signingAlgs, signingAlg) {
+		return &AlgorithmMismatchError{Dialect: conn.dialect, Signing: true, Selected: signingAlg, Allowed: signingAlgs}
+	}
+	if len(ciphers) > 0 && !containsAlgorithm(ciphers, cipher) {
+		return &AlgorithmMismatchError{Dialect: conn.dialect, Selected: cipher, Allowed: ciphers}
+	}
+	return nil
+}
+
+func containsAlgorithm(algs []uint16, alg uint16) bool {
+	for _, a := range algs {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// RawNegotiateResponse returns the SMB2 NEGOTIATE response of the connection of the session as received,
+// including the SMB2 header, so that the fields and the contexts ignored by the package can be inspected.
+func (c *Session) RawNegotiateResponse() []byte {
+	return append([]byte{}, c.s.conn.negotiateResponse...)
+}
+
+// NegotiateContexts returns the negotiate contexts of the negotiate response in the order the server sent them,
+// including the ones ignored by the package. Only SMB 3.1.1 has negotiate contexts.
+func (c *Session) NegotiateContexts() []NegotiateContext {
+	ctxs := make([]NegotiateContext, len(c.s.conn.negotiateContexts))
+	copy(ctxs, c.s.conn.negotiateContexts)
+	return ctxs
+}
+
+// parseNegotiateContext parses a negotiate context of the negotiate response, which must be valid.
+func parseNegotiateContext(ctx NegotiateContextDecoder) NegotiateContext {
+	nc := NegotiateContext{
+		Type: ctx.ContextType(),
+		Data: append([]byte{}, ctx.Data()...),
+	}
+
+	switch nc.Type {
+	case SMB2_PREAUTH_INTEGRITY_CAPABILITIES:
+		if d := HashContextDataDecoder(nc.Data); !d.IsInvalid() {
+			nc.HashAlgorithms = d.HashAlgorithms()
+		}
+	case SMB2_ENCRYPTION_CAPABILITIES:
+		if d := CipherContextDataDecoder(nc.Data); !d.IsInvalid() {
+			nc.Ciphers = d.Ciphers()
+		}
+	case SMB2_COMPRESSION_CAPABILITIES:
+		if d := CompressionContextDataDecoder(nc.Data); !d.IsInvalid() {
+			nc.CompressionAlgorithms = d.CompressionAlgorithms()
+			nc.CompressionFlags = d.Flags()
+		}
+	case SMB2_NETNAME_NEGOTIATE_CONTEXT_ID:
+		nc.NetName = utf16le.DecodeToString(nc.Data)
+	case SMB2_TRANSPORT_CAPABILITIES:
+		if d := TransportContextDataDecoder(nc.Data); !d.IsInvalid() {
+			nc.TransportFlags = d.Flags()
+		}
+	case SMB2_RDMA_TRANSFORM_CAPABILITIES:
+		if d := RDMATransformContextDataDecoder(nc.Data); !d.IsInvalid() {
+			nc.RDMATransformIds = d.RDMATransformIds()
+		}
+	case SMB2_SIGNING_CAPABILITIES:
+		if d := SigningContextDataDecoder(nc.Data); !d.IsInvalid() {
+			nc.SigningAlgorithms = d.SigningAlgorithms()
+		}
+	}
+
+	return nc
+}
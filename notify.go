@@ -0,0 +1,128 @@
+package smb2
+
+import (
+	"fmt"
+	"os"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// maxNotifySize is the max size of the changes returned by a CHANGE_NOTIFY request.
+// The server keeps the changes occurring between the requests in a buffer of this size.
+const maxNotifySize = 64 * 1024
+
+// Filters of the changes reported by func (*File) ChangeNotify. (See [MS-SMB2] 2.2.35)
+const (
+	NotifyChangeFileName    = FILE_NOTIFY_CHANGE_FILE_NAME
+	NotifyChangeDirName     = FILE_NOTIFY_CHANGE_DIR_NAME
+	NotifyChangeAttributes  = FILE_NOTIFY_CHANGE_ATTRIBUTES
+	NotifyChangeSize        = FILE_NOTIFY_CHANGE_SIZE
+	NotifyChangeLastWrite   = FILE_NOTIFY_CHANGE_LAST_WRITE
+	NotifyChangeLastAccess  = FILE_NOTIFY_CHANGE_LAST_ACCESS
+	NotifyChangeCreation    = FILE_NOTIFY_CHANGE_CREATION
+	NotifyChangeEa          = FILE_NOTIFY_CHANGE_EA
+	NotifyChangeSecurity    = FILE_NOTIFY_CHANGE_SECURITY
+	NotifyChangeStreamName  = FILE_NOTIFY_CHANGE_STREAM_NAME
+	NotifyChangeStreamSize  = FILE_NOTIFY_CHANGE_STREAM_SIZE
+	NotifyChangeStreamWrite = FILE_NOTIFY_CHANGE_STREAM_WRITE
+)
+
+// Actions of FileChange. (See [MS-FSCC] 2.7.1)
+const (
+	FileActionAdded          = FILE_ACTION_ADDED
+	FileActionRemoved        = FILE_ACTION_REMOVED
+	FileActionModified       = FILE_ACTION_MODIFIED
+	FileActionRenamedOldName = FILE_ACTION_RENAMED_OLD_NAME
+	FileActionRenamedNewName = FILE_ACTION_RENAMED_NEW_NAME
+	FileActionAddedStream    = FILE_ACTION_ADDED_STREAM
+	FileActionRemovedStream  = FILE_ACTION_REMOVED_STREAM
+	FileActionModifiedStream = FILE_ACTION_MODIFIED_STREAM
+)
+
+// FileChange is a change of an entry of a directory reported by func (*File) ChangeNotify.
+type FileChange struct {
+	Action uint32 // one of FileAction*
+	Name   string // relative to the watched directory, with backslash separators
+}
+
+// ChangeNotify waits for changes of the directory matching filter, a combination of NotifyChange*,
+// and returns them. If recursive is set, the changes of the whole subtree are reported.
+// The server keeps recording changes between the calls as long as the directory is open,
+// so calling ChangeNotify in a loop misses none, unless they overflow its buffer and ErrNotifyOverflow is returned.
+// It blocks until a change occurs, the context of the share is done, or the directory is closed,
+// in which case it returns os.ErrClosed.
+func (f *File) ChangeNotify(filter uint32, recursive bool) ([]FileChange, error) {
+	changes, err := f.changeNotify(filter, recursive)
+	if err != nil {
+		if rerr, ok := err.(*ResponseError); ok && NtStatus(rerr.Code) == STATUS_NOTIFY_ENUM_DIR {
+			err = ErrNotifyOverflow
+		}
+		return nil, &os.PathError{Op: "notify", Path: f.name, Err: err}
+	}
+	return changes, nil
+}
+
+func (f *File) changeNotify(filter uint32, recursive bool) (changes []FileChange, err error) {
+	req := &ChangeNotifyRequest{
+		OutputBufferLength: maxNotifySize,
+		CompletionFilter:   filter,
+	}
+
+	if recursive {
+		req.Flags = SMB2_WATCH_TREE
+	}
+
+	if size := f.maxTransactSize(); size < int(req.OutputBufferLength) {
+		req.OutputBufferLength = uint32(size)
+	}
+
+	payloadSize := int(req.OutputBufferLength)
+
+	req.CreditCharge, _, err = f.fs.loanCredit(payloadSize)
+	if err != nil {
+		return nil, err
+	}
+
+	req.FileId = f.fd
+
+	res, err := f.sendRecv(SMB2_CHANGE_NOTIFY, req)
+	if err != nil {
+		return nil, err
+	}
+
+	r := ChangeNotifyResponseDecoder(res)
+	if r.IsInvalid() {
+		return nil, &InvalidResponseError{"broken change notify response format"}
+	}
+
+	output := r.OutputBuffer()
+	if len(output) == 0 {
+		// the server has discarded the changes without reporting STATUS_NOTIFY_ENUM_DIR
+		return nil, ErrNotifyOverflow
+	}
+
+	for {
+		info := FileNotifyInformationDecoder(output)
+		if info.IsInvalid() {
+			return nil, &InvalidResponseError{"broken change notify response format"}
+		}
+
+		name, err := decodeName(info.FileName())
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, FileChange{Action: info.Action(), Name: name})
+
+		next := info.NextEntryOffset()
+		if next == 0 {
+			return changes, nil
+		}
+		if int(next) > len(output) {
+			return nil, &InvalidResponseError{fmt.Sprintf("change notify entry offset %d exceeds the output of %d bytes", next, len(output))}
+		}
+
+		output = output[next:]
+	}
+}
@@ -0,0 +1,155 @@
+package smb2
+
+import (
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// fakeNotifyServer answers each CHANGE_NOTIFY request with the next of replies, either changes or an error status,
+// and keeps the request pending once they run out. CLOSE requests succeed.
+type fakeNotifyServer struct {
+	tr      *fakeTransport
+	replies []interface{} // []FileChange or NtStatus
+
+	m       sync.Mutex
+	flags   []uint16
+	filters []uint32
+}
+
+func (srv *fakeNotifyServer) handle(req []byte) {
+	q := PacketCodec(req)
+
+	hdr := PacketHeader{
+		Command:               q.Command(),
+		CreditRequestResponse: q.CreditCharge(),
+		Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+		MessageId:             q.MessageId(),
+		TreeId:                q.TreeId(),
+		SessionId:             q.SessionId(),
+	}
+
+	var res Packet
+
+	switch q.Command() {
+	case SMB2_CHANGE_NOTIFY:
+		r := ChangeNotifyRequestDecoder(q.Data())
+
+		srv.m.Lock()
+		srv.flags = append(srv.flags, r.Flags())
+		srv.filters = append(srv.filters, r.CompletionFilter())
+		if len(srv.replies) == 0 {
+			srv.m.Unlock()
+			return
+		}
+		reply := srv.replies[0]
+		srv.replies = srv.replies[1:]
+		srv.m.Unlock()
+
+		switch reply := reply.(type) {
+		case NtStatus:
+			hdr.Status = uint32(reply)
+			res = &ErrorResponse{PacketHeader: hdr}
+		case []FileChange:
+			var output fakeBytes
+			for i, c := range reply {
+				info := &FileNotifyInformation{Action: c.Action, FileName: c.Name}
+				b := make([]byte, (info.Size()+3)&^3)
+				if i < len(reply)-1 {
+					info.NextEntryOffset = uint32(len(b))
+				}
+				info.Encode(b)
+				output = append(output, b...)
+			}
+			res = &ChangeNotifyResponse{PacketHeader: hdr, Output: output}
+		}
+	case SMB2_CLOSE:
+		res = &CloseResponse{
+			PacketHeader:   hdr,
+			CreationTime:   &Filetime{},
+			LastAccessTime: &Filetime{},
+			LastWriteTime:  &Filetime{},
+			ChangeTime:     &Filetime{},
+		}
+	}
+
+	pkt := make([]byte, res.Size())
+	res.Encode(pkt)
+	PacketCodec(pkt).SetCommand(q.Command())
+	srv.tr.push(pkt)
+}
+
+func TestChangeNotify(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	changes := []FileChange{
+		{FileActionAdded, "a.txt"},
+		{FileActionRenamedOldName, `sub\b.txt`},
+		{FileActionRenamedNewName, `sub\c.txt`},
+	}
+
+	srv := &fakeNotifyServer{tr: tr, replies: []interface{}{changes, STATUS_NOTIFY_ENUM_DIR}}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+	// a request is pending while the directory is closed
+	if err := openCreditWindow(fs, tr, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	d := fs.newFile(FileIdDecoder(make([]byte, 16)), "dir")
+
+	filter := uint32(NotifyChangeFileName | NotifyChangeDirName)
+
+	cs, err := d.ChangeNotify(filter, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cs, changes) {
+		t.Errorf("unexpected changes %v", cs)
+	}
+
+	_, err = d.ChangeNotify(filter, false)
+	if perr, ok := err.(*os.PathError); !ok || perr.Err != ErrNotifyOverflow {
+		t.Errorf("expected ErrNotifyOverflow, got %v", err)
+	}
+
+	if !reflect.DeepEqual(srv.flags, []uint16{SMB2_WATCH_TREE, 0}) {
+		t.Errorf("unexpected flags %v", srv.flags)
+	}
+	if !reflect.DeepEqual(srv.filters, []uint32{filter, filter}) {
+		t.Errorf("unexpected filters %v", srv.filters)
+	}
+
+	// the next request is kept pending until the directory is closed
+	done := make(chan error, 1)
+	go func() {
+		_, err := d.ChangeNotify(filter, false)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("expected ChangeNotify to block, got %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if perr, ok := err.(*os.PathError); !ok || perr.Err != os.ErrClosed {
+			t.Errorf("expected os.ErrClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ChangeNotify is still blocked after Close")
+	}
+}
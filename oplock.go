@@ -0,0 +1,191 @@
+package smb2
+
+import (
+	"context"
+	"sync/atomic"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// Oplock levels for OpenOptions.OplockLevel and func (*File) OplockLevel. (See [MS-SMB2] 2.2.13)
+const (
+	OplockLevelNone      = SMB2_OPLOCK_LEVEL_NONE
+	OplockLevelII        = SMB2_OPLOCK_LEVEL_II
+	OplockLevelExclusive = SMB2_OPLOCK_LEVEL_EXCLUSIVE
+	OplockLevelBatch     = SMB2_OPLOCK_LEVEL_BATCH
+)
+
+// oplock is the oplock granted to an open file. It's registered on the session by the file id,
+// so that the receiver finds it by the break notifications. It doesn't refer to the file,
+// which is garbage collected as usual if it's leaked.
+type oplock struct {
+	fs     *Share // share of the file, by which the acknowledgement is sent
+	fd     *FileId
+	name   string
+	_level int32 // current oplock level (accessed atomically)
+}
+
+func (o *oplock) level() uint8 {
+	return uint8(atomic.LoadInt32(&o._level))
+}
+
+// OplockLevel returns the oplock level the server has granted to the file, an OplockLevel* constant.
+// It's lowered when the server breaks the oplock, and it's OplockLevelNone if no oplock was requested.
+func (f *File) OplockLevel() uint8 {
+	if f.oplock == nil {
+		return OplockLevelNone
+	}
+	return f.oplock.level()
+}
+
+// grantOplock registers the oplock granted by the CREATE response which opened f.
+// A break which arrived before the registration is handled right away.
+func (f *File) grantOplock(level uint8) {
+	s := f.fs.session
+
+	s.oplockM.Lock()
+	defer s.oplockM.Unlock()
+
+	newLevel, early := s.earlyBreaks[*f.fd]
+	delete(s.earlyBreaks, *f.fd)
+
+	if level == SMB2_OPLOCK_LEVEL_NONE || level == SMB2_OPLOCK_LEVEL_LEASE {
+		return
+	}
+
+	o := &oplock{fs: f.fs, fd: f.fd, name: f.name, _level: int32(level)}
+	f.oplock = o
+
+	if s.oplocks == nil {
+		s.oplocks = make(map[FileId]*oplock)
+	}
+
+	if early {
+		s.breakOplock(o, newLevel)
+	}
+	if o.level() != SMB2_OPLOCK_LEVEL_NONE {
+		s.oplocks[*f.fd] = o
+	}
+}
+
+// dropOplock unregisters the oplock of f once it's closed, and forgets a break of the handle which has no oplock.
+func (f *File) dropOplock() {
+	s := f.fs.session
+
+	s.oplockM.Lock()
+	defer s.oplockM.Unlock()
+
+	delete(s.earlyBreaks, *f.fd)
+
+	if f.oplock != nil && s.oplocks[*f.fd] == f.oplock {
+		delete(s.oplocks, *f.fd)
+	}
+}
+
+// handleOplockBreak handles the oplock break notification pkt on the receiver.
+// The acknowledgement is sent by a goroutine of its own, since the receiver must keep receiving the responses
+// which grant the credits of the acknowledgement. The breaks of the files which aren't registered yet
+// are handled once the CREATE responses are.
+func (s *session) handleOplockBreak(pkt []byte) error {
+	r := OplockBreakResponseDecoder(PacketCodec(pkt).Data())
+	if r.IsInvalid() {
+		return &InvalidResponseError{"broken oplock break notification format"}
+	}
+
+	fd := *r.FileId().Decode()
+	newLevel := r.OplockLevel()
+
+	s.oplockM.Lock()
+	defer s.oplockM.Unlock()
+
+	o, ok := s.oplocks[fd]
+	if !ok {
+		if s.earlyBreaks == nil {
+			s.earlyBreaks = make(map[FileId]uint8)
+		}
+		if _, ok := s.earlyBreaks[fd]; !ok && len(s.earlyBreaks) >= clientMaxEarlyBreaks {
+			// the handles of the kept breaks may never be registered (e.g. their CREATE requests were abandoned)
+			for fd := range s.earlyBreaks {
+				delete(s.earlyBreaks, fd)
+				break
+			}
+		}
+		s.earlyBreaks[fd] = newLevel
+		return nil
+	}
+
+	s.breakOplock(o, newLevel)
+	if o.level() == SMB2_OPLOCK_LEVEL_NONE {
+		delete(s.oplocks, fd)
+	}
+
+	return nil
+}
+
+// breakOplock lowers the oplock o to newLevel, and acknowledges the break unless it's from level II,
+// which isn't acknowledged. Dialer.OnOplockBreak is called afterwards. s.oplockM must be held.
+// (See [MS-SMB2] 3.2.5.19.1)
+func (s *session) breakOplock(o *oplock, newLevel uint8) {
+	oldLevel := o.level()
+
+	atomic.StoreInt32(&o._level, int32(newLevel))
+
+	go func() {
+		if oldLevel != SMB2_OPLOCK_LEVEL_II {
+			if err := s.acknowledgeOplockBreak(o, newLevel); err != nil {
+				logger.Println("oplock break acknowledgement:", err)
+			}
+		}
+
+		if s.onOplockBreak != nil {
+			s.onOplockBreak(o.name, newLevel)
+		}
+	}()
+}
+
+// acknowledgeOplockBreak sends the acknowledgement of the break of o to newLevel,
+// and waits for the response up to Dialer.OplockBreakAckTimeout. It doesn't wait for a slot of Dialer.MaxConcurrentRequests.
+func (s *session) acknowledgeOplockBreak(o *oplock, newLevel uint8) error {
+	timeout := s.oplockBreakAckTimeout
+	if timeout <= 0 {
+		timeout = clientOplockBreakAckTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(o.fs.ctx, timeout)
+	defer cancel()
+
+	fs := o.fs.WithContext(ctx)
+
+	req := &OplockBreakAcknowledgement{
+		OplockLevel: newLevel,
+		FileId:      o.fd,
+	}
+
+	var err error
+
+	req.CreditCharge, _, err = fs.loanCredit(0)
+	if err != nil {
+		return err
+	}
+
+	rr, err := fs.send(req, ctx)
+	if err != nil {
+		return err
+	}
+
+	pkt, err := fs.recv(rr)
+	if err != nil {
+		return err
+	}
+
+	res, err := accept(SMB2_OPLOCK_BREAK, pkt)
+	if err != nil {
+		return err
+	}
+
+	if OplockBreakResponseDecoder(res).IsInvalid() {
+		return &InvalidResponseError{"broken oplock break response format"}
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package smb2
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+type oplockBreak struct {
+	name  string
+	level uint8
+	acks  int // acknowledgements received by the server when the callback was called
+}
+
+func newOplockShare(srv *fakeTreeServer) (*Share, chan oplockBreak) {
+	fs := newFakeShare(srv.tr)
+	fs.conn.account.charge(16, 0)
+
+	breaks := make(chan oplockBreak, 4)
+	fs.session.onOplockBreak = func(name string, level uint8) {
+		srv.m.Lock()
+		acks := len(srv.acks)
+		srv.m.Unlock()
+
+		breaks <- oplockBreak{name: name, level: level, acks: acks}
+	}
+
+	return fs, breaks
+}
+
+func waitOplockBreak(t *testing.T, breaks chan oplockBreak) oplockBreak {
+	select {
+	case b := <-breaks:
+		return b
+	case <-time.After(5 * time.Second):
+		t.Fatal("oplock break callback isn't called")
+		return oplockBreak{}
+	}
+}
+
+func TestOplockBreak(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`a`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+			`b`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs, breaks := newOplockShare(srv)
+
+	f, err := fs.OpenFileWithOptions(`a`, os.O_RDWR, 0666, &OpenOptions{OplockLevel: OplockLevelBatch})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level := f.OplockLevel(); level != OplockLevelBatch {
+		t.Errorf("granted level: %d, want %d", level, OplockLevelBatch)
+	}
+
+	srv.breakOplock(f.fd, OplockLevelII)
+
+	b := waitOplockBreak(t, breaks)
+	if b.name != `a` || b.level != OplockLevelII {
+		t.Errorf("break: %q to %d, want %q to %d", b.name, b.level, `a`, OplockLevelII)
+	}
+	if b.acks != 1 {
+		t.Errorf("the callback is called before the acknowledgement")
+	}
+	if level := f.OplockLevel(); level != OplockLevelII {
+		t.Errorf("level after the break: %d, want %d", level, OplockLevelII)
+	}
+
+	// the breaks from level II aren't acknowledged
+	srv.breakOplock(f.fd, OplockLevelNone)
+
+	b = waitOplockBreak(t, breaks)
+	if b.level != OplockLevelNone || b.acks != 1 {
+		t.Errorf("break from level II: to %d with %d acknowledgements, want to %d with 1", b.level, b.acks, OplockLevelNone)
+	}
+	if level := f.OplockLevel(); level != OplockLevelNone {
+		t.Errorf("level after the break: %d, want %d", level, OplockLevelNone)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a break arriving before the CREATE response is handled
+	srv.breaks = map[string]uint8{`b`: OplockLevelNone}
+
+	g, err := fs.OpenFileWithOptions(`b`, os.O_RDONLY, 0666, &OpenOptions{OplockLevel: OplockLevelExclusive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	b = waitOplockBreak(t, breaks)
+	if b.name != `b` || b.level != OplockLevelNone || b.acks != 2 {
+		t.Errorf("early break: %q to %d with %d acknowledgements, want %q to %d with 2", b.name, b.level, b.acks, `b`, OplockLevelNone)
+	}
+	if level := g.OplockLevel(); level != OplockLevelNone {
+		t.Errorf("level after the early break: %d, want %d", level, OplockLevelNone)
+	}
+
+	fs.session.oplockM.Lock()
+	n := len(fs.session.oplocks) + len(fs.session.earlyBreaks)
+	fs.session.oplockM.Unlock()
+	if n != 0 {
+		t.Errorf("%d oplocks are still registered", n)
+	}
+}
+
+func TestOplockBreakAckTimeout(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`a`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+		dropAcks: true,
+	}
+	tr.handler = srv.handle
+
+	fs, breaks := newOplockShare(srv)
+	fs.session.oplockBreakAckTimeout = 50 * time.Millisecond
+
+	f, err := fs.OpenFileWithOptions(`a`, os.O_RDONLY, 0666, &OpenOptions{OplockLevel: OplockLevelBatch})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	srv.breakOplock(f.fd, OplockLevelNone)
+
+	// the callback is called once the acknowledgement times out
+	b := waitOplockBreak(t, breaks)
+	if b.level != OplockLevelNone || b.acks != 1 {
+		t.Errorf("break: to %d with %d acknowledgements, want to %d with 1", b.level, b.acks, OplockLevelNone)
+	}
+}
+
+func TestOplockBreakAckWithoutSlot(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`a`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs, breaks := newOplockShare(srv)
+	fs.session.oplockBreakAckTimeout = time.Second
+
+	f, err := fs.OpenFileWithOptions(`a`, os.O_RDWR, 0666, &OpenOptions{OplockLevel: OplockLevelBatch})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the only slot is held, like by a request which the server blocks until the break is acknowledged
+	fs.conn.sem = make(chan struct{}, 1)
+	fs.conn.sem <- struct{}{}
+
+	srv.breakOplock(f.fd, OplockLevelNone)
+
+	// used to wait for the slot until the acknowledgement timed out
+	b := waitOplockBreak(t, breaks)
+	if b.acks != 1 {
+		t.Errorf("expected the break to be acknowledged, got %d acknowledgements", b.acks)
+	}
+
+	<-fs.conn.sem
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOplockEarlyBreaks(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`a`: {attrs: FILE_ATTRIBUTE_ARCHIVE},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs, _ := newOplockShare(srv)
+
+	earlyBreaks := func(n int) {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			fs.session.oplockM.Lock()
+			m := len(fs.session.earlyBreaks)
+			fs.session.oplockM.Unlock()
+			if m == n {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %d early breaks, got %d", n, m)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// a file opened without an oplock, whose break is forgotten once it's closed
+	f, err := fs.Open(`a`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.breakOplock(f.fd, OplockLevelNone)
+	earlyBreaks(1)
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	earlyBreaks(0)
+
+	// the breaks of handles which are never registered are bounded
+	for i := 0; i < clientMaxEarlyBreaks+8; i++ {
+		fd := &FileId{}
+		fd.Persistent[0] = 0xf0
+		fd.Volatile[0] = byte(i)
+		srv.breakOplock(fd, OplockLevelNone)
+	}
+	earlyBreaks(clientMaxEarlyBreaks)
+}
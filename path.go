@@ -2,15 +2,62 @@ package smb2
 
 import (
 	"errors"
+	"fmt"
+	"net"
 	"os"
 	"regexp"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/nodauf/go-smb2/internal/utf16le"
 )
 
-var NORMALIZE_PATH = true // normalize path arguments automatically
+// NORMALIZE_PATH cleans the path arguments of the methods of Share into their canonical form (see func CleanPath).
+// If it's false, the paths are sent as they are, and must already be canonical.
+var NORMALIZE_PATH = true
+
+// UTF16Decoding selects how the names received from the server (directory entries and link targets)
+// are decoded if they aren't valid UTF-16, like NTFS names with unpaired surrogates.
+var UTF16Decoding = UTF16Preserve
+
+// UTF16DecodeMode is a mode of UTF16Decoding.
+type UTF16DecodeMode int
+
+const (
+	// UTF16Preserve keeps unpaired surrogates as 3-byte generalized UTF-8 (WTF-8) sequences,
+	// which are encoded back into the same surrogates, so that the names refer to the same files when passed back.
+	// Such names aren't valid UTF-8.
+	UTF16Preserve UTF16DecodeMode = iota
+
+	// UTF16Replace substitutes U+FFFD for unpaired surrogates.
+	// The names are valid UTF-8, but may not refer back to the files.
+	UTF16Replace
+
+	// UTF16Strict fails the operations receiving such names with ErrInvalidUTF16.
+	UTF16Strict
+)
+
+// decodeName applies UTF16Decoding to a name received from the server.
+func decodeName(name string) (string, error) {
+	name, err := utf16le.Sanitize(name, utf16le.DecodeMode(UTF16Decoding))
+	if err != nil {
+		return "", ErrInvalidUTF16
+	}
+	return name, nil
+}
 
 const PathSeparator = '\\'
 
+// Limits of the paths relative to the root of a share, in UTF-16 code units,
+// which are the same for all the dialects. Paths beyond them fail with ErrNameTooLong before being sent.
+const (
+	// MaxNameLength is the max length of a path component, as limited by NTFS and most server file systems.
+	MaxNameLength = 255
+
+	// MaxPathLength is the max length of a path, as limited by the 16-bit byte length of the name of a CREATE request.
+	MaxPathLength = 32767
+)
+
 func IsPathSeparator(c uint8) bool {
 	return c == '\\'
 }
@@ -79,22 +126,125 @@ func validatePath(op string, path string, allowAbs bool) error {
 		}
 	}
 
+	if strings.IndexByte(path, 0) != -1 {
+		return &os.PathError{Op: op, Path: path, Err: errors.New("NUL character is not allowed in a path")}
+	}
+
 	if !allowAbs && path[0] == '\\' {
 		return &os.PathError{Op: op, Path: path, Err: errors.New("leading '\\' is not allowed in this operation")}
 	}
 
+	if utf16le.EncodedStringLen(path)/2 > MaxPathLength {
+		return &os.PathError{Op: op, Path: path, Err: ErrNameTooLong}
+	}
+
+	for _, elem := range strings.Split(path, `\`) {
+		if utf16le.EncodedStringLen(elem)/2 > MaxNameLength {
+			return &os.PathError{Op: op, Path: path, Err: ErrNameTooLong}
+		}
+	}
+
 	return nil
 }
 
-var mountPathPattern = regexp.MustCompile(`^\\\\[^\\/]+\\[^\\/]+$`)
+// CleanPath returns the canonical form of a path relative to the root of a share, as the methods of Share clean their path arguments.
+// The canonical path has '\\' separators, no leading, trailing or repeated separators, and no "." or ".." elements;
+// the root of the share is "". '/' is converted to '\\', leading separators are stripped, and ".." elements are resolved lexically,
+// so that `/dir/./a/../b` becomes `dir\b`. A path with ".." elements above the root of the share is an error,
+// as are the paths which fail validation, e.g. with a NUL character or longer than MaxPathLength.
+func CleanPath(path string) (string, error) {
+	return cleanPath("clean", path)
+}
+
+func cleanPath(op string, path string) (string, error) {
+	if !NORMALIZE_PATH {
+		return path, validatePath(op, path, false)
+	}
+
+	path = strings.Replace(path, `/`, `\`, -1)
+
+	elems := make([]string, 0, strings.Count(path, `\`)+1)
+	for _, elem := range strings.Split(path, `\`) {
+		switch elem {
+		case "", ".":
+		case "..":
+			if len(elems) == 0 {
+				return "", &os.PathError{Op: op, Path: path, Err: errors.New("path must not refer above the root of the share")}
+			}
+			elems = elems[:len(elems)-1]
+		default:
+			elems = append(elems, elem)
+		}
+	}
+
+	clean := strings.Join(elems, `\`)
+
+	if err := validatePath(op, clean, false); err != nil {
+		return "", err
+	}
+
+	return clean, nil
+}
+
+var mountPathPattern = regexp.MustCompile(`^\\\\[^\\/\x00]+\\[^\\/\x00]+$`)
+
+// invalidShareNameChars are the characters which can't be part of a share name.
+const invalidShareNameChars = `"/\[]:|<>+=;,*?`
+
+// maxShareNameLen is the max length of a share name in characters.
+const maxShareNameLen = 80
 
 func validateMountPath(path string) error {
 	if !mountPathPattern.MatchString(path) {
 		return &os.PathError{Op: "mount", Path: path, Err: errors.New(`mount path must be a valid share name (\\<server>\<share>)`)}
 	}
+
+	share := path[strings.LastIndexByte(path, '\\')+1:]
+
+	if utf8.RuneCountInString(share) > maxShareNameLen {
+		return &os.PathError{Op: "mount", Path: path, Err: fmt.Errorf("share name must not be longer than %d characters", maxShareNameLen)}
+	}
+
+	if i := strings.IndexAny(share, invalidShareNameChars); i != -1 {
+		return &os.PathError{Op: "mount", Path: path, Err: fmt.Errorf("share name must not contain %q", share[i])}
+	}
+
+	for _, r := range share {
+		if r < 0x20 {
+			return &os.PathError{Op: "mount", Path: path, Err: errors.New("share name must not contain control characters")}
+		}
+	}
+
 	return nil
 }
 
+// mountPath returns the UNC path of sharename on server. sharename is either a bare share name (e.g. "C$"),
+// or a UNC path (e.g. `\\server\C$` or "//server/C$"). A trailing separator is dropped.
+func mountPath(sharename, server string) string {
+	path := strings.Replace(sharename, `/`, `\`, -1)
+	if strings.HasSuffix(path, `\`) && strings.Count(path, `\`) > 3 {
+		path = path[:len(path)-1]
+	}
+
+	if !strings.ContainsRune(path, '\\') {
+		return `\\` + server + `\` + path
+	}
+
+	return path
+}
+
+// hostname returns the host of addr without the port. An IPv6 address is kept in brackets.
+func hostname(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if strings.ContainsRune(host, ':') {
+		return "[" + host + "]"
+	}
+	return host
+}
+
 func normPath(path string) string {
 	if !NORMALIZE_PATH {
 		return path
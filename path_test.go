@@ -1,6 +1,7 @@
 package smb2
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -57,6 +58,13 @@ var testMountPath = []struct {
 	{`\\server\share\file`, false},
 	{`\\127.0.0.1\share`, true},
 	{`\\[0:0:0:0:0:0:0:1]\share`, true},
+	{"\\\\server\\sha\x00re", false},
+	{`\\server\C$`, true},
+	{`\\server\sha:re`, false},
+	{`\\server\sha*re`, false},
+	{"\\\\server\\sha\tre", false},
+	{`\\server\` + strings.Repeat("s", 80), true},
+	{`\\server\` + strings.Repeat("s", 81), false},
 }
 
 func TestValidateMountPath(t *testing.T) {
@@ -66,3 +74,117 @@ func TestValidateMountPath(t *testing.T) {
 		}
 	}
 }
+
+func TestMountPath(t *testing.T) {
+	tests := []struct {
+		sharename string
+		want      string
+	}{
+		{`share`, `\\host\share`},
+		{`C$`, `\\host\C$`},
+		{`\\server\share`, `\\server\share`},
+		{`//server/share`, `\\server\share`},
+		{`\\server\share\`, `\\server\share`},
+		{`//server/share/`, `\\server\share`},
+	}
+	for _, tt := range tests {
+		if got := mountPath(tt.sharename, "host"); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.sharename, tt.want, got)
+		}
+	}
+
+	for addr, want := range map[string]string{
+		"server:445":    "server",
+		"127.0.0.1:445": "127.0.0.1",
+		"[::1]:445":     "[::1]",
+		"server":        "server",
+	} {
+		if got := hostname(addr); got != want {
+			t.Errorf("%s: expected %s, got %s", addr, want, got)
+		}
+	}
+}
+
+var testValidatePath = []struct {
+	Path string
+	Ok   bool
+}{
+	{``, true},
+	{`foo\bar`, true},
+	{`😀\𠀋.txt`, true},
+	{"foo\x00bar", false},
+	{"foo\\bar\x00", false},
+	{`\foo`, false},
+	{strings.Repeat("a", 255), true},
+	{strings.Repeat("a", 256), false},
+	{`foo\` + strings.Repeat("😀", 127) + "a", true}, // surrogate pairs count as 2 code units
+	{`foo\` + strings.Repeat("😀", 128), false},
+	{strings.Repeat(strings.Repeat("a", 255)+`\`, 127) + strings.Repeat("a", 255), true},
+	{strings.Repeat(strings.Repeat("a", 255)+`\`, 128) + "a", false},
+}
+
+func TestValidatePath(t *testing.T) {
+	for _, c := range testValidatePath {
+		if err := validatePath("open", c.Path, false); err == nil != c.Ok {
+			t.Errorf("path: %q, expected: %v, got: %v", c.Path, c.Ok, err)
+		}
+	}
+}
+
+func TestCleanPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{``, ``, true},
+		{`.`, ``, true},
+		{`\`, ``, true},
+		{`foo\bar`, `foo\bar`, true},
+		{`/foo/bar/`, `foo\bar`, true},
+		{`\\foo\\bar`, `foo\bar`, true},
+		{`./foo/.\bar`, `foo\bar`, true},
+		{`foo\..\bar`, `bar`, true},
+		{`foo\bar\..\..`, ``, true},
+		{`file:stream`, `file:stream`, true},
+		{`..`, ``, false},
+		{`\..\foo`, ``, false},
+		{`foo\..\..\bar`, ``, false},
+		{"foo\x00bar", ``, false},
+	}
+	for _, tt := range tests {
+		got, err := CleanPath(tt.path)
+		if err == nil != tt.ok || got != tt.want {
+			t.Errorf("path: %q, expected: %q, %v, got: %q, %v", tt.path, tt.want, tt.ok, got, err)
+		}
+	}
+}
+
+func TestDecodeName(t *testing.T) {
+	defer func(mode UTF16DecodeMode) { UTF16Decoding = mode }(UTF16Decoding)
+
+	// a name with an unpaired surrogate, as received from the server
+	name := "a\xed\xa0\x80b"
+
+	tests := []struct {
+		mode UTF16DecodeMode
+		name string
+		err  error
+	}{
+		{UTF16Preserve, name, nil},
+		{UTF16Replace, "a�b", nil},
+		{UTF16Strict, "", ErrInvalidUTF16},
+	}
+
+	for _, tt := range tests {
+		UTF16Decoding = tt.mode
+
+		got, err := decodeName(name)
+		if got != tt.name || err != tt.err {
+			t.Errorf("mode %d: expected %q, %v, got %q, %v", tt.mode, tt.name, tt.err, got, err)
+		}
+		if got, err := decodeName("ok"); got != "ok" || err != nil {
+			t.Errorf("mode %d: unexpected result for a valid name: %q, %v", tt.mode, got, err)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package smb2
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// pipeMaxMessageChunk is the size of the chunks of the messages read by func (*NamedPipe) Transact,
+// which take a single credit.
+const pipeMaxMessageChunk = 64 * 1024
+
+// NamedPipe is a named pipe of IPC$ opened by func (*Session) OpenNamedPipe.
+// Messages are written by Write and read by Read, or exchanged in a single round trip by Transact.
+type NamedPipe struct {
+	fs *Share
+	f  *File
+}
+
+// OpenNamedPipe opens the named pipe on IPC$ for reading and writing.
+// name is the name of the pipe (e.g. "srvsvc"), a `\pipe\` prefix is ignored.
+func (c *Session) OpenNamedPipe(name string) (*NamedPipe, error) {
+	fs, err := c.Mount(fmt.Sprintf(`\\%s\IPC$`, hostname(c.addr)))
+	if err != nil {
+		return nil, err
+	}
+
+	fs = fs.WithContext(c.ctx)
+
+	f, err := fs.OpenFile(pipeName(name), os.O_RDWR, 0666)
+	if err != nil {
+		fs.Umount()
+		return nil, err
+	}
+
+	return &NamedPipe{fs: fs, f: f}, nil
+}
+
+// Name returns the name of the pipe.
+func (p *NamedPipe) Name() string {
+	return p.f.name
+}
+
+// Read reads a message, or a part of it if b is too small, into b.
+// The rest of the message is returned by the following reads.
+func (p *NamedPipe) Read(b []byte) (int, error) {
+	n, err := p.f.readPipe(b)
+	if err != nil && !isBufferOverflow(err) {
+		return n, &os.PathError{Op: "read", Path: p.f.name, Err: err}
+	}
+	return n, nil
+}
+
+// Write writes b to the pipe as a message.
+func (p *NamedPipe) Write(b []byte) (int, error) {
+	n, err := p.f.writeAt(b, 0)
+	if err != nil {
+		return n, &os.PathError{Op: "write", Path: p.f.name, Err: err}
+	}
+	return n, nil
+}
+
+// Transact writes the message in to the pipe and reads the reply message,
+// in a single round trip by FSCTL_PIPE_TRANSCEIVE rather than a write followed by a read.
+// If the reply doesn't fit in the response (STATUS_BUFFER_OVERFLOW), the rest of it is read from the pipe.
+func (p *NamedPipe) Transact(in []byte) ([]byte, error) {
+	out, err := p.f.transceive(in)
+	if err != nil {
+		return nil, &os.PathError{Op: "transact", Path: p.f.name, Err: err}
+	}
+	return out, nil
+}
+
+// Stat returns the FileInfo of the pipe, whose mode has os.ModeNamedPipe.
+func (p *NamedPipe) Stat() (os.FileInfo, error) {
+	return p.f.Stat()
+}
+
+// Pipe types of PipeInfo.Type and read modes of PipeInfo.ReadMode. (See [MS-FSCC] 2.4.33 and 2.4.34)
+const (
+	PipeByteStream = FILE_PIPE_BYTE_STREAM_TYPE
+	PipeMessage    = FILE_PIPE_MESSAGE_TYPE
+)
+
+// Configurations of PipeInfo.Configuration.
+const (
+	PipeInbound    = FILE_PIPE_INBOUND
+	PipeOutbound   = FILE_PIPE_OUTBOUND
+	PipeFullDuplex = FILE_PIPE_FULL_DUPLEX
+)
+
+// States of PipeInfo.State.
+const (
+	PipeDisconnected = FILE_PIPE_DISCONNECTED_STATE
+	PipeListening    = FILE_PIPE_LISTENING_STATE
+	PipeConnected    = FILE_PIPE_CONNECTED_STATE
+	PipeClosing      = FILE_PIPE_CLOSING_STATE
+)
+
+// PipeInfo is the information of a named pipe returned by func (*NamedPipe) Info,
+// from FilePipeInformation and FilePipeLocalInformation.
+type PipeInfo struct {
+	ReadMode       uint32 // PipeByteStream or PipeMessage, how the client end reads
+	CompletionMode uint32 // 0 if the operations are queued (blocking), 1 if they complete immediately
+
+	Type          uint32 // PipeByteStream or PipeMessage, how the pipe was created
+	Configuration uint32 // PipeInbound, PipeOutbound or PipeFullDuplex
+
+	MaxInstances     uint32 // 0xFFFFFFFF if unlimited
+	CurrentInstances uint32
+
+	InboundQuota        uint32
+	ReadDataAvailable   uint32
+	OutboundQuota       uint32
+	WriteQuotaAvailable uint32
+
+	State     uint32 // PipeDisconnected, PipeListening, PipeConnected or PipeClosing
+	ServerEnd bool   // the handle is the server end of the pipe
+}
+
+// MessageMode reports whether the pipe preserves message boundaries in its reads.
+func (i *PipeInfo) MessageMode() bool {
+	return i.Type == PipeMessage && i.ReadMode == PipeMessage
+}
+
+// Info returns the type, the mode, the instances and the quotas of the pipe.
+func (p *NamedPipe) Info() (*PipeInfo, error) {
+	info, err := p.f.pipeInfo()
+	if err != nil {
+		return nil, &os.PathError{Op: "stat", Path: p.f.name, Err: err}
+	}
+	return info, nil
+}
+
+func (f *File) pipeInfo() (*PipeInfo, error) {
+	infoBytes, err := f.queryInfo(&QueryInfoRequest{
+		InfoType:           SMB2_0_INFO_FILE,
+		FileInfoClass:      FilePipeInformation,
+		OutputBufferLength: 8,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pi := FilePipeInformationDecoder(infoBytes)
+	if pi.IsInvalid() {
+		return nil, &InvalidResponseError{"broken query info response format"}
+	}
+
+	infoBytes, err = f.queryInfo(&QueryInfoRequest{
+		InfoType:           SMB2_0_INFO_FILE,
+		FileInfoClass:      FilePipeLocalInformation,
+		OutputBufferLength: 40,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	li := FilePipeLocalInformationDecoder(infoBytes)
+	if li.IsInvalid() {
+		return nil, &InvalidResponseError{"broken query info response format"}
+	}
+
+	return &PipeInfo{
+		ReadMode:            pi.ReadMode(),
+		CompletionMode:      pi.CompletionMode(),
+		Type:                li.NamedPipeType(),
+		Configuration:       li.NamedPipeConfiguration(),
+		MaxInstances:        li.MaximumInstances(),
+		CurrentInstances:    li.CurrentInstances(),
+		InboundQuota:        li.InboundQuota(),
+		ReadDataAvailable:   li.ReadDataAvailable(),
+		OutboundQuota:       li.OutboundQuota(),
+		WriteQuotaAvailable: li.WriteQuotaAvailable(),
+		State:               li.NamedPipeState(),
+		ServerEnd:           li.NamedPipeEnd() == FILE_PIPE_SERVER_END,
+	}, nil
+}
+
+// statPipe returns the FileInfo of a pipe from FileStandardInformation, which the named pipe file systems support,
+// or without sizes if they don't.
+func (f *File) statPipe() (os.FileInfo, error) {
+	fi := &FileStat{
+		FileAttributes: FILE_ATTRIBUTE_NORMAL,
+		FileName:       base(f.name),
+		pipe:           true,
+	}
+
+	infoBytes, err := f.queryInfo(&QueryInfoRequest{
+		InfoType:           SMB2_0_INFO_FILE,
+		FileInfoClass:      FileStandardInformation,
+		OutputBufferLength: 24,
+	})
+	if err != nil {
+		if _, ok := err.(*ResponseError); ok {
+			return fi, nil
+		}
+		return nil, err
+	}
+
+	std := FileStandardInformationDecoder(infoBytes)
+	if std.IsInvalid() {
+		return nil, &InvalidResponseError{"broken query info response format"}
+	}
+
+	fi.EndOfFile = std.EndOfFile()
+	fi.AllocationSize = std.AllocationSize()
+
+	return fi, nil
+}
+
+// Close closes the pipe and disconnects IPC$.
+func (p *NamedPipe) Close() error {
+	err := p.f.Close()
+	if uerr := p.fs.Umount(); err == nil {
+		err = uerr
+	}
+	return err
+}
+
+func (f *File) transceive(in []byte) ([]byte, error) {
+	chunk := pipeMaxMessageChunk
+	if size := f.maxTransactSize() - len(in); size < chunk {
+		chunk = size
+	}
+	if chunk <= 0 {
+		return nil, &InternalError{fmt.Sprintf("payload size %d exceeds max transact size %d", len(in), f.maxTransactSize())}
+	}
+
+	req := &IoctlRequest{
+		CtlCode:           FSCTL_PIPE_TRANSCEIVE,
+		OutputOffset:      0,
+		OutputCount:       0,
+		MaxInputResponse:  0,
+		MaxOutputResponse: uint32(chunk),
+		Flags:             SMB2_0_IOCTL_IS_FSCTL,
+		Input:             pipeMessage(in),
+	}
+
+	output, err := f.ioctl(req)
+	if err != nil && !isBufferOverflow(err) {
+		return nil, err
+	}
+
+	out := append([]byte{}, output...)
+
+	if size := f.maxReadSize(); size < chunk {
+		chunk = size
+	}
+
+	for err != nil {
+		buf := make([]byte, chunk)
+
+		var n int
+		n, err = f.readPipe(buf)
+		if err != nil && !isBufferOverflow(err) {
+			return nil, err
+		}
+
+		out = append(out, buf[:n]...)
+	}
+
+	return out, nil
+}
+
+// isBufferOverflow reports whether err is STATUS_BUFFER_OVERFLOW,
+// which a named pipe returns with the part of a message fitting in the buffer.
+func isBufferOverflow(err error) bool {
+	rerr, ok := err.(*ResponseError)
+	return ok && NtStatus(rerr.Code) == STATUS_BUFFER_OVERFLOW
+}
+
+// pipeMessage is a message written to a pipe.
+type pipeMessage []byte
+
+func (m pipeMessage) Size() int {
+	return len(m)
+}
+
+func (m pipeMessage) Encode(p []byte) {
+	copy(p, m)
+}
+
+// WaitNamedPipe waits until an instance of the named pipe is available on the server,
+// so that services which create their pipes lazily can be connected to.
+// name is the name of the pipe on IPC$ (e.g. "srvsvc"), a `\pipe\` prefix is ignored.
+// If timeout isn't positive, the default timeout of the server is used.
+// If no instance becomes available in time, the STATUS_IO_TIMEOUT response error is returned.
+// If the pipe doesn't exist at all, the error satisfies os.IsNotExist.
+func (c *Session) WaitNamedPipe(name string, timeout time.Duration) error {
+	fs, err := c.Mount(fmt.Sprintf(`\\%s\IPC$`, hostname(c.addr)))
+	if err != nil {
+		return err
+	}
+	defer fs.Umount()
+
+	fs = fs.WithContext(c.ctx)
+
+	return fs.waitNamedPipe(name, timeout)
+}
+
+func (fs *Share) waitNamedPipe(name string, timeout time.Duration) error {
+	name = pipeName(name)
+
+	wait := &PipeWaitRequest{Name: name}
+	if timeout > 0 {
+		// relative times are negative
+		wait.Timeout = -int64(timeout / 100)
+		wait.TimeoutSpecified = true
+	}
+
+	req := &IoctlRequest{
+		CtlCode: FSCTL_PIPE_WAIT,
+		// the request is issued on the pipe file system, not on an open
+		FileId: &FileId{
+			Persistent: [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			Volatile:   [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		},
+		MaxOutputResponse: 0,
+		Flags:             SMB2_0_IOCTL_IS_FSCTL,
+		Input:             wait,
+	}
+
+	var err error
+
+	req.CreditCharge, _, err = fs.loanCredit(0)
+	if err != nil {
+		return &os.PathError{Op: "waitnamedpipe", Path: name, Err: err}
+	}
+
+	_, err = fs.sendRecv(SMB2_IOCTL, req)
+	if err != nil {
+		return &os.PathError{Op: "waitnamedpipe", Path: name, Err: err}
+	}
+
+	return nil
+}
+
+// pipeName returns the name of a pipe relative to IPC$, without any `\pipe\` prefix.
+func pipeName(name string) string {
+	name = strings.TrimLeft(normPath(name), `\`)
+	if len(name) > 5 && strings.EqualFold(name[:5], `pipe\`) {
+		name = name[5:]
+	}
+	return name
+}
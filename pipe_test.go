@@ -0,0 +1,364 @@
+package smb2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nodauf/go-smb2/internal/utf16le"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+func TestPipeName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"srvsvc":         "srvsvc",
+		`\pipe\srvsvc`:   "srvsvc",
+		`PIPE\srvsvc`:    "srvsvc",
+		"/pipe/srvsvc":   "srvsvc",
+		`\srvsvc`:        "srvsvc",
+		`pipeline\x`:     `pipeline\x`,
+		`pipe\`:          `pipe\`,
+		`spoolss\remote`: `spoolss\remote`,
+	} {
+		if got := pipeName(name); got != expected {
+			t.Errorf("%q: expected %q, got %q", name, expected, got)
+		}
+	}
+}
+
+func TestWaitNamedPipe(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	var input []byte
+	status := STATUS_SUCCESS
+
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+		r := IoctlRequestDecoder(q.Data())
+		if q.Command() != SMB2_IOCTL || r.CtlCode() != FSCTL_PIPE_WAIT || r.Flags() != SMB2_0_IOCTL_IS_FSCTL {
+			t.Errorf("unexpected request: %v", q.Command())
+		}
+		input = append([]byte{}, req[r.InputOffset():r.InputOffset()+r.InputCount()]...)
+
+		hdr := PacketHeader{
+			Command:               SMB2_IOCTL,
+			CreditRequestResponse: 1,
+			Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+			MessageId:             q.MessageId(),
+			TreeId:                q.TreeId(),
+			SessionId:             q.SessionId(),
+			Status:                uint32(status),
+		}
+
+		var res Packet = &ErrorResponse{PacketHeader: hdr}
+		if status == STATUS_SUCCESS {
+			res = &IoctlResponse{PacketHeader: hdr, CtlCode: FSCTL_PIPE_WAIT, FileId: &FileId{}}
+		}
+
+		pkt := make([]byte, res.Size())
+		res.Encode(pkt)
+		tr.push(pkt)
+	}
+
+	fs := newFakeShare(tr)
+
+	if err := fs.waitNamedPipe(`\pipe\srvsvc`, 1500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(input) != 14+12 {
+		t.Fatalf("unexpected input length: %d", len(input))
+	}
+	if timeout := int64(binary.LittleEndian.Uint64(input[:8])); timeout != -15000000 {
+		t.Errorf("unexpected timeout: %d", timeout)
+	}
+	if binary.LittleEndian.Uint32(input[8:12]) != 12 || input[12] != 1 {
+		t.Errorf("unexpected name length or timeout flag: %v", input[8:14])
+	}
+	if name := utf16le.DecodeToString(input[14:]); name != "srvsvc" {
+		t.Errorf("unexpected name: %q", name)
+	}
+
+	if err := fs.waitNamedPipe("srvsvc", 0); err != nil {
+		t.Fatal(err)
+	}
+	if binary.LittleEndian.Uint64(input[:8]) != 0 || input[12] != 0 {
+		t.Errorf("the timeout is specified: %v", input[:14])
+	}
+
+	status = STATUS_IO_TIMEOUT
+
+	err := fs.waitNamedPipe("srvsvc", time.Second)
+	if e, ok := err.(*os.PathError); !ok || e.Op != "waitnamedpipe" {
+		t.Fatalf("expected a *os.PathError of waitnamedpipe, got %v", err)
+	} else if rerr, ok := e.Err.(*ResponseError); !ok || NtStatus(rerr.Code) != STATUS_IO_TIMEOUT {
+		t.Errorf("expected STATUS_IO_TIMEOUT, got %v", e.Err)
+	}
+
+	status = STATUS_OBJECT_NAME_NOT_FOUND
+
+	if err := fs.waitNamedPipe("nopipe", time.Second); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
+
+// fakePipeServer answers the messages of FSCTL_PIPE_TRANSCEIVE by reply.
+// The parts of reply which don't fit in the responses are returned by the following READ requests,
+// with STATUS_BUFFER_OVERFLOW if more of it follows.
+type fakePipeServer struct {
+	tr    *fakeTransport
+	reply []byte
+
+	in   []byte // last message
+	rest []byte // remaining part of the reply
+	cmds []uint16
+}
+
+func (s *fakePipeServer) handle(req []byte) {
+	q := PacketCodec(req)
+
+	s.cmds = append(s.cmds, q.Command())
+
+	hdr := PacketHeader{
+		Command:               q.Command(),
+		CreditRequestResponse: q.CreditCharge(),
+		Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+		MessageId:             q.MessageId(),
+		TreeId:                q.TreeId(),
+		SessionId:             q.SessionId(),
+	}
+
+	var out []byte
+	var max int
+
+	switch q.Command() {
+	case SMB2_IOCTL:
+		r := IoctlRequestDecoder(q.Data())
+		s.in = append([]byte{}, req[r.InputOffset():r.InputOffset()+r.InputCount()]...)
+		s.rest = s.reply
+		max = int(r.MaxOutputResponse())
+	case SMB2_READ:
+		max = int(ReadRequestDecoder(q.Data()).Length())
+	}
+
+	out = s.rest
+	if len(out) > max {
+		out = out[:max]
+		hdr.Status = uint32(STATUS_BUFFER_OVERFLOW)
+	}
+	s.rest = s.rest[len(out):]
+
+	var pkt []byte
+	if q.Command() == SMB2_IOCTL {
+		res := &IoctlResponse{PacketHeader: hdr, CtlCode: FSCTL_PIPE_TRANSCEIVE, FileId: &FileId{}, Input: fakeBytes(nil), Output: fakeBytes(out)}
+		pkt = make([]byte, res.Size())
+		res.Encode(pkt)
+	} else {
+		pkt = newTestReadResponse(hdr.MessageId, hdr.SessionId, out)
+		p := PacketCodec(pkt)
+		p.SetTreeId(hdr.TreeId)
+		p.SetCreditResponse(hdr.CreditRequestResponse)
+		p.SetStatus(hdr.Status)
+	}
+	s.tr.push(pkt)
+}
+
+func TestTransact(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakePipeServer{tr: tr}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+	fs.conn.maxReadSize = 1000
+	fs.conn.maxTransactSize = 1000
+
+	p := &NamedPipe{fs: fs, f: &File{fs: fs, fd: &FileId{}, name: "srvsvc"}}
+
+	// the reply fits in the IOCTL response
+	srv.reply = []byte("pong")
+
+	out, err := p.Transact([]byte("ping"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "pong" || string(srv.in) != "ping" {
+		t.Errorf("unexpected message or reply: %q, %q", srv.in, out)
+	}
+	if !reflect.DeepEqual(srv.cmds, []uint16{SMB2_IOCTL}) {
+		t.Errorf("expected a single IOCTL, got %v", srv.cmds)
+	}
+
+	// the rest of the reply is read
+	srv.reply = bytes.Repeat([]byte("0123456789"), 250)
+	srv.cmds = nil
+
+	out, err = p.Transact([]byte("ping"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, srv.reply) {
+		t.Errorf("unexpected reply of %d bytes", len(out))
+	}
+	if !reflect.DeepEqual(srv.cmds, []uint16{SMB2_IOCTL, SMB2_READ, SMB2_READ}) {
+		t.Errorf("expected an IOCTL followed by 2 READs, got %v", srv.cmds)
+	}
+
+	// the message can't exceed the max transact size
+	if _, err := p.Transact(make([]byte, 1000)); err == nil {
+		t.Error("expected an error for a message exceeding the max transact size")
+	}
+}
+
+func TestNamedPipeInfo(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	// the pipe file system supports the pipe and standard information, but not FileAllInformation
+	var classes []uint8
+
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+		class := QueryInfoRequestDecoder(q.Data()).FileInfoClass()
+		classes = append(classes, class)
+
+		hdr := PacketHeader{
+			Command:               q.Command(),
+			CreditRequestResponse: 1,
+			Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+			MessageId:             q.MessageId(),
+			TreeId:                q.TreeId(),
+			SessionId:             q.SessionId(),
+		}
+
+		var res Packet
+		switch class {
+		case FilePipeInformation:
+			info := make(fakeBytes, 8)
+			binary.LittleEndian.PutUint32(info[:4], FILE_PIPE_MESSAGE_MODE)
+			res = &QueryInfoResponse{PacketHeader: hdr, Output: info}
+		case FilePipeLocalInformation:
+			info := make(fakeBytes, 40)
+			binary.LittleEndian.PutUint32(info[:4], FILE_PIPE_MESSAGE_TYPE)
+			binary.LittleEndian.PutUint32(info[4:8], FILE_PIPE_FULL_DUPLEX)
+			binary.LittleEndian.PutUint32(info[8:12], 0xffffffff) // MaximumInstances
+			binary.LittleEndian.PutUint32(info[12:16], 3)         // CurrentInstances
+			binary.LittleEndian.PutUint32(info[16:20], 4280)      // InboundQuota
+			binary.LittleEndian.PutUint32(info[24:28], 4280)      // OutboundQuota
+			binary.LittleEndian.PutUint32(info[32:36], FILE_PIPE_CONNECTED_STATE)
+			res = &QueryInfoResponse{PacketHeader: hdr, Output: info}
+		case FileStandardInformation:
+			info := make(fakeBytes, 24)
+			binary.LittleEndian.PutUint64(info[:8], 4096) // AllocationSize
+			res = &QueryInfoResponse{PacketHeader: hdr, Output: info}
+		default:
+			hdr.Status = uint32(STATUS_INVALID_PARAMETER)
+			res = &ErrorResponse{PacketHeader: hdr}
+		}
+
+		pkt := make([]byte, res.Size())
+		res.Encode(pkt)
+		PacketCodec(pkt).SetCommand(q.Command())
+		tr.push(pkt)
+	}
+
+	fs := newFakeShare(tr)
+	fs.shareType = SMB2_SHARE_TYPE_PIPE
+
+	p := &NamedPipe{fs: fs, f: &File{fs: fs, fd: &FileId{}, name: "srvsvc"}}
+
+	info, err := p.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &PipeInfo{
+		ReadMode:         PipeMessage,
+		Type:             PipeMessage,
+		Configuration:    PipeFullDuplex,
+		MaxInstances:     0xffffffff,
+		CurrentInstances: 3,
+		InboundQuota:     4280,
+		OutboundQuota:    4280,
+		State:            PipeConnected,
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("unexpected info %+v", info)
+	}
+	if !info.MessageMode() {
+		t.Error("expected message mode")
+	}
+
+	// Stat falls back to FileStandardInformation
+	classes = nil
+
+	fi, err := p.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&os.ModeNamedPipe == 0 || fi.Name() != "srvsvc" || fi.Sys().(*FileStat).AllocationSize != 4096 {
+		t.Errorf("unexpected file info %v, %q, %d", fi.Mode(), fi.Name(), fi.Sys().(*FileStat).AllocationSize)
+	}
+	if !reflect.DeepEqual(classes, []uint8{FileAllInformation, FileStandardInformation}) {
+		t.Errorf("unexpected information classes %v", classes)
+	}
+}
+
+func TestReadPipeShortMessage(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	// the server answers the first READ by a message shorter than requested,
+	// and doesn't answer the next ones until another message is written
+	reads := 0
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+		if q.Command() != SMB2_READ {
+			t.Errorf("unexpected request: %v", q.Command())
+			return
+		}
+		reads++
+		if reads > 1 {
+			return
+		}
+		pkt := newTestReadResponse(q.MessageId(), q.SessionId(), []byte("hello"))
+		PacketCodec(pkt).SetTreeId(q.TreeId())
+		PacketCodec(pkt).SetCreditResponse(q.CreditCharge())
+		tr.push(pkt)
+	}
+
+	fs := newFakeShare(tr)
+	fs.shareType = SMB2_SHARE_TYPE_PIPE
+
+	f := fs.newFile(FileIdDecoder(make([]byte, 16)), "pipe")
+
+	type result struct {
+		n   int
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		n, err := f.Read(make([]byte, 64))
+		done <- result{n, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.n != 5 || r.err != nil {
+			t.Errorf("expected the message of 5 bytes, got %d, %v", r.n, r.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the read waits for the next message")
+	}
+
+	if reads != 1 {
+		t.Errorf("expected a single READ, got %d", reads)
+	}
+}
@@ -0,0 +1,318 @@
+package smb2
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// SessionPool is a fixed size set of authenticated sessions to the same server.
+// Servers limit the number of concurrent operations per session,
+// so spreading the operations over several sessions increases the throughput.
+// Sessions are picked in round-robin order. A session whose connection is lost
+// is replaced by a new one next time it's picked.
+type SessionPool struct {
+	d    *Dialer
+	addr string
+
+	m        sync.Mutex
+	sessions []*Session
+	dialing  []chan struct{} // closed once the session is redialed, nil if it isn't being redialed
+	next     int
+	closed   bool
+}
+
+// NewPool dials addr (host:port) size times over TCP and returns the pool of the established sessions.
+func (d *Dialer) NewPool(addr string, size int) (*SessionPool, error) {
+	return d.NewPoolContext(context.Background(), addr, size)
+}
+
+// NewPoolContext is like func (*Dialer) NewPool but uses ctx for dialing the initial sessions.
+// Sessions which are redialed later use the context passed to func (*SessionPool) SessionContext.
+func (d *Dialer) NewPoolContext(ctx context.Context, addr string, size int) (*SessionPool, error) {
+	if size <= 0 {
+		return nil, &InternalError{fmt.Sprintf("invalid pool size: %d", size)}
+	}
+
+	p := &SessionPool{
+		d:        d,
+		addr:     addr,
+		sessions: make([]*Session, size),
+		dialing:  make([]chan struct{}, size),
+	}
+
+	for i := range p.sessions {
+		s, err := p.dial(ctx)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.sessions[i] = s
+	}
+
+	return p, nil
+}
+
+func (p *SessionPool) dial(ctx context.Context) (*Session, error) {
+	var nd net.Dialer
+
+	tcpConn, err := nd.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return nil, &TransportError{err}
+	}
+
+	s, err := p.d.DialContext(ctx, tcpConn)
+	if err != nil {
+		tcpConn.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Session returns the next session of the pool.
+// If its connection has been lost, it's replaced by a newly dialed session.
+func (p *SessionPool) Session() (*Session, error) {
+	return p.SessionContext(context.Background())
+}
+
+// SessionContext is like func (*SessionPool) Session but uses ctx for redialing the session.
+// The pool isn't locked while dialing, so the other sessions are still handed out;
+// the callers picking the same session wait for the dial.
+func (p *SessionPool) SessionContext(ctx context.Context) (*Session, error) {
+	p.m.Lock()
+
+	if p.closed {
+		p.m.Unlock()
+		return nil, os.ErrClosed
+	}
+
+	i := p.next
+	p.next = (p.next + 1) % len(p.sessions)
+
+	for {
+		if s := p.sessions[i]; s != nil && !s.s.conn.isClosed() {
+			p.m.Unlock()
+			return s, nil
+		}
+
+		done := p.dialing[i]
+		if done == nil {
+			break
+		}
+
+		p.m.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, &ContextError{Err: ctx.Err()}
+		}
+
+		p.m.Lock()
+
+		if p.closed {
+			p.m.Unlock()
+			return nil, os.ErrClosed
+		}
+	}
+
+	done := make(chan struct{})
+	p.dialing[i] = done
+
+	p.m.Unlock()
+
+	s, err := p.dial(ctx)
+
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.dialing[i] = nil
+	close(done)
+
+	if err != nil {
+		p.sessions[i] = nil
+		return nil, err
+	}
+
+	if p.closed {
+		s.Logoff()
+		return nil, os.ErrClosed
+	}
+
+	p.sessions[i] = s
+
+	return s, nil
+}
+
+// Mount returns a share whose operations are distributed over the sessions of the pool.
+// Tree connections are established lazily for each session.
+func (p *SessionPool) Mount(sharename string) (*PoolShare, error) {
+	sharename = mountPath(sharename, hostname(p.addr))
+
+	if err := validateMountPath(sharename); err != nil {
+		return nil, err
+	}
+
+	ps := &PoolShare{
+		p:         p,
+		sharename: sharename,
+		shares:    make(map[*Session]*Share),
+	}
+
+	// fail fast if the share doesn't exist
+	if _, err := ps.share(); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// Close logs off all the sessions of the pool.
+func (p *SessionPool) Close() error {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if p.closed {
+		return os.ErrClosed
+	}
+
+	p.closed = true
+
+	var err error
+	for i, s := range p.sessions {
+		if s == nil {
+			continue
+		}
+		if !s.s.conn.isClosed() {
+			if e := s.Logoff(); err == nil {
+				err = e
+			}
+		}
+		p.sessions[i] = nil
+	}
+	return err
+}
+
+// PoolShare represents a share mounted on every session of a SessionPool.
+// Each operation runs on the next session of the pool.
+// Files opened through a PoolShare stay bound to the session they were opened on.
+type PoolShare struct {
+	p         *SessionPool
+	sharename string
+
+	m      sync.Mutex
+	shares map[*Session]*Share
+}
+
+func (ps *PoolShare) share() (*Share, error) {
+	s, err := ps.p.Session()
+	if err != nil {
+		return nil, err
+	}
+
+	ps.m.Lock()
+	defer ps.m.Unlock()
+
+	for ss := range ps.shares {
+		if ss.s.conn.isClosed() {
+			delete(ps.shares, ss)
+		}
+	}
+
+	if fs, ok := ps.shares[s]; ok {
+		return fs, nil
+	}
+
+	fs, err := s.Mount(ps.sharename)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.shares[s] = fs
+
+	return fs, nil
+}
+
+func (ps *PoolShare) Create(name string) (*File, error) {
+	fs, err := ps.share()
+	if err != nil {
+		return nil, err
+	}
+	return fs.Create(name)
+}
+
+func (ps *PoolShare) Open(name string) (*File, error) {
+	fs, err := ps.share()
+	if err != nil {
+		return nil, err
+	}
+	return fs.Open(name)
+}
+
+func (ps *PoolShare) OpenFile(name string, flag int, perm os.FileMode) (*File, error) {
+	fs, err := ps.share()
+	if err != nil {
+		return nil, err
+	}
+	return fs.OpenFile(name, flag, perm)
+}
+
+func (ps *PoolShare) Stat(name string) (os.FileInfo, error) {
+	fs, err := ps.share()
+	if err != nil {
+		return nil, err
+	}
+	return fs.Stat(name)
+}
+
+func (ps *PoolShare) Lstat(name string) (os.FileInfo, error) {
+	fs, err := ps.share()
+	if err != nil {
+		return nil, err
+	}
+	return fs.Lstat(name)
+}
+
+func (ps *PoolShare) ReadDir(dirname string) ([]os.FileInfo, error) {
+	fs, err := ps.share()
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(dirname)
+}
+
+func (ps *PoolShare) Mkdir(name string, perm os.FileMode) error {
+	fs, err := ps.share()
+	if err != nil {
+		return err
+	}
+	return fs.Mkdir(name, perm)
+}
+
+func (ps *PoolShare) Remove(name string) error {
+	fs, err := ps.share()
+	if err != nil {
+		return err
+	}
+	return fs.Remove(name)
+}
+
+// Umount disconnects the share from all the sessions of the pool.
+func (ps *PoolShare) Umount() error {
+	ps.m.Lock()
+	defer ps.m.Unlock()
+
+	var err error
+	for s, fs := range ps.shares {
+		if !s.s.conn.isClosed() {
+			if e := fs.Umount(); err == nil {
+				err = e
+			}
+		}
+		delete(ps.shares, s)
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+package smb2
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSessionPoolRedial(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// the connections after the initial ones aren't answered until hold is closed
+	hold := make(chan struct{})
+
+	go func() {
+		for n := 0; ; n++ {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func(n int) {
+				defer c.Close()
+				if n >= 2 {
+					<-hold
+				}
+				serveFakeDial(c, &fakeAuthServer{tr: newFakeTransport(), legs: 1})
+			}(n)
+		}
+	}()
+
+	d := &Dialer{Initiator: &fakeInitiator{legs: 1}}
+
+	p, err := d.NewPool(l.Addr().String(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	lost := p.sessions[0]
+	lost.s.conn.t.Close()
+	for !lost.s.conn.isClosed() {
+		time.Sleep(time.Millisecond)
+	}
+
+	redialed := make(chan error, 1)
+	go func() {
+		_, err := p.Session()
+		redialed <- err
+	}()
+
+	for {
+		p.m.Lock()
+		dialing := p.dialing[0] != nil
+		p.m.Unlock()
+		if dialing {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// the other session is handed out while the lost one is redialed
+	got := make(chan *Session, 1)
+	go func() {
+		s, _ := p.Session()
+		got <- s
+	}()
+	select {
+	case s := <-got:
+		if s != p.sessions[1] {
+			t.Error("expected the second session")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the pool is locked while redialing")
+	}
+
+	// a caller picking the session being redialed waits for the dial, up to its context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := p.SessionContext(ctx); err == nil {
+		t.Error("expected a context error")
+	} else if _, ok := err.(*ContextError); !ok {
+		t.Errorf("expected a context error, got %v", err)
+	}
+
+	close(hold)
+
+	if err := <-redialed; err != nil {
+		t.Fatal(err)
+	}
+
+	p.m.Lock()
+	s := p.sessions[0]
+	p.m.Unlock()
+
+	if s == nil || s == lost || s.s.conn.isClosed() {
+		t.Error("expected a redialed session")
+	}
+}
+
+func TestPoolShareMount(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	var srvs []*fakeAuthServer
+	var m sync.Mutex
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			srv := &fakeAuthServer{tr: newFakeTransport(), legs: 1}
+			m.Lock()
+			srvs = append(srvs, srv)
+			m.Unlock()
+			go func() {
+				defer c.Close()
+				serveFakeDial(c, srv)
+			}()
+		}
+	}()
+
+	d := &Dialer{Initiator: &fakeInitiator{legs: 1}}
+
+	p, err := d.NewPool(l.Addr().String(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	for _, sharename := range []string{"share", `\\server\share`, "//server/share/"} {
+		if _, err := p.Mount(sharename); err != nil {
+			t.Fatalf("%s: %v", sharename, err)
+		}
+	}
+
+	m.Lock()
+	srv := srvs[0]
+	m.Unlock()
+
+	srv.m.Lock()
+	paths := srv.paths
+	srv.m.Unlock()
+
+	// the share is named by the host without the port, like by func (*Session) Mount
+	want := []string{`\\127.0.0.1\share`, `\\server\share`, `\\server\share`}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("expected %q, got %q", want, paths)
+	}
+}
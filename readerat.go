@@ -0,0 +1,68 @@
+package smb2
+
+import (
+	"io"
+	"os"
+)
+
+// ReaderAtCloser is a read-only view of a file returned by func (*Share) OpenReaderAt.
+type ReaderAtCloser interface {
+	io.ReaderAt
+	io.Closer
+
+	// Size returns the size of the file when it was opened.
+	Size() int64
+}
+
+// OpenReaderAt opens the named file for reading, and returns a view of it only supporting ReadAt, Size and Close,
+// which can be passed to readers expecting a seekable source, e.g. archive/zip.NewReader, together with its size.
+// The reads are positional, so they can be issued concurrently, and don't read past the size the file had when it was opened.
+func (fs *Share) OpenReaderAt(name string) (ReaderAtCloser, int64, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size, err := f.size()
+	if err != nil {
+		f.Close()
+		return nil, 0, &os.PathError{Op: "stat", Path: f.name, Err: err}
+	}
+
+	return &readerAt{f: f, size: size}, size, nil
+}
+
+// readerAt is a ReaderAtCloser of a file opened by func (*Share) OpenReaderAt.
+type readerAt struct {
+	f    *File
+	size int64
+}
+
+func (r *readerAt) ReadAt(b []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, os.ErrInvalid
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	short := false
+	if int64(len(b)) > r.size-off {
+		b = b[:r.size-off]
+		short = true
+	}
+
+	n, err = r.f.ReadAt(b, off)
+	if err == nil && short {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (r *readerAt) Size() int64 {
+	return r.size
+}
+
+func (r *readerAt) Close() error {
+	return r.f.Close()
+}
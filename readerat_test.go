@@ -0,0 +1,69 @@
+package smb2
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+func TestOpenReaderAt(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1024)
+
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`file`: {attrs: FILE_ATTRIBUTE_ARCHIVE, size: int64(len(data))},
+		},
+	}
+	// the file grows past the size it had when it was opened
+	fileSrv := &fakeFileServer{tr: tr, data: append(data, "tail"...), maxRead: 4096}
+	tr.handler = func(req []byte) {
+		if PacketCodec(req).Command() == SMB2_READ {
+			fileSrv.handle(req)
+			return
+		}
+		srv.handle(req)
+	}
+
+	r, size, err := newFakeShare(tr).OpenReaderAt("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if size != int64(len(data)) || r.Size() != size {
+		t.Fatalf("expected size %d, got %d, %d", len(data), size, r.Size())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(off int64) {
+			defer wg.Done()
+
+			b := make([]byte, 4096)
+			if n, err := r.ReadAt(b, off); n != len(b) || err != nil {
+				t.Errorf("%d: unexpected read of %d bytes: %v", off, n, err)
+				return
+			}
+			if !bytes.Equal(b, data[off:off+4096]) {
+				t.Errorf("%d: unexpected content", off)
+			}
+		}(int64(i) * 4096)
+	}
+	wg.Wait()
+
+	b := make([]byte, 100)
+	if n, err := r.ReadAt(b, size-10); n != 10 || err != io.EOF || !bytes.Equal(b[:n], data[len(data)-10:]) {
+		t.Errorf("expected the last 10 bytes and io.EOF, got %d bytes: %v", n, err)
+	}
+	if n, err := r.ReadAt(b, size); n != 0 || err != io.EOF {
+		t.Errorf("expected io.EOF, got %d bytes: %v", n, err)
+	}
+}
@@ -0,0 +1,115 @@
+package smb2
+
+import (
+	"os"
+	"time"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+)
+
+const defaultRetryBackoff = 100 * time.Millisecond
+
+// RetryPolicy contains options for retrying operations which fail with a transient error.
+//
+// Idempotent operations are retried automatically: opening an existing file (func (*Share) OpenFile without
+// os.O_CREATE and os.O_TRUNC), Stat, Lstat, IsDir, Readlink and Statfs of Share, and Read, ReadAt, ReadAtBuffer,
+// Stat and Statfs of File.
+// Operations which modify the share (creating files, Mkdir, Remove, Rename, Symlink, Truncate, Chtimes, SetFileTime,
+// Chmod, Write and WriteAt) may have taken effect on the server even though they failed,
+// so they are only retried if RetryNonIdempotent is set. Other operations are never retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. If it's zero, operations aren't retried.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry, which doubles after each retry up to MaxBackoff.
+	// If it's zero, 100ms is used. If MaxBackoff is zero, the delay isn't capped.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Retryable reports whether an operation failing with err may be retried.
+	// If it's nil, IsRetryable is used. It can call IsRetryable to extend the default classification.
+	Retryable func(err error) bool
+
+	// RetryNonIdempotent also retries the operations which modify the share.
+	RetryNonIdempotent bool
+}
+
+// IsRetryable reports whether err is a transient error, after which an operation may succeed if it's tried again.
+// These are the responses of a busy or temporarily unavailable server (e.g. STATUS_NETWORK_BUSY or
+// STATUS_INSUFF_SERVER_RESOURCES), possibly wrapped in *os.PathError or *os.LinkError.
+// Transport errors aren't retryable, since the connection can't be used anymore. Neither is
+// STATUS_NETWORK_NAME_DELETED, since the tree connect is gone and the share has to be mounted again.
+func IsRetryable(err error) bool {
+	for {
+		switch e := err.(type) {
+		case *os.PathError:
+			err = e.Err
+		case *os.LinkError:
+			err = e.Err
+		case *ResponseError:
+			switch NtStatus(e.Code) {
+			case STATUS_NETWORK_BUSY,
+				STATUS_INSUFF_SERVER_RESOURCES,
+				STATUS_INSUFFICIENT_RESOURCES,
+				STATUS_IO_TIMEOUT,
+				STATUS_REQUEST_NOT_ACCEPTED,
+				STATUS_SERVER_UNAVAILABLE:
+				return true
+			}
+			return false
+		default:
+			return false
+		}
+	}
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// backoff returns the delay before the retry following the given number of retries.
+func (p *RetryPolicy) backoff(retries int) time.Duration {
+	d := p.Backoff
+	if d <= 0 {
+		d = defaultRetryBackoff
+	}
+	for i := 0; i < retries; i++ {
+		if (p.MaxBackoff > 0 && d >= p.MaxBackoff) || d > 1<<62 {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// retry calls op until it succeeds or fails with an error which the retry policy of the session doesn't retry.
+// Non-idempotent operations are only retried if the policy allows it.
+// If the context of the share is done while waiting for a retry, the last error is returned.
+func (fs *Share) retry(idempotent bool, op func() error) error {
+	err := op()
+
+	p := fs.retryPolicy
+	if p == nil || (!idempotent && !p.RetryNonIdempotent) {
+		return err
+	}
+
+	for retries := 0; err != nil && retries < p.MaxRetries && p.retryable(err); retries++ {
+		t := time.NewTimer(p.backoff(retries))
+		select {
+		case <-t.C:
+		case <-fs.ctx.Done():
+			t.Stop()
+			return err
+		}
+
+		err = op()
+	}
+
+	return err
+}
@@ -0,0 +1,165 @@
+package smb2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+)
+
+func TestIsRetryable(t *testing.T) {
+	busy := &ResponseError{Code: uint32(STATUS_NETWORK_BUSY)}
+
+	tests := []struct {
+		err       error
+		retryable bool
+	}{
+		{busy, true},
+		{&ResponseError{Code: uint32(STATUS_NETWORK_NAME_DELETED)}, false},
+		{&ResponseError{Code: uint32(STATUS_INSUFF_SERVER_RESOURCES)}, true},
+		{&os.PathError{Op: "open", Path: "a", Err: busy}, true},
+		{&os.LinkError{Op: "rename", Old: "a", New: "b", Err: busy}, true},
+		{&ResponseError{Code: uint32(STATUS_OBJECT_NAME_NOT_FOUND)}, false},
+		{&os.PathError{Op: "open", Path: "a", Err: &ResponseError{Code: uint32(STATUS_ACCESS_DENIED)}}, false},
+		{&TransportError{errors.New("broken pipe")}, false},
+		{&ContextError{Err: context.Canceled}, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.retryable {
+			t.Errorf("%v: expected %v, got %v", tt.err, tt.retryable, got)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &RetryPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+
+	for i, want := range []time.Duration{10, 20, 40, 50, 50} {
+		if got := p.backoff(i); got != want*time.Millisecond {
+			t.Errorf("retry %d: expected %v, got %v", i, want*time.Millisecond, got)
+		}
+	}
+
+	if got := (&RetryPolicy{}).backoff(1); got != 2*defaultRetryBackoff {
+		t.Errorf("expected %v, got %v", 2*defaultRetryBackoff, got)
+	}
+	if got := (&RetryPolicy{}).backoff(100); got <= 0 {
+		t.Errorf("uncapped backoff overflowed: %v", got)
+	}
+}
+
+func newTestRetryShare(p *RetryPolicy) *Share {
+	s := &session{retryPolicy: p}
+	return &Share{treeConn: &treeConn{session: s}, ctx: context.Background()}
+}
+
+func TestRetry(t *testing.T) {
+	busy := &os.PathError{Op: "stat", Path: "a", Err: &ResponseError{Code: uint32(STATUS_NETWORK_BUSY)}}
+	notFound := &os.PathError{Op: "stat", Path: "a", Err: &ResponseError{Code: uint32(STATUS_OBJECT_NAME_NOT_FOUND)}}
+
+	tests := []struct {
+		name       string
+		policy     *RetryPolicy
+		idempotent bool
+		errs       []error // errors of the attempts, nil after them
+		calls      int
+		err        error
+	}{
+		{name: "no policy", idempotent: true, errs: []error{busy}, calls: 1, err: busy},
+		{name: "retried", policy: &RetryPolicy{MaxRetries: 3}, idempotent: true, errs: []error{busy, busy}, calls: 3},
+		{name: "exhausted", policy: &RetryPolicy{MaxRetries: 2}, idempotent: true, errs: []error{busy, busy, busy, busy}, calls: 3, err: busy},
+		{name: "not retryable", policy: &RetryPolicy{MaxRetries: 3}, idempotent: true, errs: []error{notFound}, calls: 1, err: notFound},
+		{name: "non-idempotent", policy: &RetryPolicy{MaxRetries: 3}, errs: []error{busy}, calls: 1, err: busy},
+		{name: "non-idempotent allowed", policy: &RetryPolicy{MaxRetries: 3, RetryNonIdempotent: true}, errs: []error{busy}, calls: 2},
+		{
+			name:       "extended",
+			policy:     &RetryPolicy{MaxRetries: 3, Retryable: func(err error) bool { return IsRetryable(err) || err == notFound }},
+			idempotent: true,
+			errs:       []error{notFound, busy},
+			calls:      3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.policy != nil {
+				tt.policy.Backoff = time.Millisecond
+			}
+			fs := newTestRetryShare(tt.policy)
+
+			calls := 0
+			err := fs.retry(tt.idempotent, func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if err != tt.err {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+			if calls != tt.calls {
+				t.Errorf("expected %d calls, got %d", tt.calls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryContextDone(t *testing.T) {
+	busy := &ResponseError{Code: uint32(STATUS_SERVER_UNAVAILABLE)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fs := newTestRetryShare(&RetryPolicy{MaxRetries: 3, Backoff: time.Hour}).WithContext(ctx)
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	calls := 0
+	err := fs.retry(true, func() error {
+		calls++
+		return busy
+	})
+	if err != busy {
+		t.Errorf("expected %v, got %v", busy, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestRetryRead(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10)
+
+	f, srv, tr := newFakeFile(data, len(data))
+	defer tr.Close()
+
+	srv.fail = 2
+	srv.failStatus = STATUS_INSUFF_SERVER_RESOURCES
+	f.fs.retryPolicy = &RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}
+
+	b := make([]byte, len(data))
+	n, err := f.ReadAt(b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(data) || !bytes.Equal(b, data) {
+		t.Errorf("unexpected data: %q", b[:n])
+	}
+
+	// without retries, the transient error is returned
+	f.fs.retryPolicy = &RetryPolicy{}
+
+	srv.m.Lock()
+	srv.reads = 0
+	srv.m.Unlock()
+
+	_, err = f.ReadAt(b, 0)
+	if !IsRetryable(err) {
+		t.Errorf("expected a retryable error, got %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package smb2
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+
+	"github.com/nodauf/go-smb2/internal/msrpc"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+const rpcMaxFragSize = 4280
+
+// rpcClient is a DCE/RPC client bound to an interface over a named pipe of IPC$.
+type rpcClient struct {
+	fs     *Share
+	f      *File
+	callId uint32
+
+	maxSendFrag int
+	maxRecvFrag int
+}
+
+// openRPC opens the named pipe on IPC$ and binds the interface iface (hex encoded UUID).
+func (c *Session) openRPC(pipe string, iface []byte, version, versionMinor uint16) (*rpcClient, error) {
+	fs, err := c.Mount(fmt.Sprintf(`\\%s\IPC$`, hostname(c.addr)))
+	if err != nil {
+		return nil, err
+	}
+
+	fs = fs.WithContext(c.ctx)
+
+	f, err := fs.OpenFile(pipe, os.O_RDWR, 0666)
+	if err != nil {
+		fs.Umount()
+		return nil, err
+	}
+
+	r := &rpcClient{
+		fs:          fs,
+		f:           f,
+		callId:      rand.Uint32(),
+		maxSendFrag: rpcMaxFragSize,
+		maxRecvFrag: rpcMaxFragSize,
+	}
+
+	if err := r.bind(iface, version, versionMinor); err != nil {
+		r.close()
+		return nil, &os.PathError{Op: "bind", Path: f.name, Err: err}
+	}
+
+	return r, nil
+}
+
+func (r *rpcClient) bind(iface []byte, version, versionMinor uint16) error {
+	req := &IoctlRequest{
+		CtlCode:           FSCTL_PIPE_TRANSCEIVE,
+		OutputOffset:      0,
+		OutputCount:       0,
+		MaxInputResponse:  0,
+		MaxOutputResponse: rpcMaxFragSize,
+		Flags:             SMB2_0_IOCTL_IS_FSCTL,
+		Input: &msrpc.Bind{
+			CallId:       r.callId,
+			InterfaceId:  iface,
+			Version:      version,
+			VersionMinor: versionMinor,
+		},
+	}
+
+	output, err := r.f.ioctl(req)
+	if err != nil {
+		return err
+	}
+
+	ack := msrpc.BindAckDecoder(output)
+	if ack.IsInvalid() || ack.CallId() != r.callId {
+		return &InvalidResponseError{"broken bind ack response format"}
+	}
+
+	result, ok := ack.Result()
+	if !ok {
+		return &InvalidResponseError{"broken bind ack response format"}
+	}
+	if result != 0 {
+		return ErrNotSupported
+	}
+
+	// max_xmit_frag and max_recv_frag are from the server's point of view
+	if n := int(ack.MaxRecvFrag()); n < r.maxSendFrag {
+		r.maxSendFrag = n
+	}
+	if n := int(ack.MaxXmitFrag()); n < r.maxRecvFrag {
+		r.maxRecvFrag = n
+	}
+	if r.maxSendFrag < 24+8 || r.maxRecvFrag < 24+8 {
+		return &InvalidResponseError{"broken bind ack response format"}
+	}
+
+	return nil
+}
+
+// call invokes the operation opnum with the marshaled stub and returns the stub of the response.
+// The request is split into fragments if necessary and the response fragments are reassembled.
+func (r *rpcClient) call(opnum uint16, stub []byte) ([]byte, error) {
+	r.callId++
+
+	maxStub := (r.maxSendFrag - 24) &^ 7
+	allocHint := uint32(len(stub))
+
+	flags := uint8(msrpc.RPC_PACKET_FLAG_FIRST)
+
+	for len(stub) > maxStub {
+		req := &msrpc.Request{
+			CallId:    r.callId,
+			Flags:     flags,
+			AllocHint: allocHint,
+			Opnum:     opnum,
+			Stub:      stub[:maxStub],
+		}
+
+		bs := make([]byte, req.Size())
+		req.Encode(bs)
+
+		_, err := r.f.writeAt(bs, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		stub = stub[maxStub:]
+		flags = 0
+	}
+
+	req := &IoctlRequest{
+		CtlCode:           FSCTL_PIPE_TRANSCEIVE,
+		OutputOffset:      0,
+		OutputCount:       0,
+		MaxInputResponse:  0,
+		MaxOutputResponse: uint32(r.maxRecvFrag),
+		Flags:             SMB2_0_IOCTL_IS_FSCTL,
+		Input: &msrpc.Request{
+			CallId:    r.callId,
+			Flags:     flags | msrpc.RPC_PACKET_FLAG_LAST,
+			AllocHint: allocHint,
+			Opnum:     opnum,
+			Stub:      stub,
+		},
+	}
+
+	output, err := r.f.ioctl(req)
+	if err != nil {
+		if rerr, ok := err.(*ResponseError); !ok || NtStatus(rerr.Code) != STATUS_BUFFER_OVERFLOW {
+			return nil, err
+		}
+	}
+
+	var res []byte
+
+	for {
+		p, err := r.recv(output)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, p.Stub()...)
+
+		if p.PacketFlags()&msrpc.RPC_PACKET_FLAG_LAST != 0 {
+			return res, nil
+		}
+
+		output = nil
+	}
+}
+
+// recv completes a response fragment which begins with buf.
+// If buf is empty, the next fragment is read from the pipe.
+func (r *rpcClient) recv(buf []byte) (msrpc.ResponseDecoder, error) {
+	if len(buf) == 0 {
+		buf = make([]byte, r.maxRecvFrag)
+
+		n, err := r.f.readPipe(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		buf = buf[:n]
+	}
+
+	p := msrpc.ResponseDecoder(buf)
+	if p.IsInvalid() || p.CallId() != r.callId {
+		return nil, &InvalidResponseError{"broken rpc response format"}
+	}
+
+	for p.IsIncomplete() {
+		rest := make([]byte, int(p.FragLength())-len(p))
+
+		n, err := r.f.readPipe(rest)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, &InvalidResponseError{"broken rpc response format"}
+		}
+
+		p = append(p, rest[:n]...)
+	}
+
+	if p.PacketType() == msrpc.RPC_TYPE_FAULT {
+		return nil, &RPCError{Op: "fault", Status: p.FaultStatus()}
+	}
+
+	return p, nil
+}
+
+func (r *rpcClient) close() error {
+	err := r.f.Close()
+	if uerr := r.fs.Umount(); err == nil {
+		err = uerr
+	}
+	return err
+}
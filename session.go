@@ -5,22 +5,52 @@ import (
 	"context"
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/hmac"
 	"crypto/rand"
-	"crypto/sha256"
 	"crypto/sha512"
 	"fmt"
 	"hash"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/nodauf/go-smb2/internal/crypto/ccm"
-	"github.com/nodauf/go-smb2/internal/crypto/cmac"
 
 	. "github.com/nodauf/go-smb2/internal/erref"
 	. "github.com/nodauf/go-smb2/internal/smb2"
 )
 
-func sessionSetup(conn *conn, i Initiator, ctx context.Context) (*session, error) {
+// sessionSetup authenticates on conn by i.
+// If bind isn't nil, conn is bound to the session bind as a new channel instead of setting up a new session.
+func sessionSetup(conn *conn, i Initiator, bind *session, ctx context.Context) (*session, error) {
+	return setupSession(conn, i, bind, nil, ctx)
+}
+
+// reauth re-authenticates the session by its initiator after the server has expired it,
+// unless it has been re-authenticated since the given epoch.
+// The keys of the session are kept by a re-authentication. (See [MS-SMB2] 3.2.5.3.1)
+func (s *session) reauth(epoch uint32, ctx context.Context) error {
+	s.reauthM.Lock()
+	defer s.reauthM.Unlock()
+
+	if atomic.LoadUint32(&s._epoch) != epoch {
+		return nil
+	}
+
+	if _, err := setupSession(s.conn, s.initiator, nil, s, ctx); err != nil {
+		return err
+	}
+
+	atomic.AddUint32(&s._epoch, 1)
+
+	return nil
+}
+
+// setupSession authenticates on conn by i, like sessionSetup.
+// If reauth isn't nil, the established session reauth on conn is re-authenticated instead.
+func setupSession(conn *conn, i Initiator, bind, reauth *session, ctx context.Context) (*session, error) {
 	spnego := newSpnegoClient([]Initiator{i})
+	spnego.onToken = conn.onAuthToken
 
 	outputToken, err := spnego.initSecContext()
 	if err != nil {
@@ -41,238 +71,389 @@ func sessionSetup(conn *conn, i Initiator, ctx context.Context) (*session, error
 		req.SecurityMode = SMB2_NEGOTIATE_SIGNING_ENABLED
 	}
 
-	req.CreditCharge = 1
 	req.CreditRequestResponse = conn.account.initRequest()
 
-	rr, err := conn.send(req, ctx)
-	if err != nil {
-		return nil, err
-	}
+	var s *session
 
-	pkt, err := conn.recv(rr)
-	if err != nil {
-		return nil, err
-	}
+	if bind != nil {
+		if err := bind.checkBinding(conn); err != nil {
+			return nil, err
+		}
 
-	p := PacketCodec(pkt)
+		req.Flags = SMB2_SESSION_FLAG_BINDING
 
-	if NtStatus(p.Status()) != STATUS_MORE_PROCESSING_REQUIRED {
-		return nil, &InvalidResponseError{fmt.Sprintf("expected status: %v, got %v", STATUS_MORE_PROCESSING_REQUIRED, NtStatus(p.Status()))}
-	}
+		signer, err := newSigner(conn.dialect, conn.signingId, bind.signingKey)
+		if err != nil {
+			return nil, &InternalError{err.Error()}
+		}
 
-	res, err := accept(SMB2_SESSION_SETUP, pkt)
-	if err != nil {
-		return nil, err
-	}
+		// the binding is signed by the key of the session, the channel has its own signing key only after that
+		s = &session{
+			conn:           conn,
+			treeConnTables: make(map[uint32]*treeConn),
+			sessionFlags:   bind.sessionFlags,
+			sessionId:      bind.sessionId,
+			signingKey:     bind.signingKey,
+			binding:        true,
+			signer:         signer,
+			encrypter:      bind.encrypter,
+			decrypter:      bind.decrypter,
+		}
 
-	r := SessionSetupResponseDecoder(res)
-	if r.IsInvalid() {
-		return nil, &InvalidResponseError{"broken session setup response format"}
+		conn.session = s
 	}
 
-	sessionFlags := r.SessionFlags()
-	if conn.requireSigning {
-		if sessionFlags&SMB2_SESSION_FLAG_IS_GUEST != 0 {
-			return nil, &InvalidResponseError{"guest account doesn't support signing"}
-		}
-		if sessionFlags&SMB2_SESSION_FLAG_IS_NULL != 0 {
-			return nil, &InvalidResponseError{"anonymous account doesn't support signing"}
-		}
+	if reauth != nil {
+		// the request is sent on the session, and signed by its key
+		s = reauth
 	}
 
-	s := &session{
-		conn:           conn,
-		treeConnTables: make(map[uint32]*treeConn),
-		sessionFlags:   sessionFlags,
-		sessionId:      p.SessionId(),
-	}
+	preauthIntegrityHashValue := conn.preauthIntegrityHashValue
 
-	switch conn.dialect {
-	case SMB311:
-		s.preauthIntegrityHashValue = conn.preauthIntegrityHashValue
+	// The authentication may take any number of legs (e.g. NTLM takes two, Kerberos may take more).
+	// Each leg but the last one is answered by STATUS_MORE_PROCESSING_REQUIRED,
+	// and the SMB 3.1.1 preauth integrity hash covers all of them, except the last response.
+	for {
+		var rr *requestResponse
 
-		switch conn.preauthIntegrityHashId {
-		case SHA512:
-			h := sha512.New()
-			h.Write(s.preauthIntegrityHashValue[:])
-			h.Write(rr.pkt)
-			h.Sum(s.preauthIntegrityHashValue[:0])
+		req.CreditCharge, _, err = conn.loanCredit(0, ctx)
+		if err != nil {
+			return nil, err
+		}
 
-			h.Reset()
-			h.Write(s.preauthIntegrityHashValue[:])
-			h.Write(pkt)
-			h.Sum(s.preauthIntegrityHashValue[:0])
+		if s == nil {
+			rr, err = conn.send(req, ctx)
+		} else {
+			rr, err = s.send(req, ctx)
+		}
+		if err != nil {
+			return nil, err
 		}
 
-	}
+		var pkt []byte
 
-	outputToken, err = spnego.acceptSecContext(r.SecurityBuffer())
-	if err != nil {
-		return nil, &InvalidResponseError{err.Error()}
-	}
+		if s == nil {
+			pkt, err = conn.recv(rr)
+		} else {
+			pkt, err = s.recv(rr)
+		}
+		if err != nil {
+			return s, err
+		}
 
-	req.SecurityBuffer = outputToken
+		if conn.dialect == SMB311 {
+			switch conn.preauthIntegrityHashId {
+			case SHA512:
+				h := sha512.New()
+				h.Write(preauthIntegrityHashValue[:])
+				h.Write(rr.pkt)
+				h.Sum(preauthIntegrityHashValue[:0])
+			}
+		}
 
-	req.CreditRequestResponse = 0
+		p := PacketCodec(pkt)
 
-	// We set session before sending packet just for setting hdr.SessionId.
-	// But, we should not permit access from receiver until the session information is completed.
-	conn.session = s
+		status := NtStatus(p.Status())
 
-	rr, err = s.send(req, ctx)
-	if err != nil {
-		return nil, err
-	}
+		res, err := accept(SMB2_SESSION_SETUP, pkt)
+		if err != nil {
+			return s, err
+		}
 
-	if s.sessionFlags&(SMB2_SESSION_FLAG_IS_GUEST|SMB2_SESSION_FLAG_IS_NULL) == 0 {
-		sessionKey := spnego.sessionKey()
+		r := SessionSetupResponseDecoder(res)
+		if r.IsInvalid() {
+			return nil, &InvalidResponseError{"broken session setup response format"}
+		}
 
-		switch conn.dialect {
-		case SMB202, SMB210:
-			s.signer = hmac.New(sha256.New, sessionKey)
-			s.verifier = hmac.New(sha256.New, sessionKey)
-		case SMB300, SMB302:
-			signingKey := kdf(sessionKey, []byte("SMB2AESCMAC\x00"), []byte("SmbSign\x00"))
-			ciph, err := aes.NewCipher(signingKey)
-			if err != nil {
-				return nil, &InternalError{err.Error()}
+		if s == nil {
+			s = &session{
+				conn:           conn,
+				treeConnTables: make(map[uint32]*treeConn),
+				sessionId:      p.SessionId(),
 			}
-			s.signer = cmac.New(ciph)
-			s.verifier = cmac.New(ciph)
 
-			// s.applicationKey = kdf(sessionKey, []byte("SMB2APP\x00"), []byte("SmbRpc\x00"))
+			// We set session before sending packet just for setting hdr.SessionId.
+			// But, we should not permit access from receiver until the session information is completed.
+			conn.session = s
 
-			encryptionKey := kdf(sessionKey, []byte("SMB2AESCCM\x00"), []byte("ServerIn \x00"))
-			decryptionKey := kdf(sessionKey, []byte("SMB2AESCCM\x00"), []byte("ServerOut\x00"))
+			req.CreditRequestResponse = 0
+		}
 
-			ciph, err = aes.NewCipher(encryptionKey)
-			if err != nil {
-				return nil, &InternalError{err.Error()}
-			}
-			s.encrypter, err = ccm.NewCCMWithNonceAndTagSizes(ciph, 11, 16)
-			if err != nil {
-				return nil, &InternalError{err.Error()}
-			}
+		if !s.binding && reauth == nil {
+			s.sessionFlags = r.SessionFlags()
+		}
 
-			ciph, err = aes.NewCipher(decryptionKey)
-			if err != nil {
-				return nil, &InternalError{err.Error()}
+		if status == STATUS_SUCCESS {
+			if reauth != nil {
+				if PacketCodec(pkt).Flags()&SMB2_FLAGS_SIGNED != 0 && !s.verify(pkt, nil) {
+					return s, ErrSignatureMismatch
+				}
+				return s, nil
 			}
-			s.decrypter, err = ccm.NewCCMWithNonceAndTagSizes(ciph, 11, 16)
-			if err != nil {
-				return nil, &InternalError{err.Error()}
+
+			// the last token, if any, completes the authentication (e.g. the mutual authentication of Kerberos)
+			if len(r.SecurityBuffer()) != 0 {
+				outputToken, err = spnego.acceptSecContext(r.SecurityBuffer())
+				if err != nil {
+					return nil, &InvalidResponseError{err.Error()}
+				}
+				if len(outputToken) != 0 {
+					return nil, &InvalidResponseError{"authentication isn't completed, but session setup succeeded"}
+				}
 			}
-		case SMB311:
+
+			s.preauthIntegrityHashValue = preauthIntegrityHashValue
+
+			return s, s.complete(spnego, pkt)
+		}
+
+		if conn.dialect == SMB311 {
 			switch conn.preauthIntegrityHashId {
 			case SHA512:
 				h := sha512.New()
-				h.Write(s.preauthIntegrityHashValue[:])
-				h.Write(rr.pkt)
-				h.Sum(s.preauthIntegrityHashValue[:0])
+				h.Write(preauthIntegrityHashValue[:])
+				h.Write(pkt)
+				h.Sum(preauthIntegrityHashValue[:0])
 			}
+		}
+
+		outputToken, err = spnego.acceptSecContext(r.SecurityBuffer())
+		if err != nil {
+			return nil, &InvalidResponseError{err.Error()}
+		}
+		if len(outputToken) == 0 {
+			return nil, &InvalidResponseError{"server requires more processing, but authentication has completed"}
+		}
+
+		req.SecurityBuffer = outputToken
+	}
+}
 
-			signingKey := kdf(sessionKey, []byte("SMBSigningKey\x00"), s.preauthIntegrityHashValue[:])
-			ciph, err := aes.NewCipher(signingKey)
-			if err != nil {
-				return nil, &InternalError{err.Error()}
+// complete derives the keys of the session authenticated by spnego,
+// verifies the last session setup response pkt and allows access from receiver.
+// A bound channel only derives its own signing key, the encryption keys are the ones of the session.
+func (s *session) complete(spnego *spnegoClient, pkt []byte) error {
+	conn := s.conn
+
+	if s.sessionFlags&(SMB2_SESSION_FLAG_IS_GUEST|SMB2_SESSION_FLAG_IS_NULL) != 0 {
+		if conn.requireSigning {
+			if s.sessionFlags&SMB2_SESSION_FLAG_IS_GUEST != 0 {
+				return &InvalidResponseError{"guest account doesn't support signing"}
 			}
-			s.signer = cmac.New(ciph)
-			s.verifier = cmac.New(ciph)
+			return &InvalidResponseError{"anonymous account doesn't support signing"}
+		}
+	} else {
+		keys := deriveSessionKeys(conn.dialect, spnego.sessionKey(), s.preauthIntegrityHashValue[:], conn.cipherId)
 
-			// s.applicationKey = kdf(sessionKey, []byte("SMBAppKey\x00"), preauthIntegrityHashValue)
+		var err error
+
+		s.signer, err = newSigner(conn.dialect, conn.signingId, keys.signingKey)
+		if err != nil {
+			return &InternalError{err.Error()}
+		}
+		s.verifier, err = newSigner(conn.dialect, conn.signingId, keys.signingKey)
+		if err != nil {
+			return &InternalError{err.Error()}
+		}
 
-			encryptionKey := kdf(sessionKey, []byte("SMBC2SCipherKey\x00"), s.preauthIntegrityHashValue[:])
-			decryptionKey := kdf(sessionKey, []byte("SMBS2CCipherKey\x00"), s.preauthIntegrityHashValue[:])
+		if !s.binding {
+			s.signingKey = keys.signingKey
 
-			switch s.cipherId {
-			case AES128CCM:
-				ciph, err := aes.NewCipher(encryptionKey)
+			switch conn.dialect {
+			case SMB300, SMB302:
+				ciph, err := aes.NewCipher(keys.encryptionKey)
 				if err != nil {
-					return nil, &InternalError{err.Error()}
+					return &InternalError{err.Error()}
 				}
 				s.encrypter, err = ccm.NewCCMWithNonceAndTagSizes(ciph, 11, 16)
 				if err != nil {
-					return nil, &InternalError{err.Error()}
+					return &InternalError{err.Error()}
 				}
 
-				ciph, err = aes.NewCipher(decryptionKey)
+				ciph, err = aes.NewCipher(keys.decryptionKey)
 				if err != nil {
-					return nil, &InternalError{err.Error()}
+					return &InternalError{err.Error()}
 				}
 				s.decrypter, err = ccm.NewCCMWithNonceAndTagSizes(ciph, 11, 16)
 				if err != nil {
-					return nil, &InternalError{err.Error()}
+					return &InternalError{err.Error()}
 				}
-			case AES128GCM:
-				ciph, err := aes.NewCipher(encryptionKey)
-				if err != nil {
-					return nil, &InternalError{err.Error()}
-				}
-				s.encrypter, err = cipher.NewGCMWithNonceSize(ciph, 12)
-				if err != nil {
-					return nil, &InternalError{err.Error()}
-				}
-
-				ciph, err = aes.NewCipher(decryptionKey)
-				if err != nil {
-					return nil, &InternalError{err.Error()}
-				}
-				s.decrypter, err = cipher.NewGCMWithNonceSize(ciph, 12)
-				if err != nil {
-					return nil, &InternalError{err.Error()}
+			case SMB311:
+				switch s.cipherId {
+				case AES128CCM, AES256CCM:
+					ciph, err := aes.NewCipher(keys.encryptionKey)
+					if err != nil {
+						return &InternalError{err.Error()}
+					}
+					s.encrypter, err = ccm.NewCCMWithNonceAndTagSizes(ciph, 11, 16)
+					if err != nil {
+						return &InternalError{err.Error()}
+					}
+
+					ciph, err = aes.NewCipher(keys.decryptionKey)
+					if err != nil {
+						return &InternalError{err.Error()}
+					}
+					s.decrypter, err = ccm.NewCCMWithNonceAndTagSizes(ciph, 11, 16)
+					if err != nil {
+						return &InternalError{err.Error()}
+					}
+				case AES128GCM, AES256GCM:
+					ciph, err := aes.NewCipher(keys.encryptionKey)
+					if err != nil {
+						return &InternalError{err.Error()}
+					}
+					s.encrypter, err = cipher.NewGCMWithNonceSize(ciph, 12)
+					if err != nil {
+						return &InternalError{err.Error()}
+					}
+
+					ciph, err = aes.NewCipher(keys.decryptionKey)
+					if err != nil {
+						return &InternalError{err.Error()}
+					}
+					s.decrypter, err = cipher.NewGCMWithNonceSize(ciph, 12)
+					if err != nil {
+						return &InternalError{err.Error()}
+					}
 				}
 			}
 		}
-	}
-
-	pkt, err = s.recv(rr)
-	if err != nil {
-		return s, err
-	}
 
-	res, err = accept(SMB2_SESSION_SETUP, pkt)
-	if err != nil {
-		return s, err
-	}
-
-	r = SessionSetupResponseDecoder(res)
-	if r.IsInvalid() {
-		return nil, &InvalidResponseError{"broken session setup response format"}
+		// the receiver doesn't verify session setup responses, since the keys are only known now
+		if PacketCodec(pkt).Flags()&SMB2_FLAGS_SIGNED != 0 && !s.verify(pkt, nil) {
+			return ErrSignatureMismatch
+		}
 	}
 
-	if NtStatus(PacketCodec(pkt).Status()) != STATUS_SUCCESS {
-		return s, &InvalidResponseError{"broken session setup response format"}
+	if s.sessionFlags&SMB2_SESSION_FLAG_ENCRYPT_DATA != 0 && s.encrypter == nil {
+		return ErrEncryptionRequired
 	}
 
-	s.sessionFlags = r.SessionFlags()
-
 	// now, allow access from receiver
 	s.enableSession()
 
-	return s, nil
+	return nil
+}
+
+// checkBinding returns an error if conn can't be bound to s as a new channel.
+func (s *session) checkBinding(conn *conn) error {
+	switch s.dialect {
+	case SMB300, SMB302, SMB311:
+	default:
+		return ErrNotSupported
+	}
+	if s.sessionFlags&(SMB2_SESSION_FLAG_IS_GUEST|SMB2_SESSION_FLAG_IS_NULL) != 0 {
+		return &InternalError{"guest and anonymous sessions can't be bound"}
+	}
+	if conn.capabilities&SMB2_GLOBAL_CAP_MULTI_CHANNEL == 0 {
+		return ErrNotSupported
+	}
+	if conn.dialect != s.dialect || conn.cipherId != s.cipherId || conn.signingId != s.signingId {
+		return &InvalidResponseError{"the new channel negotiated differently from the session"}
+	}
+	if conn.clientGuid != s.clientGuid {
+		return &InternalError{"the new channel must be negotiated with the client guid of the session"}
+	}
+	return nil
 }
 
 type session struct {
 	*conn
-	treeConnTables            map[uint32]*treeConn
+	treeConnTables            map[uint32]*treeConn // mounted trees by their ids (guarded by treeConnM)
+	treeConnM                 sync.Mutex
 	sessionFlags              uint16
 	sessionId                 uint64
 	preauthIntegrityHashValue [64]byte
 
-	signer    hash.Hash
+	signingKey []byte // Session.SigningKey, which signs the binding of new channels
+	binding    bool   // a channel bound to the session of another connection
+
+	signer    hash.Hash // by Channel.SigningKey on a bound channel
 	verifier  hash.Hash
 	encrypter cipher.AEAD
 	decrypter cipher.AEAD
 
+	readLimiter  *limiter // nil means unlimited
+	writeLimiter *limiter
+
+	retryPolicy *RetryPolicy // nil means no retries
+
+	maxOpenFiles int32 // Dialer.MaxOpenFiles, zero means unlimited
+	_openFiles   int32 // files opened and not closed yet (accessed atomically)
+
+	warnOnLeak bool // Dialer.WarnOnLeak
+
+	oplocks               map[FileId]*oplock // oplocks of the open files by their ids (guarded by oplockM)
+	earlyBreaks           map[FileId]uint8   // breaks of the files whose CREATE responses aren't handled yet (guarded by oplockM)
+	oplockM               sync.Mutex
+	oplockBreakAckTimeout time.Duration                  // Dialer.OplockBreakAckTimeout
+	onOplockBreak         func(name string, level uint8) // Dialer.OnOplockBreak
+
+	initiator Initiator  // re-authenticates the expired session if Dialer.AutoReauth is set, nil otherwise
+	reauthM   sync.Mutex // serializes the re-authentications
+	_epoch    uint32     // number of re-authentications (accessed atomically)
+
+	// caps of the chunk sizes by Dialer.MaxReadChunk and Dialer.MaxWriteChunk, zero means the negotiated max sizes
+	maxReadChunk  int
+	maxWriteChunk int
+
+	serverInfo ServerInfo
+
+	closeM   sync.Mutex
+	closed   chan struct{} // closed once the session is unusable, created on demand (guarded by closeM)
+	closeErr error         // reason of the close (guarded by closeM)
+
 	// applicationKey []byte
 }
 
+// validateNegotiate validates the negotiation on the IPC$ share of servername.
+// Only SMB 3.0 and 3.0.2 need it, SMB 3.1.1 protects the negotiation by the preauth integrity hash.
+// Guest and anonymous sessions can't sign the validation, so it's skipped for them.
+// If the validation fails, the session is logged off.
+func (s *session) validateNegotiate(servername string, ctx context.Context) error {
+	switch s.dialect {
+	case SMB300, SMB302:
+	default:
+		return nil
+	}
+
+	if s.sessionFlags&(SMB2_SESSION_FLAG_IS_GUEST|SMB2_SESSION_FLAG_IS_NULL) != 0 {
+		return nil
+	}
+
+	tc, err := treeConnect(s, fmt.Sprintf(`\\%s\IPC$`, servername), 0, ctx)
+	if err != nil {
+		return err
+	}
+
+	err = tc.validateNegotiate(ctx)
+	if err != nil {
+		// the server doesn't keep the session until the connection times out
+		s.logoff(ctx)
+
+		return err
+	}
+
+	return tc.disconnect(ctx)
+}
+
 func (s *session) logoff(ctx context.Context) error {
+	// the server disconnects the trees by the logoff anyway, a failure doesn't keep the session from logging off
+	for _, tc := range s.trees() {
+		tc.disconnect(ctx)
+	}
+
 	req := new(LogoffRequest)
 
-	req.CreditCharge = 1
+	var err error
 
-	_, err := s.sendRecv(SMB2_LOGOFF, req, ctx)
+	req.CreditCharge, _, err = s.loanCredit(0, ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.sendRecv(SMB2_LOGOFF, req, ctx)
 	if err != nil {
 		return err
 	}
@@ -283,6 +464,94 @@ func (s *session) logoff(ctx context.Context) error {
 	return nil
 }
 
+// closedChan returns the channel closed once the session is unusable.
+func (s *session) closedChan() <-chan struct{} {
+	s.closeM.Lock()
+	defer s.closeM.Unlock()
+
+	if s.closed == nil {
+		s.closed = make(chan struct{})
+	}
+	return s.closed
+}
+
+// closeReason returns the reason the session is unusable, or nil if it's still usable.
+func (s *session) closeReason() error {
+	s.closeM.Lock()
+	defer s.closeM.Unlock()
+
+	return s.closeErr
+}
+
+// close marks the session unusable for the reason err. Only the first reason is kept.
+func (s *session) close(err error) {
+	s.closeM.Lock()
+	defer s.closeM.Unlock()
+
+	if s.closeErr != nil {
+		return
+	}
+	s.closeErr = err
+
+	if s.closed == nil {
+		s.closed = make(chan struct{})
+	}
+	close(s.closed)
+}
+
+// acquireFile counts a file about to be opened,
+// or returns ErrTooManyOpenFiles if Dialer.MaxOpenFiles files are already open.
+// The count is released by releaseFile if the open fails or once the file is closed.
+func (s *session) acquireFile() error {
+	if n := atomic.AddInt32(&s._openFiles, 1); s.maxOpenFiles > 0 && n > s.maxOpenFiles {
+		atomic.AddInt32(&s._openFiles, -1)
+		return ErrTooManyOpenFiles
+	}
+	return nil
+}
+
+func (s *session) releaseFile() {
+	atomic.AddInt32(&s._openFiles, -1)
+}
+
+// addTree registers tc as mounted on the session.
+func (s *session) addTree(tc *treeConn) {
+	s.treeConnM.Lock()
+	s.treeConnTables[tc.treeId] = tc
+	s.treeConnM.Unlock()
+}
+
+// removeTree unregisters tc once it's disconnected.
+func (s *session) removeTree(tc *treeConn) {
+	s.treeConnM.Lock()
+	if s.treeConnTables[tc.treeId] == tc {
+		delete(s.treeConnTables, tc.treeId)
+	}
+	s.treeConnM.Unlock()
+}
+
+// lookupTree returns the mounted tree of the id, or nil.
+func (s *session) lookupTree(treeId uint32) *treeConn {
+	s.treeConnM.Lock()
+	defer s.treeConnM.Unlock()
+
+	return s.treeConnTables[treeId]
+}
+
+// trees returns the mounted trees in the order of their ids.
+func (s *session) trees() []*treeConn {
+	s.treeConnM.Lock()
+	tcs := make([]*treeConn, 0, len(s.treeConnTables))
+	for _, tc := range s.treeConnTables {
+		tcs = append(tcs, tc)
+	}
+	s.treeConnM.Unlock()
+
+	sort.Slice(tcs, func(i, j int) bool { return tcs[i].treeId < tcs[j].treeId })
+
+	return tcs
+}
+
 func (s *session) sendRecv(cmd uint16, req Packet, ctx context.Context) (res []byte, err error) {
 	rr, err := s.send(req, ctx)
 	if err != nil {
@@ -297,6 +566,40 @@ func (s *session) sendRecv(cmd uint16, req Packet, ctx context.Context) (res []b
 	return accept(cmd, pkt)
 }
 
+// expired reports whether pkt is the response of an expired session to the request of rr, which is sent again by resend.
+func (s *session) expired(rr *requestResponse, pkt []byte) bool {
+	return rr.req != nil && NtStatus(PacketCodec(pkt).Status()) == STATUS_NETWORK_SESSION_EXPIRED
+}
+
+// resend re-authenticates the expired session, and sends the request of rr again.
+// The request isn't sent a third time if the session has expired again.
+func (s *session) resend(rr *requestResponse) (*requestResponse, error) {
+	if err := s.reauth(rr.epoch, rr.ctx); err != nil {
+		return nil, err
+	}
+
+	// the credits charged by the request have been consumed by the response
+	creditCharge := rr.req.Header().CreditCharge
+
+	var loaned uint16
+	for loaned < creditCharge {
+		n, _, err := s.account.loan(creditCharge-loaned, rr.ctx)
+		if err != nil {
+			s.account.refund(loaned)
+			return nil, err
+		}
+		loaned += n
+	}
+
+	rr2, err := s.sendWith(rr.req, rr.tc, rr.ctx)
+	if err != nil {
+		return nil, err
+	}
+	rr2.req = nil
+
+	return rr2, nil
+}
+
 func (s *session) recv(rr *requestResponse) (pkt []byte, err error) {
 	pkt, err = s.conn.recv(rr)
 	if err != nil {
@@ -308,6 +611,24 @@ func (s *session) recv(rr *requestResponse) (pkt []byte, err error) {
 	return pkt, err
 }
 
+// address sets the session and the tree, if tc isn't nil, of the request of hdr.
+func (s *session) address(hdr *PacketHeader, tc *treeConn) {
+	hdr.SessionId = s.sessionId
+	if tc != nil {
+		hdr.TreeId = tc.treeId
+	}
+}
+
+// protection tells how a request on tc is protected: encrypted if the session or the share requires it,
+// signed otherwise unless the session is a guest or anonymous one.
+func (s *session) protection(tc *treeConn) (encrypt, sign bool, err error) {
+	encrypt = s.sessionFlags&SMB2_SESSION_FLAG_ENCRYPT_DATA != 0 || (tc != nil && tc.shareFlags&SMB2_SHAREFLAG_ENCRYPT_DATA != 0)
+	if encrypt && s.encrypter == nil {
+		return false, false, ErrEncryptionRequired
+	}
+	return encrypt, !encrypt && s.sessionFlags&(SMB2_SESSION_FLAG_IS_GUEST|SMB2_SESSION_FLAG_IS_NULL) == 0, nil
+}
+
 func (s *session) sign(pkt []byte) []byte {
 	p := PacketCodec(pkt)
 
@@ -324,7 +645,8 @@ func (s *session) sign(pkt []byte) []byte {
 	return pkt
 }
 
-func (s *session) verify(pkt []byte) (ok bool) {
+// verify checks the signature of pkt followed by payload, which is usually empty.
+func (s *session) verify(pkt, payload []byte) (ok bool) {
 	p := PacketCodec(pkt)
 
 	signature := append([]byte{}, p.Signature()...)
@@ -336,6 +658,7 @@ func (s *session) verify(pkt []byte) (ok bool) {
 	h.Reset()
 
 	h.Write(pkt)
+	h.Write(payload)
 
 	p.SetSignature(h.Sum(nil))
 
@@ -350,7 +673,7 @@ func (s *session) encrypt(pkt []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	c := make([]byte, 52+len(pkt)+16)
+	c := getBuffer(52 + len(pkt) + 16)
 
 	t := TransformCodec(c)
 
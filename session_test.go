@@ -0,0 +1,888 @@
+package smb2
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nodauf/go-smb2/internal/spnego"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+var fakeMechOid = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}
+
+// fakeInitiator authenticates in legs legs. It sends "client-<i>" on the i-th leg
+// and expects "server-<i>" from the server after it.
+type fakeInitiator struct {
+	legs int
+
+	m        sync.Mutex
+	received []string
+}
+
+func (i *fakeInitiator) oid() asn1.ObjectIdentifier {
+	return fakeMechOid
+}
+
+func (i *fakeInitiator) initSecContext() ([]byte, error) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.received = nil
+
+	return []byte("client-0"), nil
+}
+
+func (i *fakeInitiator) acceptSecContext(sc []byte) ([]byte, error) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	leg := len(i.received)
+	if want := fmt.Sprintf("server-%d", leg); string(sc) != want {
+		return nil, fmt.Errorf("expected %q, got %q", want, sc)
+	}
+
+	i.received = append(i.received, string(sc))
+
+	if leg+1 >= i.legs {
+		return nil, nil
+	}
+
+	return []byte(fmt.Sprintf("client-%d", leg+1)), nil
+}
+
+func (i *fakeInitiator) sum(bs []byte) []byte {
+	return nil
+}
+
+func (i *fakeInitiator) sessionKey() []byte {
+	return bytes.Repeat([]byte{0x42}, 16)
+}
+
+// fakeAuthServer answers session setup requests as a server needing legs legs.
+// The last response carries the last server token and is signed with the session key of fakeInitiator,
+// as the dialect requires (SMB 2.1 if it's zero).
+type fakeAuthServer struct {
+	tr           *fakeTransport
+	legs         int
+	dialect      uint16
+	sessionFlags uint16 // flags of the last response
+
+	m      sync.Mutex
+	reqs   [][]byte
+	tokens []string
+	err    error
+	paths  []string // paths of the TREE_CONNECT requests served by serveFakeDial
+}
+
+func (srv *fakeAuthServer) handle(req []byte) {
+	srv.m.Lock()
+	defer srv.m.Unlock()
+
+	srv.reqs = append(srv.reqs, req)
+
+	q := PacketCodec(req)
+
+	buf := SessionSetupRequestDecoder(q.Data()).SecurityBuffer()
+
+	var token []byte
+	if len(srv.tokens) == 0 {
+		init, err := spnego.DecodeNegTokenInit(buf)
+		if err != nil {
+			srv.err = err
+			return
+		}
+		token = init.MechToken
+	} else {
+		resp, err := spnego.DecodeNegTokenResp(buf)
+		if err != nil {
+			srv.err = err
+			return
+		}
+		token = resp.ResponseToken
+	}
+
+	leg := len(srv.tokens)
+	srv.tokens = append(srv.tokens, string(token))
+
+	status := STATUS_MORE_PROCESSING_REQUIRED
+	state := asn1.Enumerated(1) // accept-incomplete
+	if leg+1 == srv.legs {
+		status = STATUS_SUCCESS
+		state = 0 // accept-completed
+	}
+
+	var supportedMech asn1.ObjectIdentifier
+	if leg == 0 {
+		supportedMech = fakeMechOid
+	}
+
+	sb, err := spnego.EncodeNegTokenResp(state, supportedMech, []byte(fmt.Sprintf("server-%d", leg)), nil)
+	if err != nil {
+		srv.err = err
+		return
+	}
+
+	res := &SessionSetupResponse{
+		PacketHeader: PacketHeader{
+			CreditRequestResponse: 1,
+			Status:                uint32(status),
+			Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+			MessageId:             q.MessageId(),
+			SessionId:             5,
+		},
+		SecurityBuffer: sb,
+	}
+	if status == STATUS_SUCCESS {
+		res.SessionFlags = srv.sessionFlags
+	}
+
+	pkt := make([]byte, res.Size())
+	res.Encode(pkt)
+
+	if status == STATUS_SUCCESS {
+		dialect := srv.dialect
+		if dialect == UnknownSMB {
+			dialect = SMB210
+		}
+		signer, err := newSigner(dialect, AES_CMAC, deriveSessionKeys(dialect, (&fakeInitiator{}).sessionKey(), nil, 0).signingKey)
+		if err != nil {
+			srv.err = err
+			return
+		}
+		s := &session{signer: signer}
+		s.sign(pkt)
+	}
+
+	srv.tr.push(pkt)
+}
+
+func TestSessionSetupLegs(t *testing.T) {
+	for _, legs := range []int{1, 2, 3, 5} {
+		t.Run(fmt.Sprint(legs), func(t *testing.T) {
+			tr := newFakeTransport()
+			defer tr.Close()
+
+			srv := &fakeAuthServer{tr: tr, legs: legs}
+			tr.handler = srv.handle
+
+			conn := newFakeConn(tr, clientMaxCreditBalance)
+
+			i := &fakeInitiator{legs: legs}
+
+			s, err := sessionSetup(conn, i, nil, context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if srv.err != nil {
+				t.Fatal(srv.err)
+			}
+
+			if s.sessionId != 5 {
+				t.Errorf("expected session id 5, got %d", s.sessionId)
+			}
+			if !conn.useSession() {
+				t.Error("session isn't enabled")
+			}
+			if s.signer == nil {
+				t.Error("session isn't signed")
+			}
+
+			if len(srv.tokens) != legs {
+				t.Fatalf("expected %d legs, got %d", legs, len(srv.tokens))
+			}
+			for leg, token := range srv.tokens {
+				if want := fmt.Sprintf("client-%d", leg); token != want {
+					t.Errorf("leg %d: expected %q, got %q", leg, want, token)
+				}
+			}
+			if len(i.received) != legs {
+				t.Errorf("expected %d server tokens, got %d", legs, len(i.received))
+			}
+		})
+	}
+}
+
+func TestSessionSetupAuthTokens(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeAuthServer{tr: tr, legs: 3}
+	tr.handler = srv.handle
+
+	conn := newFakeConn(tr, clientMaxCreditBalance)
+
+	var steps []string
+	conn.onAuthToken = func(step string, token []byte) {
+		steps = append(steps, step+":"+string(token))
+	}
+
+	if _, err := sessionSetup(conn, &fakeInitiator{legs: 3}, nil, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"negotiate:client-0",
+		"challenge:server-0",
+		"authenticate:client-1",
+		"challenge:server-1",
+		"authenticate:client-2",
+		"challenge:server-2",
+	}
+	if !reflect.DeepEqual(steps, expected) {
+		t.Errorf("expected %q, got %q", expected, steps)
+	}
+}
+
+func TestSessionSetupIncomplete(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	// the server asks for a third leg, but the initiator is done after two
+	srv := &fakeAuthServer{tr: tr, legs: 3}
+	tr.handler = srv.handle
+
+	conn := newFakeConn(tr, clientMaxCreditBalance)
+
+	_, err := sessionSetup(conn, &fakeInitiator{legs: 2}, nil, context.Background())
+	if _, ok := err.(*InvalidResponseError); !ok {
+		t.Fatalf("expected *InvalidResponseError, got %v", err)
+	}
+}
+
+// newTestBindConns returns the connection of a session to bind and a new connection over tr, both negotiated by SMB 3.0.2.
+func newTestBindConns(tr transport) (*session, *conn) {
+	conn1 := newFakeConn(newFakeTransport(), clientMaxCreditBalance)
+	conn1.dialect = SMB302
+	conn1.capabilities |= SMB2_GLOBAL_CAP_MULTI_CHANNEL
+	conn1.clientGuid = [16]byte{1, 2, 3}
+
+	bind := &session{
+		conn:       conn1,
+		sessionId:  5,
+		signingKey: bytes.Repeat([]byte{0x11}, 16),
+	}
+
+	conn2 := newFakeConn(tr, clientMaxCreditBalance)
+	conn2.dialect = SMB302
+	conn2.capabilities |= SMB2_GLOBAL_CAP_MULTI_CHANNEL
+	conn2.clientGuid = [16]byte{1, 2, 3}
+
+	return bind, conn2
+}
+
+func TestSessionBinding(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeAuthServer{tr: tr, legs: 2, dialect: SMB302}
+	tr.handler = srv.handle
+
+	bind, conn := newTestBindConns(tr)
+	defer bind.t.Close()
+
+	s, err := sessionSetup(conn, &fakeInitiator{legs: 2}, bind, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv.err != nil {
+		t.Fatal(srv.err)
+	}
+
+	if s.sessionId != bind.sessionId {
+		t.Errorf("expected session id %d, got %d", bind.sessionId, s.sessionId)
+	}
+	if !bytes.Equal(s.signingKey, bind.signingKey) {
+		t.Error("the channel must keep the signing key of the session")
+	}
+
+	// the binding requests are signed by the key of the session
+	verifier, err := newSigner(SMB302, AES_CMAC, bind.signingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &session{verifier: verifier}
+
+	if len(srv.reqs) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(srv.reqs))
+	}
+	for i, req := range srv.reqs {
+		p := PacketCodec(req)
+		if p.SessionId() != bind.sessionId {
+			t.Errorf("request %d: expected session id %d, got %d", i, bind.sessionId, p.SessionId())
+		}
+		if SessionSetupRequestDecoder(p.Data()).Flags()&SMB2_SESSION_FLAG_BINDING == 0 {
+			t.Errorf("request %d: binding flag isn't set", i)
+		}
+		if p.Flags()&SMB2_FLAGS_SIGNED == 0 || !v.verify(req, nil) {
+			t.Errorf("request %d: isn't signed by the session key", i)
+		}
+	}
+
+	// the channel signs by its own key, derived from the new authentication
+	channelKey := deriveSessionKeys(SMB302, (&fakeInitiator{}).sessionKey(), nil, 0).signingKey
+	verifier, err = newSigner(SMB302, AES_CMAC, channelKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v = &session{verifier: verifier}
+
+	if !v.verify(newTestResponse(s, 1, false), nil) {
+		t.Error("the channel doesn't sign by the channel signing key")
+	}
+}
+
+func TestSessionBindingNotSupported(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(bind *session, conn *conn)
+		err   func(err error) bool
+	}{
+		{
+			name:  "dialect",
+			setup: func(bind *session, conn *conn) { bind.dialect = SMB210; conn.dialect = SMB210 },
+			err:   func(err error) bool { return err == ErrNotSupported },
+		},
+		{
+			name:  "multichannel",
+			setup: func(bind *session, conn *conn) { conn.capabilities &^= SMB2_GLOBAL_CAP_MULTI_CHANNEL },
+			err:   func(err error) bool { return err == ErrNotSupported },
+		},
+		{
+			name:  "guest",
+			setup: func(bind *session, conn *conn) { bind.sessionFlags = SMB2_SESSION_FLAG_IS_GUEST },
+			err:   func(err error) bool { _, ok := err.(*InternalError); return ok },
+		},
+		{
+			name:  "client guid",
+			setup: func(bind *session, conn *conn) { conn.clientGuid[0] = 0 },
+			err:   func(err error) bool { _, ok := err.(*InternalError); return ok },
+		},
+		{
+			name:  "dialect mismatch",
+			setup: func(bind *session, conn *conn) { conn.dialect = SMB300 },
+			err:   func(err error) bool { _, ok := err.(*InvalidResponseError); return ok },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newFakeTransport()
+			defer tr.Close()
+
+			bind, conn := newTestBindConns(tr)
+			defer bind.t.Close()
+
+			tt.setup(bind, conn)
+
+			_, err := sessionSetup(conn, &fakeInitiator{legs: 2}, bind, context.Background())
+			if !tt.err(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSessionReauth(t *testing.T) {
+	for _, autoReauth := range []bool{false, true} {
+		t.Run(fmt.Sprint(autoReauth), func(t *testing.T) {
+			tr := newFakeTransport()
+			defer tr.Close()
+
+			srv := &fakeAuthServer{tr: tr, legs: 2}
+			tr.handler = srv.handle
+
+			conn := newFakeConn(tr, clientMaxCreditBalance)
+
+			i := &fakeInitiator{legs: 2}
+
+			s, err := sessionSetup(conn, i, nil, context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if autoReauth {
+				s.initiator = i
+			}
+
+			tc := &treeConn{session: s, treeId: 1}
+			s.addTree(tc)
+
+			srv.tokens = nil
+
+			var cmds []uint16
+			var unsigned int
+
+			expired := true
+
+			tr.handler = func(pkt []byte) {
+				q := PacketCodec(pkt)
+
+				cmds = append(cmds, q.Command())
+				if q.Flags()&SMB2_FLAGS_SIGNED == 0 {
+					unsigned++
+				}
+
+				switch {
+				case q.Command() == SMB2_SESSION_SETUP:
+					srv.handle(pkt)
+				case expired:
+					expired = false
+
+					res := &ErrorResponse{
+						PacketHeader: PacketHeader{
+							Command:               q.Command(),
+							CreditRequestResponse: q.CreditCharge(),
+							Status:                uint32(STATUS_NETWORK_SESSION_EXPIRED),
+							Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+							MessageId:             q.MessageId(),
+							TreeId:                q.TreeId(),
+							SessionId:             q.SessionId(),
+						},
+					}
+					buf := make([]byte, res.Size())
+					res.Encode(buf)
+					tr.push(buf)
+				default:
+					tr.push(newFakeResponse(pkt, q.CreditCharge()))
+				}
+			}
+
+			req := &FlushRequest{FileId: &FileId{}}
+			req.CreditCharge = 1
+
+			_, err = tc.sendRecv(SMB2_FLUSH, req, context.Background())
+
+			if !autoReauth {
+				if rerr, ok := err.(*ResponseError); !ok || NtStatus(rerr.Code) != STATUS_NETWORK_SESSION_EXPIRED {
+					t.Fatalf("expected STATUS_NETWORK_SESSION_EXPIRED, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if srv.err != nil {
+				t.Fatal(srv.err)
+			}
+
+			expected := []uint16{SMB2_FLUSH, SMB2_SESSION_SETUP, SMB2_SESSION_SETUP, SMB2_FLUSH}
+			if !reflect.DeepEqual(cmds, expected) {
+				t.Errorf("expected commands %v, got %v", expected, cmds)
+			}
+			if unsigned != 0 {
+				t.Errorf("expected the requests of the session to be signed, got %d unsigned ones", unsigned)
+			}
+			if epoch := atomic.LoadUint32(&s._epoch); epoch != 1 {
+				t.Errorf("expected a single re-authentication, got %d", epoch)
+			}
+		})
+	}
+}
+
+// serveFakeDial answers the negotiate request on c by SMB 2.1, and the session setup requests by srv.
+func serveFakeDial(c net.Conn, srv *fakeAuthServer) {
+	t := direct(c)
+
+	for {
+		n, err := t.ReadSize()
+		if err != nil {
+			return
+		}
+		pkt := make([]byte, n)
+		if _, err := t.Read(pkt); err != nil {
+			return
+		}
+
+		q := PacketCodec(pkt)
+
+		switch q.Command() {
+		case SMB2_NEGOTIATE:
+			res := &NegotiateResponse{
+				PacketHeader: PacketHeader{
+					Command:               SMB2_NEGOTIATE,
+					CreditRequestResponse: 1,
+					Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+					MessageId:             q.MessageId(),
+				},
+				SecurityMode:    SMB2_NEGOTIATE_SIGNING_ENABLED,
+				DialectRevision: SMB210,
+				MaxTransactSize: 64 * 1024,
+				MaxReadSize:     64 * 1024,
+				MaxWriteSize:    64 * 1024,
+				SystemTime:      &Filetime{},
+				ServerStartTime: &Filetime{},
+			}
+			buf := make([]byte, res.Size())
+			res.Encode(buf)
+			PacketCodec(buf).SetCommand(SMB2_NEGOTIATE)
+			t.Write(buf)
+		case SMB2_SESSION_SETUP:
+			srv.handle(pkt)
+			t.Write(<-srv.tr.in)
+		case SMB2_TREE_CONNECT:
+			srv.m.Lock()
+			srv.paths = append(srv.paths, TreeConnectRequestDecoder(q.Data()).Path())
+			srv.m.Unlock()
+
+			res := &TreeConnectResponse{
+				PacketHeader: PacketHeader{
+					CreditRequestResponse: 1,
+					Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+					MessageId:             q.MessageId(),
+					SessionId:             q.SessionId(),
+					TreeId:                1,
+				},
+				ShareType: SMB2_SHARE_TYPE_DISK,
+			}
+			buf := make([]byte, res.Size())
+			res.Encode(buf)
+			PacketCodec(buf).SetCommand(SMB2_TREE_CONNECT)
+			t.Write(buf)
+		default:
+			t.Write(newFakeResponse(pkt, 1))
+		}
+	}
+}
+
+func TestDialRejectGuest(t *testing.T) {
+	tests := []struct {
+		sessionFlags uint16
+		rejectGuest  bool
+		err          error
+	}{
+		{0, true, nil},
+		{SMB2_SESSION_FLAG_IS_GUEST, false, nil},
+		{SMB2_SESSION_FLAG_IS_GUEST, true, ErrGuestSession},
+		{SMB2_SESSION_FLAG_IS_NULL, true, ErrGuestSession},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.sessionFlags, tt.rejectGuest), func(t *testing.T) {
+			c1, c2 := net.Pipe()
+			defer c1.Close()
+			defer c2.Close()
+
+			srv := &fakeAuthServer{tr: newFakeTransport(), legs: 1, sessionFlags: tt.sessionFlags}
+
+			go serveFakeDial(c2, srv)
+
+			d := &Dialer{
+				Initiator:   &fakeInitiator{legs: 1},
+				RejectGuest: tt.rejectGuest,
+			}
+
+			c, err := d.Dial(c1)
+			if err != tt.err {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+			if srv.err != nil {
+				t.Fatal(srv.err)
+			}
+			if err != nil {
+				if c != nil {
+					t.Error("expected no session")
+				}
+				return
+			}
+
+			if c.IsGuest() != (tt.sessionFlags&SMB2_SESSION_FLAG_IS_GUEST != 0) {
+				t.Errorf("unexpected IsGuest %v", c.IsGuest())
+			}
+			if c.IsAnonymous() {
+				t.Error("unexpected IsAnonymous")
+			}
+		})
+	}
+}
+
+func TestPing(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	var status NtStatus
+
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+		if q.Command() != SMB2_ECHO || EchoRequestDecoder(q.Data()).IsInvalid() {
+			t.Errorf("unexpected request %d", q.Command())
+		}
+		hdr := PacketHeader{
+			CreditRequestResponse: 1,
+			Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+			MessageId:             q.MessageId(),
+			SessionId:             q.SessionId(),
+			Status:                uint32(status),
+		}
+		var res Packet = &EchoResponse{PacketHeader: hdr}
+		if status != STATUS_SUCCESS {
+			hdr.Command = SMB2_ECHO
+			res = &ErrorResponse{PacketHeader: hdr}
+		}
+		pkt := make([]byte, res.Size())
+		res.Encode(pkt)
+		tr.push(pkt)
+	}
+
+	fs := newFakeShare(tr)
+	c := &Session{s: fs.session, ctx: context.Background()}
+
+	if err := c.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	status = STATUS_USER_SESSION_DELETED
+	if err := c.Ping(context.Background()); err == nil {
+		t.Error("expected an error for a deleted session")
+	}
+
+	if granted, _ := c.Credits(); granted != 1 {
+		t.Errorf("expected the credit to be granted back, got %d", granted)
+	}
+}
+
+func TestSessionDeleted(t *testing.T) {
+	t.Run("unsigned", func(t *testing.T) { testSessionDeleted(t, false) })
+
+	// the server can't sign the responses of the deleted session, even if signing is required
+	t.Run("signed", func(t *testing.T) { testSessionDeleted(t, true) })
+}
+
+func testSessionDeleted(t *testing.T, signed bool) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	var fs *Share
+	var deleted int32
+
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+		if q.Command() == SMB2_READ {
+			// kept outstanding, until the session is deleted
+			return
+		}
+		hdr := PacketHeader{
+			Command:               q.Command(),
+			CreditRequestResponse: 1,
+			Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+			MessageId:             q.MessageId(),
+			SessionId:             q.SessionId(),
+		}
+		var res Packet = &EchoResponse{PacketHeader: hdr}
+		isDeleted := atomic.LoadInt32(&deleted) != 0
+		if isDeleted {
+			hdr.Status = uint32(STATUS_USER_SESSION_DELETED)
+			res = &ErrorResponse{PacketHeader: hdr}
+		}
+		pkt := make([]byte, res.Size())
+		res.Encode(pkt)
+		if signed && !isDeleted {
+			fs.session.sign(pkt)
+		}
+		tr.push(pkt)
+	}
+
+	fs = newFakeShare(tr)
+	fs.conn.account.charge(2, 0)
+	if signed {
+		signingKey := make([]byte, 16)
+
+		fs.session.sessionFlags = 0
+		fs.session.signer = hmac.New(sha256.New, signingKey)
+		fs.session.verifier = hmac.New(sha256.New, signingKey)
+		fs.conn.requireSigning = true
+	}
+	c := &Session{s: fs.session, ctx: context.Background()}
+
+	if err := c.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-c.Closed():
+		t.Fatal("the session is closed")
+	default:
+	}
+	if err := c.Err(); err != nil {
+		t.Errorf("unexpected reason %v", err)
+	}
+
+	f := &File{fs: fs, fd: &FileId{}, name: "file"}
+
+	readErr := make(chan error, 1)
+	go func() {
+		_, err := f.ReadAt(make([]byte, 16), 0)
+		readErr <- err
+	}()
+
+	// the read is sent before the session is deleted
+	for {
+		fs.conn.outstandingRequests.m.Lock()
+		n := len(fs.conn.outstandingRequests.requests)
+		fs.conn.outstandingRequests.m.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	atomic.StoreInt32(&deleted, 1)
+	if err := c.Ping(context.Background()); err == nil {
+		t.Error("expected an error for a deleted session")
+	}
+
+	select {
+	case <-c.Closed():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the session isn't closed")
+	}
+	if err := c.Err(); err != ErrSessionDeleted {
+		t.Errorf("expected %v, got %v", ErrSessionDeleted, err)
+	}
+
+	// the outstanding and the following requests fail right away
+	select {
+	case err := <-readErr:
+		if perr, ok := err.(*os.PathError); !ok || perr.Err != ErrSessionDeleted {
+			t.Errorf("expected %v for the outstanding read, got %v", ErrSessionDeleted, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the outstanding read doesn't fail")
+	}
+	if err := c.Ping(context.Background()); err != ErrSessionDeleted {
+		t.Errorf("expected %v, got %v", ErrSessionDeleted, err)
+	}
+}
+
+func TestSessionDeletedBadSignature(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	var fs *Share
+	var forged int32
+
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+		hdr := PacketHeader{
+			Command:               q.Command(),
+			CreditRequestResponse: 1,
+			Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+			MessageId:             q.MessageId(),
+			SessionId:             q.SessionId(),
+		}
+		var res Packet = &EchoResponse{PacketHeader: hdr}
+		isForged := atomic.LoadInt32(&forged) != 0
+		if isForged {
+			hdr.Status = uint32(STATUS_USER_SESSION_DELETED)
+			res = &ErrorResponse{PacketHeader: hdr}
+		}
+		pkt := make([]byte, res.Size())
+		res.Encode(pkt)
+		fs.session.sign(pkt)
+		if isForged {
+			pkt[len(pkt)-1] ^= 0xff
+		}
+		tr.push(pkt)
+	}
+
+	fs = newFakeShare(tr)
+	fs.conn.account.charge(2, 0)
+
+	signingKey := make([]byte, 16)
+
+	fs.session.sessionFlags = 0
+	fs.session.signer = hmac.New(sha256.New, signingKey)
+	fs.session.verifier = hmac.New(sha256.New, signingKey)
+	fs.conn.requireSigning = true
+
+	c := &Session{s: fs.session, ctx: context.Background()}
+
+	// a signed STATUS_USER_SESSION_DELETED response whose signature is broken is dropped like any other
+	atomic.StoreInt32(&forged, 1)
+	if err := c.Ping(context.Background()); err != ErrSignatureMismatch {
+		t.Errorf("expected %v, got %v", ErrSignatureMismatch, err)
+	}
+
+	select {
+	case <-c.Closed():
+		t.Fatal("the session is closed by an unverified response")
+	default:
+	}
+
+	atomic.StoreInt32(&forged, 0)
+	if err := c.Ping(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOnMessage(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+		res := &EchoResponse{
+			PacketHeader: PacketHeader{
+				CreditRequestResponse: 3,
+				Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+				MessageId:             q.MessageId(),
+				SessionId:             q.SessionId(),
+			},
+		}
+		pkt := make([]byte, res.Size())
+		res.Encode(pkt)
+		tr.push(pkt)
+	}
+
+	fs := newFakeShare(tr)
+	c := &Session{s: fs.session, ctx: context.Background()}
+
+	var m sync.Mutex
+	var msgs []Message
+	fs.conn.onMessage = func(msg Message) {
+		m.Lock()
+		msgs = append(msgs, msg)
+		m.Unlock()
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := c.Ping(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	if len(msgs) != 4 {
+		t.Fatalf("expected 2 requests and 2 responses, got %d messages", len(msgs))
+	}
+	for i, msg := range msgs {
+		if msg.Response != (i%2 == 1) || msg.Command != SMB2_ECHO || msg.SessionId != 1 {
+			t.Errorf("%d: unexpected message %+v", i, msg)
+		}
+	}
+	if msgs[0].MessageId == msgs[2].MessageId {
+		t.Errorf("expected the requests to be assigned different message ids, got %d", msgs[0].MessageId)
+	}
+	for i := 0; i < 4; i += 2 {
+		if msgs[i].MessageId != msgs[i+1].MessageId {
+			t.Errorf("expected the response to have the message id %d of the request, got %d", msgs[i].MessageId, msgs[i+1].MessageId)
+		}
+	}
+	if msgs[1].Credits != 3 {
+		t.Errorf("expected 3 credits granted, got %d", msgs[1].Credits)
+	}
+}
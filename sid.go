@@ -0,0 +1,113 @@
+package smb2
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+const maxSubAuthorities = 15
+
+// SID represents a security identifier. (See [MS-DTYP] 2.4.2)
+type SID struct {
+	Revision            uint8
+	IdentifierAuthority uint64 // 48-bit value
+	SubAuthority        []uint32
+}
+
+// ParseSID parses the string form of a SID like "S-1-5-21-1004336348-1177238915-682003330-512".
+// The identifier authority may be written in hexadecimal with a "0x" prefix.
+func ParseSID(s string) (SID, error) {
+	ss := strings.Split(s, "-")
+	if len(ss) < 3 || len(ss) > 3+maxSubAuthorities || (ss[0] != "S" && ss[0] != "s") {
+		return SID{}, &InvalidSIDError{s}
+	}
+
+	rev, err := strconv.ParseUint(ss[1], 10, 8)
+	if err != nil || rev != 1 {
+		return SID{}, &InvalidSIDError{s}
+	}
+
+	var auth uint64
+	if strings.HasPrefix(ss[2], "0x") || strings.HasPrefix(ss[2], "0X") {
+		auth, err = strconv.ParseUint(ss[2][2:], 16, 48)
+	} else {
+		auth, err = strconv.ParseUint(ss[2], 10, 48)
+	}
+	if err != nil {
+		return SID{}, &InvalidSIDError{s}
+	}
+
+	subs := make([]uint32, len(ss)-3)
+	for i, a := range ss[3:] {
+		u, err := strconv.ParseUint(a, 10, 32)
+		if err != nil {
+			return SID{}, &InvalidSIDError{s}
+		}
+		subs[i] = uint32(u)
+	}
+
+	return SID{Revision: uint8(rev), IdentifierAuthority: auth, SubAuthority: subs}, nil
+}
+
+// String returns the canonical string form of sid (e.g. "S-1-5-32-544").
+func (sid SID) String() string {
+	return sid.internal().String()
+}
+
+// Equal reports whether sid and other represent the same security identifier.
+func (sid SID) Equal(other SID) bool {
+	if sid.Revision != other.Revision || sid.IdentifierAuthority != other.IdentifierAuthority {
+		return false
+	}
+	if len(sid.SubAuthority) != len(other.SubAuthority) {
+		return false
+	}
+	for i, a := range sid.SubAuthority {
+		if a != other.SubAuthority[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalBinary encodes sid in the on-wire format.
+func (sid SID) MarshalBinary() ([]byte, error) {
+	if len(sid.SubAuthority) > maxSubAuthorities || sid.IdentifierAuthority >= 1<<48 {
+		return nil, errors.New("SID has too many sub authorities or too large identifier authority")
+	}
+
+	s := sid.internal()
+
+	bs := make([]byte, s.Size())
+	s.Encode(bs)
+
+	return bs, nil
+}
+
+// UnmarshalBinary decodes the on-wire format of a SID.
+// Trailing bytes are ignored.
+func (sid *SID) UnmarshalBinary(data []byte) error {
+	d := SidDecoder(data)
+	if d.IsInvalid() || d.SubAuthorityCount() > maxSubAuthorities {
+		return errors.New("broken SID format")
+	}
+
+	*sid = SID{
+		Revision:            d.Revision(),
+		IdentifierAuthority: d.IdentifierAuthority(),
+		SubAuthority:        d.SubAuthority(),
+	}
+
+	return nil
+}
+
+func (sid SID) internal() *Sid {
+	return &Sid{
+		Revision:            sid.Revision,
+		IdentifierAuthority: sid.IdentifierAuthority,
+		SubAuthority:        sid.SubAuthority,
+	}
+}
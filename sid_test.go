@@ -0,0 +1,79 @@
+package smb2
+
+import (
+	"bytes"
+	"testing"
+)
+
+var testSID = []struct {
+	String string
+	Binary []byte
+}{
+	{"S-1-0-0", []byte{1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+	{"S-1-5-18", []byte{1, 1, 0, 0, 0, 0, 0, 5, 18, 0, 0, 0}},
+	{"S-1-5-32-544", []byte{1, 2, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0, 0x20, 2, 0, 0}},
+	{
+		"S-1-5-21-1004336348-1177238915-682003330-512",
+		[]byte{
+			1, 5, 0, 0, 0, 0, 0, 5,
+			21, 0, 0, 0,
+			0xdc, 0xf4, 0xdc, 0x3b,
+			0x83, 0x3d, 0x2b, 0x46,
+			0x82, 0x8b, 0xa6, 0x28,
+			0x00, 0x02, 0x00, 0x00,
+		},
+	},
+	{"S-1-0x123456789ABC-1", []byte{1, 1, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 1, 0, 0, 0}},
+}
+
+func TestSID(t *testing.T) {
+	for _, c := range testSID {
+		sid, err := ParseSID(c.String)
+		if err != nil {
+			t.Errorf("sid: %v, unexpected error: %v", c.String, err)
+			continue
+		}
+		if sid.String() != c.String {
+			t.Errorf("sid: %v, expected: %v, got: %v", c.String, c.String, sid.String())
+		}
+		bs, err := sid.MarshalBinary()
+		if err != nil {
+			t.Errorf("sid: %v, unexpected error: %v", c.String, err)
+			continue
+		}
+		if !bytes.Equal(bs, c.Binary) {
+			t.Errorf("sid: %v, expected: %x, got: %x", c.String, c.Binary, bs)
+		}
+		var sid2 SID
+		if err := sid2.UnmarshalBinary(c.Binary); err != nil {
+			t.Errorf("sid: %v, unexpected error: %v", c.String, err)
+			continue
+		}
+		if !sid2.Equal(sid) {
+			t.Errorf("sid: %v, expected: %v, got: %v", c.String, sid, sid2)
+		}
+	}
+}
+
+var testInvalidSID = []string{
+	"",
+	"S-1",
+	"S-2-5-18",
+	"X-1-5-18",
+	"S-1-5-18-",
+	"S-1-5-4294967296",
+	"S-1-281474976710656-1",
+	"S-1-5-1-2-3-4-5-6-7-8-9-10-11-12-13-14-15-16",
+}
+
+func TestParseInvalidSID(t *testing.T) {
+	for _, s := range testInvalidSID {
+		if _, err := ParseSID(s); err == nil {
+			t.Errorf("sid: %q, expected error", s)
+		}
+	}
+	var sid SID
+	if err := sid.UnmarshalBinary([]byte{1, 2, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0}); err == nil {
+		t.Error("expected error for truncated binary SID")
+	}
+}
@@ -0,0 +1,95 @@
+package smb2
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+
+	"github.com/nodauf/go-smb2/internal/crypto/cmac"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// newSigner returns the hash which signs and verifies packets by signingKey.
+// It depends on the dialect, and on the signing algorithm negotiated by SMB 3.1.1.
+func newSigner(dialect, signingId uint16, signingKey []byte) (hash.Hash, error) {
+	switch dialect {
+	case SMB202, SMB210:
+		return hmac.New(sha256.New, signingKey), nil
+	case SMB311:
+		if signingId == HMAC_SHA256 {
+			return hmac.New(sha256.New, signingKey), nil
+		}
+	}
+
+	ciph, err := aes.NewCipher(signingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if dialect == SMB311 && signingId == AES_GMAC {
+		return newGMACSigner(ciph)
+	}
+
+	return cmac.New(ciph), nil
+}
+
+// gmacSigner computes AES-GMAC signatures of SMB 3.1.1 packets.
+// Unlike HMAC-SHA256 and AES-CMAC, the nonce of GMAC depends on the packet itself,
+// so it buffers the written packet and computes the signature on Sum.
+type gmacSigner struct {
+	aead cipher.AEAD
+	buf  []byte
+}
+
+func newGMACSigner(ciph cipher.Block) (hash.Hash, error) {
+	aead, err := cipher.NewGCMWithNonceSize(ciph, 12)
+	if err != nil {
+		return nil, err
+	}
+	return &gmacSigner{aead: aead}, nil
+}
+
+func (h *gmacSigner) Write(p []byte) (int, error) {
+	h.buf = append(h.buf, p...)
+	return len(p), nil
+}
+
+// Sum appends the signature of the packet written so far to b.
+// The nonce is the MessageId followed by a 32-bit field whose bit 0 is set for responses
+// and bit 1 is set for SMB2 CANCEL requests.
+func (h *gmacSigner) Sum(b []byte) []byte {
+	var nonce [12]byte
+
+	if len(h.buf) >= 64 {
+		p := PacketCodec(h.buf)
+
+		binary.LittleEndian.PutUint64(nonce[:8], p.MessageId())
+
+		var role uint32
+		if p.Flags()&SMB2_FLAGS_SERVER_TO_REDIR != 0 {
+			role |= 1
+		}
+		if p.Command() == SMB2_CANCEL {
+			role |= 2
+		}
+		binary.LittleEndian.PutUint32(nonce[8:], role)
+	}
+
+	return h.aead.Seal(b, nonce[:], nil, h.buf)
+}
+
+func (h *gmacSigner) Reset() {
+	h.buf = h.buf[:0]
+}
+
+func (h *gmacSigner) Size() int {
+	return 16
+}
+
+func (h *gmacSigner) BlockSize() int {
+	return 16
+}
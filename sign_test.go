@@ -2,11 +2,15 @@ package smb2
 
 import (
 	"bytes"
+	"context"
 	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/hex"
 
 	"github.com/nodauf/go-smb2/internal/crypto/cmac"
 
+	. "github.com/nodauf/go-smb2/internal/erref"
 	. "github.com/nodauf/go-smb2/internal/smb2"
 
 	"testing"
@@ -50,3 +54,209 @@ func TestSign(t *testing.T) {
 		t.Error("fail")
 	}
 }
+
+func TestSignGMAC(t *testing.T) {
+	signingKey, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciph, err := aes.NewCipher(signingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := newGMACSigner(ciph)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		pkt       string
+		signature string
+	}{
+		{ // request
+			"fe534d4240000100000000000e0001000800000000000000050000000000000000000000010000008877665544332211000000000000000000000000000000000400000000000000",
+			"430f218ee6bf7e45b600e2f76443c292",
+		},
+		{ // response
+			"fe534d4240000100000000000e0001000900000000000000050000000000000000000000010000008877665544332211000000000000000000000000000000000400000000000000",
+			"7cc3917ec53a5a95d3f1ade694a850d4",
+		},
+		{ // cancel
+			"fe534d4240000100000000000c00010008000000000000000700000000000000000000000100000088776655443322110000000000000000000000000000000004000000",
+			"a67170b4b47d4aa7df64934437c98572",
+		},
+	} {
+		pkt, err := hex.DecodeString(tc.pkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signature, err := hex.DecodeString(tc.signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signer.Reset()
+		signer.Write(pkt)
+		signer.Sum(pkt[:48])
+		if !bytes.Equal(PacketCodec(pkt).Signature(), signature) {
+			t.Errorf("expected %x, got %x", signature, PacketCodec(pkt).Signature())
+		}
+	}
+}
+
+func newSignedTestConn(tr transport) *conn {
+	conn := newFakeConn(tr, clientMaxCreditBalance)
+	conn.requireSigning = true
+
+	signingKey := make([]byte, 16)
+
+	conn.session = &session{
+		conn:           conn,
+		treeConnTables: make(map[uint32]*treeConn),
+		sessionId:      1,
+		signer:         hmac.New(sha256.New, signingKey),
+		verifier:       hmac.New(sha256.New, signingKey),
+	}
+	conn.enableSession()
+
+	return conn
+}
+
+func newTestResponse(s *session, msgId uint64, tamper bool) []byte {
+	pkt := make([]byte, 64+8)
+
+	p := PacketCodec(pkt)
+	p.SetProtocolId()
+	p.SetStructureSize()
+	p.SetCommand(SMB2_ECHO)
+	p.SetFlags(SMB2_FLAGS_SERVER_TO_REDIR)
+	p.SetMessageId(msgId)
+	p.SetSessionId(s.sessionId)
+
+	s.sign(pkt)
+
+	if tamper {
+		pkt[len(pkt)-1] ^= 0xff
+	}
+
+	return pkt
+}
+
+func newTestRequestResponse(conn *conn, msgId uint64) *requestResponse {
+	rr := &requestResponse{
+		msgId: msgId,
+		ctx:   context.Background(),
+		recv:  make(chan []byte, 1),
+	}
+	conn.outstandingRequests.set(msgId, rr)
+	return rr
+}
+
+func TestSignatureMismatch(t *testing.T) {
+	tr := newFakeTransport()
+	conn := newSignedTestConn(tr)
+	defer tr.Close()
+
+	rr1 := newTestRequestResponse(conn, 1)
+	rr2 := newTestRequestResponse(conn, 2)
+
+	tr.push(newTestResponse(conn.session, 1, true))
+	tr.push(newTestResponse(conn.session, 2, false))
+
+	if _, err := conn.recv(rr1); err != ErrSignatureMismatch {
+		t.Errorf("expected %v, got %v", ErrSignatureMismatch, err)
+	}
+
+	if _, err := conn.recv(rr2); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if conn.isClosed() {
+		t.Error("connection is closed")
+	}
+}
+
+func TestSignatureMismatchDropSession(t *testing.T) {
+	tr := newFakeTransport()
+	conn := newSignedTestConn(tr)
+	conn.dropOnSigningFailure = true
+	defer tr.Close()
+
+	rr1 := newTestRequestResponse(conn, 1)
+	rr2 := newTestRequestResponse(conn, 2)
+
+	tr.push(newTestResponse(conn.session, 1, true))
+
+	for _, rr := range []*requestResponse{rr1, rr2} {
+		if _, err := conn.recv(rr); err != ErrSignatureMismatch {
+			t.Errorf("expected %v, got %v", ErrSignatureMismatch, err)
+		}
+	}
+
+	<-conn.wdone
+
+	if conn.err != ErrSignatureMismatch {
+		t.Errorf("expected %v, got %v", ErrSignatureMismatch, conn.err)
+	}
+}
+
+func TestSigningExemptions(t *testing.T) {
+	tr := newFakeTransport()
+	conn := newSignedTestConn(tr)
+	defer tr.Close()
+
+	const (
+		unsigned = iota
+		signed
+		tampered
+	)
+
+	tests := []struct {
+		name      string
+		cmd       uint16
+		status    NtStatus
+		msgId     uint64
+		signing   int
+		encrypted bool
+		err       error
+	}{
+		{name: "signed", cmd: SMB2_ECHO, msgId: 1, signing: signed},
+		{name: "unsigned", cmd: SMB2_ECHO, msgId: 1, signing: unsigned, err: ErrSignatureMismatch},
+		{name: "tampered", cmd: SMB2_ECHO, msgId: 1, signing: tampered, err: ErrSignatureMismatch},
+		{name: "unsigned error", cmd: SMB2_CREATE, status: STATUS_ACCESS_DENIED, msgId: 1, signing: unsigned, err: ErrSignatureMismatch},
+		{name: "encrypted", cmd: SMB2_ECHO, msgId: 1, signing: unsigned, encrypted: true},
+		{name: "unsigned interim", cmd: SMB2_READ, status: STATUS_PENDING, msgId: 1, signing: unsigned},
+		{name: "tampered interim", cmd: SMB2_READ, status: STATUS_PENDING, msgId: 1, signing: tampered},
+		{name: "unsigned oplock break", cmd: SMB2_OPLOCK_BREAK, msgId: 0xFFFFFFFFFFFFFFFF, signing: unsigned},
+		{name: "unsigned negotiate", cmd: SMB2_NEGOTIATE, msgId: 0, signing: unsigned},
+		{name: "unsigned session setup leg", cmd: SMB2_SESSION_SETUP, status: STATUS_MORE_PROCESSING_REQUIRED, msgId: 1, signing: unsigned},
+		{name: "unsigned session setup", cmd: SMB2_SESSION_SETUP, msgId: 1, signing: unsigned, err: ErrSignatureMismatch},
+	}
+
+	for _, tt := range tests {
+		pkt := make([]byte, 64+8)
+
+		p := PacketCodec(pkt)
+		p.SetProtocolId()
+		p.SetStructureSize()
+		p.SetCommand(tt.cmd)
+		p.SetStatus(uint32(tt.status))
+		p.SetFlags(SMB2_FLAGS_SERVER_TO_REDIR)
+		p.SetMessageId(tt.msgId)
+		p.SetSessionId(conn.session.sessionId)
+
+		if tt.signing != unsigned {
+			conn.session.sign(pkt)
+		}
+		if tt.signing == tampered {
+			pkt[len(pkt)-1] ^= 0xff
+		}
+
+		if err := conn.tryVerify(pkt, nil, tt.encrypted); err != tt.err {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+}
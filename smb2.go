@@ -26,6 +26,9 @@ var be = binary.BigEndian
 
 var logger *log.Logger
 
+// leakLogger warns of the files garbage collected without being closed if Dialer.WarnOnLeak is set.
+var leakLogger = log.New(os.Stderr, "smb2: ", log.LstdFlags)
+
 func init() {
 	if debug {
 		logger = log.New(os.Stderr, "smb2: ", log.LstdFlags)
@@ -1,7 +1,10 @@
 package smb2_test
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -10,8 +13,12 @@ import (
 	"net"
 	"os"
 	"path"
+	"reflect"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/nodauf/go-smb2"
@@ -339,6 +346,16 @@ func TestSymlink(t *testing.T) {
 			t.Error("should be a symlink")
 		}
 
+		stat, err = fs.Stat(testDir + `\linkToTestFile`)
+		if err == nil { // if it supports follow-symlink
+			if stat.Mode()&os.ModeSymlink != 0 {
+				t.Error("should describe the target")
+			}
+			if stat.Size() != int64(len("testContent")) {
+				t.Error("unexpected size:", stat.Size())
+			}
+		}
+
 		target, err := fs.Readlink(testDir + `\linkToTestFile`)
 		if err != nil {
 			t.Fatal(err)
@@ -595,6 +612,79 @@ func TestListSharenames(t *testing.T) {
 	}
 }
 
+func TestServerInfo(t *testing.T) {
+	if session == nil {
+		t.Skip()
+	}
+	info := session.ServerInfo()
+	if info.Dialect == 0 {
+		t.Error("the dialect isn't set")
+	}
+	if _, ok := dialer.Initiator.(*smb2.NTLMInitiator); ok && info.NbComputerName == "" {
+		t.Error("the NetBIOS computer name isn't set")
+	}
+}
+
+func TestServerInfo101(t *testing.T) {
+	if session == nil {
+		t.Skip()
+	}
+	info, err := session.ServerInfo101()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name == "" {
+		t.Error("the server name isn't set")
+	}
+	if info.Type&smb2.ServerTypeServer == 0 {
+		t.Errorf("unexpected server type: %#x", info.Type)
+	}
+}
+
+func TestWorkstationInfo(t *testing.T) {
+	if session == nil {
+		t.Skip()
+	}
+	info, err := session.WorkstationInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ComputerName == "" {
+		t.Error("the computer name isn't set")
+	}
+}
+
+func TestLookupSIDs(t *testing.T) {
+	if session == nil {
+		t.Skip()
+	}
+	admins, err := smb2.ParseSID("S-1-5-32-544")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts, err := session.LookupSIDs([]smb2.SID{admins})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 {
+		t.Fatalf("expected 1 account, got %d", len(accounts))
+	}
+	if accounts[0].Use != smb2.SIDTypeAlias || accounts[0].Name != "Administrators" {
+		t.Errorf("unexpected account: %v (%v)", accounts[0], accounts[0].Use)
+	}
+
+	accounts, err = session.LookupNames([]string{accounts[0].String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 {
+		t.Fatalf("expected 1 account, got %d", len(accounts))
+	}
+	if !accounts[0].SID.Equal(admins) {
+		t.Errorf("expected %v, got %v", admins, accounts[0].SID)
+	}
+}
+
 func TestServerSideCopy(t *testing.T) {
 	if fs == nil {
 		t.Skip()
@@ -638,154 +728,555 @@ func TestServerSideCopy(t *testing.T) {
 	}
 }
 
-func TestRemoveAll(t *testing.T) {
+func TestReadWriteFile(t *testing.T) {
 	if fs == nil {
 		t.Skip()
 	}
 
-	testDir := fmt.Sprintf("testDir-%d-TestRemoveAll", os.Getpid())
+	testDir := fmt.Sprintf("testDir-%d-TestReadWriteFile", os.Getpid())
 	err := fs.Mkdir(testDir, 0755)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = fs.WriteFile(path.Join(testDir, "hello.txt"), []byte("hello world!"), 0666)
+	defer fs.RemoveAll(testDir)
+
+	name := path.Join(testDir, "data")
+
+	// larger than a read chunk, and not aligned on it
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1<<16+3)
+
+	err = fs.WriteFile(name, data, 0666)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = fs.Mkdir(path.Join(testDir, "hello"), 0755)
+
+	bs, err := fs.ReadFile(name)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = fs.WriteFile(path.Join(testDir, "hello", "hello.txt"), []byte("hello world!"), 0444)
+	if !bytes.Equal(bs, data) {
+		t.Errorf("unexpected content: %d bytes, expected %d bytes", len(bs), len(data))
+	}
+
+	// WriteFile truncates the existing file
+	err = fs.WriteFile(name, []byte("hello"), 0666)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = fs.RemoveAll(testDir)
+
+	bs, err = fs.ReadFile(name)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
+	}
+	if string(bs) != "hello" {
+		t.Errorf("expected %q, got %q", "hello", bs)
+	}
+
+	err = fs.WriteFile(name, nil, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err = fs.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bs) != 0 {
+		t.Errorf("expected an empty file, got %q", bs)
+	}
+
+	_, err = fs.ReadFile(path.Join(testDir, "missing"))
+	if !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
 	}
 }
 
-func TestContextError(t *testing.T) {
-	if session == nil {
+func TestArchive(t *testing.T) {
+	if fs == nil {
 		t.Skip()
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-
-	s := session.WithContext(ctx)
-	fs := fs.WithContext(ctx)
-	f, err := fs.Open(".")
+	testDir := fmt.Sprintf("testDir-%d-TestArchive", os.Getpid())
+	err := fs.Mkdir(testDir, 0755)
 	if err != nil {
 		t.Fatal(err)
 	}
+	defer fs.RemoveAll(testDir)
 
-	cancel()
+	files := map[string]string{
+		"a.txt":         "hello",
+		"dir/b.txt":     strings.Repeat("0123456789", 100000),
+		"dir/sub/c.txt": "",
+	}
 
-	checkError1 := func(op string, err error) {
-		if err == nil || err.(*smb2.ContextError).Err != context.Canceled {
-			t.Errorf("unexpected context handling: op=%s, type=%T, value=%v", op, err, err)
+	err = fs.MkdirAll(path.Join(testDir, "dir", "sub"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		err = fs.WriteFile(path.Join(testDir, name), []byte(content), 0666)
+		if err != nil {
+			t.Fatal(err)
 		}
 	}
 
-	checkError2 := func(op string, err error) {
-		switch e := err.(type) {
-		case *os.PathError:
-			err = e.Err.(*smb2.ContextError).Err
-			if err != context.Canceled {
-				t.Errorf("unexpected context handling: op=%s, type=%T, value=%v", op, err, err)
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	err = fs.Chtimes(path.Join(testDir, "a.txt"), mtime, mtime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"a.txt", "dir/", "dir/b.txt", "dir/sub/", "dir/sub/c.txt"}
+
+	t.Run("TarGz", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := fs.TarGz(&buf, testDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		zr, err := gzip.NewReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tr := tar.NewReader(zr)
+
+		var names []string
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
 			}
-		case *os.LinkError:
-			err = e.Err.(*smb2.ContextError).Err
-			if err != context.Canceled {
-				t.Errorf("unexpected context handling: op=%s, type=%T, value=%v", op, err, err)
+			if err != nil {
+				t.Fatal(err)
 			}
-		default:
-			t.Errorf("unexpected context handling: op=%s, type=%T, value=%v", op, err, err)
+			names = append(names, hdr.Name)
+
+			if hdr.Typeflag == tar.TypeDir {
+				continue
+			}
+
+			bs, err := ioutil.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(bs) != files[hdr.Name] {
+				t.Errorf("%s: unexpected content of %d bytes", hdr.Name, len(bs))
+			}
+			if hdr.Name == "a.txt" && !hdr.ModTime.Equal(mtime) {
+				t.Errorf("%s: expected mtime %v, got %v", hdr.Name, mtime, hdr.ModTime)
+			}
+		}
+
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("expected %v, got %v", expected, names)
+		}
+	})
+
+	t.Run("Zip", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := fs.Zip(&buf, testDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, zf := range zr.File {
+			names = append(names, zf.Name)
+
+			if zf.FileInfo().IsDir() {
+				continue
+			}
+
+			r, err := zf.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			bs, err := ioutil.ReadAll(r)
+			r.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(bs) != files[zf.Name] {
+				t.Errorf("%s: unexpected content of %d bytes", zf.Name, len(bs))
+			}
+			if zf.Name == "a.txt" && !zf.Modified.Equal(mtime) {
+				t.Errorf("%s: expected mtime %v, got %v", zf.Name, mtime, zf.Modified)
+			}
+		}
+
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("expected %v, got %v", expected, names)
 		}
+	})
+}
+
+func TestUnarchive(t *testing.T) {
+	if fs == nil {
+		t.Skip()
 	}
 
-	conn, err := net.Dial(cfg.Transport.Type, fmt.Sprintf("%s:%d", cfg.Transport.Host, cfg.Transport.Port))
+	testDir := fmt.Sprintf("testDir-%d-TestUnarchive", os.Getpid())
+	err := fs.Mkdir(testDir, 0755)
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
-	defer conn.Close()
+	defer fs.RemoveAll(testDir)
 
-	_, err = dialer.DialContext(ctx, conn)
-	checkError1("dialcontext", err)
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
 
-	_, err = s.Mount("somewhere")
-	checkError1("mount", err)
-	_, err = s.ListSharenames()
-	checkError1("listsharename", err)
-	err = s.Logoff()
-	checkError1("logoff", err)
+	newTarGz := func(hdrs []*tar.Header, contents []string) *bytes.Buffer {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(zw)
+		for i, hdr := range hdrs {
+			hdr.Size = int64(len(contents[i]))
+			if err := tw.WriteHeader(hdr); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write([]byte(contents[i])); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return &buf
+	}
 
-	err = fs.Chmod("aaa", 0)
-	checkError2("chmod", err)
-	err = fs.Chtimes("aaa", time.Time{}, time.Time{})
-	checkError2("chtimes", err)
-	_, err = fs.Create("aaa")
-	checkError2("create", err)
-	_, err = fs.Lstat("aaa")
-	checkError2("lstat", err)
-	err = fs.Mkdir("aaa", 0)
-	checkError2("mkdir", err)
-	err = fs.MkdirAll("aaa", 0)
-	checkError2("mkdirall", err)
-	_, err = fs.Open("aaa")
-	checkError2("open", err)
-	_, err = fs.OpenFile("aaa", 0, 0)
-	checkError2("openfile", err)
-	_, err = fs.ReadDir("aaa")
-	checkError2("readdir", err)
-	_, err = fs.ReadFile("aaa")
-	checkError2("readfile", err)
-	_, err = fs.Readlink("aaa")
-	checkError2("readlink", err)
-	err = fs.Remove("aaa")
-	checkError2("remove", err)
-	err = fs.RemoveAll("aaa")
-	checkError2("removeall", err)
-	err = fs.Rename("aaa", "bbb")
-	checkError2("rename", err)
-	_, err = fs.Stat("aaa")
-	checkError2("stat", err)
-	_, err = fs.Statfs("aaa")
-	checkError2("statfs", err)
-	err = fs.Symlink("aaa", "bbb")
-	checkError2("symlink", err)
-	err = fs.Truncate("aaa", 0)
-	checkError2("truncate", err)
-	err = fs.WriteFile("aaa", nil, 0)
-	checkError2("writefile", err)
-	err = fs.Umount()
-	checkError1("umount", err)
+	t.Run("UntarGz", func(t *testing.T) {
+		dest := path.Join(testDir, "tar")
 
-	err = f.Chmod(0)
-	checkError2("fchmod", err)
-	_, err = f.Read(make([]byte, 10))
-	checkError2("fread", err)
-	_, err = f.ReadAt(make([]byte, 10), 0)
-	checkError2("freadat", err)
-	_, err = f.ReadFrom(strings.NewReader("aaa"))
-	checkError2("freadfrom", err)
-	_, err = f.Readdir(-1)
-	checkError2("freaddir", err)
-	_, err = f.Readdirnames(-1)
-	checkError2("freaddirnames", err)
-	_, err = f.Seek(1, io.SeekEnd)
-	checkError2("fseek", err)
-	_, err = f.Stat()
-	checkError2("fstat", err)
-	_, err = f.Statfs()
-	checkError2("fstatfs", err)
-	err = f.Sync()
-	checkError2("fsync", err)
-	err = f.Truncate(1)
-	checkError2("ftruncate", err)
-	f.Seek(0, io.SeekStart)
-	_, err = f.Write([]byte("aa"))
+		buf := newTarGz([]*tar.Header{
+			{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755, ModTime: mtime},
+			{Name: "dir/sub/a.txt", Typeflag: tar.TypeReg, Mode: 0644, ModTime: mtime}, // the parent isn't in the archive
+			{Name: "b.txt", Typeflag: tar.TypeReg, Mode: 0644, ModTime: mtime},
+		}, []string{"", "hello", "world"})
+
+		err := fs.UntarGz(buf, dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bs, err := fs.ReadFile(path.Join(dest, "dir", "sub", "a.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bs) != "hello" {
+			t.Errorf("expected %q, got %q", "hello", bs)
+		}
+
+		fi, err := fs.Stat(path.Join(dest, "b.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !fi.ModTime().Equal(mtime) {
+			t.Errorf("expected mtime %v, got %v", mtime, fi.ModTime())
+		}
+
+		fi, err = fs.Stat(path.Join(dest, "dir"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !fi.ModTime().Equal(mtime) {
+			t.Errorf("expected mtime %v, got %v", mtime, fi.ModTime())
+		}
+	})
+
+	t.Run("Traversal", func(t *testing.T) {
+		dest := path.Join(testDir, "traversal")
+
+		for _, hdr := range []*tar.Header{
+			{Name: "../escaped.txt", Typeflag: tar.TypeReg, Mode: 0644},
+			{Name: "/escaped.txt", Typeflag: tar.TypeReg, Mode: 0644},
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../.."},
+		} {
+			err := fs.UntarGz(newTarGz([]*tar.Header{hdr}, []string{""}), dest)
+			switch err := err.(type) {
+			case *os.PathError:
+				if err.Err == os.ErrInvalid {
+					continue
+				}
+			case *os.LinkError:
+				if err.Err == os.ErrInvalid {
+					continue
+				}
+			}
+			t.Errorf("%s: expected os.ErrInvalid, got %v", hdr.Name, err)
+		}
+
+		if _, err := fs.Stat(path.Join(testDir, "escaped.txt")); !os.IsNotExist(err) {
+			t.Errorf("the entry escaped the destination: %v", err)
+		}
+	})
+
+	t.Run("Unzip", func(t *testing.T) {
+		src := path.Join(testDir, "src")
+
+		err := fs.MkdirAll(path.Join(src, "dir"), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = fs.WriteFile(path.Join(src, "dir", "a.txt"), []byte("hello"), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+
+		err = fs.Zip(&buf, src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dest := path.Join(testDir, "zip")
+
+		err = fs.Unzip(bytes.NewReader(buf.Bytes()), int64(buf.Len()), dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bs, err := fs.ReadFile(path.Join(dest, "dir", "a.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bs) != "hello" {
+			t.Errorf("expected %q, got %q", "hello", bs)
+		}
+	})
+}
+
+func TestNonBMPFilename(t *testing.T) {
+	if fs == nil {
+		t.Skip()
+	}
+	testDir := fmt.Sprintf("testDir-%d-TestNonBMPFilename", os.Getpid())
+	err := fs.Mkdir(testDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.RemoveAll(testDir)
+
+	name := "😀 𠀋.txt"
+
+	err = fs.WriteFile(testDir+`\`+name, []byte("test"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fis, err := fs.ReadDir(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) != 1 || fis[0].Name() != name {
+		t.Fatalf("unexpected entries: %v", fis)
+	}
+
+	bs, err := fs.ReadFile(testDir + `\` + fis[0].Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != "test" {
+		t.Error("unexpected content:", string(bs))
+	}
+}
+
+func TestRemoveBatch(t *testing.T) {
+	if fs == nil {
+		t.Skip()
+	}
+
+	testDir := fmt.Sprintf("testDir-%d-TestRemoveBatch", os.Getpid())
+	err := fs.Mkdir(testDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.RemoveAll(testDir)
+
+	var names []string
+	for i := 0; i < 50; i++ {
+		name := path.Join(testDir, fmt.Sprintf("file%d", i))
+		err = fs.WriteFile(name, []byte("hello"), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	names = append(names, path.Join(testDir, "missing"))
+
+	errs := fs.RemoveBatch(names)
+
+	for i, name := range names[:50] {
+		if errs[i] != nil {
+			t.Errorf("%s: %v", name, errs[i])
+		}
+		if _, err := fs.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("%s isn't removed: %v", name, err)
+		}
+	}
+	if !os.IsNotExist(errs[50]) {
+		t.Errorf("unexpected error: %v", errs[50])
+	}
+}
+
+func TestRemoveAll(t *testing.T) {
+	if fs == nil {
+		t.Skip()
+	}
+
+	testDir := fmt.Sprintf("testDir-%d-TestRemoveAll", os.Getpid())
+	err := fs.Mkdir(testDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fs.WriteFile(path.Join(testDir, "hello.txt"), []byte("hello world!"), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fs.Mkdir(path.Join(testDir, "hello"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fs.WriteFile(path.Join(testDir, "hello", "hello.txt"), []byte("hello world!"), 0444)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fs.RemoveAll(testDir)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestContextError(t *testing.T) {
+	if session == nil {
+		t.Skip()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := session.WithContext(ctx)
+	fs := fs.WithContext(ctx)
+	f, err := fs.Open(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+
+	checkError1 := func(op string, err error) {
+		if err == nil || err.(*smb2.ContextError).Err != context.Canceled {
+			t.Errorf("unexpected context handling: op=%s, type=%T, value=%v", op, err, err)
+		}
+	}
+
+	checkError2 := func(op string, err error) {
+		switch e := err.(type) {
+		case *os.PathError:
+			err = e.Err.(*smb2.ContextError).Err
+			if err != context.Canceled {
+				t.Errorf("unexpected context handling: op=%s, type=%T, value=%v", op, err, err)
+			}
+		case *os.LinkError:
+			err = e.Err.(*smb2.ContextError).Err
+			if err != context.Canceled {
+				t.Errorf("unexpected context handling: op=%s, type=%T, value=%v", op, err, err)
+			}
+		default:
+			t.Errorf("unexpected context handling: op=%s, type=%T, value=%v", op, err, err)
+		}
+	}
+
+	conn, err := net.Dial(cfg.Transport.Type, fmt.Sprintf("%s:%d", cfg.Transport.Host, cfg.Transport.Port))
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	_, err = dialer.DialContext(ctx, conn)
+	checkError1("dialcontext", err)
+
+	_, err = s.Mount("somewhere")
+	checkError1("mount", err)
+	_, err = s.ListSharenames()
+	checkError1("listsharename", err)
+	err = s.Logoff()
+	checkError1("logoff", err)
+
+	err = fs.Chmod("aaa", 0)
+	checkError2("chmod", err)
+	err = fs.Chtimes("aaa", time.Time{}, time.Time{})
+	checkError2("chtimes", err)
+	_, err = fs.Create("aaa")
+	checkError2("create", err)
+	_, err = fs.Lstat("aaa")
+	checkError2("lstat", err)
+	err = fs.Mkdir("aaa", 0)
+	checkError2("mkdir", err)
+	err = fs.MkdirAll("aaa", 0)
+	checkError2("mkdirall", err)
+	_, err = fs.Open("aaa")
+	checkError2("open", err)
+	_, err = fs.OpenFile("aaa", 0, 0)
+	checkError2("openfile", err)
+	_, err = fs.ReadDir("aaa")
+	checkError2("readdir", err)
+	_, err = fs.ReadFile("aaa")
+	checkError2("readfile", err)
+	_, err = fs.Readlink("aaa")
+	checkError2("readlink", err)
+	err = fs.Remove("aaa")
+	checkError2("remove", err)
+	err = fs.RemoveAll("aaa")
+	checkError2("removeall", err)
+	err = fs.Rename("aaa", "bbb")
+	checkError2("rename", err)
+	_, err = fs.Stat("aaa")
+	checkError2("stat", err)
+	_, err = fs.Statfs("aaa")
+	checkError2("statfs", err)
+	err = fs.Symlink("aaa", "bbb")
+	checkError2("symlink", err)
+	err = fs.Truncate("aaa", 0)
+	checkError2("truncate", err)
+	err = fs.WriteFile("aaa", nil, 0)
+	checkError2("writefile", err)
+	err = fs.Umount()
+	checkError1("umount", err)
+
+	err = f.Chmod(0)
+	checkError2("fchmod", err)
+	_, err = f.Read(make([]byte, 10))
+	checkError2("fread", err)
+	_, err = f.ReadAt(make([]byte, 10), 0)
+	checkError2("freadat", err)
+	_, err = f.ReadFrom(strings.NewReader("aaa"))
+	checkError2("freadfrom", err)
+	_, err = f.Readdir(-1)
+	checkError2("freaddir", err)
+	_, err = f.Readdirnames(-1)
+	checkError2("freaddirnames", err)
+	_, err = f.Seek(1, io.SeekEnd)
+	checkError2("fseek", err)
+	_, err = f.Stat()
+	checkError2("fstat", err)
+	_, err = f.Statfs()
+	checkError2("fstatfs", err)
+	err = f.Sync()
+	checkError2("fsync", err)
+	err = f.Truncate(1)
+	checkError2("ftruncate", err)
+	f.Seek(0, io.SeekStart)
+	_, err = f.Write([]byte("aa"))
 	checkError2("fwrite", err)
 	_, err = f.WriteAt([]byte("aa"), 0)
 	checkError2("fwriteat", err)
@@ -796,3 +1287,410 @@ func TestContextError(t *testing.T) {
 	_, err = f.WriteTo(bytes.NewBufferString("aaa"))
 	checkError2("fwriteto", err)
 }
+
+func TestCreateFile(t *testing.T) {
+	if fs == nil {
+		t.Skip()
+	}
+
+	testDir := fmt.Sprintf("testDir-%d-TestCreateFile", os.Getpid())
+	err := fs.Mkdir(testDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.RemoveAll(testDir)
+
+	req := smb2.CreateFileRequest{
+		DesiredAccess:     smb2.AccessGenericRead | smb2.AccessGenericWrite,
+		ShareAccess:       smb2.FileShareRead,
+		CreateDisposition: smb2.FileCreate,
+		CreateOptions:     smb2.FileNonDirectoryFile,
+	}
+
+	f, err := fs.CreateFile(path.Join(testDir, "hello.txt"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.WriteString("hello world!")
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		t.Error(err)
+	}
+
+	_, err = fs.CreateFile(path.Join(testDir, "hello.txt"), req)
+	if !os.IsExist(err) {
+		t.Error("unexpected error:", err)
+	}
+
+	req.DesiredAccess = smb2.AccessGenericRead
+	req.CreateDisposition = smb2.FileOpen
+
+	f, err = fs.CreateFile(path.Join(testDir, "hello.txt"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	bs, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != "hello world!" {
+		t.Error("unexpected content:", string(bs))
+	}
+}
+
+func TestNtfsVolumeData(t *testing.T) {
+	if fs == nil {
+		t.Skip()
+	}
+	data, err := fs.NtfsVolumeData()
+	if err != nil {
+		if e, ok := err.(*os.PathError); ok && e.Err == smb2.ErrNotSupported {
+			t.Skip("volume is not NTFS")
+		}
+		t.Fatal(err)
+	}
+	if data.BytesPerSector == 0 || data.BytesPerCluster%data.BytesPerSector != 0 {
+		t.Errorf("unexpected sector/cluster size: %d/%d", data.BytesPerSector, data.BytesPerCluster)
+	}
+	if data.TotalClusters < data.FreeClusters {
+		t.Errorf("free clusters %d exceeds total clusters %d", data.FreeClusters, data.TotalClusters)
+	}
+}
+
+func TestSetCaseSensitive(t *testing.T) {
+	if fs == nil {
+		t.Skip()
+	}
+	testDir := fmt.Sprintf("testDir-%d-TestSetCaseSensitive", os.Getpid())
+	err := fs.Mkdir(testDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.RemoveAll(testDir)
+
+	err = fs.SetCaseSensitive(testDir, true)
+	if err != nil {
+		if e, ok := err.(*os.PathError); ok && e.Err == smb2.ErrNotSupported {
+			t.Skip("case sensitive directories are not supported")
+		}
+		t.Fatal(err)
+	}
+
+	err = fs.WriteFile(testDir+`\a`, []byte("lower"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fs.WriteFile(testDir+`\A`, []byte("upper"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := fs.ReadFile(testDir + `\a`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != "lower" {
+		t.Errorf("expected the names to differ in a case sensitive directory, got %q", bs)
+	}
+
+	err = fs.Remove(testDir + `\A`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fs.SetCaseSensitive(testDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRetrievalPointers(t *testing.T) {
+	if fs == nil {
+		t.Skip()
+	}
+	testDir := fmt.Sprintf("testDir-%d-TestRetrievalPointers", os.Getpid())
+	err := fs.Mkdir(testDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.RemoveAll(testDir)
+
+	f, err := fs.Create(testDir + `\testFile`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(bytes.Repeat([]byte("a"), 1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extents, err := f.RetrievalPointers(0)
+	if err != nil {
+		if e, ok := err.(*os.PathError); ok && e.Err == smb2.ErrNotSupported {
+			t.Skip("retrieval pointers are not supported")
+		}
+		t.Fatal(err)
+	}
+	var vcn int64
+	for _, e := range extents {
+		if e.VCN != vcn || e.Clusters <= 0 {
+			t.Fatalf("unexpected extent: %+v", e)
+		}
+		vcn += e.Clusters
+	}
+}
+
+func TestListSnapshots(t *testing.T) {
+	if fs == nil {
+		t.Skip()
+	}
+	snaps, err := fs.ListSnapshots("")
+	if err != nil {
+		if e, ok := err.(*os.PathError); ok {
+			if _, ok := e.Err.(*smb2.ResponseError); ok {
+				t.Skip("snapshots are not supported:", err)
+			}
+		}
+		t.Fatal(err)
+	}
+	for i := 1; i < len(snaps); i++ {
+		if snaps[i].Before(snaps[i-1]) {
+			t.Errorf("snapshots are not sorted: %v", snaps)
+		}
+	}
+	if len(snaps) == 0 {
+		return
+	}
+	f, err := fs.OpenSnapshot("", snaps[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	_, err = f.Readdir(-1)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkIOChunkSize(b *testing.B) {
+	if fs == nil {
+		b.Skip()
+	}
+	testDir := fmt.Sprintf("testDir-%d-BenchmarkIOChunkSize", os.Getpid())
+	err := fs.Mkdir(testDir, 0755)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer fs.RemoveAll(testDir)
+
+	data := bytes.Repeat([]byte("a"), 4<<20)
+
+	f, err := fs.Create(testDir + `\testFile`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+
+	defer fs.SetIOChunkSize(0, 0)
+
+	for _, size := range []int{16 << 10, 64 << 10, 256 << 10, 1 << 20} {
+		b.Run(fmt.Sprintf("Write-%dK", size>>10), func(b *testing.B) {
+			fs.SetIOChunkSize(size, size)
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				_, err := f.WriteAt(data, 0)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Read-%dK", size>>10), func(b *testing.B) {
+			fs.SetIOChunkSize(size, size)
+			buf := make([]byte, len(data))
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				_, err := f.ReadAt(buf, 0)
+				if err != nil && err != io.EOF {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionPool(t *testing.T) {
+	if fs == nil {
+		t.Skip()
+	}
+	pool, err := dialer.NewPool(net.JoinHostPort(cfg.Transport.Host, strconv.Itoa(cfg.Transport.Port)), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	pfs, err := pool.Mount(cfg.TreeConn.Share1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pfs.Umount()
+
+	testDir := fmt.Sprintf("testDir-%d-TestSessionPool", os.Getpid())
+	err = pfs.Mkdir(testDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.RemoveAll(testDir)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf(`%s\testFile%d`, testDir, i)
+			f, err := pfs.Create(name)
+			if err != nil {
+				errs <- err
+				return
+			}
+			f.Close()
+			_, err = pfs.Stat(name)
+			if err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	fis, err := pfs.ReadDir(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) != 8 {
+		t.Errorf("expected 8 files, got %d", len(fis))
+	}
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	if session == nil {
+		t.Skip()
+	}
+	conn, err := net.Dial(cfg.Transport.Type, net.JoinHostPort(cfg.Transport.Host, strconv.Itoa(cfg.Transport.Port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	d := *dialer
+	d.MaxConcurrentRequests = 2
+
+	c, err := d.Dial(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Logoff()
+
+	cfs, err := c.Mount(cfg.TreeConn.Share1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cfs.Umount()
+
+	done := make(chan struct{})
+	maxInFlight := make(chan int, 1)
+	go func() {
+		max := 0
+		for {
+			select {
+			case <-done:
+				maxInFlight <- max
+				return
+			default:
+			}
+			if n := c.Stats().InFlightRequests; n > max {
+				max = n
+			}
+			runtime.Gosched()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cfs.Stat("")
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+
+	if max := <-maxInFlight; max > 2 {
+		t.Errorf("expected at most 2 in-flight requests, got %d", max)
+	}
+	if n := c.Stats().InFlightRequests; n != 0 {
+		t.Errorf("expected no in-flight requests, got %d", n)
+	}
+}
+
+func TestReadAtBuffer(t *testing.T) {
+	if fs == nil {
+		t.Skip()
+	}
+	testDir := fmt.Sprintf("testDir-%d-TestReadAtBuffer", os.Getpid())
+	err := fs.Mkdir(testDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.RemoveAll(testDir)
+
+	data := make([]byte, 3<<20+123)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	err = fs.WriteFile(testDir+`\testFile`, data, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fs.Open(testDir + `\testFile`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, off := range []int64{0, 1, 64 << 10, 2 << 20} {
+		buf := make([]byte, len(data))
+
+		n, err := f.ReadAtBuffer(buf, off)
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		if n != len(data)-int(off) {
+			t.Errorf("expected %d bytes, got %d", len(data)-int(off), n)
+		}
+		if !bytes.Equal(buf[:n], data[off:]) {
+			t.Errorf("unexpected content at offset %d", off)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package smb2
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// snapshotTokenFormat is the layout of @GMT tokens used to name snapshots (previous versions).
+const snapshotTokenFormat = "@GMT-2006.01.02-15.04.05"
+
+// ListSnapshots returns the timestamps of the snapshots (previous versions) available for name
+// via FSCTL_SRV_ENUMERATE_SNAPSHOTS. The result is sorted from the oldest to the newest.
+func (fs *Share) ListSnapshots(name string) ([]time.Time, error) {
+	name, err := cleanPath("listsnapshots", name)
+	if err != nil {
+		return nil, err
+	}
+
+	create := &CreateRequest{
+		SecurityFlags:        0,
+		RequestedOplockLevel: SMB2_OPLOCK_LEVEL_NONE,
+		ImpersonationLevel:   Impersonation,
+		SmbCreateFlags:       0,
+		DesiredAccess:        FILE_READ_DATA | FILE_READ_ATTRIBUTES | SYNCHRONIZE,
+		FileAttributes:       FILE_ATTRIBUTE_NORMAL,
+		ShareAccess:          FILE_SHARE_READ | FILE_SHARE_WRITE | FILE_SHARE_DELETE,
+		CreateDisposition:    FILE_OPEN,
+		CreateOptions:        0,
+	}
+
+	f, err := fs.createFile(name, create, true)
+	if err != nil {
+		return nil, &os.PathError{Op: "listsnapshots", Path: name, Err: err}
+	}
+
+	snaps, err := f.listSnapshots()
+	if e := f.close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "listsnapshots", Path: name, Err: err}
+	}
+	return snaps, nil
+}
+
+// OpenSnapshot opens the named file for reading as it was at the snapshot snap.
+// snap should be one of the timestamps returned by func (*Share) ListSnapshots.
+// The file is opened by prefixing name with the @GMT token of the snapshot.
+func (fs *Share) OpenSnapshot(name string, snap time.Time) (*File, error) {
+	// the name is cleaned before it's prefixed, so that ".." can't leave the snapshot
+	name, err := cleanPath("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	token := snap.UTC().Format(snapshotTokenFormat)
+	if name != "" {
+		token += `\` + name
+	}
+
+	return fs.Open(token)
+}
+
+func (f *File) listSnapshots() ([]time.Time, error) {
+	// the first request only retrieves the size of the array
+	output, err := f.enumerateSnapshots(16)
+	if err != nil {
+		return nil, err
+	}
+
+	r := SrvSnapshotArrayDecoder(output)
+	if r.IsInvalid() {
+		return nil, &InvalidResponseError{"broken srv snapshot array format"}
+	}
+
+	if r.NumberOfSnapShots() != 0 && r.NumberOfSnapShotsReturned() < r.NumberOfSnapShots() {
+		output, err = f.enumerateSnapshots(12 + int(r.SnapShotArraySize()))
+		if err != nil {
+			return nil, err
+		}
+
+		r = SrvSnapshotArrayDecoder(output)
+		if r.IsInvalid() {
+			return nil, &InvalidResponseError{"broken srv snapshot array format"}
+		}
+	}
+
+	var snaps []time.Time
+	for _, token := range r.SnapShots() {
+		t, err := time.Parse(snapshotTokenFormat, token)
+		if err != nil {
+			return nil, &InvalidResponseError{"broken srv snapshot array format"}
+		}
+		snaps = append(snaps, t)
+	}
+
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Before(snaps[j]) })
+
+	return snaps, nil
+}
+
+func (f *File) enumerateSnapshots(maxOutputResponse int) ([]byte, error) {
+	if maxOutputResponse > f.maxTransactSize() {
+		return nil, &InternalError{"snapshot array exceeds max transact size"}
+	}
+
+	req := &IoctlRequest{
+		CtlCode:           FSCTL_SRV_ENUMERATE_SNAPSHOTS,
+		OutputOffset:      0,
+		OutputCount:       0,
+		MaxInputResponse:  0,
+		MaxOutputResponse: uint32(maxOutputResponse),
+		Flags:             SMB2_0_IOCTL_IS_FSCTL,
+	}
+
+	return f.ioctl(req)
+}
@@ -2,6 +2,7 @@ package smb2
 
 import (
 	"encoding/asn1"
+	"errors"
 
 	"github.com/nodauf/go-smb2/internal/spnego"
 )
@@ -10,6 +11,8 @@ type spnegoClient struct {
 	mechs        []Initiator
 	mechTypes    []asn1.ObjectIdentifier
 	selectedMech Initiator
+
+	onToken func(step string, token []byte) // called with the tokens of the mechanism, see Dialer.OnAuthToken
 }
 
 func newSpnegoClient(mechs []Initiator) *spnegoClient {
@@ -32,6 +35,7 @@ func (c *spnegoClient) initSecContext() (negTokenInitBytes []byte, err error) {
 	if err != nil {
 		return nil, err
 	}
+	c.notify(AuthTokenNegotiate, mechToken)
 	negTokenInitBytes, err = spnego.EncodeNegTokenInit(c.mechTypes, mechToken)
 	if err != nil {
 		return nil, err
@@ -45,17 +49,36 @@ func (c *spnegoClient) acceptSecContext(negTokenRespBytes []byte) (negTokenRespB
 		return nil, err
 	}
 
-	for i, mechType := range c.mechTypes {
-		if mechType.Equal(negTokenResp.SupportedMech) {
-			c.selectedMech = c.mechs[i]
-			break
+	// only the first response is required to tell the selected mechanism
+	if c.selectedMech == nil || len(negTokenResp.SupportedMech) != 0 {
+		c.selectedMech = nil
+		for i, mechType := range c.mechTypes {
+			if mechType.Equal(negTokenResp.SupportedMech) {
+				c.selectedMech = c.mechs[i]
+				break
+			}
+		}
+		if c.selectedMech == nil {
+			return nil, errors.New("spnego: no supported mechanism selected")
 		}
 	}
 
+	// the mechanism has completed, there is nothing to send anymore
+	if len(negTokenResp.ResponseToken) == 0 {
+		return nil, nil
+	}
+
+	c.notify(AuthTokenChallenge, negTokenResp.ResponseToken)
+
 	responseToken, err := c.selectedMech.acceptSecContext(negTokenResp.ResponseToken)
 	if err != nil {
 		return nil, err
 	}
+	if len(responseToken) == 0 {
+		return nil, nil
+	}
+
+	c.notify(AuthTokenAuthenticate, responseToken)
 
 	ms, err := asn1.Marshal(c.mechTypes)
 	if err != nil {
@@ -72,6 +95,13 @@ func (c *spnegoClient) acceptSecContext(negTokenRespBytes []byte) (negTokenRespB
 	return negTokenRespBytes1, nil
 }
 
+// notify passes a copy of the token to onToken if it's set.
+func (c *spnegoClient) notify(step string, token []byte) {
+	if c.onToken != nil {
+		c.onToken(step, append([]byte{}, token...))
+	}
+}
+
 func (c *spnegoClient) sum(bs []byte) []byte {
 	return c.selectedMech.sum(bs)
 }
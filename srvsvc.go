@@ -0,0 +1,135 @@
+package smb2
+
+import (
+	"os"
+
+	"github.com/nodauf/go-smb2/internal/msrpc"
+)
+
+// Platform ids of ServerInfo101 and WorkstationInfo. (See [MS-SRVS] 2.2.4.41)
+const (
+	PlatformIdDOS = 300
+	PlatformIdOS2 = 400
+	PlatformIdNT  = 500
+	PlatformIdOSF = 600
+	PlatformIdVMS = 700
+)
+
+// Server types of ServerInfo101. (See [MS-SRVS] 2.2.2.7)
+const (
+	ServerTypeWorkstation      = 0x00000001
+	ServerTypeServer           = 0x00000002
+	ServerTypeSQLServer        = 0x00000004
+	ServerTypeDomainCtrl       = 0x00000008
+	ServerTypeDomainBakCtrl    = 0x00000010
+	ServerTypeTimeSource       = 0x00000020
+	ServerTypeAFP              = 0x00000040
+	ServerTypeNovell           = 0x00000080
+	ServerTypeDomainMember     = 0x00000100
+	ServerTypePrintQueue       = 0x00000200
+	ServerTypeDialinServer     = 0x00000400
+	ServerTypeXenixServer      = 0x00000800
+	ServerTypeNT               = 0x00001000
+	ServerTypeWFW              = 0x00002000
+	ServerTypeServerMFPN       = 0x00004000
+	ServerTypeServerNT         = 0x00008000
+	ServerTypePotentialBrowser = 0x00010000
+	ServerTypeBackupBrowser    = 0x00020000
+	ServerTypeMasterBrowser    = 0x00040000
+	ServerTypeDomainMaster     = 0x00080000
+	ServerTypeWindows          = 0x00400000
+	ServerTypeDFS              = 0x00800000
+	ServerTypeClusterNT        = 0x01000000
+	ServerTypeTerminalServer   = 0x02000000
+	ServerTypeClusterVSNT      = 0x04000000
+	ServerTypeDCE              = 0x10000000
+)
+
+// ServerInfo101 represents the SERVER_INFO_101 returned by func (*Session) ServerInfo101.
+type ServerInfo101 struct {
+	PlatformId   uint32 // e.g. PlatformIdNT
+	Name         string // NetBIOS name of the server
+	VersionMajor uint32 // e.g. 10 for Windows 10 and Windows Server 2016 or later
+	VersionMinor uint32
+	Type         uint32 // ServerType* flags
+	Comment      string
+}
+
+// ServerInfo101 returns the information the server advertises about itself
+// by calling NetrServerGetInfo over the SRVSVC named pipe.
+func (c *Session) ServerInfo101() (*ServerInfo101, error) {
+	r, err := c.openRPC("srvsvc", msrpc.SRVSVC_UUID, msrpc.SRVSVC_VERSION, msrpc.SRVSVC_VERSION_MINOR)
+	if err != nil {
+		return nil, err
+	}
+	defer r.close()
+
+	req := &msrpc.NetrServerGetInfoRequest{
+		ServerName: hostname(c.addr),
+		Level:      101,
+	}
+
+	stub, err := r.call(msrpc.OP_NETR_SERVER_GET_INFO, req.Marshal())
+	if err != nil {
+		return nil, &os.PathError{Op: "serverInfo", Path: r.f.name, Err: err}
+	}
+
+	var res msrpc.NetrServerGetInfoResponse
+	if !res.Unmarshal(stub) {
+		return nil, &os.PathError{Op: "serverInfo", Path: r.f.name, Err: &InvalidResponseError{"broken server get info response format"}}
+	}
+	if res.Status != 0 {
+		return nil, &os.PathError{Op: "serverInfo", Path: r.f.name, Err: &RPCError{Op: "NetrServerGetInfo", Status: res.Status}}
+	}
+	if res.Info == nil {
+		return nil, &os.PathError{Op: "serverInfo", Path: r.f.name, Err: &InvalidResponseError{"broken server get info response format"}}
+	}
+
+	info := ServerInfo101(*res.Info)
+
+	return &info, nil
+}
+
+// WorkstationInfo represents the WKSTA_INFO_100 returned by func (*Session) WorkstationInfo.
+type WorkstationInfo struct {
+	PlatformId   uint32 // e.g. PlatformIdNT
+	ComputerName string // NetBIOS name of the computer
+	LanGroup     string // domain or workgroup the computer belongs to
+	VersionMajor uint32
+	VersionMinor uint32
+}
+
+// WorkstationInfo returns the computer name, the domain and the version of the server
+// by calling NetrWkstaGetInfo over the WKSSVC named pipe.
+func (c *Session) WorkstationInfo() (*WorkstationInfo, error) {
+	r, err := c.openRPC("wkssvc", msrpc.WKSSVC_UUID, msrpc.WKSSVC_VERSION, msrpc.WKSSVC_VERSION_MINOR)
+	if err != nil {
+		return nil, err
+	}
+	defer r.close()
+
+	req := &msrpc.NetrWkstaGetInfoRequest{
+		ServerName: hostname(c.addr),
+		Level:      100,
+	}
+
+	stub, err := r.call(msrpc.OP_NETR_WKSTA_GET_INFO, req.Marshal())
+	if err != nil {
+		return nil, &os.PathError{Op: "workstationInfo", Path: r.f.name, Err: err}
+	}
+
+	var res msrpc.NetrWkstaGetInfoResponse
+	if !res.Unmarshal(stub) {
+		return nil, &os.PathError{Op: "workstationInfo", Path: r.f.name, Err: &InvalidResponseError{"broken wksta get info response format"}}
+	}
+	if res.Status != 0 {
+		return nil, &os.PathError{Op: "workstationInfo", Path: r.f.name, Err: &RPCError{Op: "NetrWkstaGetInfo", Status: res.Status}}
+	}
+	if res.Info == nil {
+		return nil, &os.PathError{Op: "workstationInfo", Path: r.f.name, Err: &InvalidResponseError{"broken wksta get info response format"}}
+	}
+
+	info := WorkstationInfo(*res.Info)
+
+	return &info, nil
+}
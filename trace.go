@@ -0,0 +1,52 @@
+package smb2
+
+import (
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// Message describes a request sent or a response received by a session, for Dialer.OnMessage.
+// The ids are the ones of the SMB2 header, so that the calls can be correlated with packet captures
+// (e.g. by the smb2.msg_id field of Wireshark). (See [MS-SMB2] 2.2.1)
+type Message struct {
+	Response bool // false for a request, true for a response or a notification of the server
+
+	Command   uint16 // e.g. 0x0005 for CREATE (See [MS-SMB2] 2.2.1.2)
+	MessageId uint64 // 0xFFFFFFFFFFFFFFFF for an oplock break notification
+	AsyncId   uint64 // nonzero for the responses of the requests the server handles asynchronously
+	SessionId uint64
+	TreeId    uint32 // zero if AsyncId is set, since they share the header field
+	Status    uint32 // NTSTATUS of a response, zero for a request
+
+	CreditCharge uint16
+	Credits      uint16 // credits requested by a request, or granted by a response
+}
+
+// traceMessage passes the header of the plaintext packet pkt to Dialer.OnMessage, if it's set.
+func (conn *conn) traceMessage(pkt []byte, response bool) {
+	if conn.onMessage == nil {
+		return
+	}
+
+	p := PacketCodec(pkt)
+
+	m := Message{
+		Response:     response,
+		Command:      p.Command(),
+		MessageId:    p.MessageId(),
+		SessionId:    p.SessionId(),
+		CreditCharge: p.CreditCharge(),
+		Credits:      p.CreditRequest(),
+	}
+
+	if p.Flags()&SMB2_FLAGS_ASYNC_COMMAND != 0 {
+		m.AsyncId = p.AsyncId()
+	} else {
+		m.TreeId = p.TreeId()
+	}
+
+	if response {
+		m.Status = p.Status()
+	}
+
+	conn.onMessage(m)
+}
@@ -1,19 +1,29 @@
 package smb2
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"sync/atomic"
 
+	. "github.com/nodauf/go-smb2/internal/erref"
 	. "github.com/nodauf/go-smb2/internal/smb2"
 )
 
 type treeConn struct {
 	*session
 	treeId     uint32
+	path       string // e.g. `\\server\share`
+	shareType  uint8
 	shareFlags uint32
 
-	// path string
-	// shareType  uint8
+	// chunk sizes set by func (*Share) SetIOChunkSize, zero means the negotiated max size
+	_readChunkSize  int32
+	_writeChunkSize int32
+
+	_dirInfoLevel int32 // index of the dirInfoClasses accepted by the server (accessed atomically)
+
 	// capabilities uint32
 	// maximalAccess uint32
 }
@@ -24,7 +34,12 @@ func treeConnect(s *session, path string, flags uint16, ctx context.Context) (*t
 		Path:  path,
 	}
 
-	req.CreditCharge = 1
+	var err error
+
+	req.CreditCharge, _, err = s.loanCredit(0, ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	rr, err := s.send(req, ctx)
 	if err != nil {
@@ -32,12 +47,23 @@ func treeConnect(s *session, path string, flags uint16, ctx context.Context) (*t
 	}
 
 	pkt, err := s.recv(rr)
+	if err == nil && s.expired(rr, pkt) {
+		rr, err = s.resend(rr)
+		if err == nil {
+			pkt, err = s.recv(rr)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	res, err := accept(SMB2_TREE_CONNECT, pkt)
 	if err != nil {
+		if err == os.ErrPermission && s.encrypter == nil && s.dialect < SMB300 {
+			// servers rejecting unencrypted access (e.g. Windows with RejectUnencryptedAccess) deny the tree connect
+			// of the clients which can't encrypt rather than telling that the share requires encryption
+			return nil, &os.PathError{Op: "mount", Path: path, Err: &EncryptionMismatchError{Dialect: s.dialect}}
+		}
 		return nil, err
 	}
 
@@ -49,20 +75,34 @@ func treeConnect(s *session, path string, flags uint16, ctx context.Context) (*t
 	tc := &treeConn{
 		session:    s,
 		treeId:     PacketCodec(pkt).TreeId(),
+		shareType:  r.ShareType(),
 		shareFlags: r.ShareFlags(),
-		// path:    path,
-		// shareType:  r.ShareType(),
+		path:       path,
 		// capabilities: r.Capabilities(),
 		// maximalAccess: r.MaximalAccess(),
 	}
 
+	if tc.shareFlags&SMB2_SHAREFLAG_ENCRYPT_DATA != 0 && s.encrypter == nil {
+		// the server rejects any unencrypted request on this tree, even the disconnect
+		return nil, ErrEncryptionRequired
+	}
+
+	s.addTree(tc)
+
 	return tc, nil
 }
 
 func (tc *treeConn) disconnect(ctx context.Context) error {
+	defer tc.removeTree(tc)
+
 	req := new(TreeDisconnectRequest)
 
-	req.CreditCharge = 1
+	var err error
+
+	req.CreditCharge, _, err = tc.loanCredit(0, ctx)
+	if err != nil {
+		return err
+	}
 
 	res, err := tc.sendRecv(SMB2_TREE_DISCONNECT, req, ctx)
 	if err != nil {
@@ -77,6 +117,81 @@ func (tc *treeConn) disconnect(ctx context.Context) error {
 	return nil
 }
 
+// validateNegotiate asks the server what it received and sent during the negotiation,
+// and fails with ErrNegotiateMismatch if a man in the middle changed it (e.g. downgraded the dialect).
+// The answer is trusted because it's signed by the session key.
+func (tc *treeConn) validateNegotiate(ctx context.Context) error {
+	req := &IoctlRequest{
+		CtlCode: FSCTL_VALIDATE_NEGOTIATE_INFO,
+		FileId: &FileId{
+			Persistent: [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			Volatile:   [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		},
+		MaxOutputResponse: 24,
+		Flags:             SMB2_0_IOCTL_IS_FSCTL,
+		Input: &ValidateNegotiateInfoRequest{
+			Capabilities: clientCapabilities,
+			Guid:         tc.clientGuid,
+			SecurityMode: tc.clientSecurityMode,
+			Dialects:     tc.clientDialects,
+		},
+	}
+
+	var err error
+
+	req.CreditCharge, _, err = tc.loanCredit(0, ctx)
+	if err != nil {
+		return err
+	}
+
+	rr, err := tc.send(req, ctx)
+	if err != nil {
+		return err
+	}
+
+	pkt, err := tc.recv(rr)
+	if err != nil {
+		return err
+	}
+
+	// encrypted responses aren't signed, but they're authenticated by the decryption
+	encrypted := tc.sessionFlags&SMB2_SESSION_FLAG_ENCRYPT_DATA != 0 || tc.shareFlags&SMB2_SHAREFLAG_ENCRYPT_DATA != 0
+	if !encrypted && PacketCodec(pkt).Flags()&SMB2_FLAGS_SIGNED == 0 {
+		return ErrSignatureMismatch
+	}
+
+	res, err := accept(SMB2_IOCTL, pkt)
+	if err != nil {
+		if rerr, ok := err.(*ResponseError); ok {
+			switch NtStatus(rerr.Code) {
+			case STATUS_NOT_SUPPORTED, STATUS_INVALID_DEVICE_REQUEST:
+				// the server doesn't implement the validation, but the refusal is signed
+				return nil
+			}
+		}
+		return err
+	}
+
+	r := IoctlResponseDecoder(res)
+	if r.IsInvalid() {
+		return &InvalidResponseError{"broken ioctl response format"}
+	}
+
+	info := ValidateNegotiateInfoResponseDecoder(r.Output())
+	if info.IsInvalid() {
+		return &InvalidResponseError{"broken validate negotiate info format"}
+	}
+
+	if info.Capabilities() != tc.serverCapabilities ||
+		!bytes.Equal(info.Guid(), tc.serverGuid[:]) ||
+		info.SecurityMode() != tc.serverSecurityMode ||
+		info.Dialect() != tc.dialect {
+		return ErrNegotiateMismatch
+	}
+
+	return nil
+}
+
 func (tc *treeConn) sendRecv(cmd uint16, req Packet, ctx context.Context) (res []byte, err error) {
 	rr, err := tc.send(req, ctx)
 	if err != nil {
@@ -100,6 +215,13 @@ func (tc *treeConn) recv(rr *requestResponse) (pkt []byte, err error) {
 	if err != nil {
 		return nil, err
 	}
+	if tc.expired(rr, pkt) {
+		rr, err = tc.resend(rr)
+		if err != nil {
+			return nil, err
+		}
+		return tc.recv(rr)
+	}
 	if rr.asyncId != 0 {
 		if asyncId := PacketCodec(pkt).AsyncId(); asyncId != rr.asyncId {
 			return nil, &InvalidResponseError{fmt.Sprintf("expected async id: %v, got %v", rr.asyncId, asyncId)}
@@ -111,3 +233,16 @@ func (tc *treeConn) recv(rr *requestResponse) (pkt []byte, err error) {
 	}
 	return pkt, err
 }
+
+func (tc *treeConn) readChunkSize() int {
+	return int(atomic.LoadInt32(&tc._readChunkSize))
+}
+
+func (tc *treeConn) writeChunkSize() int {
+	return int(atomic.LoadInt32(&tc._writeChunkSize))
+}
+
+func (tc *treeConn) setChunkSize(read, write int) {
+	atomic.StoreInt32(&tc._readChunkSize, int32(read))
+	atomic.StoreInt32(&tc._writeChunkSize, int32(write))
+}
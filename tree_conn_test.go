@@ -0,0 +1,429 @@
+package smb2
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/nodauf/go-smb2/internal/erref"
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+// newTestValidateNegotiateConn returns a tree of a signed SMB 3.0.2 session over tr,
+// which negotiated with the values echoed by newTestValidateNegotiateInfo.
+func newTestValidateNegotiateConn(tr transport) *treeConn {
+	conn := newSignedTestConn(tr)
+	conn.dialect = SMB302
+	conn.clientGuid = [16]byte{1, 2, 3}
+	conn.clientSecurityMode = SMB2_NEGOTIATE_SIGNING_ENABLED
+	conn.clientDialects = []uint16{SMB202, SMB210, SMB300, SMB302}
+	conn.serverGuid = [16]byte{4, 5, 6}
+	conn.serverSecurityMode = SMB2_NEGOTIATE_SIGNING_ENABLED
+	conn.serverCapabilities = SMB2_GLOBAL_CAP_LARGE_MTU
+
+	return &treeConn{session: conn.session, treeId: 1}
+}
+
+// newTestValidateNegotiateInfo returns the output of FSCTL_VALIDATE_NEGOTIATE_INFO matching newTestValidateNegotiateConn.
+func newTestValidateNegotiateInfo() []byte {
+	info := make([]byte, 24)
+	binary.LittleEndian.PutUint32(info[:4], SMB2_GLOBAL_CAP_LARGE_MTU)
+	copy(info[4:20], []byte{4, 5, 6})
+	binary.LittleEndian.PutUint16(info[20:22], SMB2_NEGOTIATE_SIGNING_ENABLED)
+	binary.LittleEndian.PutUint16(info[22:24], SMB302)
+	return info
+}
+
+// newTestIoctlResponse returns a response to the ioctl request req, with output or status if it isn't zero.
+func newTestIoctlResponse(s *session, req []byte, output []byte, status NtStatus, sign bool) []byte {
+	q := PacketCodec(req)
+
+	hdr := PacketHeader{
+		Command:               SMB2_IOCTL,
+		CreditRequestResponse: q.CreditCharge(),
+		Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+		MessageId:             q.MessageId(),
+		TreeId:                q.TreeId(),
+		SessionId:             q.SessionId(),
+		Status:                uint32(status),
+	}
+
+	var pkt []byte
+	if status != STATUS_SUCCESS {
+		res := &ErrorResponse{PacketHeader: hdr}
+		pkt = make([]byte, res.Size())
+		res.Encode(pkt)
+	} else {
+		pkt = newFakeResponse(req, hdr.CreditRequestResponse)
+		pkt = append(pkt[:64], make([]byte, 48+len(output))...)
+
+		r := pkt[64:]
+		binary.LittleEndian.PutUint16(r[:2], 49) // StructureSize
+		binary.LittleEndian.PutUint32(r[4:8], FSCTL_VALIDATE_NEGOTIATE_INFO)
+		binary.LittleEndian.PutUint32(r[32:36], 64+48)               // OutputOffset
+		binary.LittleEndian.PutUint32(r[36:40], uint32(len(output))) // OutputCount
+		copy(r[48:], output)
+	}
+
+	if sign {
+		s.sign(pkt)
+	}
+
+	return pkt
+}
+
+func TestValidateNegotiate(t *testing.T) {
+	mismatched := func(f func(info []byte)) []byte {
+		info := newTestValidateNegotiateInfo()
+		f(info)
+		return info
+	}
+
+	tests := []struct {
+		name           string
+		requireSigning bool
+		output         []byte
+		status         NtStatus
+		unsigned       bool
+		err            error
+	}{
+		{name: "match", requireSigning: true, output: newTestValidateNegotiateInfo()},
+		{
+			name:           "dialect",
+			requireSigning: true,
+			output:         mismatched(func(info []byte) { binary.LittleEndian.PutUint16(info[22:24], SMB300) }),
+			err:            ErrNegotiateMismatch,
+		},
+		{
+			name:           "capabilities",
+			requireSigning: true,
+			output:         mismatched(func(info []byte) { binary.LittleEndian.PutUint32(info[:4], 0) }),
+			err:            ErrNegotiateMismatch,
+		},
+		{
+			name:           "guid",
+			requireSigning: true,
+			output:         mismatched(func(info []byte) { info[4] = 0 }),
+			err:            ErrNegotiateMismatch,
+		},
+		{
+			name:           "security mode",
+			requireSigning: true,
+			output:         mismatched(func(info []byte) { binary.LittleEndian.PutUint16(info[20:22], SMB2_NEGOTIATE_SIGNING_REQUIRED) }),
+			err:            ErrNegotiateMismatch,
+		},
+		{name: "not supported", requireSigning: true, status: STATUS_NOT_SUPPORTED},
+		{name: "unsigned", output: newTestValidateNegotiateInfo(), unsigned: true, err: ErrSignatureMismatch},
+		{name: "unsigned refusal", status: STATUS_NOT_SUPPORTED, unsigned: true, err: ErrSignatureMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newFakeTransport()
+			defer tr.Close()
+
+			tc := newTestValidateNegotiateConn(tr)
+			tc.requireSigning = tt.requireSigning
+
+			var input []byte
+
+			tr.handler = func(req []byte) {
+				r := IoctlRequestDecoder(PacketCodec(req).Data())
+				off := int(r.InputOffset()) - 64
+				input = append([]byte{}, PacketCodec(req).Data()[off:off+int(r.InputCount())]...)
+				tr.push(newTestIoctlResponse(tc.session, req, tt.output, tt.status, !tt.unsigned))
+			}
+
+			err := tc.validateNegotiate(context.Background())
+			if err != tt.err {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+
+			if len(input) != 24+2*4 {
+				t.Fatalf("unexpected input size: %d", len(input))
+			}
+			if dialects := binary.LittleEndian.Uint16(input[22:24]); dialects != 4 {
+				t.Errorf("expected 4 dialects, got %d", dialects)
+			}
+			if input[4] != 1 || input[5] != 2 || input[6] != 3 {
+				t.Errorf("unexpected client guid: %x", input[4:20])
+			}
+		})
+	}
+}
+
+func TestValidateNegotiateLogoff(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	tc := newTestValidateNegotiateConn(tr)
+	s := tc.session
+
+	var cmds []uint16
+
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+		cmds = append(cmds, q.Command())
+
+		hdr := PacketHeader{
+			Command:               q.Command(),
+			CreditRequestResponse: 1,
+			Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+			MessageId:             q.MessageId(),
+			SessionId:             q.SessionId(),
+			TreeId:                q.TreeId(),
+		}
+
+		var res Packet
+		switch q.Command() {
+		case SMB2_IOCTL:
+			info := newTestValidateNegotiateInfo()
+			binary.LittleEndian.PutUint16(info[22:24], SMB300)
+			tr.push(newTestIoctlResponse(s, req, info, 0, true))
+			return
+		case SMB2_TREE_CONNECT:
+			hdr.TreeId = 1
+			res = &TreeConnectResponse{PacketHeader: hdr, ShareType: SMB2_SHARE_TYPE_PIPE}
+		case SMB2_TREE_DISCONNECT:
+			res = &TreeDisconnectResponse{PacketHeader: hdr}
+		case SMB2_LOGOFF:
+			res = &LogoffResponse{PacketHeader: hdr}
+		}
+		pkt := make([]byte, res.Size())
+		res.Encode(pkt)
+		PacketCodec(pkt).SetCommand(q.Command())
+		s.sign(pkt)
+		tr.push(pkt)
+	}
+
+	err := s.validateNegotiate("server", context.Background())
+	if err != ErrNegotiateMismatch {
+		t.Fatalf("expected ErrNegotiateMismatch, got %v", err)
+	}
+
+	expected := []uint16{SMB2_TREE_CONNECT, SMB2_IOCTL, SMB2_TREE_DISCONNECT, SMB2_LOGOFF}
+	if !reflect.DeepEqual(cmds, expected) {
+		t.Errorf("expected commands %v, got %v", expected, cmds)
+	}
+}
+
+func TestTreeConnectEncryptionMismatch(t *testing.T) {
+	for _, dialect := range []uint16{SMB210, SMB311} {
+		tr := newFakeTransport()
+
+		conn := newSignedTestConn(tr)
+		conn.dialect = dialect
+
+		tr.handler = func(req []byte) {
+			q := PacketCodec(req)
+			res := &ErrorResponse{
+				PacketHeader: PacketHeader{
+					Command:               SMB2_TREE_CONNECT,
+					CreditRequestResponse: 1,
+					Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+					MessageId:             q.MessageId(),
+					SessionId:             q.SessionId(),
+					Status:                uint32(STATUS_ACCESS_DENIED),
+				},
+			}
+			pkt := make([]byte, res.Size())
+			res.Encode(pkt)
+			PacketCodec(pkt).SetCommand(SMB2_TREE_CONNECT)
+			conn.session.sign(pkt)
+			tr.push(pkt)
+		}
+
+		_, err := treeConnect(conn.session, `\\server\secure`, 0, context.Background())
+		switch dialect {
+		case SMB210:
+			e, ok := err.(*os.PathError)
+			if !ok || e.Op != "mount" || e.Path != `\\server\secure` {
+				t.Fatalf("expected a mount error, got %v", err)
+			}
+			if merr, ok := e.Err.(*EncryptionMismatchError); !ok || merr.Dialect != SMB210 || !strings.Contains(merr.Error(), "2.1") {
+				t.Errorf("expected an encryption mismatch error, got %v", e.Err)
+			}
+			// denied Mounts used to be permission errors, and still are for errors.Is
+			if !errors.Is(err, os.ErrPermission) {
+				t.Errorf("expected a permission error, got %v", err)
+			}
+		default:
+			// SMB 3.x servers tell that the share requires encryption, so the access is really denied
+			if err != os.ErrPermission {
+				t.Errorf("expected a permission error, got %v", err)
+			}
+		}
+
+		tr.Close()
+	}
+}
+
+func TestMountEncryptionRequired(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	fs := newFakeShare(tr)
+
+	var cmds []uint16
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+		cmds = append(cmds, q.Command())
+
+		res := &TreeConnectResponse{
+			PacketHeader: PacketHeader{
+				Command:               q.Command(),
+				CreditRequestResponse: 1,
+				Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+				MessageId:             q.MessageId(),
+				SessionId:             q.SessionId(),
+				TreeId:                2,
+			},
+			ShareType:  SMB2_SHARE_TYPE_DISK,
+			ShareFlags: SMB2_SHAREFLAG_ENCRYPT_DATA,
+		}
+		pkt := make([]byte, res.Size())
+		res.Encode(pkt)
+		PacketCodec(pkt).SetCommand(q.Command())
+		tr.push(pkt)
+	}
+
+	// the session of the fake share has no cipher
+	c := &Session{s: fs.session, ctx: context.Background(), addr: "server:445"}
+
+	if _, err := c.Mount("secure"); err != ErrEncryptionRequired {
+		t.Fatalf("expected ErrEncryptionRequired, got %v", err)
+	}
+
+	// the tree isn't disconnected, since the server would reject the unencrypted request
+	if !reflect.DeepEqual(cmds, []uint16{SMB2_TREE_CONNECT}) {
+		t.Errorf("unexpected requests %v", cmds)
+	}
+	if n := len(c.Shares()); n != 1 {
+		t.Errorf("expected the share of the encrypting tree not to be mounted, got %d shares", n)
+	}
+
+	// a session requiring encryption fails its requests before sending them
+	fs.session.sessionFlags |= SMB2_SESSION_FLAG_ENCRYPT_DATA
+
+	_, err := fs.Stat("file")
+	if perr, ok := err.(*os.PathError); !ok || perr.Err != ErrEncryptionRequired {
+		t.Errorf("expected ErrEncryptionRequired, got %v", err)
+	}
+	if !reflect.DeepEqual(cmds, []uint16{SMB2_TREE_CONNECT}) {
+		t.Errorf("unexpected requests %v", cmds)
+	}
+}
+
+func TestMountConcurrent(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	fs := newFakeShare(tr)
+	fs.path = `\\server\share`
+
+	var cmds []uint16
+	nextTreeId := uint32(2)
+
+	// the handler is called by the sender goroutine, which serializes the requests
+	tr.handler = func(req []byte) {
+		q := PacketCodec(req)
+		cmds = append(cmds, q.Command())
+
+		hdr := PacketHeader{
+			Command:               q.Command(),
+			CreditRequestResponse: 1,
+			Flags:                 SMB2_FLAGS_SERVER_TO_REDIR,
+			MessageId:             q.MessageId(),
+			SessionId:             q.SessionId(),
+			TreeId:                q.TreeId(),
+		}
+
+		var res Packet
+		switch q.Command() {
+		case SMB2_TREE_CONNECT:
+			hdr.TreeId = nextTreeId
+			nextTreeId++
+			res = &TreeConnectResponse{PacketHeader: hdr, ShareType: SMB2_SHARE_TYPE_DISK}
+		case SMB2_TREE_DISCONNECT:
+			res = &TreeDisconnectResponse{PacketHeader: hdr}
+		case SMB2_LOGOFF:
+			res = &LogoffResponse{PacketHeader: hdr}
+		}
+		pkt := make([]byte, res.Size())
+		res.Encode(pkt)
+		PacketCodec(pkt).SetCommand(q.Command())
+		tr.push(pkt)
+	}
+
+	c := &Session{s: fs.session, ctx: context.Background(), addr: "server:445"}
+
+	const n = 8
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := c.Mount(fmt.Sprintf("share%d", i))
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	shares := c.Shares()
+	if len(shares) != n+1 {
+		t.Fatalf("expected %d shares, got %d", n+1, len(shares))
+	}
+	names := make(map[string]bool)
+	for i, share := range shares {
+		if share.treeId != uint32(i+1) {
+			t.Errorf("expected tree id %d, got %d", i+1, share.treeId)
+		}
+		names[share.Name()] = true
+	}
+	for i := 0; i < n; i++ {
+		if name := fmt.Sprintf(`\\server\share%d`, i); !names[name] {
+			t.Errorf("%s isn't mounted", name)
+		}
+	}
+
+	if err := shares[1].Umount(); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Shares()) != n {
+		t.Errorf("expected the unmounted share to be forgotten")
+	}
+
+	cmds = nil
+
+	if err := c.Logoff(); err != nil {
+		t.Fatal(err)
+	}
+
+	var disconnects int
+	for _, cmd := range cmds {
+		if cmd == SMB2_TREE_DISCONNECT {
+			disconnects++
+		}
+	}
+	if disconnects != n || cmds[len(cmds)-1] != SMB2_LOGOFF {
+		t.Errorf("expected %d disconnects followed by a logoff, got %v", n, cmds)
+	}
+	if len(c.Shares()) != 0 {
+		t.Errorf("expected no share to be mounted after the logoff, got %d", len(c.Shares()))
+	}
+}
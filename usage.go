@@ -0,0 +1,156 @@
+package smb2
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// DiskUsageOptions configures func (*Share) DiskUsageWithOptions.
+type DiskUsageOptions struct {
+	// Concurrency is the number of directories enumerated concurrently.
+	// If it's less than 1, the directories are enumerated one at a time.
+	Concurrency int
+
+	// AllocationSize sums the sizes the files take on disk rather than their logical sizes,
+	// like du does without --apparent-size. They differ for sparse and compressed files.
+	AllocationSize bool
+}
+
+// DiskUsage walks the tree rooted at root and returns the number of files and the sum of their sizes, like du -s.
+// See func (*Share) DiskUsageWithOptions for more details.
+func (fs *Share) DiskUsage(ctx context.Context, root string, concurrency int) (files int64, bytes int64, err error) {
+	return fs.DiskUsageWithOptions(ctx, root, &DiskUsageOptions{Concurrency: concurrency})
+}
+
+// DiskUsageWithOptions walks the tree rooted at root and returns the number of files and the sum of their sizes,
+// as configured by opts. A nil opts uses the defaults.
+// The sizes come from the directory enumerations, so the files themselves aren't opened, and up to
+// opts.Concurrency directories are enumerated at a time, which hides the latency of high-latency links.
+// Directories aren't counted, and symbolic links and junctions are counted as files, but not followed.
+// A directory removed in the middle of the walk is skipped. Otherwise, the walk stops at the first error,
+// and the counts so far are returned with it. If ctx is done, they're returned with ctx.Err().
+func (fs *Share) DiskUsageWithOptions(ctx context.Context, root string, opts *DiskUsageOptions) (files int64, bytes int64, err error) {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	var o DiskUsageOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Concurrency < 1 {
+		o.Concurrency = 1
+	}
+
+	size := func(fi os.FileInfo) int64 {
+		if st, ok := fi.(*FileStat); ok && o.AllocationSize {
+			return st.AllocationSize
+		}
+		return fi.Size()
+	}
+
+	root, err = cleanPath("du", root)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fi, err := fs.WithContext(ctx).Stat(root)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, 0, ctx.Err()
+		}
+		return 0, 0, err
+	}
+	if !fi.IsDir() {
+		return 1, size(fi), nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := &diskUsageWalker{
+		fs:     fs,
+		ctx:    ctx,
+		cancel: cancel,
+		sem:    make(chan struct{}, o.Concurrency),
+		size:   size,
+	}
+
+	w.wg.Add(1)
+	go w.walk(root, true)
+	w.wg.Wait()
+
+	files, bytes = atomic.LoadInt64(&w.files), atomic.LoadInt64(&w.bytes)
+
+	if w.err != nil {
+		return files, bytes, w.err
+	}
+	return files, bytes, nil
+}
+
+// diskUsageWalker is the state of a walk of func (*Share) DiskUsageWithOptions.
+// Each directory is walked by its own goroutine, whose enumeration waits for a slot of sem.
+type diskUsageWalker struct {
+	fs     *Share
+	ctx    context.Context
+	cancel context.CancelFunc
+	sem    chan struct{}
+	size   func(os.FileInfo) int64
+
+	wg    sync.WaitGroup
+	files int64 // atomic
+	bytes int64 // atomic
+
+	m   sync.Mutex
+	err error // first error, which stops the walk
+}
+
+func (w *diskUsageWalker) walk(dir string, isRoot bool) {
+	defer w.wg.Done()
+
+	select {
+	case w.sem <- struct{}{}:
+	case <-w.ctx.Done():
+		w.fail(w.ctx.Err())
+		return
+	}
+
+	fis, err := w.fs.ReadDirContext(w.ctx, dir)
+
+	<-w.sem
+
+	if err != nil {
+		if os.IsNotExist(err) && !isRoot {
+			return
+		}
+		w.fail(err)
+		return
+	}
+
+	for _, fi := range fis {
+		if fi.IsDir() {
+			name := fi.Name()
+			if dir != "" {
+				name = dir + string(PathSeparator) + name
+			}
+			w.wg.Add(1)
+			go w.walk(name, false)
+			continue
+		}
+		atomic.AddInt64(&w.files, 1)
+		atomic.AddInt64(&w.bytes, w.size(fi))
+	}
+}
+
+// fail records the first error of the walk, and stops the other enumerations.
+func (w *diskUsageWalker) fail(err error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if w.err == nil {
+		w.err = err
+		w.cancel()
+	}
+}
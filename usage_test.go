@@ -0,0 +1,69 @@
+package smb2
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/nodauf/go-smb2/internal/smb2"
+)
+
+func TestDiskUsage(t *testing.T) {
+	tr := newFakeTransport()
+	defer tr.Close()
+
+	srv := &fakeTreeServer{
+		tr: tr,
+		entries: map[string]*fakeEntry{
+			`dir`:       {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\a`:     {attrs: FILE_ATTRIBUTE_ARCHIVE, size: 100, alloc: 4096},
+			`dir\b`:     {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\b\c`:   {attrs: FILE_ATTRIBUTE_ARCHIVE, size: 5000, alloc: 8192},
+			`dir\b\d`:   {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\b\d\e`: {attrs: FILE_ATTRIBUTE_SPARSE_FILE, size: 1 << 20, alloc: 4096},
+			`dir\f`:     {attrs: FILE_ATTRIBUTE_DIRECTORY},
+			`dir\link`:  {attrs: FILE_ATTRIBUTE_REPARSE_POINT | FILE_ATTRIBUTE_DIRECTORY, tag: IO_REPARSE_TAG_SYMLINK, link: `b`},
+			`other`:     {attrs: FILE_ATTRIBUTE_ARCHIVE, size: 7, alloc: 4096},
+		},
+	}
+	tr.handler = srv.handle
+
+	fs := newFakeShare(tr)
+	fs.conn.account.charge(15, 0)
+
+	tests := []struct {
+		root  string
+		opts  *DiskUsageOptions
+		files int64
+		bytes int64
+	}{
+		{`dir`, nil, 4, 100 + 5000 + 1<<20},
+		{`dir`, &DiskUsageOptions{Concurrency: 4}, 4, 100 + 5000 + 1<<20},
+		{`dir`, &DiskUsageOptions{Concurrency: 4, AllocationSize: true}, 4, 4096 + 8192 + 4096},
+		{`other`, nil, 1, 7},
+	}
+
+	for _, tt := range tests {
+		files, bytes, err := fs.DiskUsageWithOptions(context.Background(), tt.root, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if files != tt.files || bytes != tt.bytes {
+			t.Errorf("%s, %+v: expected %d files and %d bytes, got %d, %d", tt.root, tt.opts, tt.files, tt.bytes, files, bytes)
+		}
+	}
+
+	if n := atomic.LoadInt32(&fs.session._openFiles); n != 0 {
+		t.Errorf("expected the directories to be closed, %d are open", n)
+	}
+
+	if _, _, err := fs.DiskUsage(context.Background(), `missing`, 4); err == nil {
+		t.Error("expected an error for a missing root")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := fs.DiskUsage(ctx, `dir`, 4); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}